@@ -0,0 +1,61 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"os"
+
+	"github.com/ar4ie13/shortener/internal/clock"
+	"github.com/ar4ie13/shortener/internal/config"
+	"github.com/ar4ie13/shortener/internal/logger"
+	"github.com/ar4ie13/shortener/internal/repository"
+	"github.com/ar4ie13/shortener/internal/service"
+)
+
+// backfillHostSubcommand is the offline CLI subcommand name for backfilling the host column
+// on rows saved before it existed
+const backfillHostSubcommand = "backfill-host"
+
+// hostBackfiller is the subset of service.Service used by the backfill-host CLI subcommand
+type hostBackfiller interface {
+	BackfillHosts(ctx context.Context, limit int) (int, error)
+}
+
+// runBackfillHost initializes the configured backend and backfills the host column on rows
+// that were saved before it existed, reporting how many rows it touched
+func runBackfillHost() error {
+	cfg, err := config.NewConfig()
+	if err != nil {
+		return fmt.Errorf("invalid configuration: %w", err)
+	}
+	zlog := logger.NewLogger(cfg.GetLogLevel(), cfg.GetLogFormat())
+	repo, readiness, err := repository.NewRepository(context.Background(), cfg.FilePath, cfg.PostgresDSN, cfg.RedisDSN, cfg.S3, zlog.Logger, 0, cfg.GetMemorySnapshotPath(), 0)
+	if err != nil {
+		return fmt.Errorf("cannot initialize repository: %w", err)
+	}
+	readiness.Wait()
+	srv := service.NewService(repo, zlog.Logger, cfg.GetAvoidRecentSlugs(), cfg.GetDeleteFlushTimeout(), cfg.GetSlugAlphabet(), cfg.GetSlugLength(), clock.RealClock{}, cfg.GetLinkTTL(), cfg.GetSweepInterval(), cfg.GetSweepLimit(), cfg.GetDeleteGracePeriod(), cfg.GetHTTPSUpgradeHosts(), cfg.GetDeleteQueueLimit(), cfg.GetAutoExtendSlug(), cfg.GetAnonymousLinkTTL(), cfg.GetPrivateLinks(), zlog.Logger, cfg.GetDeleteWorkers(), cfg.GetSlugStrategy(), cfg.GetCacheSize(), cfg.GetCacheTTL(), nil, cfg.GetTrackingParams(), cfg.GetEventBufferSize())
+	defer func() { _ = srv.Close() }()
+	defer func() { _ = repo.Close() }()
+
+	return backfillHost(context.Background(), srv, os.Stdout)
+}
+
+// backfillHost repeatedly backfills hosts in batches until none remain, printing the running
+// total to out
+func backfillHost(ctx context.Context, srv hostBackfiller, out *os.File) error {
+	total := 0
+	for {
+		backfilled, err := srv.BackfillHosts(ctx, 0)
+		if err != nil {
+			return fmt.Errorf("failed to backfill hosts: %w", err)
+		}
+		total += backfilled
+		if backfilled == 0 {
+			break
+		}
+	}
+
+	fmt.Fprintf(out, "backfilled %d rows\n", total)
+	return nil
+}
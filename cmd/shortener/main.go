@@ -4,35 +4,121 @@ import (
 	"context"
 	"fmt"
 	"log"
+	"os"
 
 	"github.com/ar4ie13/shortener/internal/auth"
+	"github.com/ar4ie13/shortener/internal/clock"
 	"github.com/ar4ie13/shortener/internal/config"
 	"github.com/ar4ie13/shortener/internal/handlers"
 	"github.com/ar4ie13/shortener/internal/logger"
 	"github.com/ar4ie13/shortener/internal/repository"
 	"github.com/ar4ie13/shortener/internal/service"
+	"github.com/ar4ie13/shortener/internal/tlscert"
 )
 
+// shortenBatchSubcommand is the offline CLI subcommand name for bulk-shortening URLs read
+// from stdin
+const shortenBatchSubcommand = "shorten-batch"
+
 func main() {
+	if len(os.Args) > 1 && os.Args[1] == shortenBatchSubcommand {
+		// Drop the subcommand so the remaining arguments parse as ordinary flags.
+		os.Args = append(os.Args[:1], os.Args[2:]...)
+		if err := runShortenBatch(); err != nil {
+			log.Fatal(err)
+		}
+		return
+	}
+
+	if len(os.Args) > 1 && os.Args[1] == backfillHostSubcommand {
+		// Drop the subcommand so the remaining arguments parse as ordinary flags.
+		os.Args = append(os.Args[:1], os.Args[2:]...)
+		if err := runBackfillHost(); err != nil {
+			log.Fatal(err)
+		}
+		return
+	}
+
+	if len(os.Args) > 1 && os.Args[1] == verifySubcommand {
+		// Drop the subcommand so the remaining arguments parse as ordinary flags.
+		os.Args = append(os.Args[:1], os.Args[2:]...)
+		if err := runVerify(); err != nil {
+			log.Fatal(err)
+		}
+		return
+	}
+
 	if err := run(); err != nil {
 		log.Fatal(err)
 	}
 }
 
 func run() error {
-	cfg := config.NewConfig()
-	zlog := logger.NewLogger(cfg.GetLogLevel())
+	cfg, err := config.NewConfig()
+	if err != nil {
+		return fmt.Errorf("invalid configuration: %w", err)
+	}
+	zlog := logger.NewLogger(cfg.GetLogLevel(), cfg.GetLogFormat())
 	authorize := auth.NewAuth(cfg.AuthConf)
-	repo, err := repository.NewRepository(context.Background(), cfg.FilePath, cfg.PostgresDSN, zlog.Logger)
+	repo, readiness, err := repository.NewRepository(context.Background(), cfg.FilePath, cfg.PostgresDSN, cfg.RedisDSN, cfg.S3, zlog.Logger, cfg.GetDBPingInterval(), cfg.GetMemorySnapshotPath(), cfg.GetMemorySnapshotInterval())
 	if err != nil {
 		return fmt.Errorf("cannot initialize repository: %w", err)
 	}
-	srv := service.NewService(repo, zlog.Logger)
-	hdlr := handlers.NewHandler(srv, cfg, authorize, zlog.Logger)
+	auditLog, err := logger.NewAuditLogger(cfg.GetAuditLogPath())
+	if err != nil {
+		return fmt.Errorf("cannot initialize audit logger: %w", err)
+	}
+	if cfg.GetEnableHTTPS() {
+		if err = tlscert.EnsureSelfSigned(cfg.GetTLSCertPath(), cfg.GetTLSKeyPath()); err != nil {
+			return fmt.Errorf("cannot prepare TLS certificate: %w", err)
+		}
+	}
+	validator, err := newURLValidator(cfg)
+	if err != nil {
+		return fmt.Errorf("cannot initialize URL safety validator: %w", err)
+	}
+	srv := service.NewService(repo, zlog.Logger, cfg.GetAvoidRecentSlugs(), cfg.GetDeleteFlushTimeout(), cfg.GetSlugAlphabet(), cfg.GetSlugLength(), clock.RealClock{}, cfg.GetLinkTTL(), cfg.GetSweepInterval(), cfg.GetSweepLimit(), cfg.GetDeleteGracePeriod(), cfg.GetHTTPSUpgradeHosts(), cfg.GetDeleteQueueLimit(), cfg.GetAutoExtendSlug(), cfg.GetAnonymousLinkTTL(), cfg.GetPrivateLinks(), auditLog.Logger, cfg.GetDeleteWorkers(), cfg.GetSlugStrategy(), cfg.GetCacheSize(), cfg.GetCacheTTL(), validator, cfg.GetTrackingParams(), cfg.GetEventBufferSize())
+	hdlr := handlers.NewHandler(srv, cfg, authorize, zlog.Logger, readiness)
 
-	if err = hdlr.ListenAndServe(); err != nil {
-		return fmt.Errorf("shortener service error: %w", err)
+	listenErr := hdlr.ListenAndServe()
+
+	// Drain any pending deletions and close the repository (e.g. the pgx pool) even if
+	// ListenAndServe returned an error, so a failed listener still leaves things tidy.
+	if err = srv.Close(); err != nil {
+		zlog.Error().Err(err).Msg("failed to close service cleanly")
+	}
+	if err = repo.Close(); err != nil {
+		zlog.Error().Err(err).Msg("failed to close repository cleanly")
+	}
+
+	if listenErr != nil {
+		return fmt.Errorf("shortener service error: %w", listenErr)
 	}
 
 	return nil
 }
+
+// newURLValidator builds the Validator chain configured via -blocklist-path and
+// -safe-browsing-api-key, returning nil if neither is set so Service treats every URL as
+// safe without an extra nil check at each call site.
+func newURLValidator(cfg *config.Config) (service.Validator, error) {
+	var validators []service.Validator
+
+	if blocklistPath := cfg.GetBlocklistPath(); blocklistPath != "" {
+		blocklist, err := service.NewBlocklistValidator(blocklistPath)
+		if err != nil {
+			return nil, fmt.Errorf("failed to load blocklist: %w", err)
+		}
+		validators = append(validators, blocklist)
+	}
+
+	if apiKey := cfg.GetSafeBrowsingAPIKey(); apiKey != "" {
+		validators = append(validators, service.NewSafeBrowsingValidator(apiKey, ""))
+	}
+
+	if len(validators) == 0 {
+		return nil, nil
+	}
+
+	return service.NewMultiValidator(validators...), nil
+}
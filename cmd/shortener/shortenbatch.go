@@ -0,0 +1,104 @@
+package main
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"github.com/ar4ie13/shortener/internal/clock"
+	"github.com/ar4ie13/shortener/internal/config"
+	"github.com/ar4ie13/shortener/internal/logger"
+	"github.com/ar4ie13/shortener/internal/model"
+	"github.com/ar4ie13/shortener/internal/repository"
+	"github.com/ar4ie13/shortener/internal/service"
+	"github.com/google/uuid"
+)
+
+// shortenBatchChunkSize bounds how many lines are submitted to SaveBatch at once
+const shortenBatchChunkSize = 100
+
+// batchShortener is the subset of service.Service used by the shorten-batch CLI subcommand
+type batchShortener interface {
+	SaveBatch(ctx context.Context, userUUID uuid.UUID, batch []model.URL, referer string, userAgent string, reuseOnConflict bool, anonymous bool) ([]model.URL, error)
+	SaveURL(ctx context.Context, userUUID uuid.UUID, urlLink string, referer string, userAgent string, customAlias string, password string, anonymous bool, redirectStatus int) (slug string, err error)
+}
+
+// runShortenBatch initializes the configured backend and shortens newline-delimited URLs
+// read from stdin, writing "long<TAB>short" lines to stdout and per-line errors to stderr
+func runShortenBatch() error {
+	cfg, err := config.NewConfig()
+	if err != nil {
+		return fmt.Errorf("invalid configuration: %w", err)
+	}
+	zlog := logger.NewLogger(cfg.GetLogLevel(), cfg.GetLogFormat())
+	repo, readiness, err := repository.NewRepository(context.Background(), cfg.FilePath, cfg.PostgresDSN, cfg.RedisDSN, cfg.S3, zlog.Logger, 0, cfg.GetMemorySnapshotPath(), 0)
+	if err != nil {
+		return fmt.Errorf("cannot initialize repository: %w", err)
+	}
+	readiness.Wait()
+	validator, err := newURLValidator(cfg)
+	if err != nil {
+		return fmt.Errorf("cannot initialize URL safety validator: %w", err)
+	}
+	srv := service.NewService(repo, zlog.Logger, cfg.GetAvoidRecentSlugs(), cfg.GetDeleteFlushTimeout(), cfg.GetSlugAlphabet(), cfg.GetSlugLength(), clock.RealClock{}, cfg.GetLinkTTL(), cfg.GetSweepInterval(), cfg.GetSweepLimit(), cfg.GetDeleteGracePeriod(), cfg.GetHTTPSUpgradeHosts(), cfg.GetDeleteQueueLimit(), cfg.GetAutoExtendSlug(), cfg.GetAnonymousLinkTTL(), cfg.GetPrivateLinks(), zlog.Logger, cfg.GetDeleteWorkers(), cfg.GetSlugStrategy(), cfg.GetCacheSize(), cfg.GetCacheTTL(), validator, cfg.GetTrackingParams(), cfg.GetEventBufferSize())
+	defer func() { _ = srv.Close() }()
+	defer func() { _ = repo.Close() }()
+
+	shortenBatchFromReader(context.Background(), srv, uuid.New(), os.Stdin, os.Stdout, os.Stderr)
+
+	return nil
+}
+
+// shortenBatchFromReader reads newline-delimited URLs from r, shortens them in chunks via
+// srv, and writes "long<TAB>short" lines to out. A chunk that fails as a whole (e.g. one
+// bad or duplicate URL in it) falls back to shortening its lines one at a time so a single
+// bad line never prevents the rest of the input from being shortened; line-level failures
+// are reported to errOut.
+func shortenBatchFromReader(ctx context.Context, srv batchShortener, userUUID uuid.UUID, r io.Reader, out io.Writer, errOut io.Writer) {
+	var lines []string
+	scanner := bufio.NewScanner(r)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" {
+			continue
+		}
+		lines = append(lines, line)
+	}
+
+	for start := 0; start < len(lines); start += shortenBatchChunkSize {
+		end := start + shortenBatchChunkSize
+		if end > len(lines) {
+			end = len(lines)
+		}
+		shortenChunk(ctx, srv, userUUID, lines[start:end], out, errOut)
+	}
+}
+
+// shortenChunk shortens a single chunk of URLs via SaveBatch, falling back to shortening
+// each URL individually if the chunk fails as a whole
+func shortenChunk(ctx context.Context, srv batchShortener, userUUID uuid.UUID, chunk []string, out io.Writer, errOut io.Writer) {
+	batch := make([]model.URL, len(chunk))
+	for i, line := range chunk {
+		batch[i] = model.URL{OriginalURL: line}
+	}
+
+	result, err := srv.SaveBatch(ctx, userUUID, batch, "", "", false, false)
+	if err == nil {
+		for _, u := range result {
+			fmt.Fprintf(out, "%s\t%s\n", u.OriginalURL, u.ShortURL)
+		}
+		return
+	}
+
+	for _, line := range chunk {
+		slug, err := srv.SaveURL(ctx, userUUID, line, "", "", "", "", false, 0)
+		if err != nil {
+			fmt.Fprintf(errOut, "error shortening %q: %v\n", line, err)
+			continue
+		}
+		fmt.Fprintf(out, "%s\t%s\n", line, slug)
+	}
+}
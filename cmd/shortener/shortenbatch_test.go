@@ -0,0 +1,79 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/ar4ie13/shortener/internal/clock"
+	"github.com/ar4ie13/shortener/internal/repository/memory"
+	"github.com/ar4ie13/shortener/internal/service"
+	"github.com/google/uuid"
+	"github.com/rs/zerolog"
+)
+
+func TestShortenBatchFromReader_FallsBackPerLineOnChunkFailure(t *testing.T) {
+	repo := memory.NewMemStorage()
+	srv := service.NewService(repo, zerolog.Nop(), false, time.Second, "abcdefghijklmnopqrstuvwxyz0123456789", 8, clock.RealClock{}, 0, 0, 0, 0, nil, 0, false, 0, false, zerolog.Nop(), 0, "", 0, 0, nil, nil, 0)
+	defer func() { _ = srv.Close() }()
+
+	userUUID := uuid.New()
+
+	// Pre-shorten one URL so the batch below contains a duplicate that forces SaveBatch
+	// to fail for the whole chunk.
+	if _, err := srv.SaveURL(context.Background(), userUUID, "https://duplicate.example.com", "", "", "", "", false, 0); err != nil {
+		t.Fatalf("seeding duplicate URL: %v", err)
+	}
+
+	input := strings.NewReader(strings.Join([]string{
+		"https://one.example.com",
+		"https://two.example.com",
+		"https://duplicate.example.com",
+		"not-a-url",
+		"",
+	}, "\n"))
+
+	var out, errOut bytes.Buffer
+	shortenBatchFromReader(context.Background(), srv, userUUID, input, &out, &errOut)
+
+	outLines := strings.Split(strings.TrimRight(out.String(), "\n"), "\n")
+	if len(outLines) != 2 {
+		t.Fatalf("stdout lines = %d, want 2: %q", len(outLines), out.String())
+	}
+	if !strings.HasPrefix(outLines[0], "https://one.example.com\t") {
+		t.Errorf("stdout line 0 = %q, want prefix for one.example.com", outLines[0])
+	}
+	if !strings.HasPrefix(outLines[1], "https://two.example.com\t") {
+		t.Errorf("stdout line 1 = %q, want prefix for two.example.com", outLines[1])
+	}
+
+	errStr := errOut.String()
+	if !strings.Contains(errStr, "duplicate.example.com") {
+		t.Errorf("stderr missing duplicate URL error: %q", errStr)
+	}
+	if !strings.Contains(errStr, "not-a-url") {
+		t.Errorf("stderr missing invalid URL error: %q", errStr)
+	}
+}
+
+func TestShortenBatchFromReader_HappyPathUsesBatchSave(t *testing.T) {
+	repo := memory.NewMemStorage()
+	srv := service.NewService(repo, zerolog.Nop(), false, time.Second, "abcdefghijklmnopqrstuvwxyz0123456789", 8, clock.RealClock{}, 0, 0, 0, 0, nil, 0, false, 0, false, zerolog.Nop(), 0, "", 0, 0, nil, nil, 0)
+	defer func() { _ = srv.Close() }()
+
+	userUUID := uuid.New()
+	input := strings.NewReader("https://alpha.example.com\nhttps://beta.example.com\n")
+
+	var out, errOut bytes.Buffer
+	shortenBatchFromReader(context.Background(), srv, userUUID, input, &out, &errOut)
+
+	if errOut.Len() != 0 {
+		t.Errorf("unexpected stderr output: %q", errOut.String())
+	}
+	outLines := strings.Split(strings.TrimRight(out.String(), "\n"), "\n")
+	if len(outLines) != 2 {
+		t.Fatalf("stdout lines = %d, want 2: %q", len(outLines), out.String())
+	}
+}
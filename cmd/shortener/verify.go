@@ -0,0 +1,62 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"os"
+
+	"github.com/ar4ie13/shortener/internal/clock"
+	"github.com/ar4ie13/shortener/internal/config"
+	"github.com/ar4ie13/shortener/internal/logger"
+	"github.com/ar4ie13/shortener/internal/repository"
+	"github.com/ar4ie13/shortener/internal/service"
+)
+
+// verifySubcommand is the offline CLI subcommand name for checking the configured backend's
+// stored data for internal inconsistencies
+const verifySubcommand = "verify"
+
+// integrityVerifier is the subset of service.Service used by the verify CLI subcommand
+type integrityVerifier interface {
+	VerifyIntegrity(ctx context.Context) ([]string, error)
+}
+
+// runVerify initializes the configured backend and reports any inconsistencies found in its
+// stored data
+func runVerify() error {
+	cfg, err := config.NewConfig()
+	if err != nil {
+		return fmt.Errorf("invalid configuration: %w", err)
+	}
+	zlog := logger.NewLogger(cfg.GetLogLevel(), cfg.GetLogFormat())
+	repo, readiness, err := repository.NewRepository(context.Background(), cfg.FilePath, cfg.PostgresDSN, cfg.RedisDSN, cfg.S3, zlog.Logger, 0, cfg.GetMemorySnapshotPath(), 0)
+	if err != nil {
+		return fmt.Errorf("cannot initialize repository: %w", err)
+	}
+	readiness.Wait()
+	srv := service.NewService(repo, zlog.Logger, cfg.GetAvoidRecentSlugs(), cfg.GetDeleteFlushTimeout(), cfg.GetSlugAlphabet(), cfg.GetSlugLength(), clock.RealClock{}, cfg.GetLinkTTL(), cfg.GetSweepInterval(), cfg.GetSweepLimit(), cfg.GetDeleteGracePeriod(), cfg.GetHTTPSUpgradeHosts(), cfg.GetDeleteQueueLimit(), cfg.GetAutoExtendSlug(), cfg.GetAnonymousLinkTTL(), cfg.GetPrivateLinks(), zlog.Logger, cfg.GetDeleteWorkers(), cfg.GetSlugStrategy(), cfg.GetCacheSize(), cfg.GetCacheTTL(), nil, cfg.GetTrackingParams(), cfg.GetEventBufferSize())
+	defer func() { _ = srv.Close() }()
+	defer func() { _ = repo.Close() }()
+
+	return verify(context.Background(), srv, os.Stdout)
+}
+
+// verify prints every inconsistency found in srv's stored data to out, returning an error if
+// inconsistencies were found or the check itself failed
+func verify(ctx context.Context, srv integrityVerifier, out *os.File) error {
+	violations, err := srv.VerifyIntegrity(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to verify repository: %w", err)
+	}
+
+	if len(violations) == 0 {
+		fmt.Fprintln(out, "no inconsistencies found")
+		return nil
+	}
+
+	for _, violation := range violations {
+		fmt.Fprintln(out, violation)
+	}
+
+	return fmt.Errorf("found %d inconsistencies", len(violations))
+}
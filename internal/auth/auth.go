@@ -1,6 +1,9 @@
 package auth
 
 import (
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
 	"errors"
 	"fmt"
 	"time"
@@ -11,16 +14,28 @@ import (
 	"github.com/google/uuid"
 )
 
+const (
+	// apiKeySecretBytes is the number of random bytes read to build a plaintext API key's
+	// secret portion
+	apiKeySecretBytes = 32
+	// apiKeyPrefix identifies a token as an API key rather than a JWT, so authMiddleware can
+	// tell the two apart without attempting to parse one as the other
+	apiKeyPrefix = "sk_"
+)
+
 // Auth describes claims required for authorization and provisioning of JWT token
 type Auth struct {
 	Claims Claims
 	conf   authconf.Config
 }
 
-// Claims consists of registered claims and personal UserUUID claim
+// Claims consists of registered claims and personal UserUUID and Anonymous claims
 type Claims struct {
 	jwt.RegisteredClaims
 	UserUUID uuid.UUID
+	// Anonymous marks an identity that was auto-minted by authMiddleware rather than
+	// explicitly claimed via POST /api/user/claim
+	Anonymous bool
 }
 
 // NewAuth creates Auth object
@@ -35,8 +50,9 @@ func (a Auth) GenerateUserUUID() uuid.UUID {
 	return uuid.New()
 }
 
-// BuildJWTString creates new JWT token
-func (a Auth) BuildJWTString(userUUID uuid.UUID) (string, error) {
+// BuildJWTString creates new JWT token. anonymous marks the identity as auto-minted; it is
+// cleared once the identity is claimed via POST /api/user/claim.
+func (a Auth) BuildJWTString(userUUID uuid.UUID, anonymous bool) (string, error) {
 	// creating new token with HS256 algorithm and claims — Auth
 	token := jwt.NewWithClaims(jwt.SigningMethodHS256, Claims{
 		RegisteredClaims: jwt.RegisteredClaims{
@@ -44,7 +60,8 @@ func (a Auth) BuildJWTString(userUUID uuid.UUID) (string, error) {
 			ExpiresAt: jwt.NewNumericDate(time.Now().Add(a.conf.TokenExpiration)),
 		},
 		// personal claim
-		UserUUID: userUUID,
+		UserUUID:  userUUID,
+		Anonymous: anonymous,
 	})
 
 	// creating signed token string
@@ -56,31 +73,50 @@ func (a Auth) BuildJWTString(userUUID uuid.UUID) (string, error) {
 	return tokenString, nil
 }
 
-// ValidateUserUUID validates token and return the UUID of user
-func (a Auth) ValidateUserUUID(tokenString string) (uuid.UUID, error) {
+// ValidateUserUUID validates token and returns the UUID of the user along with whether the
+// identity is still anonymous (never claimed via POST /api/user/claim)
+func (a Auth) ValidateUserUUID(tokenString string) (uuid.UUID, bool, error) {
 	claims, token, err := a.parseTokenString(tokenString)
 	if err != nil {
 		if errors.Is(err, jwt.ErrTokenExpired) {
-			tokenString, err = a.BuildJWTString(claims.UserUUID)
+			tokenString, err = a.BuildJWTString(claims.UserUUID, claims.Anonymous)
 			if err != nil {
-				return uuid.Nil, err
+				return uuid.Nil, false, err
 			}
 			claims, token, err = a.parseTokenString(tokenString)
 			if err != nil {
-				return uuid.Nil, err
+				return uuid.Nil, false, err
 			}
 		} else {
-			return uuid.Nil, err
+			return uuid.Nil, false, err
 		}
 	}
 	if claims.UserUUID.String() == "" || claims.UserUUID == uuid.Nil {
-		return uuid.Nil, myerrors.ErrInvalidUserUUID
+		return uuid.Nil, false, myerrors.ErrInvalidUserUUID
 	}
 
 	if !token.Valid {
-		return uuid.Nil, fmt.Errorf("invalid token")
+		return uuid.Nil, false, fmt.Errorf("invalid token")
+	}
+	return claims.UserUUID, claims.Anonymous, nil
+}
+
+// GenerateAPIKey returns a new plaintext API key. It is shown to the caller only once, at
+// creation, since only its hash is ever persisted.
+func (a Auth) GenerateAPIKey() (string, error) {
+	secret := make([]byte, apiKeySecretBytes)
+	if _, err := rand.Read(secret); err != nil {
+		return "", fmt.Errorf("failed to generate API key: %w", err)
 	}
-	return claims.UserUUID, nil
+
+	return apiKeyPrefix + hex.EncodeToString(secret), nil
+}
+
+// HashAPIKey returns the sha256 hex digest of key, the form in which API keys are stored and
+// looked up so that a leaked database or log never exposes a usable plaintext key.
+func (a Auth) HashAPIKey(key string) string {
+	sum := sha256.Sum256([]byte(key))
+	return hex.EncodeToString(sum[:])
 }
 
 // parseTokenString parses token string and returns claims and token (for validation)
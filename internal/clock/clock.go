@@ -0,0 +1,17 @@
+package clock
+
+import "time"
+
+// Clock abstracts time.Now so time-dependent logic (TTL expiry, sweeping) can be tested
+// deterministically without sleeping real wall-clock time.
+type Clock interface {
+	Now() time.Time
+}
+
+// RealClock is the production Clock backed by the system wall clock
+type RealClock struct{}
+
+// Now returns the current system time
+func (RealClock) Now() time.Time {
+	return time.Now()
+}
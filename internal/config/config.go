@@ -1,11 +1,12 @@
 package config
 
 import (
-	"context"
-	"database/sql"
+	"encoding/json"
 	"errors"
 	"flag"
 	"fmt"
+	"net"
+	"net/http"
 	"net/url"
 	"os"
 	"strconv"
@@ -15,11 +16,13 @@ import (
 	authconf "github.com/ar4ie13/shortener/internal/auth/config"
 	pgconf "github.com/ar4ie13/shortener/internal/repository/db/postgresql/config"
 	fileconf "github.com/ar4ie13/shortener/internal/repository/filestorage/config"
+	redisconf "github.com/ar4ie13/shortener/internal/repository/redis/config"
+	s3conf "github.com/ar4ie13/shortener/internal/repository/s3storage/config"
 
 	"github.com/rs/zerolog"
-	"github.com/rs/zerolog/log"
 
-	_ "github.com/jackc/pgx/v5/stdlib"
+	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/redis/go-redis/v9"
 )
 
 var (
@@ -28,6 +31,21 @@ var (
 	errMustIncludeHost = errors.New("URL template must include a host")
 )
 
+// slugPreset bundles an alphabet and length that are known to work well together
+type slugPreset struct {
+	Alphabet string
+	Length   int
+}
+
+// slugPresets maps a SLUG_PRESET name to its alphabet/length. "compact" trades keyspace for
+// shorter slugs, "standard" matches the service package's historical default, and "safe"
+// avoids visually ambiguous characters (0/O, 1/I/l) for slugs that may be read aloud or typed.
+var slugPresets = map[string]slugPreset{
+	"compact":  {Alphabet: "123456789ABCDEFGHJKLMNPQRSTUVWXYZabcdefghijkmnopqrstuvwxyz", Length: 6},
+	"standard": {Alphabet: "abcdefghijklmnopqrstuvwxyzABCDEFGHIJKLMNOPQRSTUVWXYZ", Length: 8},
+	"safe":     {Alphabet: "23456789ABCDEFGHJKLMNPQRSTUVWXYZ", Length: 10},
+}
+
 // ShortURLTemplate type for short URL template flag
 type ShortURLTemplate string
 
@@ -38,15 +56,86 @@ type Config struct {
 	LogLevel         LogLevel
 	FilePath         fileconf.Config
 	PostgresDSN      pgconf.Config
+	RedisDSN         redisconf.Config
+	S3               s3conf.Config
 	AuthConf         authconf.Config
+	// ConfigFilePath is a JSON file supplying defaults for any flag not explicitly passed on
+	// the command line; see applyConfigFile.
+	ConfigFilePath              string
+	TrackCreationSource         bool
+	ReadOnly                    bool
+	TrustedSubnet               string
+	AvoidRecentSlugs            bool
+	JSONDeleteResponse          bool
+	DeleteFlushTimeout          time.Duration
+	SlugPreset                  string
+	SlugAlphabet                string
+	SlugLength                  int
+	SlugStrategy                string
+	LinkTTL                     time.Duration
+	SweepInterval               time.Duration
+	SweepLimit                  int
+	RedirectRateLimitRPS        float64
+	CookieDomain                string
+	CookieMaxAge                time.Duration
+	EnableBatch                 bool
+	EnableUserURLs              bool
+	EnableDelete                bool
+	RedirectBody                bool
+	RedirectStatus              int
+	RootBehavior                string
+	RootRedirectURL             string
+	LogBodies                   bool
+	DeleteGracePeriod           time.Duration
+	PurgeBatchSize              int
+	PurgeBatchPause             time.Duration
+	PurgeRetention              time.Duration
+	UpgradeHTTPToHTTPS          bool
+	HTTPSUpgradeHosts           string
+	DeleteQueueLimit            int
+	DeleteWorkers               int
+	CacheSize                   int
+	CacheTTL                    time.Duration
+	MaxExpandBatch              int
+	EnableJSONNegotiation       bool
+	AutoExtendSlug              bool
+	EnableImportStream          bool
+	ImportStreamMaxLine         int
+	ImportStreamRateLimit       float64
+	AnonymousLinkTTL            time.Duration
+	ForceShortURLScheme         string
+	PrivateLinks                bool
+	MaxResponseURLs             int
+	AuditLogPath                string
+	AdminAddress                string
+	DBPingInterval              time.Duration
+	ShutdownTimeout             time.Duration
+	EnableHTTPS                 bool
+	TLSCertPath                 string
+	TLSKeyPath                  string
+	CreationRateLimitPerUserRPS float64
+	CreationRateLimitPerIPRPS   float64
+	LogFormat                   string
+	BlocklistPath               string
+	SafeBrowsingAPIKey          string
+	TrackingParams              string
+	EnableEvents                bool
+	EventBufferSize             int
+	MemorySnapshotPath          string
+	MemorySnapshotInterval      time.Duration
 }
 
-// NewConfig constructor for Config
-func NewConfig() *Config {
+// NewConfig constructor for Config. It parses flags and applies environment overrides,
+// returning an error on invalid configuration instead of terminating the process; callers
+// that run as a standalone process (e.g. main) are expected to log and exit on that error
+// themselves.
+func NewConfig() (*Config, error) {
 	c := &Config{}
-	c.InitConfig()
+	if err := c.InitConfig(); err != nil {
+		return nil, err
+	}
 
-	return c
+	return c, nil
 }
 
 // String return short URL in string format
@@ -101,41 +190,198 @@ func (l *LogLevel) Set(value string) error {
 	return nil
 }
 
-// InitConfig initialize configuration
-func (c *Config) InitConfig() {
-	var err error
+// intFlagSetter returns a flag.Func callback that parses its argument as an int and stores it
+// into dst, for flags backed by an int32 field rather than the int flag.IntVar expects.
+func intFlagSetter(dst *int32) func(string) error {
+	return func(value string) error {
+		n, err := strconv.Atoi(value)
+		if err != nil {
+			return fmt.Errorf("invalid integer: %w", err)
+		}
+		*dst = int32(n)
+		return nil
+	}
+}
+
+// InitConfig initializes configuration from flags and environment variables, returning any
+// invalid values as an aggregated error instead of terminating the process.
+func (c *Config) InitConfig() error {
 	defaultServerAddr := "localhost:8080"
 	defaultURL := "http://localhost:8080"
 	defaultLogLevel := LogLevel{Level: zerolog.InfoLevel}
 	defaultFileStorage := ""
 	defaultDatabaseDSN := ""
+	defaultRedisDSN := ""
 	defaultSecretKey := "nHhjHgahbioHBGbBHJ"
 	defaultTokenExpiration := time.Hour * 24
+	defaultMaxExpandBatch := 100
+	defaultImportStreamMaxLine := 65536
+	defaultFileStorageCompactionThreshold := int64(10 * 1024 * 1024)
+	defaultTLSCertPath := "server.crt"
+	defaultTLSKeyPath := "server.key"
 
+	flag.StringVar(&c.ConfigFilePath, "c", "", "path to a JSON config file; see -config")
+	flag.StringVar(&c.ConfigFilePath, "config", "", "path to a JSON config file supplying defaults for any flag not explicitly passed on the command line (or via the CONFIG env var). Each key is a flag name (e.g. \"a\", \"redirect-rate-limit-rps\") mapped to its string value, accepting the same representation as the command line or an env var (e.g. \"5m\" for a duration)")
 	flag.StringVar(&c.LocalServerAddr, "a", defaultServerAddr, "local server address")
 	flag.Var(&c.ShortURLTemplate, "b", "short url template")
 	flag.Var(&c.LogLevel, "l", "log level (debug, info, warn, error, fatal, panic)")
 	flag.StringVar(&c.FilePath.FilePath, "f", defaultFileStorage, "file storage path")
+	flag.DurationVar(&c.FilePath.OperationTimeout, "file-storage-timeout", 5*time.Second, "maximum duration of a single file storage operation (load, save, or delete); 0 disables the timeout")
+	flag.Int64Var(&c.FilePath.CompactionThreshold, "file-storage-compaction-threshold", defaultFileStorageCompactionThreshold, "file size in bytes above which file storage compacts its append-only log down to one record per short URL; 0 disables size-triggered compaction")
+	flag.BoolVar(&c.FilePath.Durable, "file-storage-durable", false, "trade write throughput for crash safety: full-file rewrites go through a temp-file-then-rename and appends are fsynced before the call returns")
 	flag.StringVar(&c.PostgresDSN.DatabaseDSN, "d", defaultDatabaseDSN, "database DSN")
+	flag.StringVar(&c.PostgresDSN.ReadDSN, "db-read-dsn", "", "read replica DSN; if set, GetURL, GetShortURL and GetUserShortURLs query it instead of the primary, falling back to the primary when the replica is unreachable")
+	flag.BoolVar(&c.PostgresDSN.HashOriginalURL, "hash-original-urls", false, "dedup and look up postgres rows by sha256(original_url) instead of original_url itself, so URLs too long for a btree index entry can still be saved")
+	flag.StringVar(&c.RedisDSN.RedisDSN, "redis-dsn", defaultRedisDSN, "redis DSN (e.g. redis://localhost:6379/0); if set and no database DSN is given, the redis repository backend is used instead of the file/memory backend")
+	flag.StringVar(&c.S3.Bucket, "s3-bucket", "", "S3 bucket name; if set and no database or redis DSN is given, the S3 repository backend is used instead of the file/memory backend")
+	flag.StringVar(&c.S3.Endpoint, "s3-endpoint", "", "S3-compatible endpoint, e.g. http://localhost:9000 for a local MinIO instance; empty uses the real AWS endpoint for s3-region")
+	flag.StringVar(&c.S3.Region, "s3-region", "", "S3 region")
+	flag.StringVar(&c.S3.AccessKeyID, "s3-access-key-id", "", "S3 access key ID; empty falls back to the AWS SDK's default credential chain")
+	flag.StringVar(&c.S3.SecretAccessKey, "s3-secret-access-key", "", "S3 secret access key")
+	flag.BoolVar(&c.S3.UsePathStyle, "s3-use-path-style", false, "address S3 objects as endpoint/bucket/key instead of bucket.endpoint/key, required by most non-AWS S3-compatible services such as MinIO")
+	flag.StringVar(&c.S3.ObjectPrefix, "s3-object-prefix", "", "prefix applied to every object the S3 repository backend writes, so one bucket can be shared by multiple deployments")
+	flag.DurationVar(&c.S3.CompactionInterval, "s3-compaction-interval", 0, "how often the S3 repository backend folds its append-only log into a single snapshot object; 0 disables background compaction, leaving it to run only at startup")
+	flag.Func("db-max-conns", "maximum number of postgres connections kept open by the pool; 0 uses pgxpool's own default", intFlagSetter(&c.PostgresDSN.MaxConns))
+	flag.Func("db-min-conns", "minimum number of idle postgres connections kept warm by the pool; 0 uses pgxpool's own default", intFlagSetter(&c.PostgresDSN.MinConns))
+	flag.DurationVar(&c.PostgresDSN.MaxConnLifetime, "db-max-conn-lifetime", 0, "maximum age of a postgres connection before it is closed and replaced; 0 uses pgxpool's own default")
+	flag.DurationVar(&c.PostgresDSN.MaxConnIdleTime, "db-max-conn-idle-time", 0, "maximum time a postgres connection may sit idle before it is closed; 0 uses pgxpool's own default")
+	flag.DurationVar(&c.PostgresDSN.HealthCheckPeriod, "db-health-check-period", 0, "how often idle postgres connections are health-checked; 0 uses pgxpool's own default")
 	flag.StringVar(&c.AuthConf.SecretKey, "k", defaultSecretKey, "secret key")
 	flag.DurationVar(&c.AuthConf.TokenExpiration, "e", defaultTokenExpiration, "token expiration")
+	flag.BoolVar(&c.TrackCreationSource, "track-creation-source", false, "record referer and user-agent of the request that created a short URL")
+	flag.BoolVar(&c.ReadOnly, "read-only", false, "reject write requests and keep serving reads")
+	flag.StringVar(&c.TrustedSubnet, "trusted-subnet", "", "CIDR allowed to call admin endpoints")
+	flag.BoolVar(&c.AvoidRecentSlugs, "avoid-recent-slugs", false, "bias slug generation away from a small bounded set of recently generated slugs")
+	flag.BoolVar(&c.JSONDeleteResponse, "json-delete-response", false, "return a JSON body with the accepted count on DELETE /api/user/urls instead of a bare 202")
+	flag.DurationVar(&c.DeleteFlushTimeout, "delete-flush-timeout", 5*time.Second, "maximum duration of a single delete worker flush to the repository")
+	flag.StringVar(&c.SlugPreset, "slug-preset", "", "named slug alphabet/length preset: compact, standard, or safe")
+	flag.StringVar(&c.SlugAlphabet, "slug-alphabet", "", "explicit slug alphabet, overrides the alphabet from slug-preset")
+	flag.IntVar(&c.SlugLength, "slug-length", 0, "explicit slug length, overrides the length from slug-preset")
+	flag.StringVar(&c.SlugStrategy, "slug-strategy", "", "slug generation strategy: random, sequential, or hash; empty uses random")
+	flag.DurationVar(&c.LinkTTL, "link-ttl", 0, "TTL applied to newly created links; 0 disables expiry")
+	flag.DurationVar(&c.SweepInterval, "sweep-interval", 0, "interval between background sweeps that soft-delete expired links; 0 disables the sweeper")
+	flag.IntVar(&c.SweepLimit, "sweep-limit", 0, "maximum number of expired links processed per sweep")
+	flag.Float64Var(&c.RedirectRateLimitRPS, "redirect-rate-limit-rps", 0, "requests per second allowed on GET /{id} redirects; 0 disables the limit. Does not apply to creation endpoints.")
+	flag.Float64Var(&c.CreationRateLimitPerUserRPS, "creation-rate-limit-per-user-rps", 0, "requests per second allowed per user UUID on the plain-text and JSON shorten endpoints; 0 disables the limit")
+	flag.Float64Var(&c.CreationRateLimitPerIPRPS, "creation-rate-limit-per-ip-rps", 0, "requests per second allowed per client IP on the plain-text and JSON shorten endpoints; 0 disables the limit")
+	flag.StringVar(&c.CookieDomain, "cookie-domain", "", "domain attribute set on the auth cookie; empty leaves it unset (host-only cookie)")
+	flag.DurationVar(&c.CookieMaxAge, "cookie-max-age", 0, "max-age of the auth cookie; 0 makes it a session cookie")
+	flag.BoolVar(&c.EnableBatch, "enable-batch", true, "register the POST /api/shorten/batch endpoint")
+	flag.BoolVar(&c.EnableUserURLs, "enable-user-urls", true, "register the GET and PUT /api/user/urls endpoints")
+	flag.BoolVar(&c.EnableDelete, "enable-delete", true, "register the DELETE /api/user/urls endpoint")
+	flag.BoolVar(&c.RedirectBody, "redirect-body", false, "write the destination URL as a text/html body alongside the Location header on GET /{id}")
+	flag.IntVar(&c.RedirectStatus, "redirect-status", 0, "HTTP status for GET/HEAD /{id} redirects: 301, 302, 307 or 308; 0 uses the default (307). A non-GET request (e.g. HEAD) swaps 301/302 for their method-preserving equivalent (308/307)")
+	flag.StringVar(&c.RootBehavior, "root-behavior", "405", "behavior for GET /: 405 (no handler), landing (serve an embedded landing page), or redirect (to root-redirect-url)")
+	flag.StringVar(&c.RootRedirectURL, "root-redirect-url", "", "URL to redirect GET / to when root-behavior is redirect")
+	flag.BoolVar(&c.LogBodies, "log-bodies", false, "log request and response bodies at trace level, with sensitive fields redacted")
+	flag.StringVar(&c.LogFormat, "log-format", "console", "log output format: console (human-readable) or json")
+	flag.DurationVar(&c.DeleteGracePeriod, "delete-grace-period", 0, "how long a soft-deleted slug keeps redirecting (with Cache-Control: no-store) before returning 410; 0 means immediate 410")
+	flag.IntVar(&c.PurgeBatchSize, "purge-batch-size", 0, "maximum number of soft-deleted rows permanently removed per batch by the admin purge endpoint")
+	flag.DurationVar(&c.PurgeBatchPause, "purge-batch-pause", 0, "pause between batches during an admin purge of soft-deleted rows; 0 means no pause")
+	flag.DurationVar(&c.PurgeRetention, "purge-retention", 0, "minimum age of a soft-deleted row before it is eligible for the admin purge, unless overridden per-request")
+	flag.BoolVar(&c.UpgradeHTTPToHTTPS, "upgrade-http-to-https", false, "upgrade http:// destinations to https:// on save when the host is in https-upgrade-hosts")
+	flag.StringVar(&c.HTTPSUpgradeHosts, "https-upgrade-hosts", "", "comma-separated hostnames eligible for automatic http-to-https upgrade")
+	flag.IntVar(&c.DeleteQueueLimit, "delete-queue-limit", 0, "maximum number of slugs awaiting asynchronous deletion; 0 means unlimited")
+	flag.IntVar(&c.DeleteWorkers, "delete-workers", 0, "number of background workers consuming the asynchronous delete queue; 0 uses the package default")
+	flag.IntVar(&c.CacheSize, "cache-size", 0, "maximum number of resolved short URLs cached in front of the repository; 0 disables the cache")
+	flag.DurationVar(&c.CacheTTL, "cache-ttl", 0, "how long a cached short URL resolution stays valid; 0 disables the cache")
+	flag.IntVar(&c.MaxExpandBatch, "max-expand-batch", defaultMaxExpandBatch, "maximum number of slugs resolvable in one expand-batch request")
+	flag.BoolVar(&c.EnableJSONNegotiation, "enable-json-negotiation", true, "honor Accept: application/json on routes that otherwise respond with plain text or a raw redirect, e.g. GET /{id}")
+	flag.BoolVar(&c.AutoExtendSlug, "auto-extend-slug", false, "retry a generated slug save at progressively longer lengths if every attempt at the configured length collides, instead of failing the request")
+	flag.BoolVar(&c.EnableImportStream, "enable-import-stream", true, "register the POST /api/user/urls/import/stream endpoint")
+	flag.IntVar(&c.ImportStreamMaxLine, "import-stream-max-line", defaultImportStreamMaxLine, "maximum size in bytes of a single line accepted by the import stream endpoint; a longer line is rejected with a per-line error")
+	flag.Float64Var(&c.ImportStreamRateLimit, "import-stream-rate-limit-rps", 0, "lines per second allowed on the import stream endpoint; 0 disables the limit")
+	flag.DurationVar(&c.AnonymousLinkTTL, "anonymous-link-ttl", 0, "TTL applied to links saved by an identity that has not been claimed via POST /api/user/claim; 0 falls back to link-ttl")
+	flag.StringVar(&c.ForceShortURLScheme, "force-short-url-scheme", "", "override the scheme of returned short URLs: http, https, or auto (take it from X-Forwarded-Proto on requests from trusted-subnet); empty uses the scheme from -b/BASE_URL as-is")
+	flag.BoolVar(&c.PrivateLinks, "private-links", false, "restrict GET /{id} to the slug's owner, returning 404 to every other caller instead of resolving it publicly")
+	flag.IntVar(&c.MaxResponseURLs, "max-response-urls", 0, "maximum number of URLs GET /api/user/urls ever returns, regardless of the requested limit; 0 means unlimited")
+	flag.StringVar(&c.AuditLogPath, "audit-log-path", "", "file to append structured JSON audit entries for deletions and purges to; empty writes them to stdout")
+	flag.StringVar(&c.AdminAddress, "admin-address", "", "if set, serve the trusted-subnet-gated /api/internal/* routes on a second listener bound here instead of the public server, removing them from the public router entirely")
+	flag.DurationVar(&c.DBPingInterval, "db-ping-interval", 0, "interval between background pings of the postgres connection pool; 0 disables the warmup loop. Tracks healthy/unhealthy transitions for GET /readyz instead of waiting for a request to fail")
+	flag.DurationVar(&c.ShutdownTimeout, "shutdown-timeout", 10*time.Second, "how long to wait for in-flight requests and the pending delete queue to drain on SIGINT/SIGTERM before the shutdown proceeds anyway")
+	flag.BoolVar(&c.EnableHTTPS, "s", false, "serve over HTTPS (ListenAndServeTLS) instead of plain HTTP, generating a self-signed certificate at tls-cert-path/tls-key-path on first start if neither already exists")
+	flag.StringVar(&c.TLSCertPath, "tls-cert-path", defaultTLSCertPath, "path to the TLS certificate used when -s/ENABLE_HTTPS is set")
+	flag.StringVar(&c.TLSKeyPath, "tls-key-path", defaultTLSKeyPath, "path to the TLS private key used when -s/ENABLE_HTTPS is set")
+	flag.StringVar(&c.BlocklistPath, "blocklist-path", "", "file of blocked hosts (one per line) to reject on URL creation; empty disables the blocklist check")
+	flag.StringVar(&c.SafeBrowsingAPIKey, "safe-browsing-api-key", "", "Google Safe Browsing API key to reject URLs flagged as malware or phishing on creation; empty disables the check")
+	flag.StringVar(&c.TrackingParams, "tracking-params", "", "comma-separated query parameter names (e.g. utm_source,gclid) to strip when canonicalizing a URL on creation; empty strips none")
+	flag.BoolVar(&c.EnableEvents, "enable-events", true, "register the GET /api/events Server-Sent Events endpoint")
+	flag.IntVar(&c.EventBufferSize, "event-buffer-size", 0, "number of events buffered per subscriber of GET /api/events before the slowest ones are dropped; 0 uses the package default")
+	flag.StringVar(&c.MemorySnapshotPath, "memory-snapshot-path", "", "file the memory backend periodically dumps its state to, and restores from on startup; only used when no file, postgres, redis or S3 backend is configured. Empty disables snapshotting")
+	flag.DurationVar(&c.MemorySnapshotInterval, "memory-snapshot-interval", 0, "interval between memory backend snapshots to memory-snapshot-path; 0 disables periodic snapshotting even when memory-snapshot-path is set")
 
 	if err := c.ShortURLTemplate.Set(defaultURL); err != nil {
-		log.Fatal().Err(err).Msg("Failed to set default URL")
+		return fmt.Errorf("failed to set default URL: %w", err)
 	}
 
 	if err := c.LogLevel.Set(defaultLogLevel.String()); err != nil {
-		log.Fatal().Err(err).Msg("Failed to set default log level")
+		return fmt.Errorf("failed to set default log level: %w", err)
 	}
 
 	flag.Parse()
 
+	configPath := c.ConfigFilePath
+	if envPath := os.Getenv("CONFIG"); envPath != "" {
+		configPath = envPath
+	}
+	if configPath != "" {
+		if err := applyConfigFile(flag.CommandLine, configPath); err != nil {
+			return fmt.Errorf("failed to apply config file: %w", err)
+		}
+	}
+
+	return c.applyEnvOverrides()
+}
+
+// applyConfigFile overlays JSON key/value pairs read from path onto any fs flag not
+// explicitly set on the command line, via that flag's own Value.Set, so a config file
+// accepts the same string representation for a value as the command line or an env var
+// (e.g. "5m" for a duration). A key that does not name a registered flag is an error, so a
+// typo in the file doesn't silently go unnoticed.
+func applyConfigFile(fs *flag.FlagSet, path string) error {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return fmt.Errorf("failed to read config file: %w", err)
+	}
+
+	var values map[string]string
+	if err = json.Unmarshal(data, &values); err != nil {
+		return fmt.Errorf("failed to parse config file: %w", err)
+	}
+
+	explicit := make(map[string]bool)
+	fs.Visit(func(f *flag.Flag) { explicit[f.Name] = true })
+
+	for name, value := range values {
+		if explicit[name] {
+			continue
+		}
+
+		f := fs.Lookup(name)
+		if f == nil {
+			return fmt.Errorf("unknown config file key %q", name)
+		}
+		if err = f.Value.Set(value); err != nil {
+			return fmt.Errorf("invalid value for %q in config file: %w", name, err)
+		}
+	}
+
+	return nil
+}
+
+// applyEnvOverrides applies environment variable overrides on top of the flag-parsed
+// configuration and runs cross-field validation. Every invalid value is collected and
+// returned together via errors.Join, rather than stopping at the first one, so a caller
+// sees every problem in a single pass. It touches no flag state, so it can be called
+// directly (bypassing flag registration) to exercise validation in isolation, e.g. from
+// tests.
+func (c *Config) applyEnvOverrides() error {
+	var err error
+	var errs []error
+
 	if serverAddr := os.Getenv("SERVER_ADDRESS"); serverAddr != "" {
-		if _, err := strconv.Unquote("\"" + serverAddr + "\""); err != nil {
-			parts := strings.SplitN(serverAddr, ":", 2)
-			if len(parts) != 2 || parts[0] == "" || parts[1] == "" {
-				log.Fatal().Err(err).Msg("Failed to set server address from SERVER_ADDRESS")
-			}
+		if err := validateServerAddr(serverAddr); err != nil {
+			errs = append(errs, fmt.Errorf("failed to set server address from SERVER_ADDRESS: %w", err))
 		}
 		c.LocalServerAddr = serverAddr
 	}
@@ -143,14 +389,14 @@ func (c *Config) InitConfig() {
 	if baseURL := os.Getenv("BASE_URL"); baseURL != "" {
 		err := c.ShortURLTemplate.Set(baseURL)
 		if err != nil {
-			log.Fatal().Err(err).Msg("Failed to set URL template from BASE_URL")
+			errs = append(errs, fmt.Errorf("failed to set URL template from BASE_URL: %w", err))
 		}
 	}
 
 	if logLevelStr := os.Getenv("LOG_LEVEL"); logLevelStr != "" {
 		err := c.LogLevel.Set(logLevelStr)
 		if err != nil {
-			log.Fatal().Err(err).Msg("Failed to set log level from LOG_LEVEL")
+			errs = append(errs, fmt.Errorf("failed to set log level from LOG_LEVEL: %w", err))
 		}
 	}
 
@@ -158,10 +404,123 @@ func (c *Config) InitConfig() {
 		c.FilePath.FilePath = fileStorage
 	}
 
+	if fileStorageTimeoutStr := os.Getenv("FILE_STORAGE_TIMEOUT"); fileStorageTimeoutStr != "" {
+		c.FilePath.OperationTimeout, err = time.ParseDuration(fileStorageTimeoutStr)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("cannot parse file storage timeout environment variable: %w", err))
+		}
+	}
+
+	if fileStorageCompactionThresholdStr := os.Getenv("FILE_STORAGE_COMPACTION_THRESHOLD"); fileStorageCompactionThresholdStr != "" {
+		c.FilePath.CompactionThreshold, err = strconv.ParseInt(fileStorageCompactionThresholdStr, 10, 64)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("cannot parse file storage compaction threshold environment variable: %w", err))
+		}
+	}
+
+	if fileStorageDurableStr := os.Getenv("FILE_STORAGE_DURABLE"); fileStorageDurableStr != "" {
+		c.FilePath.Durable, err = strconv.ParseBool(fileStorageDurableStr)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("cannot parse file storage durable environment variable: %w", err))
+		}
+	}
+
 	if databaseDSN := os.Getenv("DATABASE_DSN"); databaseDSN != "" {
 		c.PostgresDSN.DatabaseDSN = databaseDSN
 	}
 
+	if dbReadDSN := os.Getenv("DB_READ_DSN"); dbReadDSN != "" {
+		c.PostgresDSN.ReadDSN = dbReadDSN
+	}
+
+	if redisDSN := os.Getenv("REDIS_DSN"); redisDSN != "" {
+		c.RedisDSN.RedisDSN = redisDSN
+	}
+
+	if s3Bucket := os.Getenv("S3_BUCKET"); s3Bucket != "" {
+		c.S3.Bucket = s3Bucket
+	}
+
+	if s3Endpoint := os.Getenv("S3_ENDPOINT"); s3Endpoint != "" {
+		c.S3.Endpoint = s3Endpoint
+	}
+
+	if s3Region := os.Getenv("S3_REGION"); s3Region != "" {
+		c.S3.Region = s3Region
+	}
+
+	if s3AccessKeyID := os.Getenv("S3_ACCESS_KEY_ID"); s3AccessKeyID != "" {
+		c.S3.AccessKeyID = s3AccessKeyID
+	}
+
+	if s3SecretAccessKey := os.Getenv("S3_SECRET_ACCESS_KEY"); s3SecretAccessKey != "" {
+		c.S3.SecretAccessKey = s3SecretAccessKey
+	}
+
+	if s3UsePathStyleStr := os.Getenv("S3_USE_PATH_STYLE"); s3UsePathStyleStr != "" {
+		c.S3.UsePathStyle, err = strconv.ParseBool(s3UsePathStyleStr)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("cannot parse s3 use path style environment variable: %w", err))
+		}
+	}
+
+	if s3ObjectPrefix := os.Getenv("S3_OBJECT_PREFIX"); s3ObjectPrefix != "" {
+		c.S3.ObjectPrefix = s3ObjectPrefix
+	}
+
+	if s3CompactionIntervalStr := os.Getenv("S3_COMPACTION_INTERVAL"); s3CompactionIntervalStr != "" {
+		c.S3.CompactionInterval, err = time.ParseDuration(s3CompactionIntervalStr)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("cannot parse s3 compaction interval environment variable: %w", err))
+		}
+	}
+
+	if hashOriginalURLStr := os.Getenv("HASH_ORIGINAL_URLS"); hashOriginalURLStr != "" {
+		c.PostgresDSN.HashOriginalURL, err = strconv.ParseBool(hashOriginalURLStr)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("cannot parse hash original urls environment variable: %w", err))
+		}
+	}
+
+	if dbMaxConnsStr := os.Getenv("DB_MAX_CONNS"); dbMaxConnsStr != "" {
+		n, convErr := strconv.Atoi(dbMaxConnsStr)
+		if convErr != nil {
+			errs = append(errs, fmt.Errorf("cannot parse db max conns environment variable: %w", convErr))
+		} else {
+			c.PostgresDSN.MaxConns = int32(n)
+		}
+	}
+
+	if dbMinConnsStr := os.Getenv("DB_MIN_CONNS"); dbMinConnsStr != "" {
+		n, convErr := strconv.Atoi(dbMinConnsStr)
+		if convErr != nil {
+			errs = append(errs, fmt.Errorf("cannot parse db min conns environment variable: %w", convErr))
+		} else {
+			c.PostgresDSN.MinConns = int32(n)
+		}
+	}
+
+	if dbMaxConnLifetimeStr := os.Getenv("DB_MAX_CONN_LIFETIME"); dbMaxConnLifetimeStr != "" {
+		c.PostgresDSN.MaxConnLifetime, err = time.ParseDuration(dbMaxConnLifetimeStr)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("cannot parse db max conn lifetime environment variable: %w", err))
+		}
+	}
+
+	if dbMaxConnIdleTimeStr := os.Getenv("DB_MAX_CONN_IDLE_TIME"); dbMaxConnIdleTimeStr != "" {
+		c.PostgresDSN.MaxConnIdleTime, err = time.ParseDuration(dbMaxConnIdleTimeStr)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("cannot parse db max conn idle time environment variable: %w", err))
+		}
+	}
+
+	if dbHealthCheckPeriodStr := os.Getenv("DB_HEALTH_CHECK_PERIOD"); dbHealthCheckPeriodStr != "" {
+		c.PostgresDSN.HealthCheckPeriod, err = time.ParseDuration(dbHealthCheckPeriodStr)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("cannot parse db health check period environment variable: %w", err))
+		}
+	}
+
 	if secretKey := os.Getenv("SECRET_KEY"); secretKey != "" {
 		c.AuthConf.SecretKey = secretKey
 	}
@@ -169,24 +528,582 @@ func (c *Config) InitConfig() {
 	if tokenExpirationStr := os.Getenv("TOKEN_EXPIRATION"); tokenExpirationStr != "" {
 		c.AuthConf.TokenExpiration, err = time.ParseDuration(tokenExpirationStr)
 		if err != nil {
-			log.Fatal().Err(err).Msg("cannot parse token expiration environment variable")
+			errs = append(errs, fmt.Errorf("cannot parse token expiration environment variable: %w", err))
+		}
+
+	}
+
+	if trackCreationSourceStr := os.Getenv("TRACK_CREATION_SOURCE"); trackCreationSourceStr != "" {
+		c.TrackCreationSource, err = strconv.ParseBool(trackCreationSourceStr)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("cannot parse track creation source environment variable: %w", err))
+		}
+	}
+
+	if readOnlyStr := os.Getenv("READ_ONLY"); readOnlyStr != "" {
+		c.ReadOnly, err = strconv.ParseBool(readOnlyStr)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("cannot parse read only environment variable: %w", err))
+		}
+	}
+
+	if trustedSubnet := os.Getenv("TRUSTED_SUBNET"); trustedSubnet != "" {
+		c.TrustedSubnet = trustedSubnet
+	}
+
+	if avoidRecentSlugsStr := os.Getenv("AVOID_RECENT_SLUGS"); avoidRecentSlugsStr != "" {
+		c.AvoidRecentSlugs, err = strconv.ParseBool(avoidRecentSlugsStr)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("cannot parse avoid recent slugs environment variable: %w", err))
+		}
+	}
+
+	if jsonDeleteResponseStr := os.Getenv("JSON_DELETE_RESPONSE"); jsonDeleteResponseStr != "" {
+		c.JSONDeleteResponse, err = strconv.ParseBool(jsonDeleteResponseStr)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("cannot parse json delete response environment variable: %w", err))
+		}
+	}
+
+	if deleteFlushTimeoutStr := os.Getenv("DELETE_FLUSH_TIMEOUT"); deleteFlushTimeoutStr != "" {
+		c.DeleteFlushTimeout, err = time.ParseDuration(deleteFlushTimeoutStr)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("cannot parse delete flush timeout environment variable: %w", err))
+		}
+	}
+
+	if slugPreset := os.Getenv("SLUG_PRESET"); slugPreset != "" {
+		c.SlugPreset = slugPreset
+	}
+
+	if slugAlphabet := os.Getenv("SLUG_ALPHABET"); slugAlphabet != "" {
+		c.SlugAlphabet = slugAlphabet
+	}
+
+	if slugLengthStr := os.Getenv("SLUG_LENGTH"); slugLengthStr != "" {
+		c.SlugLength, err = strconv.Atoi(slugLengthStr)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("cannot parse slug length environment variable: %w", err))
+		}
+	}
+
+	if err := c.resolveSlugPreset(); err != nil {
+		errs = append(errs, err)
+	}
+
+	if slugStrategy := os.Getenv("SLUG_STRATEGY"); slugStrategy != "" {
+		c.SlugStrategy = slugStrategy
+	}
+
+	if err := c.validateSlugStrategy(); err != nil {
+		errs = append(errs, err)
+	}
+
+	if linkTTLStr := os.Getenv("LINK_TTL"); linkTTLStr != "" {
+		c.LinkTTL, err = time.ParseDuration(linkTTLStr)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("cannot parse link TTL environment variable: %w", err))
+		}
+	}
+
+	if sweepIntervalStr := os.Getenv("SWEEP_INTERVAL"); sweepIntervalStr != "" {
+		c.SweepInterval, err = time.ParseDuration(sweepIntervalStr)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("cannot parse sweep interval environment variable: %w", err))
+		}
+	}
+
+	if sweepLimitStr := os.Getenv("SWEEP_LIMIT"); sweepLimitStr != "" {
+		c.SweepLimit, err = strconv.Atoi(sweepLimitStr)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("cannot parse sweep limit environment variable: %w", err))
+		}
+	}
+
+	if redirectRateLimitStr := os.Getenv("REDIRECT_RATE_LIMIT_RPS"); redirectRateLimitStr != "" {
+		c.RedirectRateLimitRPS, err = strconv.ParseFloat(redirectRateLimitStr, 64)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("cannot parse redirect rate limit environment variable: %w", err))
+		}
+	}
+
+	if creationRateLimitPerUserStr := os.Getenv("CREATION_RATE_LIMIT_PER_USER_RPS"); creationRateLimitPerUserStr != "" {
+		c.CreationRateLimitPerUserRPS, err = strconv.ParseFloat(creationRateLimitPerUserStr, 64)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("cannot parse creation rate limit per user environment variable: %w", err))
+		}
+	}
+
+	if creationRateLimitPerIPStr := os.Getenv("CREATION_RATE_LIMIT_PER_IP_RPS"); creationRateLimitPerIPStr != "" {
+		c.CreationRateLimitPerIPRPS, err = strconv.ParseFloat(creationRateLimitPerIPStr, 64)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("cannot parse creation rate limit per ip environment variable: %w", err))
+		}
+	}
+
+	if cookieDomain := os.Getenv("COOKIE_DOMAIN"); cookieDomain != "" {
+		c.CookieDomain = cookieDomain
+	}
+
+	if cookieMaxAgeStr := os.Getenv("COOKIE_MAX_AGE"); cookieMaxAgeStr != "" {
+		c.CookieMaxAge, err = time.ParseDuration(cookieMaxAgeStr)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("cannot parse cookie max age environment variable: %w", err))
+		}
+	}
+
+	if enableBatchStr := os.Getenv("ENABLE_BATCH"); enableBatchStr != "" {
+		c.EnableBatch, err = strconv.ParseBool(enableBatchStr)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("cannot parse enable batch environment variable: %w", err))
+		}
+	}
+
+	if enableUserURLsStr := os.Getenv("ENABLE_USER_URLS"); enableUserURLsStr != "" {
+		c.EnableUserURLs, err = strconv.ParseBool(enableUserURLsStr)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("cannot parse enable user urls environment variable: %w", err))
+		}
+	}
+
+	if enableDeleteStr := os.Getenv("ENABLE_DELETE"); enableDeleteStr != "" {
+		c.EnableDelete, err = strconv.ParseBool(enableDeleteStr)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("cannot parse enable delete environment variable: %w", err))
+		}
+	}
+
+	if redirectBodyStr := os.Getenv("REDIRECT_BODY"); redirectBodyStr != "" {
+		c.RedirectBody, err = strconv.ParseBool(redirectBodyStr)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("cannot parse redirect body environment variable: %w", err))
+		}
+	}
+
+	if rootBehavior := os.Getenv("ROOT_BEHAVIOR"); rootBehavior != "" {
+		c.RootBehavior = rootBehavior
+	}
+
+	if rootRedirectURL := os.Getenv("ROOT_REDIRECT_URL"); rootRedirectURL != "" {
+		c.RootRedirectURL = rootRedirectURL
+	}
+
+	if err := c.validateRootBehavior(); err != nil {
+		errs = append(errs, err)
+	}
+
+	if logBodiesStr := os.Getenv("LOG_BODIES"); logBodiesStr != "" {
+		c.LogBodies, err = strconv.ParseBool(logBodiesStr)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("cannot parse log bodies environment variable: %w", err))
+		}
+	}
+
+	if logFormat := os.Getenv("LOG_FORMAT"); logFormat != "" {
+		c.LogFormat = logFormat
+	}
+
+	if err := c.validateLogFormat(); err != nil {
+		errs = append(errs, err)
+	}
+
+	if deleteGracePeriodStr := os.Getenv("DELETE_GRACE_PERIOD"); deleteGracePeriodStr != "" {
+		c.DeleteGracePeriod, err = time.ParseDuration(deleteGracePeriodStr)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("cannot parse delete grace period environment variable: %w", err))
+		}
+	}
+
+	if purgeBatchSizeStr := os.Getenv("PURGE_BATCH_SIZE"); purgeBatchSizeStr != "" {
+		c.PurgeBatchSize, err = strconv.Atoi(purgeBatchSizeStr)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("cannot parse purge batch size environment variable: %w", err))
+		}
+	}
+
+	if purgeBatchPauseStr := os.Getenv("PURGE_BATCH_PAUSE"); purgeBatchPauseStr != "" {
+		c.PurgeBatchPause, err = time.ParseDuration(purgeBatchPauseStr)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("cannot parse purge batch pause environment variable: %w", err))
+		}
+	}
+
+	if purgeRetentionStr := os.Getenv("PURGE_RETENTION"); purgeRetentionStr != "" {
+		c.PurgeRetention, err = time.ParseDuration(purgeRetentionStr)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("cannot parse purge retention environment variable: %w", err))
+		}
+	}
+
+	if upgradeHTTPToHTTPSStr := os.Getenv("UPGRADE_HTTP_TO_HTTPS"); upgradeHTTPToHTTPSStr != "" {
+		c.UpgradeHTTPToHTTPS, err = strconv.ParseBool(upgradeHTTPToHTTPSStr)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("cannot parse upgrade http to https environment variable: %w", err))
+		}
+	}
+
+	if httpsUpgradeHosts := os.Getenv("HTTPS_UPGRADE_HOSTS"); httpsUpgradeHosts != "" {
+		c.HTTPSUpgradeHosts = httpsUpgradeHosts
+	}
+
+	if deleteQueueLimitStr := os.Getenv("DELETE_QUEUE_LIMIT"); deleteQueueLimitStr != "" {
+		c.DeleteQueueLimit, err = strconv.Atoi(deleteQueueLimitStr)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("cannot parse delete queue limit environment variable: %w", err))
+		}
+	}
+
+	if deleteWorkersStr := os.Getenv("DELETE_WORKERS"); deleteWorkersStr != "" {
+		c.DeleteWorkers, err = strconv.Atoi(deleteWorkersStr)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("cannot parse delete workers environment variable: %w", err))
+		}
+	}
+
+	if cacheSizeStr := os.Getenv("CACHE_SIZE"); cacheSizeStr != "" {
+		c.CacheSize, err = strconv.Atoi(cacheSizeStr)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("cannot parse cache size environment variable: %w", err))
+		}
+	}
+
+	if cacheTTLStr := os.Getenv("CACHE_TTL"); cacheTTLStr != "" {
+		c.CacheTTL, err = time.ParseDuration(cacheTTLStr)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("cannot parse cache ttl environment variable: %w", err))
+		}
+	}
+
+	if maxExpandBatchStr := os.Getenv("MAX_EXPAND_BATCH"); maxExpandBatchStr != "" {
+		c.MaxExpandBatch, err = strconv.Atoi(maxExpandBatchStr)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("cannot parse max expand batch environment variable: %w", err))
+		}
+	}
+
+	if enableJSONNegotiationStr := os.Getenv("ENABLE_JSON_NEGOTIATION"); enableJSONNegotiationStr != "" {
+		c.EnableJSONNegotiation, err = strconv.ParseBool(enableJSONNegotiationStr)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("cannot parse enable json negotiation environment variable: %w", err))
+		}
+	}
+
+	if autoExtendSlugStr := os.Getenv("AUTO_EXTEND_SLUG"); autoExtendSlugStr != "" {
+		c.AutoExtendSlug, err = strconv.ParseBool(autoExtendSlugStr)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("cannot parse auto extend slug environment variable: %w", err))
+		}
+	}
+
+	if enableImportStreamStr := os.Getenv("ENABLE_IMPORT_STREAM"); enableImportStreamStr != "" {
+		c.EnableImportStream, err = strconv.ParseBool(enableImportStreamStr)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("cannot parse enable import stream environment variable: %w", err))
+		}
+	}
+
+	if importStreamMaxLineStr := os.Getenv("IMPORT_STREAM_MAX_LINE"); importStreamMaxLineStr != "" {
+		c.ImportStreamMaxLine, err = strconv.Atoi(importStreamMaxLineStr)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("cannot parse import stream max line environment variable: %w", err))
+		}
+	}
+
+	if importStreamRateLimitStr := os.Getenv("IMPORT_STREAM_RATE_LIMIT_RPS"); importStreamRateLimitStr != "" {
+		c.ImportStreamRateLimit, err = strconv.ParseFloat(importStreamRateLimitStr, 64)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("cannot parse import stream rate limit environment variable: %w", err))
 		}
+	}
+
+	if anonymousLinkTTLStr := os.Getenv("ANONYMOUS_LINK_TTL"); anonymousLinkTTLStr != "" {
+		c.AnonymousLinkTTL, err = time.ParseDuration(anonymousLinkTTLStr)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("cannot parse anonymous link TTL environment variable: %w", err))
+		}
+	}
+
+	if forceShortURLScheme := os.Getenv("FORCE_SHORT_URL_SCHEME"); forceShortURLScheme != "" {
+		c.ForceShortURLScheme = forceShortURLScheme
+	}
+
+	if err := c.validateForceShortURLScheme(); err != nil {
+		errs = append(errs, err)
+	}
+
+	if privateLinksStr := os.Getenv("PRIVATE_LINKS"); privateLinksStr != "" {
+		c.PrivateLinks, err = strconv.ParseBool(privateLinksStr)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("cannot parse private links environment variable: %w", err))
+		}
+	}
+
+	if maxResponseURLsStr := os.Getenv("MAX_RESPONSE_URLS"); maxResponseURLsStr != "" {
+		c.MaxResponseURLs, err = strconv.Atoi(maxResponseURLsStr)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("cannot parse max response urls environment variable: %w", err))
+		}
+	}
+
+	if redirectStatusStr := os.Getenv("REDIRECT_STATUS"); redirectStatusStr != "" {
+		c.RedirectStatus, err = strconv.Atoi(redirectStatusStr)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("cannot parse redirect status environment variable: %w", err))
+		}
+	}
+
+	if err := c.validateRedirectStatus(); err != nil {
+		errs = append(errs, err)
+	}
+
+	if auditLogPathStr := os.Getenv("AUDIT_LOG_PATH"); auditLogPathStr != "" {
+		c.AuditLogPath = auditLogPathStr
+	}
+
+	if adminAddrStr := os.Getenv("ADMIN_ADDRESS"); adminAddrStr != "" {
+		c.AdminAddress = adminAddrStr
+	}
+
+	if dbPingIntervalStr := os.Getenv("DB_PING_INTERVAL"); dbPingIntervalStr != "" {
+		c.DBPingInterval, err = time.ParseDuration(dbPingIntervalStr)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("cannot parse db ping interval environment variable: %w", err))
+		}
+	}
+
+	if shutdownTimeoutStr := os.Getenv("SHUTDOWN_TIMEOUT"); shutdownTimeoutStr != "" {
+		c.ShutdownTimeout, err = time.ParseDuration(shutdownTimeoutStr)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("cannot parse shutdown timeout environment variable: %w", err))
+		}
+	}
+
+	if enableHTTPSStr := os.Getenv("ENABLE_HTTPS"); enableHTTPSStr != "" {
+		c.EnableHTTPS, err = strconv.ParseBool(enableHTTPSStr)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("cannot parse enable https environment variable: %w", err))
+		}
+	}
+
+	if tlsCertPathStr := os.Getenv("TLS_CERT_PATH"); tlsCertPathStr != "" {
+		c.TLSCertPath = tlsCertPathStr
+	}
+
+	if tlsKeyPathStr := os.Getenv("TLS_KEY_PATH"); tlsKeyPathStr != "" {
+		c.TLSKeyPath = tlsKeyPathStr
+	}
+
+	if blocklistPath := os.Getenv("BLOCKLIST_PATH"); blocklistPath != "" {
+		c.BlocklistPath = blocklistPath
+	}
+
+	if safeBrowsingAPIKey := os.Getenv("SAFE_BROWSING_API_KEY"); safeBrowsingAPIKey != "" {
+		c.SafeBrowsingAPIKey = safeBrowsingAPIKey
+	}
+
+	if trackingParams := os.Getenv("TRACKING_PARAMS"); trackingParams != "" {
+		c.TrackingParams = trackingParams
+	}
+
+	if enableEventsStr := os.Getenv("ENABLE_EVENTS"); enableEventsStr != "" {
+		c.EnableEvents, err = strconv.ParseBool(enableEventsStr)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("cannot parse enable events environment variable: %w", err))
+		}
+	}
+
+	if eventBufferSizeStr := os.Getenv("EVENT_BUFFER_SIZE"); eventBufferSizeStr != "" {
+		c.EventBufferSize, err = strconv.Atoi(eventBufferSizeStr)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("cannot parse event buffer size environment variable: %w", err))
+		}
+	}
+
+	if memorySnapshotPath := os.Getenv("MEMORY_SNAPSHOT_PATH"); memorySnapshotPath != "" {
+		c.MemorySnapshotPath = memorySnapshotPath
+	}
+
+	if memorySnapshotIntervalStr := os.Getenv("MEMORY_SNAPSHOT_INTERVAL"); memorySnapshotIntervalStr != "" {
+		c.MemorySnapshotInterval, err = time.ParseDuration(memorySnapshotIntervalStr)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("cannot parse memory snapshot interval environment variable: %w", err))
+		}
+	}
 
+	if err := validateDatabaseDSN(c.PostgresDSN.DatabaseDSN); err != nil {
+		errs = append(errs, fmt.Errorf("invalid database DSN: %w", err))
 	}
+
+	if err := validateRedisDSN(c.RedisDSN.RedisDSN); err != nil {
+		errs = append(errs, fmt.Errorf("invalid redis DSN: %w", err))
+	}
+
+	return errors.Join(errs...)
+}
+
+// validateDatabaseDSN fails fast on a malformed DATABASE_DSN, rather than deferring the parse
+// error to the first connection attempt made by postgresql.NewDB. An empty DSN is valid here;
+// it means Postgres is not in use and repository.NewRepository falls back to another backend.
+func validateDatabaseDSN(dsn string) error {
+	if dsn == "" {
+		return nil
+	}
+
+	if _, err := pgxpool.ParseConfig(dsn); err != nil {
+		return fmt.Errorf("failed to parse database DSN: %w", err)
+	}
+
+	return nil
+}
+
+// validateRedisDSN fails fast on a malformed REDIS_DSN, rather than deferring the parse error
+// to the first connection attempt made by redis.NewDB. An empty DSN is valid here; it means
+// Redis is not in use and repository.NewRepository falls back to another backend.
+func validateRedisDSN(dsn string) error {
+	if dsn == "" {
+		return nil
+	}
+
+	if _, err := redis.ParseURL(dsn); err != nil {
+		return fmt.Errorf("failed to parse redis DSN: %w", err)
+	}
+
+	return nil
 }
 
-// CheckPostgresConnection validates the connection to PostgreSQL database
-func (c *Config) CheckPostgresConnection(ctx context.Context) error {
-	db, err := sql.Open("pgx", c.PostgresDSN.DatabaseDSN)
+// validateServerAddr rejects a SERVER_ADDRESS that net.SplitHostPort cannot parse into a
+// host and a numeric port. An empty host is valid; it means bind all interfaces. Using
+// net.SplitHostPort (rather than a naive split on ":") also means IPv6 literals like
+// "[::1]:8080" are handled correctly.
+func validateServerAddr(addr string) error {
+	_, port, err := net.SplitHostPort(addr)
 	if err != nil {
-		return err
+		return fmt.Errorf("invalid address %q: %w", addr, err)
+	}
+
+	portNum, err := strconv.Atoi(port)
+	if err != nil || portNum < 0 || portNum > 65535 {
+		return fmt.Errorf("invalid port %q: must be a number between 0 and 65535", port)
 	}
-	defer db.Close()
-	ctxPg, cancel := context.WithTimeout(ctx, 5*time.Second)
-	defer cancel()
-	if err = db.PingContext(ctxPg); err != nil {
-		return err
+
+	return nil
+}
+
+// validateRootBehavior rejects an unknown ROOT_BEHAVIOR and ensures root-redirect-url is
+// set whenever redirect behavior is selected
+func (c *Config) validateRootBehavior() error {
+	switch c.RootBehavior {
+	case "405", "landing":
+	case "redirect":
+		if c.RootRedirectURL == "" {
+			return errors.New("root-redirect-url must be set when root-behavior is redirect")
+		}
+	default:
+		return fmt.Errorf("unknown root behavior %q", c.RootBehavior)
 	}
+
+	return nil
+}
+
+// validateSlugStrategy rejects an unknown SLUG_STRATEGY
+func (c *Config) validateSlugStrategy() error {
+	switch c.SlugStrategy {
+	case "", "random", "sequential", "hash":
+		return nil
+	default:
+		return fmt.Errorf("unknown slug strategy %q", c.SlugStrategy)
+	}
+}
+
+// validateLogFormat rejects an unknown LOG_FORMAT
+func (c *Config) validateLogFormat() error {
+	switch c.LogFormat {
+	case "console", "json":
+		return nil
+	default:
+		return fmt.Errorf("unknown log format %q", c.LogFormat)
+	}
+}
+
+// validateForceShortURLScheme rejects an unknown FORCE_SHORT_URL_SCHEME
+func (c *Config) validateForceShortURLScheme() error {
+	switch c.ForceShortURLScheme {
+	case "", "http", "https", "auto":
+		return nil
+	default:
+		return fmt.Errorf("unknown force short url scheme %q", c.ForceShortURLScheme)
+	}
+}
+
+// validateRedirectStatus rejects an unsupported REDIRECT_STATUS; 0 means unset and falls
+// back to the handler's default
+func (c *Config) validateRedirectStatus() error {
+	switch c.RedirectStatus {
+	case 0, http.StatusMovedPermanently, http.StatusFound, http.StatusTemporaryRedirect, http.StatusPermanentRedirect:
+		return nil
+	default:
+		return fmt.Errorf("unsupported redirect status %d", c.RedirectStatus)
+	}
+}
+
+// resolveSlugPreset applies SlugPreset to SlugAlphabet/SlugLength, with any explicitly
+// configured alphabet or length taking precedence over the preset's values, and validates
+// the resulting keyspace. Leaves SlugAlphabet/SlugLength zero-valued when neither a preset
+// nor an explicit override was configured, so the service package falls back to its own
+// default.
+func (c *Config) resolveSlugPreset() error {
+	explicitAlphabet := c.SlugAlphabet
+	explicitLength := c.SlugLength
+
+	if c.SlugPreset == "" && explicitAlphabet == "" && explicitLength == 0 {
+		return nil
+	}
+
+	var alphabet string
+	var length int
+
+	if c.SlugPreset != "" {
+		preset, ok := slugPresets[c.SlugPreset]
+		if !ok {
+			return fmt.Errorf("unknown slug preset %q", c.SlugPreset)
+		}
+		alphabet, length = preset.Alphabet, preset.Length
+	}
+
+	if explicitAlphabet != "" {
+		alphabet = explicitAlphabet
+	}
+	if explicitLength != 0 {
+		length = explicitLength
+	}
+
+	if err := validateSlugKeyspace(alphabet, length); err != nil {
+		return fmt.Errorf("invalid slug alphabet/length configuration: %w", err)
+	}
+
+	c.SlugAlphabet = alphabet
+	c.SlugLength = length
+	return nil
+}
+
+// validateSlugKeyspace rejects slug alphabet/length combinations that cannot produce slugs
+func validateSlugKeyspace(alphabet string, length int) error {
+	if length <= 0 {
+		return fmt.Errorf("slug length must be positive, got %d", length)
+	}
+
+	if len(alphabet) < 2 {
+		return fmt.Errorf("slug alphabet must contain at least 2 characters")
+	}
+
+	seen := make(map[rune]struct{}, len(alphabet))
+	for _, r := range alphabet {
+		if _, ok := seen[r]; ok {
+			return fmt.Errorf("slug alphabet contains duplicate character %q", r)
+		}
+		seen[r] = struct{}{}
+	}
+
 	return nil
 }
 
@@ -204,3 +1121,359 @@ func (c *Config) GetShortURLTemplate() string {
 func (c *Config) GetLogLevel() zerolog.Level {
 	return c.LogLevel.Level
 }
+
+// GetTrackCreationSource returns whether referer and user-agent should be recorded on short URL creation
+func (c *Config) GetTrackCreationSource() bool {
+	return c.TrackCreationSource
+}
+
+// GetReadOnly returns the initial state of the read-only flag
+func (c *Config) GetReadOnly() bool {
+	return c.ReadOnly
+}
+
+// GetTrustedSubnet returns the CIDR allowed to call admin endpoints
+func (c *Config) GetTrustedSubnet() string {
+	return c.TrustedSubnet
+}
+
+// GetAvoidRecentSlugs returns whether slug generation should bias away from recently generated slugs
+func (c *Config) GetAvoidRecentSlugs() bool {
+	return c.AvoidRecentSlugs
+}
+
+// GetJSONDeleteResponse returns whether DELETE /api/user/urls should return a JSON body with the accepted count
+func (c *Config) GetJSONDeleteResponse() bool {
+	return c.JSONDeleteResponse
+}
+
+// GetDeleteFlushTimeout returns the maximum duration of a single delete worker flush to the repository
+func (c *Config) GetDeleteFlushTimeout() time.Duration {
+	return c.DeleteFlushTimeout
+}
+
+// GetSlugAlphabet returns the resolved slug alphabet, or "" if none was configured and the
+// service package should use its own default
+func (c *Config) GetSlugAlphabet() string {
+	return c.SlugAlphabet
+}
+
+// GetSlugLength returns the resolved slug length, or 0 if none was configured and the
+// service package should use its own default
+func (c *Config) GetSlugLength() int {
+	return c.SlugLength
+}
+
+// GetSlugStrategy returns "random", "sequential", "hash", or "" (use the service package's
+// default, currently random)
+func (c *Config) GetSlugStrategy() string {
+	return c.SlugStrategy
+}
+
+// GetLogFormat returns "console" or "json". Used in logger.NewLogger constructor.
+func (c *Config) GetLogFormat() string {
+	return c.LogFormat
+}
+
+// GetLinkTTL returns the TTL applied to newly created links, or 0 if links never expire
+func (c *Config) GetLinkTTL() time.Duration {
+	return c.LinkTTL
+}
+
+// GetSweepInterval returns the interval between background sweeps of expired links, or 0
+// if the sweeper is disabled
+func (c *Config) GetSweepInterval() time.Duration {
+	return c.SweepInterval
+}
+
+// GetSweepLimit returns the maximum number of expired links processed per sweep, or 0 if
+// none was configured and the service package should use its own default
+func (c *Config) GetSweepLimit() int {
+	return c.SweepLimit
+}
+
+// GetRedirectRateLimitRPS returns the requests-per-second limit applied to GET /{id}
+// redirects, or 0 if redirects are unlimited. It is independent of any creation-endpoint
+// rate limiting.
+func (c *Config) GetRedirectRateLimitRPS() float64 {
+	return c.RedirectRateLimitRPS
+}
+
+// GetCookieDomain returns the domain attribute to set on the auth cookie, or "" to leave it
+// unset and let the browser scope the cookie to the exact host
+func (c *Config) GetCookieDomain() string {
+	return c.CookieDomain
+}
+
+// GetCookieMaxAge returns the max-age of the auth cookie, or 0 to make it a session cookie
+// that expires when the browser closes
+func (c *Config) GetCookieMaxAge() time.Duration {
+	return c.CookieMaxAge
+}
+
+// GetEnableBatch returns whether the POST /api/shorten/batch endpoint should be registered
+func (c *Config) GetEnableBatch() bool {
+	return c.EnableBatch
+}
+
+// GetEnableUserURLs returns whether the GET and PUT /api/user/urls endpoints should be registered
+func (c *Config) GetEnableUserURLs() bool {
+	return c.EnableUserURLs
+}
+
+// GetEnableDelete returns whether the DELETE /api/user/urls endpoint should be registered
+func (c *Config) GetEnableDelete() bool {
+	return c.EnableDelete
+}
+
+// GetRedirectBody returns whether GET /{id} should write the destination URL as a body
+// alongside the Location header
+func (c *Config) GetRedirectBody() bool {
+	return c.RedirectBody
+}
+
+// GetRedirectStatus returns the operator-preferred HTTP status for GET/HEAD /{id} redirects,
+// or 0 to use the handler's default
+func (c *Config) GetRedirectStatus() int {
+	return c.RedirectStatus
+}
+
+// GetRootBehavior returns the configured behavior for GET /: "405", "landing", or "redirect"
+func (c *Config) GetRootBehavior() string {
+	return c.RootBehavior
+}
+
+// GetRootRedirectURL returns the URL that GET / redirects to when root-behavior is redirect
+func (c *Config) GetRootRedirectURL() string {
+	return c.RootRedirectURL
+}
+
+// GetLogBodies returns whether request and response bodies should be logged at trace level
+func (c *Config) GetLogBodies() bool {
+	return c.LogBodies
+}
+
+// GetDeleteGracePeriod returns how long a soft-deleted slug keeps redirecting before
+// returning 410, or 0 if it returns 410 immediately
+func (c *Config) GetDeleteGracePeriod() time.Duration {
+	return c.DeleteGracePeriod
+}
+
+// GetPurgeBatchSize returns the maximum number of soft-deleted rows permanently removed
+// per batch by the admin purge endpoint, or 0 if none was configured and the service
+// package should use its own default
+func (c *Config) GetPurgeBatchSize() int {
+	return c.PurgeBatchSize
+}
+
+// GetPurgeBatchPause returns the pause between batches during an admin purge of
+// soft-deleted rows, or 0 for no pause
+func (c *Config) GetPurgeBatchPause() time.Duration {
+	return c.PurgeBatchPause
+}
+
+// GetPurgeRetention returns the minimum age of a soft-deleted row before it is eligible
+// for the admin purge, unless overridden per-request
+func (c *Config) GetPurgeRetention() time.Duration {
+	return c.PurgeRetention
+}
+
+// GetHTTPSUpgradeHosts returns the hostnames eligible for automatic http-to-https upgrade
+// on save, or nil if the upgrade is disabled or no hosts were configured
+func (c *Config) GetHTTPSUpgradeHosts() []string {
+	if !c.UpgradeHTTPToHTTPS || c.HTTPSUpgradeHosts == "" {
+		return nil
+	}
+
+	hosts := strings.Split(c.HTTPSUpgradeHosts, ",")
+	for i, host := range hosts {
+		hosts[i] = strings.TrimSpace(host)
+	}
+
+	return hosts
+}
+
+// GetDeleteQueueLimit returns the maximum number of slugs awaiting asynchronous deletion,
+// or 0 if the backlog is unlimited
+func (c *Config) GetDeleteQueueLimit() int {
+	return c.DeleteQueueLimit
+}
+
+// GetDeleteWorkers returns the number of background workers consuming the asynchronous
+// delete queue, or 0 to let the service package pick its default
+func (c *Config) GetDeleteWorkers() int {
+	return c.DeleteWorkers
+}
+
+// GetCacheSize returns the maximum number of resolved short URLs cached in front of the
+// repository, or 0 if the cache is disabled
+func (c *Config) GetCacheSize() int {
+	return c.CacheSize
+}
+
+// GetCacheTTL returns how long a cached short URL resolution stays valid, or 0 if the cache
+// is disabled
+func (c *Config) GetCacheTTL() time.Duration {
+	return c.CacheTTL
+}
+
+// GetMaxExpandBatch returns the maximum number of slugs resolvable in one expand-batch request
+func (c *Config) GetMaxExpandBatch() int {
+	return c.MaxExpandBatch
+}
+
+// GetEnableJSONNegotiation returns whether routes that otherwise respond with plain text or a
+// raw redirect should honor Accept: application/json and return a JSON representation instead
+func (c *Config) GetEnableJSONNegotiation() bool {
+	return c.EnableJSONNegotiation
+}
+
+// GetAutoExtendSlug returns whether a generated-slug save should retry at progressively longer
+// lengths after exhausting collision retries at the configured length, instead of failing the
+// request
+func (c *Config) GetAutoExtendSlug() bool {
+	return c.AutoExtendSlug
+}
+
+// GetEnableImportStream returns whether the POST /api/user/urls/import/stream endpoint
+// should be registered
+func (c *Config) GetEnableImportStream() bool {
+	return c.EnableImportStream
+}
+
+// GetImportStreamMaxLine returns the maximum size in bytes of a single line accepted by the
+// import stream endpoint
+func (c *Config) GetImportStreamMaxLine() int {
+	return c.ImportStreamMaxLine
+}
+
+// GetImportStreamRateLimit returns the lines-per-second limit applied to the import stream
+// endpoint; 0 disables the limit
+func (c *Config) GetImportStreamRateLimit() float64 {
+	return c.ImportStreamRateLimit
+}
+
+// GetAnonymousLinkTTL returns the TTL applied to links saved by an identity that has not been
+// claimed via POST /api/user/claim, or 0 to fall back to GetLinkTTL
+func (c *Config) GetAnonymousLinkTTL() time.Duration {
+	return c.AnonymousLinkTTL
+}
+
+// GetForceShortURLScheme returns "http", "https", "auto", or "" (use the short URL
+// template's scheme as-is)
+func (c *Config) GetForceShortURLScheme() string {
+	return c.ForceShortURLScheme
+}
+
+// GetPrivateLinks returns whether GET /{id} is restricted to the slug's owner
+func (c *Config) GetPrivateLinks() bool {
+	return c.PrivateLinks
+}
+
+// GetMaxResponseURLs returns the maximum number of URLs GET /api/user/urls ever returns,
+// or 0 if unlimited
+func (c *Config) GetMaxResponseURLs() int {
+	return c.MaxResponseURLs
+}
+
+// GetAuditLogPath returns the file path structured deletion and purge audit entries are
+// appended to, or an empty string if they should go to stdout instead
+func (c *Config) GetAuditLogPath() string {
+	return c.AuditLogPath
+}
+
+// GetAdminAddress returns the address the /api/internal/* routes are served on when they
+// should be split off the public listener, or an empty string if they stay on it
+func (c *Config) GetAdminAddress() string {
+	return c.AdminAddress
+}
+
+// GetDBPingInterval returns the interval between background pings of the postgres connection
+// pool, or 0 if the warmup loop is disabled
+func (c *Config) GetDBPingInterval() time.Duration {
+	return c.DBPingInterval
+}
+
+// GetShutdownTimeout returns how long a graceful shutdown waits for in-flight requests and
+// the pending delete queue to drain before proceeding anyway
+func (c *Config) GetShutdownTimeout() time.Duration {
+	return c.ShutdownTimeout
+}
+
+// GetEnableHTTPS reports whether the server should serve over HTTPS instead of plain HTTP
+func (c *Config) GetEnableHTTPS() bool {
+	return c.EnableHTTPS
+}
+
+// GetTLSCertPath returns the path to the TLS certificate used when GetEnableHTTPS is true
+func (c *Config) GetTLSCertPath() string {
+	return c.TLSCertPath
+}
+
+// GetTLSKeyPath returns the path to the TLS private key used when GetEnableHTTPS is true
+func (c *Config) GetTLSKeyPath() string {
+	return c.TLSKeyPath
+}
+
+// GetCreationRateLimitPerUserRPS returns the requests-per-second limit applied per user
+// UUID on the plain-text and JSON shorten endpoints; 0 disables the limit
+func (c *Config) GetCreationRateLimitPerUserRPS() float64 {
+	return c.CreationRateLimitPerUserRPS
+}
+
+// GetCreationRateLimitPerIPRPS returns the requests-per-second limit applied per client IP
+// on the plain-text and JSON shorten endpoints; 0 disables the limit
+func (c *Config) GetCreationRateLimitPerIPRPS() float64 {
+	return c.CreationRateLimitPerIPRPS
+}
+
+// GetBlocklistPath returns the file of blocked hosts checked on URL creation, or an empty
+// string if the blocklist check is disabled
+func (c *Config) GetBlocklistPath() string {
+	return c.BlocklistPath
+}
+
+// GetSafeBrowsingAPIKey returns the Google Safe Browsing API key used to check URLs on
+// creation, or an empty string if the check is disabled
+func (c *Config) GetSafeBrowsingAPIKey() string {
+	return c.SafeBrowsingAPIKey
+}
+
+// GetTrackingParams returns the query parameter names stripped when canonicalizing a URL
+// on creation, or nil if none were configured
+func (c *Config) GetTrackingParams() []string {
+	if c.TrackingParams == "" {
+		return nil
+	}
+
+	params := strings.Split(c.TrackingParams, ",")
+	for i, param := range params {
+		params[i] = strings.TrimSpace(param)
+	}
+
+	return params
+}
+
+// GetEnableEvents returns whether the GET /api/events Server-Sent Events endpoint should be
+// registered
+func (c *Config) GetEnableEvents() bool {
+	return c.EnableEvents
+}
+
+// GetEventBufferSize returns the number of events buffered per subscriber of GET /api/events
+// before the slowest ones are dropped, or 0 to use the package default
+func (c *Config) GetEventBufferSize() int {
+	return c.EventBufferSize
+}
+
+// GetMemorySnapshotPath returns the file the memory backend periodically dumps its state to
+// and restores from on startup, or an empty string if snapshotting is disabled
+func (c *Config) GetMemorySnapshotPath() string {
+	return c.MemorySnapshotPath
+}
+
+// GetMemorySnapshotInterval returns the interval between memory backend snapshots, or 0 if
+// periodic snapshotting is disabled
+func (c *Config) GetMemorySnapshotInterval() time.Duration {
+	return c.MemorySnapshotInterval
+}
@@ -0,0 +1,80 @@
+// Package encoding provides shared base62/base58 encoding utilities for turning numeric
+// IDs into short, URL-safe strings. It centralizes the logic needed by slug schemes such as
+// sequence-based or hash-derived slugs so it is implemented and tested once.
+package encoding
+
+import (
+	"errors"
+	"strings"
+)
+
+const (
+	base62Alphabet = "0123456789ABCDEFGHIJKLMNOPQRSTUVWXYZabcdefghijklmnopqrstuvwxyz"
+	base58Alphabet = "123456789ABCDEFGHJKLMNPQRSTUVWXYZabcdefghijkmnopqrstuvwxyz"
+)
+
+// ErrInvalidCharacter is returned when a string being decoded contains a character outside
+// the target alphabet
+var ErrInvalidCharacter = errors.New("invalid character for encoding alphabet")
+
+// EncodeBase62 encodes n using the base62 alphabet (0-9, A-Z, a-z). It returns "0" for n == 0.
+func EncodeBase62(n uint64) string {
+	return encode(n, base62Alphabet)
+}
+
+// DecodeBase62 decodes a base62-encoded string back into its numeric value
+func DecodeBase62(s string) (uint64, error) {
+	return decode(s, base62Alphabet)
+}
+
+// EncodeBase58 encodes n using the base58 alphabet (Bitcoin-style, excludes 0/O/I/l to avoid
+// visual ambiguity). It returns "1" for n == 0.
+func EncodeBase58(n uint64) string {
+	return encode(n, base58Alphabet)
+}
+
+// DecodeBase58 decodes a base58-encoded string back into its numeric value
+func DecodeBase58(s string) (uint64, error) {
+	return decode(s, base58Alphabet)
+}
+
+// encode converts n into a string of characters from alphabet, most significant digit first
+func encode(n uint64, alphabet string) string {
+	base := uint64(len(alphabet))
+	if n == 0 {
+		return string(alphabet[0])
+	}
+
+	var sb strings.Builder
+	for n > 0 {
+		sb.WriteByte(alphabet[n%base])
+		n /= base
+	}
+
+	encoded := []byte(sb.String())
+	for i, j := 0, len(encoded)-1; i < j; i, j = i+1, j-1 {
+		encoded[i], encoded[j] = encoded[j], encoded[i]
+	}
+
+	return string(encoded)
+}
+
+// decode converts a string of characters from alphabet back into its numeric value
+func decode(s string, alphabet string) (uint64, error) {
+	base := uint64(len(alphabet))
+	index := make(map[byte]uint64, len(alphabet))
+	for i := 0; i < len(alphabet); i++ {
+		index[alphabet[i]] = uint64(i)
+	}
+
+	var n uint64
+	for i := 0; i < len(s); i++ {
+		digit, ok := index[s[i]]
+		if !ok {
+			return 0, ErrInvalidCharacter
+		}
+		n = n*base + digit
+	}
+
+	return n, nil
+}
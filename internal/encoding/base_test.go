@@ -0,0 +1,86 @@
+package encoding
+
+import (
+	"math"
+	"testing"
+)
+
+func TestEncodeDecodeBase62_RoundTrip(t *testing.T) {
+	values := []uint64{0, 1, 61, 62, 63, 12345, math.MaxUint32, math.MaxUint64}
+
+	for _, v := range values {
+		encoded := EncodeBase62(v)
+		decoded, err := DecodeBase62(encoded)
+		if err != nil {
+			t.Fatalf("DecodeBase62(%q) error: %v", encoded, err)
+		}
+		if decoded != v {
+			t.Errorf("round trip for %d: encoded %q decoded back to %d", v, encoded, decoded)
+		}
+	}
+}
+
+func TestEncodeBase62_Zero(t *testing.T) {
+	if got := EncodeBase62(0); got != "0" {
+		t.Errorf("EncodeBase62(0) = %q, want %q", got, "0")
+	}
+}
+
+func TestDecodeBase62_InvalidCharacter(t *testing.T) {
+	_, err := DecodeBase62("abc!def")
+	if err != ErrInvalidCharacter {
+		t.Errorf("DecodeBase62 error = %v, want %v", err, ErrInvalidCharacter)
+	}
+}
+
+func TestEncodeDecodeBase58_RoundTrip(t *testing.T) {
+	values := []uint64{0, 1, 57, 58, 59, 12345, math.MaxUint32, math.MaxUint64}
+
+	for _, v := range values {
+		encoded := EncodeBase58(v)
+		decoded, err := DecodeBase58(encoded)
+		if err != nil {
+			t.Fatalf("DecodeBase58(%q) error: %v", encoded, err)
+		}
+		if decoded != v {
+			t.Errorf("round trip for %d: encoded %q decoded back to %d", v, encoded, decoded)
+		}
+	}
+}
+
+func TestEncodeBase58_Zero(t *testing.T) {
+	if got := EncodeBase58(0); got != "1" {
+		t.Errorf("EncodeBase58(0) = %q, want %q", got, "1")
+	}
+}
+
+func TestDecodeBase58_InvalidCharacter(t *testing.T) {
+	// '0', 'O', 'I', 'l' are excluded from the base58 alphabet
+	for _, s := range []string{"0abc", "Oabc", "Iabc", "labc"} {
+		if _, err := DecodeBase58(s); err != ErrInvalidCharacter {
+			t.Errorf("DecodeBase58(%q) error = %v, want %v", s, err, ErrInvalidCharacter)
+		}
+	}
+}
+
+func TestEncodeBase62_NoInvalidCharactersInAlphabet(t *testing.T) {
+	seen := make(map[byte]bool)
+	for i := 0; i < len(base62Alphabet); i++ {
+		c := base62Alphabet[i]
+		if seen[c] {
+			t.Fatalf("base62 alphabet contains duplicate character %q", c)
+		}
+		seen[c] = true
+	}
+}
+
+func TestEncodeBase58_NoInvalidCharactersInAlphabet(t *testing.T) {
+	seen := make(map[byte]bool)
+	for i := 0; i < len(base58Alphabet); i++ {
+		c := base58Alphabet[i]
+		if seen[c] {
+			t.Fatalf("base58 alphabet contains duplicate character %q", c)
+		}
+		seen[c] = true
+	}
+}
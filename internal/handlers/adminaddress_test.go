@@ -0,0 +1,76 @@
+package handlers
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestNewRouter_InternalRoutesOnPublicRouterByDefault(t *testing.T) {
+	h := newTestHandler(fakeConfig{trustedSubnet: "192.168.1.0/24"})
+	router := h.newRouter()
+
+	req := httptest.NewRequest(http.MethodGet, "/api/internal/top-hosts", nil)
+	req.RemoteAddr = "192.168.1.10:12345"
+	rec := httptest.NewRecorder()
+	router.ServeHTTP(rec, req)
+
+	if rec.Code == http.StatusNotFound {
+		t.Fatalf("got 404 for /api/internal/top-hosts on public router, want it present when GetAdminAddress is empty")
+	}
+}
+
+func TestNewRouter_InternalRoutesAbsentWhenAdminAddressSet(t *testing.T) {
+	h := newTestHandler(fakeConfig{trustedSubnet: "192.168.1.0/24", adminAddress: "localhost:9090"})
+	router := h.newRouter()
+
+	req := httptest.NewRequest(http.MethodGet, "/api/internal/top-hosts", nil)
+	req.RemoteAddr = "192.168.1.10:12345"
+	rec := httptest.NewRecorder()
+	router.ServeHTTP(rec, req)
+
+	if rec.Code != http.StatusNotFound {
+		t.Errorf("got status %d for /api/internal/top-hosts on public router, want %d when GetAdminAddress is set", rec.Code, http.StatusNotFound)
+	}
+}
+
+func TestNewAdminRouter_ServesInternalRoutes(t *testing.T) {
+	h := newTestHandler(fakeConfig{trustedSubnet: "192.168.1.0/24", adminAddress: "localhost:9090"})
+	router := h.newAdminRouter()
+
+	req := httptest.NewRequest(http.MethodGet, "/api/internal/top-hosts", nil)
+	req.RemoteAddr = "192.168.1.10:12345"
+	rec := httptest.NewRecorder()
+	router.ServeHTTP(rec, req)
+
+	if rec.Code == http.StatusNotFound {
+		t.Fatalf("got 404 for /api/internal/top-hosts on admin router, want it present")
+	}
+}
+
+func TestNewAdminRouter_ServesOnlyInternalRoutes(t *testing.T) {
+	h := newTestHandler(fakeConfig{adminAddress: "localhost:9090"})
+	router := h.newAdminRouter()
+
+	req := httptest.NewRequest(http.MethodPost, "/", nil)
+	rec := httptest.NewRecorder()
+	router.ServeHTTP(rec, req)
+
+	if rec.Code != http.StatusNotFound {
+		t.Errorf("got status %d for / on admin router, want %d", rec.Code, http.StatusNotFound)
+	}
+}
+
+func TestNewAdminRouter_StillEnforcesTrustedSubnet(t *testing.T) {
+	h := newTestHandler(fakeConfig{trustedSubnet: "192.168.1.0/24", adminAddress: "localhost:9090"})
+	router := h.newAdminRouter()
+
+	req := httptest.NewRequest(http.MethodGet, "/api/internal/top-hosts", nil)
+	req.RemoteAddr = "10.0.0.5:12345"
+	rec := httptest.NewRecorder()
+	router.ServeHTTP(rec, req)
+
+	if rec.Code != http.StatusForbidden {
+		t.Errorf("got status %d for an untrusted caller on the admin router, want %d", rec.Code, http.StatusForbidden)
+	}
+}
@@ -0,0 +1,58 @@
+package handlers
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"github.com/go-chi/chi/v5"
+)
+
+// postUserAPIKey handles POST /api/user/apikeys: issues a new API key tied to the caller's
+// identity, for use as an Authorization: Bearer <api-key> header on later requests. The
+// plaintext key is returned once, in this response, and never again.
+func (h Handler) postUserAPIKey(w http.ResponseWriter, r *http.Request) {
+	userUUID, err := h.getUserUUIDFromRequest(r)
+	if err != nil {
+		h.writeError(w, r, err, http.StatusBadRequest)
+		return
+	}
+
+	key, err := h.auth.GenerateAPIKey()
+	if err != nil {
+		h.reqLog(r).Error().Msgf("Error generating API key: %v", err)
+		h.writeError(w, r, err, http.StatusInternalServerError)
+		return
+	}
+
+	if err = h.service.CreateAPIKey(r.Context(), userUUID, h.auth.HashAPIKey(key)); err != nil {
+		h.writeError(w, r, err, http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusCreated)
+	if err = json.NewEncoder(w).Encode(APIKeyResp{Key: key}); err != nil {
+		h.reqLog(r).Debug().Msgf("error encoding response: %v", err)
+	}
+}
+
+// deleteUserAPIKey handles DELETE /api/user/apikeys/{id}: revokes the key whose hash is id,
+// if it is owned by the caller. A key id that exists but is owned by someone else is reported
+// as 403, distinct from the 204 reported for an id that does not exist at all.
+func (h Handler) deleteUserAPIKey(w http.ResponseWriter, r *http.Request) {
+	userUUID, err := h.getUserUUIDFromRequest(r)
+	if err != nil {
+		h.writeError(w, r, err, http.StatusBadRequest)
+		return
+	}
+
+	keyHash := chi.URLParam(r, "id")
+
+	if err = h.service.RevokeAPIKey(r.Context(), userUUID, keyHash); err != nil {
+		statusCode := h.getStatusCode(err)
+		h.writeError(w, r, err, statusCode)
+		return
+	}
+
+	w.WriteHeader(http.StatusOK)
+}
@@ -2,53 +2,121 @@ package handlers
 
 import (
 	"context"
+	"errors"
 	"net/http"
+	"strings"
 
 	"github.com/google/uuid"
 )
 
+// errInvalidAPIKey is returned when the Authorization: Bearer header does not resolve to a
+// known, unrevoked API key
+var errInvalidAPIKey = errors.New("Invalid API key")
+
+// errInvalidCookie is returned when the user_id cookie fails signature or claim validation
+var errInvalidCookie = errors.New("Invalid cookie")
+
 // contextKey is a personal type for context UUID keys
 type contextUUIDKey string
 
 // userUUIDKey is a unique key for user_id in context
 const userUUIDKey contextUUIDKey = "user_id"
 
+// anonymousKey is a unique key for the request identity's anonymous flag in context
+const anonymousKey contextUUIDKey = "anonymous"
+
 // authMiddleware used as middleware for authentication
 func (h Handler) authMiddleware(next http.Handler) http.Handler {
 	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if apiKey, ok := bearerAPIKey(r); ok {
+			userUUID, err := h.service.ResolveAPIKey(r.Context(), h.auth.HashAPIKey(apiKey))
+			if err != nil {
+				h.reqLog(r).Error().Msgf("Error resolving API key: %v", err)
+				h.writeError(w, r, errInvalidAPIKey, http.StatusUnauthorized)
+				return
+			}
+
+			ctx := context.WithValue(r.Context(), userUUIDKey, userUUID.String())
+			ctx = context.WithValue(ctx, anonymousKey, false)
+			next.ServeHTTP(w, r.WithContext(ctx))
+			return
+		}
+
 		cookie, err := r.Cookie("user_id")
 		var (
 			tokenString string
 			userUUID    uuid.UUID
+			anonymous   bool
 		)
 
 		if err != nil || cookie == nil {
 			// If no cookie - creating new userUUID and JWT token
 			userUUID = h.auth.GenerateUserUUID()
-			tokenString, err = h.auth.BuildJWTString(userUUID)
+			anonymous = true
+			tokenString, err = h.auth.BuildJWTString(userUUID, anonymous)
 			if err != nil {
-				http.Error(w, err.Error(), http.StatusInternalServerError)
-				h.zlog.Error().Msgf("Error building JWT string: %v", err)
+				h.reqLog(r).Error().Msgf("Error building JWT string: %v", err)
+				h.writeError(w, r, err, http.StatusInternalServerError)
+				return
 			}
-			http.SetCookie(w, &http.Cookie{
-				Name:     "user_id",
-				Value:    tokenString,
-				Path:     "/",
-				HttpOnly: true,
-				Secure:   false, // true when HTTPS in prod
-				SameSite: http.SameSiteLaxMode,
-			})
+			h.setUserCookie(w, tokenString)
 		} else {
 			// Checking existing cookie
-			userUUID, err = h.auth.ValidateUserUUID(cookie.Value)
+			userUUID, anonymous, err = h.auth.ValidateUserUUID(cookie.Value)
 			if err != nil {
-				h.zlog.Error().Msgf("Error validating user UUID: %v", err)
-				http.Error(w, "Invalid cookie", http.StatusUnauthorized)
+				h.reqLog(r).Error().Msgf("Error validating user UUID: %v", err)
+				h.writeError(w, r, errInvalidCookie, http.StatusUnauthorized)
 				return
 			}
 		}
-		ctxUserUUID := userUUID.String()
-		ctx := context.WithValue(r.Context(), userUUIDKey, ctxUserUUID)
+		ctx := context.WithValue(r.Context(), userUUIDKey, userUUID.String())
+		ctx = context.WithValue(ctx, anonymousKey, anonymous)
 		next.ServeHTTP(w, r.WithContext(ctx))
 	})
 }
+
+// bearerAPIKey extracts the plaintext API key from an Authorization: Bearer <api-key> header,
+// if one is present on r
+func bearerAPIKey(r *http.Request) (string, bool) {
+	const prefix = "Bearer "
+	header := r.Header.Get("Authorization")
+	if !strings.HasPrefix(header, prefix) {
+		return "", false
+	}
+
+	return strings.TrimPrefix(header, prefix), true
+}
+
+// setUserCookie issues the user_id cookie carrying tokenString
+func (h Handler) setUserCookie(w http.ResponseWriter, tokenString string) {
+	http.SetCookie(w, &http.Cookie{
+		Name:     "user_id",
+		Value:    tokenString,
+		Path:     "/",
+		Domain:   h.cfg.GetCookieDomain(),
+		MaxAge:   int(h.cfg.GetCookieMaxAge().Seconds()),
+		HttpOnly: true,
+		Secure:   h.cfg.GetEnableHTTPS(),
+		SameSite: http.SameSiteLaxMode,
+	})
+}
+
+// claimUser marks the caller's identity as no longer anonymous, exempting links it saves from
+// that point on from the anonymous-link TTL. It reissues the user_id cookie with Anonymous=false.
+func (h Handler) claimUser(w http.ResponseWriter, r *http.Request) {
+	userUUID, err := h.getUserUUIDFromRequest(r)
+	if err != nil {
+		h.writeError(w, r, err, http.StatusBadRequest)
+		return
+	}
+
+	tokenString, err := h.auth.BuildJWTString(userUUID, false)
+	if err != nil {
+		h.reqLog(r).Error().Msgf("Error building JWT string: %v", err)
+		h.writeError(w, r, err, http.StatusInternalServerError)
+		return
+	}
+
+	h.setUserCookie(w, tokenString)
+	w.WriteHeader(http.StatusOK)
+}
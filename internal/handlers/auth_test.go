@@ -0,0 +1,212 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/rs/zerolog"
+)
+
+// jwtBuildErrAuth wraps fakeAuth, failing BuildJWTString so tests can exercise the
+// auto-mint error path without a real token generation error
+type jwtBuildErrAuth struct {
+	fakeAuth
+}
+
+func (jwtBuildErrAuth) BuildJWTString(_ uuid.UUID, _ bool) (string, error) {
+	return "", errors.New("build failed")
+}
+
+// invalidCookieAuth wraps fakeAuth, failing ValidateUserUUID so tests can exercise the
+// invalid-cookie rejection path
+type invalidCookieAuth struct {
+	fakeAuth
+}
+
+func (invalidCookieAuth) ValidateUserUUID(_ string) (uuid.UUID, bool, error) {
+	return uuid.Nil, false, errors.New("invalid token")
+}
+
+func TestAuthMiddleware_SetsCookieDomainAndMaxAge(t *testing.T) {
+	h := newTestHandler(fakeConfig{cookieDomain: "example.com", cookieMaxAge: time.Hour})
+
+	next := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	})
+
+	req := httptest.NewRequest(http.MethodGet, "/", nil)
+	rec := httptest.NewRecorder()
+
+	h.authMiddleware(next).ServeHTTP(rec, req)
+
+	cookies := rec.Result().Cookies()
+	if len(cookies) != 1 {
+		t.Fatalf("got %d cookies, want 1", len(cookies))
+	}
+	cookie := cookies[0]
+	if cookie.Domain != "example.com" {
+		t.Errorf("cookie domain = %q, want %q", cookie.Domain, "example.com")
+	}
+	if cookie.MaxAge != 3600 {
+		t.Errorf("cookie max-age = %d, want 3600", cookie.MaxAge)
+	}
+}
+
+func TestAuthMiddleware_SecureCookieWhenHTTPSEnabled(t *testing.T) {
+	h := newTestHandler(fakeConfig{enableHTTPS: true})
+
+	next := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	})
+
+	req := httptest.NewRequest(http.MethodGet, "/", nil)
+	rec := httptest.NewRecorder()
+
+	h.authMiddleware(next).ServeHTTP(rec, req)
+
+	cookies := rec.Result().Cookies()
+	if len(cookies) != 1 {
+		t.Fatalf("got %d cookies, want 1", len(cookies))
+	}
+	if !cookies[0].Secure {
+		t.Error("cookie Secure = false, want true when HTTPS is enabled")
+	}
+}
+
+func TestAuthMiddleware_ValidCookiePresent_DoesNotReissue(t *testing.T) {
+	h := newTestHandler(fakeConfig{})
+
+	nextCalled := false
+	next := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		nextCalled = true
+		w.WriteHeader(http.StatusOK)
+	})
+
+	req := httptest.NewRequest(http.MethodGet, "/", nil)
+	req.AddCookie(&http.Cookie{Name: "user_id", Value: "valid-token"})
+	rec := httptest.NewRecorder()
+
+	h.authMiddleware(next).ServeHTTP(rec, req)
+
+	if !nextCalled {
+		t.Error("next handler was not called for a valid cookie")
+	}
+	if cookies := rec.Result().Cookies(); len(cookies) != 0 {
+		t.Errorf("got %d Set-Cookie headers, want 0 when a valid cookie is already present", len(cookies))
+	}
+}
+
+func TestAuthMiddleware_InvalidCookie_RejectsWithoutCallingNext(t *testing.T) {
+	h := NewHandler(fakeService{}, fakeConfig{}, invalidCookieAuth{}, zerolog.Nop(), fakeReadiness{})
+
+	nextCalled := false
+	next := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		nextCalled = true
+		w.WriteHeader(http.StatusOK)
+	})
+
+	req := httptest.NewRequest(http.MethodGet, "/", nil)
+	req.AddCookie(&http.Cookie{Name: "user_id", Value: "garbage"})
+	rec := httptest.NewRecorder()
+
+	h.authMiddleware(next).ServeHTTP(rec, req)
+
+	if nextCalled {
+		t.Error("next handler was called despite an invalid cookie")
+	}
+	if rec.Code != http.StatusUnauthorized {
+		t.Errorf("got status %d, want %d", rec.Code, http.StatusUnauthorized)
+	}
+	if cookies := rec.Result().Cookies(); len(cookies) != 0 {
+		t.Errorf("got %d Set-Cookie headers, want 0 for an invalid cookie", len(cookies))
+	}
+}
+
+func TestAuthMiddleware_JWTBuildError_RejectsWithoutCallingNextOrCookie(t *testing.T) {
+	h := NewHandler(fakeService{}, fakeConfig{}, jwtBuildErrAuth{}, zerolog.Nop(), fakeReadiness{})
+
+	nextCalled := false
+	next := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		nextCalled = true
+		w.WriteHeader(http.StatusOK)
+	})
+
+	req := httptest.NewRequest(http.MethodGet, "/", nil)
+	rec := httptest.NewRecorder()
+
+	h.authMiddleware(next).ServeHTTP(rec, req)
+
+	if nextCalled {
+		t.Error("next handler was called despite a JWT build error")
+	}
+	if rec.Code != http.StatusInternalServerError {
+		t.Errorf("got status %d, want %d", rec.Code, http.StatusInternalServerError)
+	}
+	if cookies := rec.Result().Cookies(); len(cookies) != 0 {
+		t.Errorf("got %d Set-Cookie headers, want 0 when the auto-mint path fails", len(cookies))
+	}
+}
+
+func TestClaimUser_ReissuesCookie(t *testing.T) {
+	h := newTestHandler(fakeConfig{})
+
+	req := httptest.NewRequest(http.MethodPost, "/api/user/claim", nil)
+	req.AddCookie(&http.Cookie{Name: "user_id", Value: "valid-token"})
+	rec := httptest.NewRecorder()
+
+	h.newRouter().ServeHTTP(rec, req)
+
+	if rec.Code != http.StatusOK {
+		t.Fatalf("got status %d, want %d", rec.Code, http.StatusOK)
+	}
+	cookies := rec.Result().Cookies()
+	if len(cookies) != 1 {
+		t.Fatalf("got %d cookies, want 1", len(cookies))
+	}
+	if cookies[0].Name != "user_id" {
+		t.Errorf("cookie name = %q, want %q", cookies[0].Name, "user_id")
+	}
+}
+
+func TestClaimUser_JWTBuildError_Returns500(t *testing.T) {
+	h := NewHandler(fakeService{}, fakeConfig{}, jwtBuildErrAuth{}, zerolog.Nop(), fakeReadiness{})
+
+	req := httptest.NewRequest(http.MethodPost, "/api/user/claim", nil)
+	req.AddCookie(&http.Cookie{Name: "user_id", Value: "valid-token"})
+	rec := httptest.NewRecorder()
+
+	h.newRouter().ServeHTTP(rec, req)
+
+	if rec.Code != http.StatusInternalServerError {
+		t.Errorf("got status %d, want %d", rec.Code, http.StatusInternalServerError)
+	}
+}
+
+func TestAuthMiddleware_NoCookieDomainOrMaxAgeByDefault(t *testing.T) {
+	h := newTestHandler(fakeConfig{})
+
+	next := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	})
+
+	req := httptest.NewRequest(http.MethodGet, "/", nil)
+	rec := httptest.NewRecorder()
+
+	h.authMiddleware(next).ServeHTTP(rec, req)
+
+	cookies := rec.Result().Cookies()
+	if len(cookies) != 1 {
+		t.Fatalf("got %d cookies, want 1", len(cookies))
+	}
+	cookie := cookies[0]
+	if cookie.Domain != "" {
+		t.Errorf("cookie domain = %q, want empty", cookie.Domain)
+	}
+	if cookie.MaxAge != 0 {
+		t.Errorf("cookie max-age = %d, want 0 (session cookie)", cookie.MaxAge)
+	}
+}
@@ -0,0 +1,89 @@
+package handlers
+
+import (
+	"bytes"
+	"io"
+	"net/http"
+	"regexp"
+)
+
+// bodyLogCap bounds how much of a request or response body is captured for logging, so a
+// large upload or download cannot blow up the log
+const bodyLogCap = 4096
+
+// sensitiveFieldPattern matches common sensitive JSON fields so their values can be redacted
+// before a body is logged
+var sensitiveFieldPattern = regexp.MustCompile(`(?i)"(password|secret|token|api_key|authorization)"\s*:\s*"[^"]*"`)
+
+// redactSensitiveFields replaces the value of any sensitive field matched by
+// sensitiveFieldPattern with "***"
+func redactSensitiveFields(body []byte) []byte {
+	return sensitiveFieldPattern.ReplaceAll(body, []byte(`"$1":"***"`))
+}
+
+// truncateBody returns at most bodyLogCap bytes of body
+func truncateBody(body []byte) []byte {
+	if len(body) > bodyLogCap {
+		return body[:bodyLogCap]
+	}
+	return body
+}
+
+// bodyCapturingResponseWriter wraps http.ResponseWriter to capture up to bodyLogCap bytes of
+// the response body alongside writing it through unchanged
+type bodyCapturingResponseWriter struct {
+	http.ResponseWriter
+	captured bytes.Buffer
+}
+
+// Write captures up to bodyLogCap bytes of b before passing it through to the wrapped writer
+func (w *bodyCapturingResponseWriter) Write(b []byte) (int, error) {
+	if remaining := bodyLogCap - w.captured.Len(); remaining > 0 {
+		if remaining > len(b) {
+			remaining = len(b)
+		}
+		w.captured.Write(b[:remaining])
+	}
+	return w.ResponseWriter.Write(b)
+}
+
+// Flush forwards to the wrapped ResponseWriter's Flush when it supports it, so a streaming
+// handler further down the chain still flushes through this wrapper when LOG_BODIES is set.
+func (w *bodyCapturingResponseWriter) Flush() {
+	if f, ok := w.ResponseWriter.(http.Flusher); ok {
+		f.Flush()
+	}
+}
+
+// bodyLoggingMiddleware logs request and response bodies at trace level when LOG_BODIES is
+// enabled, for diagnosing client integration issues. It reads and restores r.Body so the
+// handler still sees the full body, and wraps the response writer to capture its output.
+// Logged bodies are capped at bodyLogCap and have sensitive fields redacted.
+func (h Handler) bodyLoggingMiddleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if !h.cfg.GetLogBodies() {
+			next.ServeHTTP(w, r)
+			return
+		}
+
+		var reqBody []byte
+		if r.Body != nil {
+			bodyBytes, err := io.ReadAll(r.Body)
+			r.Body.Close()
+			if err == nil {
+				r.Body = io.NopCloser(bytes.NewReader(bodyBytes))
+				reqBody = redactSensitiveFields(truncateBody(bodyBytes))
+			}
+		}
+
+		bw := &bodyCapturingResponseWriter{ResponseWriter: w}
+		next.ServeHTTP(bw, r)
+
+		h.reqLog(r).Trace().
+			Str("method", r.Method).
+			Str("url", r.RequestURI).
+			Bytes("request_body", reqBody).
+			Bytes("response_body", redactSensitiveFields(truncateBody(bw.captured.Bytes()))).
+			Msg("request/response body")
+	})
+}
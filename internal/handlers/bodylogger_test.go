@@ -0,0 +1,90 @@
+package handlers
+
+import (
+	"bytes"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/rs/zerolog"
+)
+
+func newBodyLoggingTestHandler(logBodies bool, buf *bytes.Buffer) *Handler {
+	zlog := zerolog.New(buf).Level(zerolog.TraceLevel)
+	return NewHandler(fakeService{}, fakeConfig{logBodies: logBodies}, fakeAuth{}, zlog, fakeReadiness{})
+}
+
+func TestBodyLoggingMiddleware_LogsBodiesWhenEnabled(t *testing.T) {
+	var buf bytes.Buffer
+	h := newBodyLoggingTestHandler(true, &buf)
+	router := h.newRouter()
+
+	req := httptest.NewRequest(http.MethodPost, "/api/shorten", strings.NewReader(`{"url":"https://example.com"}`))
+	req.Header.Set("Content-Type", "application/json")
+	rec := httptest.NewRecorder()
+	router.ServeHTTP(rec, req)
+
+	if rec.Code != http.StatusCreated {
+		t.Fatalf("got status %d, want %d", rec.Code, http.StatusCreated)
+	}
+
+	logged := buf.String()
+	if !strings.Contains(logged, `https://example.com`) {
+		t.Errorf("log output missing request body, got: %s", logged)
+	}
+	if !strings.Contains(logged, "result") {
+		t.Errorf("log output missing response body, got: %s", logged)
+	}
+}
+
+func TestBodyLoggingMiddleware_HandlerStillReceivesFullBody(t *testing.T) {
+	var buf bytes.Buffer
+	h := newBodyLoggingTestHandler(true, &buf)
+	router := h.newRouter()
+
+	req := httptest.NewRequest(http.MethodPost, "/api/shorten", strings.NewReader(`{"url":"https://example.com"}`))
+	req.Header.Set("Content-Type", "application/json")
+	rec := httptest.NewRecorder()
+	router.ServeHTTP(rec, req)
+
+	if rec.Code != http.StatusCreated {
+		t.Fatalf("got status %d, want %d", rec.Code, http.StatusCreated)
+	}
+	if !strings.Contains(rec.Body.String(), "abc123") {
+		t.Errorf("handler response = %q, want it to contain the slug from the full request body", rec.Body.String())
+	}
+}
+
+func TestBodyLoggingMiddleware_DisabledByDefault(t *testing.T) {
+	var buf bytes.Buffer
+	h := newBodyLoggingTestHandler(false, &buf)
+	router := h.newRouter()
+
+	req := httptest.NewRequest(http.MethodPost, "/api/shorten", strings.NewReader(`{"url":"https://example.com"}`))
+	req.Header.Set("Content-Type", "application/json")
+	rec := httptest.NewRecorder()
+	router.ServeHTTP(rec, req)
+
+	if rec.Code != http.StatusCreated {
+		t.Fatalf("got status %d, want %d", rec.Code, http.StatusCreated)
+	}
+	if strings.Contains(buf.String(), "request/response body") {
+		t.Errorf("expected no body logging output when disabled, got: %s", buf.String())
+	}
+}
+
+func TestRedactSensitiveFields(t *testing.T) {
+	body := []byte(`{"user":"bob","password":"s3cr3t","token":"abc.def.ghi"}`)
+	got := string(redactSensitiveFields(body))
+
+	if strings.Contains(got, "s3cr3t") || strings.Contains(got, "abc.def.ghi") {
+		t.Errorf("redactSensitiveFields() did not redact secret values, got: %s", got)
+	}
+	if !strings.Contains(got, `"password":"***"`) || !strings.Contains(got, `"token":"***"`) {
+		t.Errorf("redactSensitiveFields() = %s, want redacted password/token fields", got)
+	}
+	if !strings.Contains(got, `"user":"bob"`) {
+		t.Errorf("redactSensitiveFields() should leave non-sensitive fields untouched, got: %s", got)
+	}
+}
@@ -0,0 +1,22 @@
+package handlers
+
+import (
+	"encoding/json"
+	"net/http"
+)
+
+// cacheStats reports how many GetURL lookups since startup were served from the in-process
+// cache versus missed and fetched from the repository. Restricted to the trusted subnet,
+// like dedupStats.
+func (h Handler) cacheStats(w http.ResponseWriter, r *http.Request) {
+	if !h.isTrustedSubnet(r) {
+		h.writeError(w, r, errUntrustedSubnet, http.StatusForbidden)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	if err := json.NewEncoder(w).Encode(h.service.CacheStats()); err != nil {
+		h.reqLog(r).Debug().Msgf("error encoding response: %v", err)
+	}
+}
@@ -0,0 +1,65 @@
+package handlers
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/ar4ie13/shortener/internal/metrics"
+	"github.com/rs/zerolog"
+)
+
+// cacheStatsService wraps fakeService, returning a fixed cache snapshot
+type cacheStatsService struct {
+	fakeService
+	stats metrics.CacheSnapshot
+}
+
+func (s cacheStatsService) CacheStats() metrics.CacheSnapshot {
+	return s.stats
+}
+
+func TestCacheStats(t *testing.T) {
+	tests := []struct {
+		name          string
+		trustedSubnet string
+		remoteAddr    string
+		wantStatus    int
+		wantBody      string
+	}{
+		{
+			name:          "trusted caller gets the snapshot",
+			trustedSubnet: "192.168.1.0/24",
+			remoteAddr:    "192.168.1.10:12345",
+			wantStatus:    http.StatusOK,
+			wantBody:      `{"hits":7,"misses":3}`,
+		},
+		{
+			name:          "untrusted caller is forbidden",
+			trustedSubnet: "192.168.1.0/24",
+			remoteAddr:    "10.0.0.5:12345",
+			wantStatus:    http.StatusForbidden,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			svc := cacheStatsService{stats: metrics.CacheSnapshot{Hits: 7, Misses: 3}}
+			h := NewHandler(svc, fakeConfig{trustedSubnet: tt.trustedSubnet}, fakeAuth{}, zerolog.Nop(), fakeReadiness{})
+
+			req := httptest.NewRequest(http.MethodGet, "/api/internal/cache-stats", nil)
+			req.RemoteAddr = tt.remoteAddr
+			rec := httptest.NewRecorder()
+
+			h.cacheStats(rec, req)
+
+			if rec.Code != tt.wantStatus {
+				t.Errorf("cacheStats() status = %d, want %d", rec.Code, tt.wantStatus)
+			}
+			if tt.wantStatus == http.StatusOK && strings.TrimSpace(rec.Body.String()) != tt.wantBody {
+				t.Errorf("cacheStats() body = %q, want %q", rec.Body.String(), tt.wantBody)
+			}
+		})
+	}
+}
@@ -0,0 +1,69 @@
+package handlers
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestGetCapabilities(t *testing.T) {
+	tests := []struct {
+		name string
+		cfg  fakeConfig
+		want CapabilitiesResp
+	}{
+		{
+			name: "all features disabled",
+			cfg:  fakeConfig{},
+			want: CapabilitiesResp{JSONNegotiation: true},
+		},
+		{
+			name: "all features enabled",
+			cfg: fakeConfig{
+				trackCreationSrc:   true,
+				avoidRecentSlugs:   true,
+				jsonDeleteResponse: true,
+				readOnly:           true,
+				trustedSubnet:      "192.168.1.0/24",
+			},
+			want: CapabilitiesResp{
+				TrackCreationSource: true,
+				AvoidRecentSlugs:    true,
+				JSONDeleteResponse:  true,
+				ReadOnly:            true,
+				TrustedSubnet:       true,
+				JSONNegotiation:     true,
+			},
+		},
+		{
+			name: "json negotiation disabled",
+			cfg:  fakeConfig{disableJSONNegotiation: true},
+			want: CapabilitiesResp{},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			h := newTestHandler(tt.cfg)
+
+			req := httptest.NewRequest(http.MethodGet, "/api/capabilities", nil)
+			rec := httptest.NewRecorder()
+
+			h.getCapabilities(rec, req)
+
+			if rec.Code != http.StatusOK {
+				t.Fatalf("getCapabilities() status = %d, want %d", rec.Code, http.StatusOK)
+			}
+
+			var got CapabilitiesResp
+			if err := json.NewDecoder(rec.Body).Decode(&got); err != nil {
+				t.Fatalf("failed to decode response: %v", err)
+			}
+
+			if got != tt.want {
+				t.Errorf("getCapabilities() = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
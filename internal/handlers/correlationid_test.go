@@ -0,0 +1,74 @@
+package handlers
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/google/uuid"
+)
+
+func TestPostURL_EchoesCorrelationID(t *testing.T) {
+	h := newTestHandler(fakeConfig{})
+	router := h.newRouter()
+
+	req := httptest.NewRequest(http.MethodPost, "/", strings.NewReader("https://example.com"))
+	req.Header.Set("X-Correlation-Id", "caller-supplied-id")
+	rec := httptest.NewRecorder()
+	router.ServeHTTP(rec, req)
+
+	if got := rec.Header().Get("X-Correlation-Id"); got != "caller-supplied-id" {
+		t.Errorf("X-Correlation-Id = %q, want %q", got, "caller-supplied-id")
+	}
+}
+
+func TestPostURL_GeneratesCorrelationIDWhenAbsent(t *testing.T) {
+	h := newTestHandler(fakeConfig{})
+	router := h.newRouter()
+
+	req := httptest.NewRequest(http.MethodPost, "/", strings.NewReader("https://example.com"))
+	rec := httptest.NewRecorder()
+	router.ServeHTTP(rec, req)
+
+	got := rec.Header().Get("X-Correlation-Id")
+	if got == "" {
+		t.Fatal("X-Correlation-Id is empty, want a generated value")
+	}
+	if _, err := uuid.Parse(got); err != nil {
+		t.Errorf("X-Correlation-Id = %q, want a valid UUID: %v", got, err)
+	}
+}
+
+func TestPostURLJSON_EchoesCorrelationID(t *testing.T) {
+	h := newTestHandler(fakeConfig{})
+	router := h.newRouter()
+
+	req := httptest.NewRequest(http.MethodPost, "/api/shorten", strings.NewReader(`{"url":"https://example.com"}`))
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("X-Correlation-Id", "caller-supplied-id")
+	rec := httptest.NewRecorder()
+	router.ServeHTTP(rec, req)
+
+	if got := rec.Header().Get("X-Correlation-Id"); got != "caller-supplied-id" {
+		t.Errorf("X-Correlation-Id = %q, want %q", got, "caller-supplied-id")
+	}
+}
+
+func TestPostURLJSON_GeneratesCorrelationIDWhenAbsent(t *testing.T) {
+	h := newTestHandler(fakeConfig{})
+	router := h.newRouter()
+
+	req := httptest.NewRequest(http.MethodPost, "/api/shorten", strings.NewReader(`{"url":"https://example.com"}`))
+	req.Header.Set("Content-Type", "application/json")
+	rec := httptest.NewRecorder()
+	router.ServeHTTP(rec, req)
+
+	got := rec.Header().Get("X-Correlation-Id")
+	if got == "" {
+		t.Fatal("X-Correlation-Id is empty, want a generated value")
+	}
+	if _, err := uuid.Parse(got); err != nil {
+		t.Errorf("X-Correlation-Id = %q, want a valid UUID: %v", got, err)
+	}
+}
@@ -0,0 +1,111 @@
+package handlers
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/google/uuid"
+)
+
+func TestCreationRateLimitMiddleware_ThrottlesPerIP(t *testing.T) {
+	h := newTestHandler(fakeConfig{creationRateLimitPerIPRPS: 1})
+	router := h.newRouter()
+
+	req := httptest.NewRequest(http.MethodPost, "/", strings.NewReader("https://example.com"))
+	req.RemoteAddr = "10.0.0.1:12345"
+
+	rec := httptest.NewRecorder()
+	router.ServeHTTP(rec, req)
+	if rec.Code != http.StatusCreated {
+		t.Fatalf("first creation request: got status %d, want %d", rec.Code, http.StatusCreated)
+	}
+
+	rec = httptest.NewRecorder()
+	router.ServeHTTP(rec, req)
+	if rec.Code != http.StatusTooManyRequests {
+		t.Fatalf("second creation request within the same second: got status %d, want %d", rec.Code, http.StatusTooManyRequests)
+	}
+	if rec.Header().Get("Retry-After") == "" {
+		t.Error("Retry-After header missing on 429 response")
+	}
+
+	otherReq := httptest.NewRequest(http.MethodPost, "/", strings.NewReader("https://example.com"))
+	otherReq.RemoteAddr = "10.0.0.2:12345"
+	rec = httptest.NewRecorder()
+	router.ServeHTTP(rec, otherReq)
+	if rec.Code != http.StatusCreated {
+		t.Fatalf("creation request from a different IP: got status %d, want %d", rec.Code, http.StatusCreated)
+	}
+}
+
+func TestCreationRateLimitMiddleware_ThrottlesPerUser(t *testing.T) {
+	h := newTestHandler(fakeConfig{creationRateLimitPerUserRPS: 1})
+
+	next := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	})
+	mw := h.creationRateLimitMiddleware(next)
+
+	userUUID := uuid.New().String()
+	newReq := func(remoteAddr string) *http.Request {
+		req := httptest.NewRequest(http.MethodPost, "/api/shorten", strings.NewReader(`{"url":"https://example.com"}`))
+		req.RemoteAddr = remoteAddr
+		return req.WithContext(context.WithValue(req.Context(), userUUIDKey, userUUID))
+	}
+
+	rec := httptest.NewRecorder()
+	mw.ServeHTTP(rec, newReq("10.0.0.1:12345"))
+	if rec.Code != http.StatusOK {
+		t.Fatalf("first creation request: got status %d, want %d", rec.Code, http.StatusOK)
+	}
+
+	// A second request for the same user UUID but a different IP must still be throttled:
+	// the per-user bucket is shared across IPs.
+	rec = httptest.NewRecorder()
+	mw.ServeHTTP(rec, newReq("10.0.0.2:12345"))
+	if rec.Code != http.StatusTooManyRequests {
+		t.Fatalf("second creation request for the same user within the same second: got status %d, want %d", rec.Code, http.StatusTooManyRequests)
+	}
+}
+
+func TestCreationRateLimitMiddleware_UnlimitedWhenUnset(t *testing.T) {
+	h := newTestHandler(fakeConfig{})
+	router := h.newRouter()
+
+	for i := 0; i < 100; i++ {
+		req := httptest.NewRequest(http.MethodPost, "/", strings.NewReader("https://example.com"))
+		rec := httptest.NewRecorder()
+		router.ServeHTTP(rec, req)
+		if rec.Code != http.StatusCreated {
+			t.Fatalf("creation request %d with no configured limit: got status %d, want %d", i, rec.Code, http.StatusCreated)
+		}
+	}
+}
+
+func TestCreationRateLimitMiddleware_DoesNotThrottleRedirects(t *testing.T) {
+	h := newTestHandler(fakeConfig{creationRateLimitPerIPRPS: 1, creationRateLimitPerUserRPS: 1})
+	router := h.newRouter()
+
+	postReq := httptest.NewRequest(http.MethodPost, "/", strings.NewReader("https://example.com"))
+	rec := httptest.NewRecorder()
+	router.ServeHTTP(rec, postReq)
+	if rec.Code != http.StatusCreated {
+		t.Fatalf("first creation request: got status %d, want %d", rec.Code, http.StatusCreated)
+	}
+
+	rec = httptest.NewRecorder()
+	router.ServeHTTP(rec, postReq)
+	if rec.Code != http.StatusTooManyRequests {
+		t.Fatalf("second creation request within the same second: got status %d, want %d", rec.Code, http.StatusTooManyRequests)
+	}
+
+	getReq := httptest.NewRequest(http.MethodGet, "/abc123", nil)
+	rec = httptest.NewRecorder()
+	router.ServeHTTP(rec, getReq)
+	if rec.Code != http.StatusTemporaryRedirect {
+		t.Fatalf("redirect after exhausting the creation limiter: got status %d, want %d", rec.Code, http.StatusTemporaryRedirect)
+	}
+}
@@ -0,0 +1,44 @@
+package handlers
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestPostURLJSON_RejectsReservedAlias(t *testing.T) {
+	reserved := []string{"api", "ping", "health", "API"}
+
+	for _, alias := range reserved {
+		t.Run(alias, func(t *testing.T) {
+			h := newTestHandler(fakeConfig{})
+			router := h.newRouter()
+
+			body := `{"url":"https://example.com","custom_alias":"` + alias + `"}`
+			req := httptest.NewRequest(http.MethodPost, "/api/shorten", strings.NewReader(body))
+			req.Header.Set("Content-Type", "application/json")
+			rec := httptest.NewRecorder()
+			router.ServeHTTP(rec, req)
+
+			if rec.Code != http.StatusBadRequest {
+				t.Errorf("alias %q: got status %d, want %d", alias, rec.Code, http.StatusBadRequest)
+			}
+		})
+	}
+}
+
+func TestPostURLJSON_AllowsNonReservedAlias(t *testing.T) {
+	h := newTestHandler(fakeConfig{})
+	router := h.newRouter()
+
+	body := `{"url":"https://example.com","custom_alias":"mycustom"}`
+	req := httptest.NewRequest(http.MethodPost, "/api/shorten", strings.NewReader(body))
+	req.Header.Set("Content-Type", "application/json")
+	rec := httptest.NewRecorder()
+	router.ServeHTTP(rec, req)
+
+	if rec.Code != http.StatusCreated {
+		t.Errorf("got status %d, want %d", rec.Code, http.StatusCreated)
+	}
+}
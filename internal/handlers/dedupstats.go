@@ -0,0 +1,22 @@
+package handlers
+
+import (
+	"encoding/json"
+	"net/http"
+)
+
+// dedupStats reports how many shorten requests since startup created a brand-new slug,
+// reused an existing one, or hit a slug collision that had to be retried. Restricted to the
+// trusted subnet, like topHosts.
+func (h Handler) dedupStats(w http.ResponseWriter, r *http.Request) {
+	if !h.isTrustedSubnet(r) {
+		h.writeError(w, r, errUntrustedSubnet, http.StatusForbidden)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	if err := json.NewEncoder(w).Encode(h.service.DedupStats()); err != nil {
+		h.reqLog(r).Debug().Msgf("error encoding response: %v", err)
+	}
+}
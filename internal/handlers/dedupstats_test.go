@@ -0,0 +1,65 @@
+package handlers
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/ar4ie13/shortener/internal/metrics"
+	"github.com/rs/zerolog"
+)
+
+// dedupStatsService wraps fakeService, returning a fixed dedup snapshot
+type dedupStatsService struct {
+	fakeService
+	stats metrics.DedupSnapshot
+}
+
+func (s dedupStatsService) DedupStats() metrics.DedupSnapshot {
+	return s.stats
+}
+
+func TestDedupStats(t *testing.T) {
+	tests := []struct {
+		name          string
+		trustedSubnet string
+		remoteAddr    string
+		wantStatus    int
+		wantBody      string
+	}{
+		{
+			name:          "trusted caller gets the snapshot",
+			trustedSubnet: "192.168.1.0/24",
+			remoteAddr:    "192.168.1.10:12345",
+			wantStatus:    http.StatusOK,
+			wantBody:      `{"created":3,"reused":2,"collision_retries":1}`,
+		},
+		{
+			name:          "untrusted caller is forbidden",
+			trustedSubnet: "192.168.1.0/24",
+			remoteAddr:    "10.0.0.5:12345",
+			wantStatus:    http.StatusForbidden,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			svc := dedupStatsService{stats: metrics.DedupSnapshot{Created: 3, Reused: 2, CollisionRetries: 1}}
+			h := NewHandler(svc, fakeConfig{trustedSubnet: tt.trustedSubnet}, fakeAuth{}, zerolog.Nop(), fakeReadiness{})
+
+			req := httptest.NewRequest(http.MethodGet, "/api/internal/dedup-stats", nil)
+			req.RemoteAddr = tt.remoteAddr
+			rec := httptest.NewRecorder()
+
+			h.dedupStats(rec, req)
+
+			if rec.Code != tt.wantStatus {
+				t.Errorf("dedupStats() status = %d, want %d", rec.Code, tt.wantStatus)
+			}
+			if tt.wantStatus == http.StatusOK && strings.TrimSpace(rec.Body.String()) != tt.wantBody {
+				t.Errorf("dedupStats() body = %q, want %q", rec.Body.String(), tt.wantBody)
+			}
+		})
+	}
+}
@@ -0,0 +1,111 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/ar4ie13/shortener/internal/myerrors"
+	"github.com/google/uuid"
+	"github.com/rs/zerolog"
+)
+
+// queueFullService rejects every delete with myerrors.ErrDeleteQueueFull, simulating a
+// backlog that is already at its configured limit
+type queueFullService struct {
+	fakeService
+}
+
+func (queueFullService) SendShortURLForDelete(_ context.Context, _ uuid.UUID, _ []string) (uuid.UUID, error) {
+	return uuid.Nil, myerrors.ErrDeleteQueueFull
+}
+
+func TestDeleteUsersShortURL(t *testing.T) {
+	tests := []struct {
+		name               string
+		jsonDeleteResponse bool
+		body               string
+		wantAccepted       int
+	}{
+		{
+			name:               "json response reflects accepted count",
+			jsonDeleteResponse: true,
+			body:               `["abc123","def456"]`,
+			wantAccepted:       2,
+		},
+		{
+			name:               "json response filters out invalid entries",
+			jsonDeleteResponse: true,
+			body:               `["abc123","","abc123"]`,
+			wantAccepted:       1,
+		},
+		{
+			name:               "bare 202 when json response is disabled",
+			jsonDeleteResponse: false,
+			body:               `["abc123"]`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			h := newTestHandler(fakeConfig{jsonDeleteResponse: tt.jsonDeleteResponse})
+
+			req := httptest.NewRequest(http.MethodDelete, "/api/user/urls", strings.NewReader(tt.body))
+			ctx := context.WithValue(req.Context(), userUUIDKey, uuid.New().String())
+			req = req.WithContext(ctx)
+			rec := httptest.NewRecorder()
+
+			h.deleteUsersShortURL(rec, req)
+
+			if rec.Code != http.StatusAccepted {
+				t.Fatalf("deleteUsersShortURL() status = %d, want %d", rec.Code, http.StatusAccepted)
+			}
+
+			if rec.Header().Get("X-Delete-Job-Id") == "" {
+				t.Error("deleteUsersShortURL() missing X-Delete-Job-Id header")
+			}
+
+			if !tt.jsonDeleteResponse {
+				if rec.Body.Len() != 0 {
+					t.Errorf("deleteUsersShortURL() body = %q, want empty", rec.Body.String())
+				}
+				return
+			}
+
+			var resp DeleteAcceptedResp
+			if err := json.Unmarshal(rec.Body.Bytes(), &resp); err != nil {
+				t.Fatalf("failed to unmarshal response: %v", err)
+			}
+			if resp.Accepted != tt.wantAccepted {
+				t.Errorf("Accepted = %d, want %d", resp.Accepted, tt.wantAccepted)
+			}
+			if !resp.Queued {
+				t.Errorf("Queued = false, want true")
+			}
+			if resp.JobID == "" {
+				t.Error("JobID = \"\", want non-empty")
+			}
+		})
+	}
+}
+
+func TestDeleteUsersShortURL_BacklogFullReturns503WithRetryAfter(t *testing.T) {
+	h := NewHandler(queueFullService{}, fakeConfig{}, fakeAuth{}, zerolog.Nop(), fakeReadiness{})
+
+	req := httptest.NewRequest(http.MethodDelete, "/api/user/urls", strings.NewReader(`["abc123"]`))
+	ctx := context.WithValue(req.Context(), userUUIDKey, uuid.New().String())
+	req = req.WithContext(ctx)
+	rec := httptest.NewRecorder()
+
+	h.deleteUsersShortURL(rec, req)
+
+	if rec.Code != http.StatusServiceUnavailable {
+		t.Fatalf("deleteUsersShortURL() status = %d, want %d", rec.Code, http.StatusServiceUnavailable)
+	}
+	if rec.Header().Get("Retry-After") == "" {
+		t.Error("deleteUsersShortURL() missing Retry-After header")
+	}
+}
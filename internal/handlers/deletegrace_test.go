@@ -0,0 +1,41 @@
+package handlers
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/ar4ie13/shortener/internal/myerrors"
+	"github.com/google/uuid"
+	"github.com/rs/zerolog"
+)
+
+// graceService wraps fakeService, returning myerrors.ErrShortURLGracePeriod alongside the
+// original URL as Service.GetURL does for a slug still within its delete grace period
+type graceService struct {
+	fakeService
+}
+
+func (graceService) GetURL(_ context.Context, _ uuid.UUID, _ string) (string, error) {
+	return "https://example.com", myerrors.ErrShortURLGracePeriod
+}
+
+func TestGetURL_WithinDeleteGracePeriod_SetsNoStore(t *testing.T) {
+	h := NewHandler(graceService{}, fakeConfig{}, fakeAuth{}, zerolog.Nop(), fakeReadiness{})
+	router := h.newRouter()
+
+	req := httptest.NewRequest(http.MethodGet, "/abc123", nil)
+	rec := httptest.NewRecorder()
+	router.ServeHTTP(rec, req)
+
+	if rec.Code != http.StatusTemporaryRedirect {
+		t.Fatalf("got status %d, want %d", rec.Code, http.StatusTemporaryRedirect)
+	}
+	if rec.Header().Get("Location") != "https://example.com" {
+		t.Errorf("Location header = %q, want %q", rec.Header().Get("Location"), "https://example.com")
+	}
+	if rec.Header().Get("Cache-Control") != "no-store" {
+		t.Errorf("Cache-Control header = %q, want %q", rec.Header().Get("Cache-Control"), "no-store")
+	}
+}
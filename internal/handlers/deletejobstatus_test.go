@@ -0,0 +1,104 @@
+package handlers
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/ar4ie13/shortener/internal/model"
+	"github.com/ar4ie13/shortener/internal/myerrors"
+	"github.com/go-chi/chi/v5"
+	"github.com/google/uuid"
+	"github.com/rs/zerolog"
+)
+
+// deleteJobStatusService wraps fakeService, returning a fixed job status or
+// myerrors.ErrNotFound
+type deleteJobStatusService struct {
+	fakeService
+	job model.DeleteJob
+	err error
+}
+
+func (s deleteJobStatusService) DeleteJobStatus(_ context.Context, _ uuid.UUID, _ uuid.UUID) (model.DeleteJob, error) {
+	return s.job, s.err
+}
+
+func TestDeleteJobStatus(t *testing.T) {
+	tests := []struct {
+		name       string
+		job        model.DeleteJob
+		err        error
+		wantStatus int
+		wantBody   string
+	}{
+		{
+			name:       "pending job",
+			job:        model.DeleteJob{Status: model.DeleteJobPending},
+			wantStatus: http.StatusOK,
+			wantBody:   `{"status":"pending"}`,
+		},
+		{
+			name:       "completed job",
+			job:        model.DeleteJob{Status: model.DeleteJobCompleted},
+			wantStatus: http.StatusOK,
+			wantBody:   `{"status":"completed"}`,
+		},
+		{
+			name:       "failed job reports its error",
+			job:        model.DeleteJob{Status: model.DeleteJobFailed, Error: "boom"},
+			wantStatus: http.StatusOK,
+			wantBody:   `{"status":"failed","error":"boom"}`,
+		},
+		{
+			name:       "unknown job is not found",
+			err:        myerrors.ErrNotFound,
+			wantStatus: http.StatusNotFound,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			h := NewHandler(deleteJobStatusService{job: tt.job, err: tt.err}, fakeConfig{}, fakeAuth{}, zerolog.Nop(), fakeReadiness{})
+
+			req := httptest.NewRequest(http.MethodGet, "/api/user/urls/delete/"+uuid.New().String(), nil)
+			ctx := context.WithValue(req.Context(), userUUIDKey, uuid.New().String())
+			rctx := chi.NewRouteContext()
+			rctx.URLParams.Add("jobID", uuid.New().String())
+			ctx = context.WithValue(ctx, chi.RouteCtxKey, rctx)
+			req = req.WithContext(ctx)
+			rec := httptest.NewRecorder()
+
+			h.deleteJobStatus(rec, req)
+
+			if rec.Code != tt.wantStatus {
+				t.Fatalf("deleteJobStatus() status = %d, want %d", rec.Code, tt.wantStatus)
+			}
+			if tt.wantBody != "" {
+				got := rec.Body.String()
+				if len(got) == 0 || got[len(got)-1] != '\n' || got[:len(got)-1] != tt.wantBody {
+					t.Errorf("deleteJobStatus() body = %q, want %q", got, tt.wantBody)
+				}
+			}
+		})
+	}
+}
+
+func TestDeleteJobStatus_InvalidJobID(t *testing.T) {
+	h := newTestHandler(fakeConfig{})
+
+	req := httptest.NewRequest(http.MethodGet, "/api/user/urls/delete/not-a-uuid", nil)
+	ctx := context.WithValue(req.Context(), userUUIDKey, uuid.New().String())
+	rctx := chi.NewRouteContext()
+	rctx.URLParams.Add("jobID", "not-a-uuid")
+	ctx = context.WithValue(ctx, chi.RouteCtxKey, rctx)
+	req = req.WithContext(ctx)
+	rec := httptest.NewRecorder()
+
+	h.deleteJobStatus(rec, req)
+
+	if rec.Code != http.StatusBadRequest {
+		t.Fatalf("deleteJobStatus() status = %d, want %d", rec.Code, http.StatusBadRequest)
+	}
+}
@@ -1,9 +1,18 @@
 package handlers
 
-import "github.com/google/uuid"
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
 
 type LongURLReq struct {
-	LongURL string `json:"url"`
+	LongURL     string `json:"url"`
+	CustomAlias string `json:"custom_alias,omitempty"`
+	Password    string `json:"password,omitempty"`
+	// RedirectStatus overrides the configured default HTTP status (301, 302, 307 or 308)
+	// used when redirecting GET/HEAD /{id} for this link; 0 or omitted uses the default.
+	RedirectStatus int `json:"redirect_status,omitempty"`
 }
 
 type ShortURLResp struct {
@@ -18,9 +27,117 @@ type BatchRequest struct {
 type BatchResponse struct {
 	UUID     uuid.UUID `json:"correlation_id"`
 	ShortURL string    `json:"short_url"`
+	// Conflict is true when ShortURL is the link's pre-existing slug rather than a newly
+	// generated one
+	Conflict bool `json:"conflict,omitempty"`
 }
 
 type UserShortURLs struct {
-	ShortURL string `json:"short_url"`
-	LongURL  string `json:"original_url"`
+	ShortURL  string     `json:"short_url"`
+	LongURL   string     `json:"original_url"`
+	Referer   string     `json:"referer,omitempty"`
+	UserAgent string     `json:"user_agent,omitempty"`
+	CreatedAt *time.Time `json:"created_at,omitempty"`
+	UpdatedAt *time.Time `json:"updated_at,omitempty"`
+}
+
+// ExportedURL is one line of a GET /api/user/urls/export?format=jsonl response
+type ExportedURL struct {
+	OriginalURL string `json:"original_url"`
+	ShortURL    string `json:"short_url"`
+	CreatedAt   string `json:"created_at,omitempty"`
+	Deleted     bool   `json:"deleted"`
+}
+
+// ImportStreamLineReq is one decoded line of a POST /api/user/urls/import/stream request body
+type ImportStreamLineReq struct {
+	OriginalURL string `json:"original_url"`
+	Alias       string `json:"alias,omitempty"`
+}
+
+// ImportStreamLineResp is one encoded line of a POST /api/user/urls/import/stream response
+// body, reported for every non-blank input line in the same order it was received
+type ImportStreamLineResp struct {
+	OriginalURL string `json:"original_url"`
+	ShortURL    string `json:"short_url,omitempty"`
+	Error       string `json:"error,omitempty"`
+}
+
+// DeleteAcceptedResp reports how many slugs were accepted into the delete queue
+type DeleteAcceptedResp struct {
+	Accepted int    `json:"accepted"`
+	Queued   bool   `json:"queued"`
+	JobID    string `json:"job_id"`
+}
+
+// CapabilitiesResp reports which optional features are active in this deployment
+type CapabilitiesResp struct {
+	TrackCreationSource bool `json:"track_creation_source"`
+	AvoidRecentSlugs    bool `json:"avoid_recent_slugs"`
+	JSONDeleteResponse  bool `json:"json_delete_response"`
+	ReadOnly            bool `json:"read_only"`
+	TrustedSubnet       bool `json:"trusted_subnet"`
+	JSONNegotiation     bool `json:"json_negotiation"`
+}
+
+// ErrorResp is the JSON envelope returned to a client that sent Accept: application/json to a
+// route that would otherwise respond with plain text. Code is a stable, machine-readable
+// identifier from errorCatalog that a client can branch on without parsing Message.
+type ErrorResp struct {
+	Code    string `json:"code"`
+	Message string `json:"message"`
+}
+
+// RedirectResp is the JSON envelope returned for GET /{id} when the client sent
+// Accept: application/json instead of following the Location header directly
+type RedirectResp struct {
+	Location string `json:"location"`
+	Status   int    `json:"status"`
+}
+
+// APIKeyResp is returned from POST /api/user/apikeys. Key is the plaintext API key; it is
+// shown only this once and cannot be recovered later, since only its hash is persisted.
+type APIKeyResp struct {
+	Key string `json:"key"`
+}
+
+// ExpandResp is returned by GET /api/expand/{id}: the destination of a short URL and its
+// deletion state, without redirecting to it, so a caller can inspect where a link leads
+// before following it. Creation time is not included, since model.URL does not currently
+// track it.
+type ExpandResp struct {
+	OriginalURL string     `json:"original_url"`
+	IsDeleted   bool       `json:"is_deleted"`
+	DeletedAt   *time.Time `json:"deleted_at,omitempty"`
+}
+
+// ExpandBatchReq is the body of POST /api/expand/batch: the slugs to resolve, in the same
+// form as the {id} path parameter of GET /api/expand/{id}
+type ExpandBatchReq struct {
+	ShortURLs []string `json:"short_urls"`
+}
+
+// ExpandBatchResp is one entry of the response to POST /api/expand/batch, shaped like
+// ExpandResp but echoing the slug it resolves and, if it could not be resolved, why
+type ExpandBatchResp struct {
+	ShortURL    string     `json:"short_url"`
+	OriginalURL string     `json:"original_url,omitempty"`
+	IsDeleted   bool       `json:"is_deleted,omitempty"`
+	DeletedAt   *time.Time `json:"deleted_at,omitempty"`
+	Error       string     `json:"error,omitempty"`
+}
+
+// ComponentHealth is the status of one dependency checked by /healthz and /readyz. Detail
+// carries the reason for a non-"ok" status, or extra context (e.g. a queue depth) alongside
+// an "ok" one.
+type ComponentHealth struct {
+	Status string `json:"status"`
+	Detail string `json:"detail,omitempty"`
+}
+
+// HealthResp is the JSON body returned by /healthz and /readyz: an overall status plus the
+// per-component breakdown it was derived from
+type HealthResp struct {
+	Status     string                     `json:"status"`
+	Components map[string]ComponentHealth `json:"components"`
 }
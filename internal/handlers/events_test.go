@@ -0,0 +1,101 @@
+package handlers
+
+import (
+	"bufio"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/ar4ie13/shortener/internal/model"
+	"github.com/google/uuid"
+	"github.com/rs/zerolog"
+)
+
+// eventsService wraps fakeService, handing back an eventsService-controlled channel from
+// Subscribe so tests can push events and observe unsubscribe being called on disconnect
+type eventsService struct {
+	fakeService
+	ch             chan model.Event
+	unsubscribeHit *atomic.Bool
+}
+
+func (s eventsService) Subscribe(_ uuid.UUID) (<-chan model.Event, func()) {
+	return s.ch, func() { s.unsubscribeHit.Store(true) }
+}
+
+func TestGetEvents_StreamsPublishedEvent(t *testing.T) {
+	var unsubscribed atomic.Bool
+	svc := eventsService{ch: make(chan model.Event, 1), unsubscribeHit: &unsubscribed}
+	h := NewHandler(svc, fakeConfig{}, fakeAuth{}, zerolog.Nop(), fakeReadiness{})
+	server := httptest.NewServer(h.newRouter())
+	defer server.Close()
+
+	resp, err := http.Get(server.URL + "/api/events")
+	if err != nil {
+		t.Fatalf("GET /api/events: %v", err)
+	}
+	defer resp.Body.Close()
+
+	if ct := resp.Header.Get("Content-Type"); ct != "text/event-stream" {
+		t.Errorf("Content-Type = %q, want text/event-stream", ct)
+	}
+
+	svc.ch <- model.Event{Type: model.EventCreated, ShortURL: "abc123", OriginalURL: "https://example.com"}
+
+	line := readLineWithDeadline(t, resp.Body, time.Second)
+	if !strings.HasPrefix(line, "data: ") {
+		t.Errorf("line = %q, want it to start with an SSE data: frame", line)
+	}
+	if !strings.Contains(line, `"short_url":"abc123"`) {
+		t.Errorf("line = %q, want it to contain the published event", line)
+	}
+
+	resp.Body.Close()
+
+	deadline := time.Now().Add(time.Second)
+	for !unsubscribed.Load() {
+		if time.Now().After(deadline) {
+			t.Fatal("unsubscribe was never called")
+		}
+		time.Sleep(time.Millisecond)
+	}
+}
+
+// readLineWithDeadline reads a single line from r, failing the test if none arrives before
+// timeout elapses
+func readLineWithDeadline(t *testing.T, r io.Reader, timeout time.Duration) string {
+	t.Helper()
+
+	lines := make(chan string, 1)
+	go func() {
+		scanner := bufio.NewScanner(r)
+		if scanner.Scan() {
+			lines <- scanner.Text()
+		}
+	}()
+
+	select {
+	case line := <-lines:
+		return line
+	case <-time.After(timeout):
+		t.Fatal("no line observed in stream before deadline")
+		return ""
+	}
+}
+
+func TestGetEvents_NotFoundWhenDisabled(t *testing.T) {
+	h := NewHandler(fakeService{}, fakeConfig{disableEvents: true}, fakeAuth{}, zerolog.Nop(), fakeReadiness{})
+	router := h.newRouter()
+
+	req := httptest.NewRequest(http.MethodGet, "/api/events", nil)
+	rec := httptest.NewRecorder()
+	router.ServeHTTP(rec, req)
+
+	if rec.Code != http.StatusNotFound {
+		t.Errorf("got status %d, want %d", rec.Code, http.StatusNotFound)
+	}
+}
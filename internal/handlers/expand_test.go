@@ -0,0 +1,98 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/ar4ie13/shortener/internal/myerrors"
+	"github.com/google/uuid"
+	"github.com/rs/zerolog"
+)
+
+// deletedInspectService wraps fakeService, returning a deleted result as Service.InspectURL
+// does for a slug that was soft-deleted
+type deletedInspectService struct {
+	fakeService
+}
+
+var deletedInspectAt = time.Date(2026, 1, 2, 3, 4, 5, 0, time.UTC)
+
+func (deletedInspectService) InspectURL(_ context.Context, _ uuid.UUID, _ string) (string, bool, time.Time, error) {
+	return "https://example.com", true, deletedInspectAt, nil
+}
+
+// notFoundInspectService wraps fakeService, returning myerrors.ErrNotFound as
+// Service.InspectURL does for a slug that was never saved
+type notFoundInspectService struct {
+	fakeService
+}
+
+func (notFoundInspectService) InspectURL(_ context.Context, _ uuid.UUID, _ string) (string, bool, time.Time, error) {
+	return "", false, time.Time{}, myerrors.ErrNotFound
+}
+
+func TestGetURLInspection_ReturnsDestinationWithoutRedirecting(t *testing.T) {
+	h := newTestHandler(fakeConfig{})
+	router := h.newRouter()
+
+	req := httptest.NewRequest(http.MethodGet, "/api/expand/abc123", nil)
+	rec := httptest.NewRecorder()
+	router.ServeHTTP(rec, req)
+
+	if rec.Code != http.StatusOK {
+		t.Fatalf("status = %d, want %d", rec.Code, http.StatusOK)
+	}
+	if rec.Header().Get("Location") != "" {
+		t.Errorf("Location header = %q, want unset (no redirect)", rec.Header().Get("Location"))
+	}
+
+	var body ExpandResp
+	if err := json.NewDecoder(rec.Body).Decode(&body); err != nil {
+		t.Fatalf("failed to decode response: %v", err)
+	}
+	want := ExpandResp{OriginalURL: "https://example.com", IsDeleted: false}
+	if body != want {
+		t.Errorf("body = %+v, want %+v", body, want)
+	}
+}
+
+func TestGetURLInspection_DeletedSlugReportsDeletedState(t *testing.T) {
+	h := NewHandler(deletedInspectService{}, fakeConfig{}, fakeAuth{}, zerolog.Nop(), fakeReadiness{})
+	router := h.newRouter()
+
+	req := httptest.NewRequest(http.MethodGet, "/api/expand/abc123", nil)
+	rec := httptest.NewRecorder()
+	router.ServeHTTP(rec, req)
+
+	if rec.Code != http.StatusOK {
+		t.Fatalf("status = %d, want %d", rec.Code, http.StatusOK)
+	}
+
+	var body ExpandResp
+	if err := json.NewDecoder(rec.Body).Decode(&body); err != nil {
+		t.Fatalf("failed to decode response: %v", err)
+	}
+	if !body.IsDeleted {
+		t.Error("body.IsDeleted = false, want true")
+	}
+	if body.DeletedAt == nil || !body.DeletedAt.Equal(deletedInspectAt) {
+		t.Errorf("body.DeletedAt = %v, want %v", body.DeletedAt, deletedInspectAt)
+	}
+}
+
+func TestGetURLInspection_UnknownSlugIsNotFound(t *testing.T) {
+	h := NewHandler(notFoundInspectService{}, fakeConfig{}, fakeAuth{}, zerolog.Nop(), fakeReadiness{})
+	router := h.newRouter()
+
+	req := httptest.NewRequest(http.MethodGet, "/api/expand/missing", nil)
+	rec := httptest.NewRecorder()
+	router.ServeHTTP(rec, req)
+
+	if rec.Code != http.StatusNoContent {
+		t.Fatalf("status = %d, want %d", rec.Code, http.StatusNoContent)
+	}
+}
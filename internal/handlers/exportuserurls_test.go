@@ -0,0 +1,165 @@
+package handlers
+
+import (
+	"context"
+	"encoding/csv"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/ar4ie13/shortener/internal/model"
+	"github.com/google/uuid"
+	"github.com/rs/zerolog"
+)
+
+// exportFixtureService wraps fakeService, honoring limit/offset against a fixed set of URLs so
+// exportUserURLs's paging loop can be exercised across more than one page.
+type exportFixtureService struct {
+	fakeService
+	urls []model.URL
+}
+
+func (s exportFixtureService) GetUserShortURLs(_ context.Context, _ uuid.UUID, _ string, limit int, offset int) ([]model.URL, error) {
+	if offset >= len(s.urls) {
+		return nil, nil
+	}
+	end := len(s.urls)
+	if limit > 0 && offset+limit < end {
+		end = offset + limit
+	}
+	return s.urls[offset:end], nil
+}
+
+func TestExportUserURLs_RejectsUnknownFormat(t *testing.T) {
+	h := newTestHandler(fakeConfig{})
+	router := h.newRouter()
+
+	req := httptest.NewRequest(http.MethodGet, "/api/user/urls/export?format=xml", nil)
+	rec := httptest.NewRecorder()
+	router.ServeHTTP(rec, req)
+
+	if rec.Code != http.StatusBadRequest {
+		t.Errorf("got status %d, want %d", rec.Code, http.StatusBadRequest)
+	}
+}
+
+func TestExportUserURLs_NotFoundWhenUserURLsDisabled(t *testing.T) {
+	h := newTestHandler(fakeConfig{disableUserURLs: true})
+	router := h.newRouter()
+
+	req := httptest.NewRequest(http.MethodGet, "/api/user/urls/export?format=csv", nil)
+	rec := httptest.NewRecorder()
+	router.ServeHTTP(rec, req)
+
+	if rec.Code != http.StatusNotFound {
+		t.Errorf("got status %d, want %d", rec.Code, http.StatusNotFound)
+	}
+}
+
+func TestExportUserURLs_CSV(t *testing.T) {
+	created := time.Date(2026, 1, 2, 3, 4, 5, 0, time.UTC)
+	h := NewHandler(exportFixtureService{urls: []model.URL{
+		{ShortURL: "abc123", OriginalURL: "https://example.com/one", CreatedAt: created},
+		{ShortURL: "def456", OriginalURL: "https://example.com/two"},
+	}}, fakeConfig{}, fakeAuth{}, zerolog.Nop(), fakeReadiness{})
+	router := h.newRouter()
+
+	req := httptest.NewRequest(http.MethodGet, "/api/user/urls/export?format=csv", nil)
+	rec := httptest.NewRecorder()
+	router.ServeHTTP(rec, req)
+
+	if rec.Code != http.StatusOK {
+		t.Fatalf("got status %d, want %d", rec.Code, http.StatusOK)
+	}
+	if ct := rec.Header().Get("Content-Type"); ct != "text/csv" {
+		t.Errorf("Content-Type = %q, want %q", ct, "text/csv")
+	}
+
+	rows, err := csv.NewReader(rec.Body).ReadAll()
+	if err != nil {
+		t.Fatalf("ReadAll() unexpected error = %v", err)
+	}
+	if len(rows) != 3 {
+		t.Fatalf("got %d rows (incl. header), want 3", len(rows))
+	}
+	if got, want := rows[0], []string{"original_url", "short_url", "created_at", "deleted"}; !equalRows(got, want) {
+		t.Errorf("header = %v, want %v", got, want)
+	}
+	if got, want := rows[1][0], "https://example.com/one"; got != want {
+		t.Errorf("row 1 original_url = %q, want %q", got, want)
+	}
+	if got, want := rows[1][2], created.Format(time.RFC3339); got != want {
+		t.Errorf("row 1 created_at = %q, want %q", got, want)
+	}
+	if got, want := rows[1][3], "false"; got != want {
+		t.Errorf("row 1 deleted = %q, want %q", got, want)
+	}
+	if got, want := rows[2][2], ""; got != want {
+		t.Errorf("row 2 created_at = %q, want %q (zero CreatedAt omitted)", got, want)
+	}
+}
+
+func TestExportUserURLs_JSONL(t *testing.T) {
+	h := NewHandler(exportFixtureService{urls: []model.URL{
+		{ShortURL: "abc123", OriginalURL: "https://example.com/one"},
+		{ShortURL: "def456", OriginalURL: "https://example.com/two"},
+	}}, fakeConfig{}, fakeAuth{}, zerolog.Nop(), fakeReadiness{})
+	router := h.newRouter()
+
+	req := httptest.NewRequest(http.MethodGet, "/api/user/urls/export?format=jsonl", nil)
+	rec := httptest.NewRecorder()
+	router.ServeHTTP(rec, req)
+
+	if rec.Code != http.StatusOK {
+		t.Fatalf("got status %d, want %d", rec.Code, http.StatusOK)
+	}
+	if ct := rec.Header().Get("Content-Type"); ct != "application/x-ndjson" {
+		t.Errorf("Content-Type = %q, want %q", ct, "application/x-ndjson")
+	}
+
+	lines := strings.Split(strings.TrimSpace(rec.Body.String()), "\n")
+	if len(lines) != 2 {
+		t.Fatalf("got %d lines, want 2", len(lines))
+	}
+
+	var first ExportedURL
+	if err := json.Unmarshal([]byte(lines[0]), &first); err != nil {
+		t.Fatalf("Unmarshal() unexpected error = %v", err)
+	}
+	if first.OriginalURL != "https://example.com/one" || first.Deleted {
+		t.Errorf("first = %+v, want OriginalURL=https://example.com/one, Deleted=false", first)
+	}
+}
+
+func TestExportUserURLs_PagesPastExportPageSize(t *testing.T) {
+	urls := make([]model.URL, exportPageSize+1)
+	for i := range urls {
+		urls[i] = model.URL{ShortURL: uuid.New().String(), OriginalURL: "https://example.com/n"}
+	}
+	h := NewHandler(exportFixtureService{urls: urls}, fakeConfig{}, fakeAuth{}, zerolog.Nop(), fakeReadiness{})
+	router := h.newRouter()
+
+	req := httptest.NewRequest(http.MethodGet, "/api/user/urls/export?format=jsonl", nil)
+	rec := httptest.NewRecorder()
+	router.ServeHTTP(rec, req)
+
+	lines := strings.Split(strings.TrimSpace(rec.Body.String()), "\n")
+	if len(lines) != len(urls) {
+		t.Errorf("got %d lines, want %d (spanning more than one exportPageSize page)", len(lines), len(urls))
+	}
+}
+
+func equalRows(a, b []string) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,61 @@
+package handlers
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestFeatureFlags_DisabledEndpointsReturn404(t *testing.T) {
+	tests := []struct {
+		name   string
+		cfg    fakeConfig
+		method string
+		path   string
+	}{
+		{"batch disabled", fakeConfig{disableBatch: true}, http.MethodPost, "/api/shorten/batch"},
+		{"user urls disabled", fakeConfig{disableUserURLs: true}, http.MethodGet, "/api/user/urls"},
+		{"delete disabled", fakeConfig{disableDelete: true}, http.MethodDelete, "/api/user/urls"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			h := newTestHandler(tt.cfg)
+			router := h.newRouter()
+
+			req := httptest.NewRequest(tt.method, tt.path, strings.NewReader("{}"))
+			rec := httptest.NewRecorder()
+			router.ServeHTTP(rec, req)
+
+			if rec.Code != http.StatusNotFound {
+				t.Errorf("%s %s: got status %d, want %d", tt.method, tt.path, rec.Code, http.StatusNotFound)
+			}
+		})
+	}
+}
+
+func TestFeatureFlags_EnabledByDefault(t *testing.T) {
+	h := newTestHandler(fakeConfig{})
+	router := h.newRouter()
+
+	tests := []struct {
+		method string
+		path   string
+		body   string
+	}{
+		{http.MethodPost, "/api/shorten/batch", `[{"correlation_id":"1","original_url":"https://example.com"}]`},
+		{http.MethodGet, "/api/user/urls", ""},
+		{http.MethodDelete, "/api/user/urls", `["abc123"]`},
+	}
+
+	for _, tt := range tests {
+		req := httptest.NewRequest(tt.method, tt.path, strings.NewReader(tt.body))
+		rec := httptest.NewRecorder()
+		router.ServeHTTP(rec, req)
+
+		if rec.Code == http.StatusNotFound {
+			t.Errorf("%s %s: got 404, endpoint should be enabled by default", tt.method, tt.path)
+		}
+	}
+}
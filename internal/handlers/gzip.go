@@ -2,11 +2,25 @@ package handlers
 
 import (
 	"compress/gzip"
+	"errors"
 	"io"
 	"net/http"
 	"strings"
+	"sync"
 )
 
+// errDecompressBody is returned when a request declares Content-Encoding: gzip but its body
+// cannot actually be decompressed
+var errDecompressBody = errors.New("Failed to decompress request body")
+
+// gzipWriterPool reuses gzip.Writer values across requests to avoid allocating
+// a new compressor (and its internal buffers) on every compressed response.
+var gzipWriterPool = sync.Pool{
+	New: func() any {
+		return gzip.NewWriter(io.Discard)
+	},
+}
+
 // compressWriter implements http.ResponseWriter
 type compressWriter struct {
 	w  http.ResponseWriter
@@ -14,9 +28,11 @@ type compressWriter struct {
 }
 
 func newCompressWriter(w http.ResponseWriter) *compressWriter {
+	zw, _ := gzipWriterPool.Get().(*gzip.Writer)
+	zw.Reset(w)
 	return &compressWriter{
 		w:  w,
-		zw: gzip.NewWriter(w),
+		zw: zw,
 	}
 }
 
@@ -33,8 +49,21 @@ func (c *compressWriter) WriteHeader(statusCode int) {
 	c.w.WriteHeader(statusCode)
 }
 
+// Flush flushes buffered gzip output and, when the wrapped ResponseWriter supports it, flushes
+// that too, so a streaming handler further down the chain still flushes through this wrapper.
+func (c *compressWriter) Flush() {
+	_ = c.zw.Flush()
+	if f, ok := c.w.(http.Flusher); ok {
+		f.Flush()
+	}
+}
+
+// Close flushes the underlying gzip stream and returns the writer to the pool.
+// It must only be called once per compressWriter.
 func (c *compressWriter) Close() error {
-	return c.zw.Close()
+	err := c.zw.Close()
+	gzipWriterPool.Put(c.zw)
+	return err
 }
 
 // compressReader implements io.ReadCloser
@@ -87,7 +116,7 @@ func (h Handler) gzipMiddleware(next http.Handler) http.Handler {
 			if err != nil {
 				// Write error response without compression to avoid mismatch
 				w.Header().Del("Content-Encoding") // Ensure no gzip header
-				http.Error(w, "Failed to decompress request body", http.StatusInternalServerError)
+				h.writeError(w, r, errDecompressBody, http.StatusInternalServerError)
 				return
 			}
 			r.Body = cr
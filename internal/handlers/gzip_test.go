@@ -0,0 +1,54 @@
+package handlers
+
+import (
+	"compress/gzip"
+	"io"
+	"net/http/httptest"
+	"testing"
+)
+
+// TestCompressWriter_PooledWriterProducesValidGzip confirms a compressWriter
+// obtained after a previous one has been closed (and its gzip.Writer returned
+// to the pool) still produces output a standard gzip reader can decode.
+func TestCompressWriter_PooledWriterProducesValidGzip(t *testing.T) {
+	for i := 0; i < 3; i++ {
+		rec := httptest.NewRecorder()
+		cw := newCompressWriter(rec)
+		if _, err := cw.Write([]byte("hello, world")); err != nil {
+			t.Fatalf("Write() unexpected error = %v", err)
+		}
+		if err := cw.Close(); err != nil {
+			t.Fatalf("Close() unexpected error = %v", err)
+		}
+
+		zr, err := gzip.NewReader(rec.Body)
+		if err != nil {
+			t.Fatalf("gzip.NewReader() unexpected error = %v", err)
+		}
+		got, err := io.ReadAll(zr)
+		if err != nil {
+			t.Fatalf("ReadAll() unexpected error = %v", err)
+		}
+		if string(got) != "hello, world" {
+			t.Errorf("decompressed body = %q, want %q", got, "hello, world")
+		}
+	}
+}
+
+// BenchmarkCompressWriter measures per-response allocations with the pooled
+// gzip.Writer in place.
+func BenchmarkCompressWriter(b *testing.B) {
+	payload := []byte("the quick brown fox jumps over the lazy dog")
+
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		rec := httptest.NewRecorder()
+		cw := newCompressWriter(rec)
+		if _, err := cw.Write(payload); err != nil {
+			b.Fatal(err)
+		}
+		if err := cw.Close(); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
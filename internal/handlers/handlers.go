@@ -1,45 +1,260 @@
 package handlers
 
 import (
+	"bufio"
 	"bytes"
 	"context"
+	"encoding/csv"
 	"encoding/json"
 	"errors"
+	"fmt"
 	"io"
+	"math"
 	"net/http"
+	"os"
+	"os/signal"
+	"strconv"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"syscall"
+	"time"
 
+	"github.com/ar4ie13/shortener/internal/metrics"
 	"github.com/ar4ie13/shortener/internal/model"
 	"github.com/ar4ie13/shortener/internal/myerrors"
+	"github.com/ar4ie13/shortener/internal/ratelimit"
 	"github.com/go-chi/chi/v5"
 	"github.com/google/uuid"
 	"github.com/rs/zerolog"
 )
 
-// errorStatusMap used for fast error check in get
-var errorStatusMap = map[error]int{
-	myerrors.ErrEmptyURL:          http.StatusBadRequest,
-	myerrors.ErrInvalidURLFormat:  http.StatusBadRequest,
-	myerrors.ErrWrongHTTPScheme:   http.StatusBadRequest,
-	myerrors.ErrMustIncludeHost:   http.StatusBadRequest,
-	myerrors.ErrURLExist:          http.StatusConflict,
-	myerrors.ErrNotFound:          http.StatusNoContent,
-	myerrors.ErrShortURLIsDeleted: http.StatusGone,
+// errTrailingData is returned when a JSON request body contains additional data after the
+// first decoded value
+var errTrailingData = errors.New("request body must contain a single JSON value")
+
+// errAliasInUse is returned instead of myerrors.ErrShortURLExist's own message when a
+// caller-supplied custom alias collides with one already saved, since "alias already in use"
+// is clearer to a client than the generic "shortURL already exist"
+var errAliasInUse = errors.New("alias already in use")
+
+// errTooManyShortURLs is wrapped with the requested and configured-max counts and returned
+// when a batch request (shorten or expand) exceeds its configured size limit
+var errTooManyShortURLs = errors.New("too many short_urls")
+
+// errTooManyRedirectRequests is returned by redirectRateLimitMiddleware once
+// REDIRECT_RATE_LIMIT_RPS is exceeded
+var errTooManyRedirectRequests = errors.New("too many redirect requests")
+
+// errTooManyCreationRequests is returned by creationRateLimitMiddleware once a per-user or
+// per-IP creation rate limit is exceeded
+var errTooManyCreationRequests = errors.New("too many URL creation requests")
+
+// errUnsupportedImportContentType is returned by postURLImport for a Content-Type other than
+// application/x-ndjson or text/csv
+var errUnsupportedImportContentType = errors.New(`Content-Type must be "application/x-ndjson" or "text/csv"`)
+
+// errUnsupportedExportFormat is returned by exportUserURLs for a format query parameter other
+// than csv or jsonl
+var errUnsupportedExportFormat = errors.New(`format must be "csv" or "jsonl"`)
+
+// errInvalidJobID is returned by deleteJobStatus when the jobID path parameter is not a
+// valid UUID
+var errInvalidJobID = errors.New("invalid job id")
+
+// errRequestBodyRead is returned by deleteUsersShortURL when the request body cannot be read
+var errRequestBodyRead = errors.New("Error reading request body")
+
+// errJSONUnmarshal is returned by deleteUsersShortURL when the request body is not valid JSON
+var errJSONUnmarshal = errors.New("Error unmarshalling JSON")
+
+// errStreamingUnsupported is returned by getEvents when the underlying ResponseWriter does
+// not support flushing, so the SSE stream cannot be delivered incrementally
+var errStreamingUnsupported = errors.New("streaming unsupported")
+
+// jsonBodyBufferPool reuses bytes.Buffer values across requests to avoid allocating a fresh
+// buffer for every JSON body read
+var jsonBodyBufferPool = sync.Pool{
+	New: func() any {
+		return new(bytes.Buffer)
+	},
+}
+
+// getJSONBodyBuffer returns a reset bytes.Buffer from jsonBodyBufferPool. Callers must return
+// it via putJSONBodyBuffer once they are done reading from it.
+func getJSONBodyBuffer() *bytes.Buffer {
+	buf, _ := jsonBodyBufferPool.Get().(*bytes.Buffer)
+	buf.Reset()
+	return buf
+}
+
+// putJSONBodyBuffer returns buf to jsonBodyBufferPool
+func putJSONBodyBuffer(buf *bytes.Buffer) {
+	jsonBodyBufferPool.Put(buf)
+}
+
+// decodeJSONStrict decodes the next JSON value from dec into v and rejects any trailing
+// data in the stream, so a body like {"url":"x"}{"url":"y"} is not silently truncated to
+// just its first value
+func decodeJSONStrict(dec *json.Decoder, v any) error {
+	if err := dec.Decode(v); err != nil {
+		return err
+	}
+	if err := dec.Decode(&struct{}{}); !errors.Is(err, io.EOF) {
+		return errTrailingData
+	}
+	return nil
+}
+
+// errorInfo pairs the HTTP status and the stable, machine-readable code reported for a known
+// error, so a client can branch on errorInfo.code without parsing the human-readable message.
+type errorInfo struct {
+	status int
+	code   string
+}
+
+// defaultErrorCode is reported for an error with no entry in errorCatalog, i.e. one that was
+// never meant to be shown to a caller
+const defaultErrorCode = "internal_error"
+
+// errorCatalog used for fast error check in getStatusCode and getErrorCode
+var errorCatalog = map[error]errorInfo{
+	myerrors.ErrEmptyURL:              {http.StatusBadRequest, "empty_url"},
+	myerrors.ErrInvalidURLFormat:      {http.StatusBadRequest, "invalid_url_format"},
+	myerrors.ErrWrongHTTPScheme:       {http.StatusBadRequest, "wrong_http_scheme"},
+	myerrors.ErrMustIncludeHost:       {http.StatusBadRequest, "must_include_host"},
+	myerrors.ErrURLExist:              {http.StatusConflict, "url_exists"},
+	myerrors.ErrNotFound:              {http.StatusNoContent, "not_found"},
+	myerrors.ErrShortURLIsDeleted:     {http.StatusGone, "short_url_deleted"},
+	myerrors.ErrShortURLExist:         {http.StatusConflict, "short_url_exists"},
+	myerrors.ErrAliasReserved:         {http.StatusBadRequest, "alias_reserved"},
+	myerrors.ErrRepositoryNotReady:    {http.StatusServiceUnavailable, "repository_not_ready"},
+	myerrors.ErrForbidden:             {http.StatusForbidden, "forbidden"},
+	myerrors.ErrURLBlocked:            {http.StatusBadRequest, "url_blocked"},
+	myerrors.ErrInvalidRedirectStatus: {http.StatusBadRequest, "invalid_redirect_status"},
+
+	errTrailingData:                 {http.StatusBadRequest, "trailing_json_data"},
+	errAliasInUse:                   {http.StatusConflict, "alias_in_use"},
+	errTooManyShortURLs:             {http.StatusBadRequest, "batch_too_large"},
+	errTooManyRedirectRequests:      {http.StatusTooManyRequests, "rate_limited"},
+	errTooManyCreationRequests:      {http.StatusTooManyRequests, "rate_limited"},
+	errUnsupportedImportContentType: {http.StatusBadRequest, "unsupported_content_type"},
+	errUnsupportedExportFormat:      {http.StatusBadRequest, "unsupported_format"},
+	errInvalidJobID:                 {http.StatusBadRequest, "invalid_job_id"},
+	errRequestBodyRead:              {http.StatusInternalServerError, "body_read_error"},
+	errJSONUnmarshal:                {http.StatusBadRequest, "invalid_json"},
+	errStreamingUnsupported:         {http.StatusInternalServerError, "streaming_unsupported"},
+	errInvalidAPIKey:                {http.StatusUnauthorized, "invalid_api_key"},
+	errInvalidCookie:                {http.StatusUnauthorized, "invalid_cookie"},
+	errUntrustedSubnet:              {http.StatusForbidden, "untrusted_subnet"},
+	errReadOnly:                     {http.StatusServiceUnavailable, "read_only"},
+	errDecompressBody:               {http.StatusInternalServerError, "decompress_error"},
+	errInvalidN:                     {http.StatusBadRequest, "invalid_query_param"},
+	errInvalidOlderThan:             {http.StatusBadRequest, "invalid_duration"},
+}
+
+// reservedPathSegments are top-level path segments claimed by registered routes, plus
+// "health" reserved alongside "healthz" in case a future route needs it. Kept centralized
+// here so a custom alias is validated against the same set newRouter registers, instead of a
+// second list that could drift out of sync.
+var reservedPathSegments = map[string]struct{}{
+	"api":     {},
+	"ping":    {},
+	"health":  {},
+	"healthz": {},
+	"readyz":  {},
+}
+
+// isReservedAlias reports whether alias matches a reserved path segment and so would shadow
+// a registered route or be unreachable behind one
+func isReservedAlias(alias string) bool {
+	_, reserved := reservedPathSegments[strings.ToLower(alias)]
+	return reserved
 }
 
 // Service interface interacts with service package
 type Service interface {
+	// GetURL resolves id to its destination. userUUID is only consulted when the service is
+	// configured for private links, in which case resolving a slug owned by someone else is
+	// reported the same as id not existing at all.
 	GetURL(ctx context.Context, userUUID uuid.UUID, id string) (string, error)
-	SaveURL(ctx context.Context, userUUID uuid.UUID, url string) (slug string, err error)
-	SaveBatch(ctx context.Context, userUUID uuid.UUID, batch []model.URL) ([]model.URL, error)
-	GetUserShortURLs(ctx context.Context, userUUID uuid.UUID) (map[string]string, error)
-	SendShortURLForDelete(ctx context.Context, userUUID uuid.UUID, shortURLs []string)
+	// InspectURL returns id's destination and deletion state without resolving it as a
+	// redirect, so a caller can preview where it leads before following it
+	InspectURL(ctx context.Context, userUUID uuid.UUID, id string) (originalURL string, isDeleted bool, deletedAt time.Time, err error)
+	// InspectURLs is the batch form of InspectURL, resolving many slugs in one call
+	InspectURLs(ctx context.Context, userUUID uuid.UUID, shortURLs []string) ([]model.URLExpansion, error)
+	// SaveURL shortens url for userUUID; anonymous, when true, subjects the saved link to
+	// the configured anonymous-link TTL instead of (or in addition to) the regular one
+	SaveURL(ctx context.Context, userUUID uuid.UUID, url string, referer string, userAgent string, customAlias string, password string, anonymous bool, redirectStatus int) (slug string, err error)
+	// SaveBatch shortens batch for userUUID; anonymous has the same meaning as in SaveURL
+	SaveBatch(ctx context.Context, userUUID uuid.UUID, batch []model.URL, referer string, userAgent string, reuseOnConflict bool, anonymous bool) ([]model.URL, error)
+	// GetUserShortURLs returns userUUID's non-deleted short URLs in a stable order. sortBy is
+	// "short_url" or "created_at" (see parseSort). limit caps how many URLs are returned, 0
+	// meaning unlimited; offset skips that many URLs from the start of the ordered result
+	// before limit is applied.
+	GetUserShortURLs(ctx context.Context, userUUID uuid.UUID, sortBy string, limit int, offset int) ([]model.URL, error)
+	// UpdateURL changes the destination behind shortURL, owned by userUUID, to url
+	UpdateURL(ctx context.Context, userUUID uuid.UUID, shortURL string, url string) error
+	// SendShortURLForDelete enqueues shortURLs for asynchronous deletion, returning a job ID
+	// that DeleteJobStatus can be polled with to learn whether the deletion completed
+	SendShortURLForDelete(ctx context.Context, userUUID uuid.UUID, shortURLs []string) (uuid.UUID, error)
+	// DeleteJobStatus reports the status of a job previously returned by
+	// SendShortURLForDelete, restricted to its owner
+	DeleteJobStatus(ctx context.Context, userUUID uuid.UUID, jobID uuid.UUID) (model.DeleteJob, error)
+	PurgeDeleted(ctx context.Context, before time.Time, batchSize int, pause time.Duration) (int, error)
+	Ping(ctx context.Context) error
+	// VerifyPassword reports whether password unlocks shortURL; a slug with no stored
+	// password is never protected
+	VerifyPassword(ctx context.Context, shortURL string, password string) (bool, error)
+	// GetRedirectStatus returns shortURL's per-link redirect status override, or 0 if it has
+	// none
+	GetRedirectStatus(ctx context.Context, shortURL string) (int, error)
+	// TopHosts returns the n hosts with the most non-deleted short URLs, ordered by count descending
+	TopHosts(ctx context.Context, n int) ([]model.HostCount, error)
+	// DedupStats returns a snapshot of how shorten requests have been resolved since startup
+	DedupStats() metrics.DedupSnapshot
+	// CacheStats returns a snapshot of how GetURL lookups have been resolved since startup
+	CacheStats() metrics.CacheSnapshot
+	// Stats returns the total number of non-deleted short URLs and the total number of
+	// distinct users that have ever saved one
+	Stats(ctx context.Context) (model.Stats, error)
+	// CreateAPIKey stores a new API key record for userUUID, keyed by keyHash
+	CreateAPIKey(ctx context.Context, userUUID uuid.UUID, keyHash string) error
+	// ResolveAPIKey returns the UserUUID owning keyHash, or myerrors.ErrNotFound if it does
+	// not exist or has been revoked
+	ResolveAPIKey(ctx context.Context, keyHash string) (uuid.UUID, error)
+	// RevokeAPIKey marks keyHash as revoked. It returns myerrors.ErrNotFound if keyHash does
+	// not exist and myerrors.ErrForbidden if it exists but is not owned by userUUID.
+	RevokeAPIKey(ctx context.Context, userUUID uuid.UUID, keyHash string) error
+	// PendingDeletes returns how many slugs are currently queued for asynchronous deletion
+	// but have not yet been flushed to the repository
+	PendingDeletes() int
+	// Subscribe registers a new listener for userUUID's create/delete/redirect events. The
+	// caller must invoke the returned unsubscribe function exactly once, when it stops
+	// listening, to release the channel.
+	Subscribe(userUUID uuid.UUID) (<-chan model.Event, func())
+}
+
+// Readiness reports whether the backend is ready to serve traffic, e.g. whether any required
+// database migrations have finished applying
+type Readiness interface {
+	Ready() bool
 }
 
 // Auth used for authentication
 type Auth interface {
 	GenerateUserUUID() uuid.UUID
-	BuildJWTString(userUUID uuid.UUID) (string, error)
-	ValidateUserUUID(tokenString string) (uuid.UUID, error)
+	// BuildJWTString issues a token for userUUID; anonymous marks the identity as
+	// auto-minted, distinguishing it from one explicitly claimed via POST /api/user/claim
+	BuildJWTString(userUUID uuid.UUID, anonymous bool) (string, error)
+	// ValidateUserUUID validates tokenString, returning the user's UUID and whether that
+	// identity is still anonymous
+	ValidateUserUUID(tokenString string) (uuid.UUID, bool, error)
+	// GenerateAPIKey returns a new plaintext API key, shown to the caller only once
+	GenerateAPIKey() (string, error)
+	// HashAPIKey returns the sha256 hex digest of key, the form in which API keys are stored
+	// and looked up
+	HashAPIKey(key string) string
 }
 
 // Config interface gets configuration flags from config package
@@ -47,84 +262,389 @@ type Config interface {
 	GetLocalServerAddr() string
 	GetShortURLTemplate() string
 	GetLogLevel() zerolog.Level
-	CheckPostgresConnection(ctx context.Context) error
+	GetTrackCreationSource() bool
+	GetReadOnly() bool
+	GetTrustedSubnet() string
+	GetJSONDeleteResponse() bool
+	GetAvoidRecentSlugs() bool
+	GetRedirectRateLimitRPS() float64
+	// GetCreationRateLimitPerUserRPS returns the requests-per-second limit applied per user
+	// UUID on the plain-text and JSON shorten endpoints, or 0 to disable it
+	GetCreationRateLimitPerUserRPS() float64
+	// GetCreationRateLimitPerIPRPS returns the requests-per-second limit applied per client
+	// IP on the plain-text and JSON shorten endpoints, or 0 to disable it
+	GetCreationRateLimitPerIPRPS() float64
+	GetCookieDomain() string
+	GetCookieMaxAge() time.Duration
+	GetEnableBatch() bool
+	GetEnableUserURLs() bool
+	GetEnableDelete() bool
+	GetRedirectBody() bool
+	// GetRedirectStatus returns the operator-preferred HTTP status for GET/HEAD /{id}
+	// redirects, or 0 to use the default (http.StatusTemporaryRedirect)
+	GetRedirectStatus() int
+	GetRootBehavior() string
+	GetRootRedirectURL() string
+	GetLogBodies() bool
+	GetPurgeBatchSize() int
+	GetPurgeBatchPause() time.Duration
+	GetPurgeRetention() time.Duration
+	GetEnableJSONNegotiation() bool
+	GetEnableImportStream() bool
+	GetImportStreamMaxLine() int
+	GetImportStreamRateLimit() float64
+	// GetEnableEvents reports whether GET /api/events is served
+	GetEnableEvents() bool
+	// GetMaxExpandBatch returns the maximum number of slugs resolvable in one
+	// POST /api/expand/batch request, or 0 if unlimited
+	GetMaxExpandBatch() int
+	// GetForceShortURLScheme returns "http", "https", "auto", or "" (use the short URL
+	// template's scheme as-is)
+	GetForceShortURLScheme() string
+	// GetMaxResponseURLs returns the maximum number of URLs GET /api/user/urls ever
+	// returns, or 0 if unlimited
+	GetMaxResponseURLs() int
+	// GetAdminAddress returns the address the /api/internal/* routes are served on when
+	// they should be split off the public listener, or "" to keep them on it
+	GetAdminAddress() string
+	// GetShutdownTimeout returns how long a graceful shutdown waits for in-flight requests
+	// to drain before proceeding anyway
+	GetShutdownTimeout() time.Duration
+	// GetEnableHTTPS reports whether the server should serve over HTTPS instead of plain HTTP
+	GetEnableHTTPS() bool
+	// GetTLSCertPath returns the path to the TLS certificate used when GetEnableHTTPS is true
+	GetTLSCertPath() string
+	// GetTLSKeyPath returns the path to the TLS private key used when GetEnableHTTPS is true
+	GetTLSKeyPath() string
+	// GetDeleteQueueLimit returns the maximum number of slugs awaiting asynchronous
+	// deletion, or 0 if unlimited
+	GetDeleteQueueLimit() int
 }
 
 // Handler is a main object for package handlers
 type Handler struct {
-	service Service
-	cfg     Config
-	auth    Auth
-	zlog    zerolog.Logger
+	service               Service
+	cfg                   Config
+	auth                  Auth
+	zlog                  zerolog.Logger
+	readOnly              *atomic.Bool
+	readiness             Readiness
+	redirectLimiter       *ratelimit.Limiter
+	importLimiter         *ratelimit.Limiter
+	creationLimiterByUser *ratelimit.KeyedLimiter
+	creationLimiterByIP   *ratelimit.KeyedLimiter
+	// shortURLPrefixStatic is the precomputed "<base>/" prefix for the common case where
+	// GetForceShortURLScheme is not "auto", so the hot path of composing a short URL
+	// avoids rebuilding it on every request. Left empty when the scheme must be decided
+	// per request (see shortURLPrefix).
+	shortURLPrefixStatic string
 }
 
 // NewHandler constructs Handler object
-func NewHandler(s Service, c Config, a Auth, zlog zerolog.Logger) *Handler {
-	return &Handler{s, c, a, zlog}
+func NewHandler(s Service, c Config, a Auth, zlog zerolog.Logger, ready Readiness) *Handler {
+	h := &Handler{
+		service:               s,
+		cfg:                   c,
+		auth:                  a,
+		zlog:                  zlog,
+		readOnly:              &atomic.Bool{},
+		readiness:             ready,
+		redirectLimiter:       ratelimit.NewLimiter(c.GetRedirectRateLimitRPS(), nil),
+		importLimiter:         ratelimit.NewLimiter(c.GetImportStreamRateLimit(), nil),
+		creationLimiterByUser: ratelimit.NewKeyedLimiter(c.GetCreationRateLimitPerUserRPS(), nil),
+		creationLimiterByIP:   ratelimit.NewKeyedLimiter(c.GetCreationRateLimitPerIPRPS(), nil),
+	}
+	h.readOnly.Store(c.GetReadOnly())
+
+	switch c.GetForceShortURLScheme() {
+	case "auto":
+		// scheme depends on a per-request header, so it cannot be precomputed
+	case "http", "https":
+		h.shortURLPrefixStatic = rewriteScheme(c.GetShortURLTemplate(), c.GetForceShortURLScheme()) + "/"
+	default:
+		h.shortURLPrefixStatic = c.GetShortURLTemplate() + "/"
+	}
+
+	return h
 }
 
-// ListenAndServe starts web server with specified chi router
-func (h Handler) ListenAndServe() error {
+// newRouter builds the chi router with all middleware and routes wired up
+func (h Handler) newRouter() chi.Router {
 	router := chi.NewRouter()
 
 	// middleware for router
+	router.Use(h.recoveryMiddleware)
 	router.Use(h.requestLogger)
 	router.Use(h.authMiddleware)
 	router.Use(h.gzipMiddleware)
+	router.Use(h.bodyLoggingMiddleware)
+	router.Use(h.readOnlyMiddleware)
 
 	router.Route("/", func(router chi.Router) {
-		router.Post("/", h.postURL)
-		router.Get("/{id}", h.getURL)
-		router.Get("/ping", h.checkPostgresConnection)
+		router.With(h.creationRateLimitMiddleware).Post("/", h.postURL)
+		switch h.cfg.GetRootBehavior() {
+		case "landing":
+			router.Get("/", h.getRootLanding)
+		case "redirect":
+			router.Get("/", h.getRootRedirect)
+		}
+		router.With(h.redirectRateLimitMiddleware).Get("/{id}", h.getURL)
+		router.With(h.redirectRateLimitMiddleware).Head("/{id}", h.getURL)
+		router.Get("/ping", h.ping)
+		router.Get("/healthz", h.healthz)
+		router.Get("/readyz", h.readyz)
 		router.Route("/api", func(router chi.Router) {
-			router.Post("/shorten", h.postURLJSON)
-			router.Post("/shorten/batch", h.postURLJSONBatch)
-			router.Get("/user/urls", h.getUsersShortURL)
-			router.Delete("/user/urls", h.deleteUsersShortURL)
+			router.Get("/capabilities", h.getCapabilities)
+			router.Get("/expand/{id}", h.getURLInspection)
+			router.Post("/expand/batch", h.postURLInspectionBatch)
+			router.With(h.creationRateLimitMiddleware).Post("/shorten", h.postURLJSON)
+			if h.cfg.GetEnableBatch() {
+				router.Post("/shorten/batch", h.postURLJSONBatch)
+			}
+			if h.cfg.GetEnableUserURLs() {
+				router.Get("/user/urls", h.getUsersShortURL)
+				router.Get("/user/urls/export", h.exportUserURLs)
+				router.Put("/user/urls/{id}", h.putUserShortURL)
+			} else {
+				router.Get("/user/urls", http.NotFound)
+				router.Get("/user/urls/export", http.NotFound)
+				router.Put("/user/urls/{id}", http.NotFound)
+			}
+			if h.cfg.GetEnableDelete() {
+				router.Delete("/user/urls", h.deleteUsersShortURL)
+				router.Get("/user/urls/delete/{jobID}", h.deleteJobStatus)
+			} else {
+				router.Delete("/user/urls", http.NotFound)
+				router.Get("/user/urls/delete/{jobID}", http.NotFound)
+			}
+			if h.cfg.GetEnableImportStream() {
+				router.Post("/user/urls/import/stream", h.postURLImportStream)
+				router.Post("/user/urls/import", h.postURLImport)
+			} else {
+				router.Post("/user/urls/import/stream", http.NotFound)
+				router.Post("/user/urls/import", http.NotFound)
+			}
+			if h.cfg.GetEnableEvents() {
+				router.Get("/events", h.getEvents)
+			} else {
+				router.Get("/events", http.NotFound)
+			}
+			router.Post("/user/claim", h.claimUser)
+			router.Post("/user/apikeys", h.postUserAPIKey)
+			router.Delete("/user/apikeys/{id}", h.deleteUserAPIKey)
+			if h.cfg.GetAdminAddress() == "" {
+				router.Route("/internal", h.internalRoutes)
+			}
 		})
 	})
+
+	return router
+}
+
+// internalRoutes registers the trusted-subnet-gated admin/internal endpoints. It is shared
+// between newRouter, mounted under /api on the public router, and newAdminRouter, so the two
+// stay in sync regardless of which listener they end up served on.
+func (h Handler) internalRoutes(router chi.Router) {
+	router.Post("/read-only", h.setReadOnly)
+	router.Post("/purge-deleted", h.purgeDeleted)
+	router.Get("/top-hosts", h.topHosts)
+	router.Get("/dedup-stats", h.dedupStats)
+	router.Get("/cache-stats", h.cacheStats)
+	router.Get("/stats", h.stats)
+}
+
+// newAdminRouter builds a router serving only the /api/internal routes, for the separate
+// listener bound to GetAdminAddress when one is configured. Each handler still checks
+// isTrustedSubnet itself, so this is defense in depth on top of binding to an internal
+// interface, not a replacement for it.
+func (h Handler) newAdminRouter() chi.Router {
+	router := chi.NewRouter()
+	router.Use(h.recoveryMiddleware)
+	router.Use(h.requestLogger)
+	router.Route("/api/internal", h.internalRoutes)
+	return router
+}
+
+// ListenAndServe starts the web server with the configured chi router and blocks until it
+// stops. If GetAdminAddress is set, the /api/internal/* routes are removed from the public
+// router and served instead on a second http.Server bound to that address. If GetEnableHTTPS
+// is set, both servers are started with ListenAndServeTLS against GetTLSCertPath/
+// GetTLSKeyPath instead of plain HTTP. On SIGINT or SIGTERM, both servers are given up to
+// GetShutdownTimeout (0 means no limit) to drain in-flight requests via
+// http.Server.Shutdown, and ListenAndServe returns nil once they do; callers are still
+// responsible for draining the delete queue and closing the repository afterward (see
+// Service.Close and Repository.Close). If either server instead exits on its own, e.g. a
+// listen error, the other is stopped immediately and that error is returned.
+func (h Handler) ListenAndServe() error {
+	router := h.newRouter()
+
+	publicSrv := &http.Server{Addr: h.cfg.GetLocalServerAddr(), Handler: router}
+	var adminSrv *http.Server
+	adminAddr := h.cfg.GetAdminAddress()
+	if adminAddr != "" {
+		adminSrv = &http.Server{Addr: adminAddr, Handler: h.newAdminRouter()}
+	}
+
+	serve := func(srv *http.Server) error { return srv.ListenAndServe() }
+	if h.cfg.GetEnableHTTPS() {
+		serve = func(srv *http.Server) error {
+			return srv.ListenAndServeTLS(h.cfg.GetTLSCertPath(), h.cfg.GetTLSKeyPath())
+		}
+	}
+
 	h.zlog.Info().Msgf("listening on %v\nURL Template: %v\nLog Level: %v", h.cfg.GetLocalServerAddr(), h.cfg.GetShortURLTemplate(), h.cfg.GetLogLevel())
 
-	if err := http.ListenAndServe(h.cfg.GetLocalServerAddr(), router); err != nil {
-		return err
+	errCh := make(chan error, 2)
+	go func() { errCh <- serve(publicSrv) }()
+	if adminSrv != nil {
+		h.zlog.Info().Msgf("listening for admin/internal routes on %v", adminAddr)
+		go func() { errCh <- serve(adminSrv) }()
 	}
 
-	return nil
+	sigCh := make(chan os.Signal, 1)
+	signal.Notify(sigCh, syscall.SIGINT, syscall.SIGTERM)
+	defer signal.Stop(sigCh)
+
+	select {
+	case err := <-errCh:
+		_ = publicSrv.Close()
+		if adminSrv != nil {
+			_ = adminSrv.Close()
+		}
+		return err
+	case sig := <-sigCh:
+		h.zlog.Info().Msgf("received %v, shutting down gracefully", sig)
+
+		shutdownCtx := context.Background()
+		if timeout := h.cfg.GetShutdownTimeout(); timeout > 0 {
+			var cancel context.CancelFunc
+			shutdownCtx, cancel = context.WithTimeout(shutdownCtx, timeout)
+			defer cancel()
+		}
+
+		if err := publicSrv.Shutdown(shutdownCtx); err != nil {
+			h.zlog.Error().Err(err).Msg("public server did not shut down cleanly")
+		}
+		if adminSrv != nil {
+			if err := adminSrv.Shutdown(shutdownCtx); err != nil {
+				h.zlog.Error().Err(err).Msg("admin server did not shut down cleanly")
+			}
+		}
+		return nil
+	}
 }
 
 // getUserUID
 func (h Handler) getUserUUIDFromRequest(r *http.Request) (uuid.UUID, error) {
 	userUUID, err := uuid.Parse(r.Context().Value(userUUIDKey).(string))
 	if err != nil {
-		h.zlog.Debug().Msgf("cannot parse user UUID: %v", err)
+		h.reqLog(r).Debug().Msgf("cannot parse user UUID: %v", err)
 		return uuid.Nil, err
 	}
 
 	return userUUID, nil
 }
 
+// isAnonymousFromRequest reports whether the request's identity is still anonymous. It
+// defaults to true when the anonymous flag is missing from the context, since that is the
+// safer assumption for TTL purposes.
+func (h Handler) isAnonymousFromRequest(r *http.Request) bool {
+	if anonymous, ok := r.Context().Value(anonymousKey).(bool); ok {
+		return anonymous
+	}
+	return true
+}
+
+// shortURLPrefix returns the "<base>/" prefix to prepend to a generated slug, applying
+// GetForceShortURLScheme on top of the configured short URL template. "http" and "https"
+// force that scheme unconditionally; "auto" takes the scheme from X-Forwarded-Proto, but
+// only when the request comes from the trusted subnet; any other value (including "")
+// leaves the template's own scheme untouched. Outside of "auto" mode the prefix is
+// identical for every request, so it is precomputed once in NewHandler and returned
+// here without touching the config or rebuilding the string.
+func (h Handler) shortURLPrefix(r *http.Request) string {
+	if h.shortURLPrefixStatic != "" {
+		return h.shortURLPrefixStatic
+	}
+
+	base := h.cfg.GetShortURLTemplate()
+	if h.isTrustedSubnet(r) {
+		if proto := r.Header.Get("X-Forwarded-Proto"); proto == "http" || proto == "https" {
+			return rewriteScheme(base, proto) + "/"
+		}
+	}
+
+	return base + "/"
+}
+
+// rewriteScheme replaces the scheme of base (e.g. "http://example.com") with scheme,
+// leaving the rest of base untouched
+func rewriteScheme(base string, scheme string) string {
+	if idx := strings.Index(base, "://"); idx != -1 {
+		return scheme + base[idx:]
+	}
+	return scheme + "://" + base
+}
+
+// getCreationSource returns the Referer and User-Agent of the request when creation-source tracking is enabled
+func (h Handler) getCreationSource(r *http.Request) (referer string, userAgent string) {
+	if !h.cfg.GetTrackCreationSource() {
+		return "", ""
+	}
+
+	return r.Referer(), r.UserAgent()
+}
+
+// correlationID returns the caller-supplied X-Correlation-Id header, generating a new one
+// if the caller did not send it, so every single-shorten request can be traced end to end
+func correlationID(r *http.Request) string {
+	if id := r.Header.Get("X-Correlation-Id"); id != "" {
+		return id
+	}
+
+	return uuid.NewString()
+}
+
 // getStatusCode process error and return the correlated status code
 func (h Handler) getStatusCode(err error) int {
 	// fast error check
-	if status, exists := errorStatusMap[err]; exists {
-		return status
+	if info, exists := errorCatalog[err]; exists {
+		return info.status
 	}
 
 	// For wrapped errors
-	for errType, status := range errorStatusMap {
+	for errType, info := range errorCatalog {
 		if errors.Is(err, errType) {
-			return status
+			return info.status
 		}
 	}
 
 	return http.StatusInternalServerError
 }
 
+// getErrorCode returns the stable, machine-readable code reported alongside err in a JSON error
+// envelope, falling back to defaultErrorCode for an error not in errorCatalog.
+func (h Handler) getErrorCode(err error) string {
+	// fast error check
+	if info, exists := errorCatalog[err]; exists {
+		return info.code
+	}
+
+	// For wrapped errors
+	for errType, info := range errorCatalog {
+		if errors.Is(err, errType) {
+			return info.code
+		}
+	}
+
+	return defaultErrorCode
+}
+
 // postURL handles POST requests from clients and receives URL from body to store it in the Repository via Service
 func (h Handler) postURL(w http.ResponseWriter, r *http.Request) {
 	userUUID, err := h.getUserUUIDFromRequest(r)
 	if err != nil {
-		http.Error(w, err.Error(), http.StatusBadRequest)
+		h.writeError(w, r, err, http.StatusBadRequest)
 	}
 
 	body, err := io.ReadAll(r.Body)
@@ -133,249 +653,1135 @@ func (h Handler) postURL(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	slug, err := h.service.SaveURL(r.Context(), userUUID, string(body))
+	corrID := correlationID(r)
+	w.Header().Set("X-Correlation-Id", corrID)
+	h.reqLog(r).Debug().Str("correlation_id", corrID).Msg("handling shorten request")
+
+	referer, userAgent := h.getCreationSource(r)
+	slug, err := h.service.SaveURL(r.Context(), userUUID, string(body), referer, userAgent, "", "", h.isAnonymousFromRequest(r), 0)
 	if err != nil {
 		statusCode := h.getStatusCode(err)
 		switch statusCode {
 		case http.StatusConflict:
+			shortURL := h.shortURLPrefix(r) + slug
+			if h.wantsJSON(r) {
+				w.Header().Set("Content-Type", "application/json")
+				w.WriteHeader(statusCode)
+				if err = json.NewEncoder(w).Encode(ShortURLResp{ShortURL: shortURL}); err != nil {
+					h.reqLog(r).Error().Err(err).Msg("failed to write response body")
+					w.WriteHeader(http.StatusInternalServerError)
+				}
+				return
+			}
 			w.WriteHeader(statusCode)
-			_, err = w.Write([]byte(h.cfg.GetShortURLTemplate() + "/" + slug))
+			_, err = w.Write([]byte(shortURL))
 			if err != nil {
-				h.zlog.Error().Err(err).Msg("failed to write response body")
+				h.reqLog(r).Error().Err(err).Msg("failed to write response body")
 				w.WriteHeader(http.StatusInternalServerError)
 			}
 			return
 		case http.StatusBadRequest:
-			http.Error(w, err.Error(), statusCode)
+			h.writeError(w, r, err, statusCode)
 			return
 		}
 
-		http.Error(w, err.Error(), http.StatusInternalServerError)
-		h.zlog.Error().Msgf("Failed to generate short url: %v", err)
+		h.writeError(w, r, err, http.StatusInternalServerError)
+		h.reqLog(r).Error().Msgf("Failed to generate short url: %v", err)
 		return
 	}
 
-	host := h.cfg.GetShortURLTemplate() + "/" + slug
+	host := h.shortURLPrefix(r) + slug
 	w.WriteHeader(http.StatusCreated)
 	if _, err = w.Write([]byte(host)); err != nil {
-		h.zlog.Error().Msgf("Failed to write response: %v", err)
+		h.reqLog(r).Error().Msgf("Failed to write response: %v", err)
 	}
 }
 
 func (h Handler) postURLJSON(w http.ResponseWriter, r *http.Request) {
 	userUUID, err := h.getUserUUIDFromRequest(r)
 	if err != nil {
-		http.Error(w, err.Error(), http.StatusBadRequest)
+		h.writeError(w, r, err, http.StatusBadRequest)
 	}
 
 	if r.Header.Get("Content-Type") != "application/json" {
 		w.WriteHeader(http.StatusBadRequest)
 	}
 
-	buf := new(bytes.Buffer)
+	buf := getJSONBodyBuffer()
+	defer putJSONBodyBuffer(buf)
 	n, err := buf.ReadFrom(r.Body)
 	if err != nil || n == 0 {
 		w.WriteHeader(http.StatusBadRequest)
 		return
 	}
 
-	h.zlog.Debug().Msg("decoding request")
+	h.reqLog(r).Debug().Msg("decoding request")
 	var req LongURLReq
 	dec := json.NewDecoder(buf)
-	if err = dec.Decode(&req); err != nil {
-		h.zlog.Debug().Msgf("cannot decode request JSON body: %v", err)
-		http.Error(w, err.Error(), http.StatusBadRequest)
+	if err = decodeJSONStrict(dec, &req); err != nil {
+		h.reqLog(r).Debug().Msgf("cannot decode request JSON body: %v", err)
+		h.writeError(w, r, err, http.StatusBadRequest)
 		return
 	}
-	h.zlog.Debug().Msg("request decoded successfully")
+	h.reqLog(r).Debug().Msg("request decoded successfully")
 
-	slug, err := h.service.SaveURL(r.Context(), userUUID, req.LongURL)
+	corrID := correlationID(r)
+	w.Header().Set("X-Correlation-Id", corrID)
+	h.reqLog(r).Debug().Str("correlation_id", corrID).Msg("handling shorten request")
+
+	if req.CustomAlias != "" && isReservedAlias(req.CustomAlias) {
+		h.writeError(w, r, myerrors.ErrAliasReserved, http.StatusBadRequest)
+		return
+	}
+
+	idempotent, err := parseIdempotent(r.URL.Query().Get("idempotent"))
+	if err != nil {
+		h.writeError(w, r, err, http.StatusBadRequest)
+		return
+	}
+
+	referer, userAgent := h.getCreationSource(r)
+	slug, err := h.service.SaveURL(r.Context(), userUUID, req.LongURL, referer, userAgent, req.CustomAlias, req.Password, h.isAnonymousFromRequest(r), req.RedirectStatus)
 	if err != nil {
+		if errors.Is(err, myerrors.ErrShortURLExist) {
+			h.writeError(w, r, errAliasInUse, http.StatusConflict)
+			return
+		}
+
 		statusCode := h.getStatusCode(err)
 		switch statusCode {
 		case http.StatusConflict:
+			if idempotent && errors.Is(err, myerrors.ErrURLExist) {
+				statusCode = http.StatusOK
+			}
+
 			resp := ShortURLResp{
-				ShortURL: h.cfg.GetShortURLTemplate() + "/" + slug,
+				ShortURL: h.shortURLPrefix(r) + slug,
 			}
 
 			w.Header().Set("Content-Type", "application/json")
 			w.WriteHeader(statusCode)
 			enc := json.NewEncoder(w)
 			if err = enc.Encode(resp); err != nil {
-				h.zlog.Debug().Msgf("error encoding response: %v", err)
-				http.Error(w, err.Error(), http.StatusInternalServerError)
+				h.reqLog(r).Debug().Msgf("error encoding response: %v", err)
+				h.writeError(w, r, err, http.StatusInternalServerError)
 				return
 			}
 			return
 		case http.StatusBadRequest:
-			http.Error(w, err.Error(), statusCode)
+			h.writeError(w, r, err, statusCode)
 			return
 		}
 
-		http.Error(w, err.Error(), http.StatusInternalServerError)
-		h.zlog.Error().Msgf("Failed to generate short url: %v", err)
+		h.writeError(w, r, err, http.StatusInternalServerError)
+		h.reqLog(r).Error().Msgf("Failed to generate short url: %v", err)
 		return
 	}
 
 	resp := ShortURLResp{
-		ShortURL: h.cfg.GetShortURLTemplate() + "/" + slug,
+		ShortURL: h.shortURLPrefix(r) + slug,
 	}
 
 	w.Header().Set("Content-Type", "application/json")
 	w.WriteHeader(http.StatusCreated)
 	enc := json.NewEncoder(w)
 	if err = enc.Encode(resp); err != nil {
-		h.zlog.Debug().Msgf("error encoding response: %v", err)
-		http.Error(w, err.Error(), http.StatusInternalServerError)
+		h.reqLog(r).Debug().Msgf("error encoding response: %v", err)
+		h.writeError(w, r, err, http.StatusInternalServerError)
 		return
 	}
 }
 
+// redirectRateLimitMiddleware rejects redirect requests with 429 once the configured
+// REDIRECT_RATE_LIMIT_RPS is exceeded. It is wired only onto GET /{id}, so it never
+// throttles URL creation endpoints.
+func (h Handler) redirectRateLimitMiddleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if !h.redirectLimiter.Allow() {
+			h.writeError(w, r, errTooManyRedirectRequests, http.StatusTooManyRequests)
+			return
+		}
+		next.ServeHTTP(w, r)
+	})
+}
+
+// creationRateLimitMiddleware rejects URL-creation requests with 429 once either the
+// caller's per-user or per-IP creation rate limit is exceeded. It is shared between the
+// plain-text and JSON shorten endpoints, so both draw from the same per-caller buckets,
+// and is never wired onto the redirect path.
+func (h Handler) creationRateLimitMiddleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if userUUID, err := h.getUserUUIDFromRequest(r); err == nil && !h.creationLimiterByUser.Allow(userUUID.String()) {
+			h.tooManyCreationRequests(w, r, h.cfg.GetCreationRateLimitPerUserRPS())
+			return
+		}
+		if !h.creationLimiterByIP.Allow(clientIP(r)) {
+			h.tooManyCreationRequests(w, r, h.cfg.GetCreationRateLimitPerIPRPS())
+			return
+		}
+		next.ServeHTTP(w, r)
+	})
+}
+
+// tooManyCreationRequests writes a 429 response with a Retry-After hint based on rps, the
+// limit that was exceeded
+func (h Handler) tooManyCreationRequests(w http.ResponseWriter, r *http.Request, rps float64) {
+	w.Header().Set("Retry-After", strconv.Itoa(retryAfterSeconds(rps)))
+	h.writeError(w, r, errTooManyCreationRequests, http.StatusTooManyRequests)
+}
+
+// retryAfterSeconds estimates the wait, in whole seconds, before a token-bucket limiter
+// allowing rps requests per second is likely to have a token available again
+func retryAfterSeconds(rps float64) int {
+	if rps >= 1 {
+		return 1
+	}
+	return int(math.Ceil(1 / rps))
+}
+
 // getURL handles get requests and redirects to the URL by provided shortURL if it is found in Repository
 func (h Handler) getURL(w http.ResponseWriter, r *http.Request) {
 	userUUID, err := h.getUserUUIDFromRequest(r)
 
 	if err != nil {
-		http.Error(w, err.Error(), http.StatusBadRequest)
+		h.writeError(w, r, err, http.StatusBadRequest)
+		return
 	}
 
 	id := chi.URLParam(r, "id")
 	url, err := h.service.GetURL(r.Context(), userUUID, id)
-	if err != nil {
+	if err != nil && !errors.Is(err, myerrors.ErrShortURLGracePeriod) {
 		statusCode := h.getStatusCode(err)
-		http.Error(w, err.Error(), statusCode)
+		h.writeError(w, r, err, statusCode)
 		return
 	}
+	if errors.Is(err, myerrors.ErrShortURLGracePeriod) {
+		w.Header().Set("Cache-Control", "no-store")
+	}
+
+	ok, err := h.service.VerifyPassword(r.Context(), id, r.URL.Query().Get("password"))
+	if err != nil {
+		h.writeError(w, r, err, http.StatusInternalServerError)
+		return
+	}
+	if !ok {
+		w.Header().Set("Content-Type", "text/html; charset=utf-8")
+		w.WriteHeader(http.StatusUnauthorized)
+		if _, err = w.Write([]byte(passwordPromptHTML)); err != nil {
+			h.reqLog(r).Error().Msgf("Failed to write password prompt response: %v", err)
+		}
+		return
+	}
+
+	configuredStatus := h.cfg.GetRedirectStatus()
+	if linkStatus, err := h.service.GetRedirectStatus(r.Context(), id); err == nil && linkStatus != 0 {
+		configuredStatus = linkStatus
+	}
+	status := redirectStatusFor(r.Method, configuredStatus)
+
 	w.Header().Set("Location", url)
-	w.WriteHeader(http.StatusTemporaryRedirect)
+	if h.wantsJSON(r) {
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(status)
+		if err = json.NewEncoder(w).Encode(RedirectResp{Location: url, Status: status}); err != nil {
+			h.reqLog(r).Error().Msgf("Failed to write redirect response: %v", err)
+		}
+		return
+	}
+	if h.cfg.GetRedirectBody() {
+		w.Header().Set("Content-Type", "text/html; charset=utf-8")
+	}
+	w.WriteHeader(status)
+	if h.cfg.GetRedirectBody() {
+		fmt.Fprintf(w, `<a href="%s">%s</a>`, url, url)
+	}
+}
+
+// getURLInspection handles GET /api/expand/{id}: it returns id's destination and deletion
+// state as JSON without redirecting to it, so a caller can safely check where a short link
+// leads before following it. Unlike GET /{id}, it never enforces the delete grace period's
+// transparent pass-through: a soft-deleted slug is always reported as deleted.
+func (h Handler) getURLInspection(w http.ResponseWriter, r *http.Request) {
+	userUUID, err := h.getUserUUIDFromRequest(r)
+	if err != nil {
+		h.writeError(w, r, err, http.StatusBadRequest)
+		return
+	}
+
+	id := chi.URLParam(r, "id")
+	originalURL, isDeleted, deletedAt, err := h.service.InspectURL(r.Context(), userUUID, id)
+	if err != nil {
+		h.writeError(w, r, err, h.getStatusCode(err))
+		return
+	}
+
+	resp := ExpandResp{OriginalURL: originalURL, IsDeleted: isDeleted}
+	if isDeleted {
+		resp.DeletedAt = &deletedAt
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	if err = json.NewEncoder(w).Encode(resp); err != nil {
+		h.reqLog(r).Error().Msgf("Failed to write URL inspection response: %v", err)
+	}
+}
+
+// postURLInspectionBatch handles POST /api/expand/batch: it is the batch form of
+// GET /api/expand/{id}, resolving many slugs in one round trip instead of one request per
+// slug. A slug that cannot be resolved is reported in that entry's Error field rather than
+// failing the whole batch. The number of slugs per request is capped by max-expand-batch.
+func (h Handler) postURLInspectionBatch(w http.ResponseWriter, r *http.Request) {
+	userUUID, err := h.getUserUUIDFromRequest(r)
+	if err != nil {
+		h.writeError(w, r, err, http.StatusBadRequest)
+		return
+	}
+
+	if r.Header.Get("Content-Type") != "application/json" {
+		w.WriteHeader(http.StatusBadRequest)
+	}
+
+	buf := getJSONBodyBuffer()
+	defer putJSONBodyBuffer(buf)
+	n, err := buf.ReadFrom(r.Body)
+	if err != nil || n == 0 {
+		w.WriteHeader(http.StatusBadRequest)
+		return
+	}
+
+	var req ExpandBatchReq
+	dec := json.NewDecoder(buf)
+	if err = decodeJSONStrict(dec, &req); err != nil {
+		h.reqLog(r).Debug().Msgf("cannot decode expand batch request JSON body: %v", err)
+		h.writeError(w, r, err, http.StatusBadRequest)
+		return
+	}
+
+	if maxBatch := h.cfg.GetMaxExpandBatch(); maxBatch > 0 && len(req.ShortURLs) > maxBatch {
+		h.writeError(w, r, fmt.Errorf("%w: got %d, max is %d", errTooManyShortURLs, len(req.ShortURLs), maxBatch), http.StatusBadRequest)
+		return
+	}
+
+	expansions, err := h.service.InspectURLs(r.Context(), userUUID, req.ShortURLs)
+	if err != nil {
+		h.writeError(w, r, err, http.StatusInternalServerError)
+		h.reqLog(r).Error().Msgf("error handling expand batch: %v", err)
+		return
+	}
+
+	resp := make([]ExpandBatchResp, len(expansions))
+	for i, exp := range expansions {
+		resp[i] = ExpandBatchResp{
+			ShortURL:    exp.ShortURL,
+			OriginalURL: exp.OriginalURL,
+			IsDeleted:   exp.IsDeleted,
+			Error:       exp.Err,
+		}
+		if exp.IsDeleted {
+			resp[i].DeletedAt = &exp.DeletedAt
+		}
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	if err = json.NewEncoder(w).Encode(resp); err != nil {
+		h.reqLog(r).Error().Msgf("Failed to write URL inspection batch response: %v", err)
+	}
+}
+
+// redirectStatusFor picks the HTTP status for a redirect response. configured is the
+// operator's preferred status (0 falls back to http.StatusTemporaryRedirect). A GET request
+// always gets exactly the configured status; for any other method (e.g. a HEAD probe), a
+// legacy status that does not guarantee method preservation is swapped for its
+// method-preserving equivalent, since only GET is safe to resend as-is after a redirect.
+func redirectStatusFor(method string, configured int) int {
+	status := configured
+	if status == 0 {
+		status = http.StatusTemporaryRedirect
+	}
+	if method == http.MethodGet {
+		return status
+	}
+	switch status {
+	case http.StatusMovedPermanently:
+		return http.StatusPermanentRedirect
+	case http.StatusFound:
+		return http.StatusTemporaryRedirect
+	default:
+		return status
+	}
+}
+
+// passwordPromptHTML is served at GET /{id} with 401 when the slug is password-protected
+// and the caller did not supply a matching password
+const passwordPromptHTML = `<!DOCTYPE html>
+<html>
+<head><title>Password required</title></head>
+<body>
+<p>This link is password protected.</p>
+<form method="get">
+<input type="password" name="password" placeholder="Password" autofocus>
+<button type="submit">Unlock</button>
+</form>
+</body>
+</html>`
+
+// rootLandingHTML is a minimal landing/health page served at GET / when root-behavior is
+// "landing", for humans or monitors that hit the shortener's base domain directly
+const rootLandingHTML = `<!DOCTYPE html>
+<html>
+<head><title>shortener</title></head>
+<body><p>This is a URL shortener. POST a URL to / to create a short link.</p></body>
+</html>`
+
+// getRootLanding serves a small embedded landing page at GET /
+func (h Handler) getRootLanding(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "text/html; charset=utf-8")
+	w.WriteHeader(http.StatusOK)
+	if _, err := w.Write([]byte(rootLandingHTML)); err != nil {
+		h.reqLog(r).Error().Msgf("Failed to write root landing response: %v", err)
+	}
+}
+
+// getRootRedirect redirects GET / to the configured root-redirect-url
+func (h Handler) getRootRedirect(w http.ResponseWriter, r *http.Request) {
+	http.Redirect(w, r, h.cfg.GetRootRedirectURL(), http.StatusFound)
+}
+
+// getCapabilities reports which optional features are active in this deployment so
+// clients can adapt their behavior without guessing at server configuration
+func (h Handler) getCapabilities(w http.ResponseWriter, r *http.Request) {
+	resp := CapabilitiesResp{
+		TrackCreationSource: h.cfg.GetTrackCreationSource(),
+		AvoidRecentSlugs:    h.cfg.GetAvoidRecentSlugs(),
+		JSONDeleteResponse:  h.cfg.GetJSONDeleteResponse(),
+		ReadOnly:            h.readOnly.Load(),
+		TrustedSubnet:       h.cfg.GetTrustedSubnet() != "",
+		JSONNegotiation:     h.cfg.GetEnableJSONNegotiation(),
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	if err := json.NewEncoder(w).Encode(resp); err != nil {
+		h.reqLog(r).Debug().Msgf("error encoding capabilities response: %v", err)
+	}
 }
 
-// checkPostgresConnection used in /ping GET request
-func (h Handler) checkPostgresConnection(w http.ResponseWriter, r *http.Request) {
-	err := h.cfg.CheckPostgresConnection(r.Context())
+// ping used in /ping GET request; it reports the health of the active backend, so it is
+// always 200 for memory/file deployments and only reflects real DB state for postgres
+func (h Handler) ping(w http.ResponseWriter, r *http.Request) {
+	err := h.service.Ping(r.Context())
 	if err != nil {
-		http.Error(w, err.Error(), http.StatusInternalServerError)
+		h.writeError(w, r, err, http.StatusInternalServerError)
 		return
 	}
 	w.WriteHeader(http.StatusOK)
 }
 
+// healthComponents checks the components examined by /healthz and /readyz, beyond the bare
+// reachability check /ping performs: the active repository backend (a real connectivity
+// check for postgres, redis and s3; always ok for memory and file, which cannot become
+// unavailable independently of the process) and the asynchronous delete queue backlog. It
+// returns each component's status and whether every one of them is healthy.
+func (h Handler) healthComponents(ctx context.Context) (map[string]ComponentHealth, bool) {
+	healthy := true
+	components := make(map[string]ComponentHealth, 2)
+
+	if err := h.service.Ping(ctx); err != nil {
+		components["repository"] = ComponentHealth{Status: "error", Detail: err.Error()}
+		healthy = false
+	} else {
+		components["repository"] = ComponentHealth{Status: "ok"}
+	}
+
+	pending := h.service.PendingDeletes()
+	limit := h.cfg.GetDeleteQueueLimit()
+	detail := fmt.Sprintf("%d queued", pending)
+	if limit > 0 && pending >= limit {
+		components["delete_queue"] = ComponentHealth{Status: "error", Detail: detail + fmt.Sprintf(", at limit of %d", limit)}
+		healthy = false
+	} else {
+		components["delete_queue"] = ComponentHealth{Status: "ok", Detail: detail}
+	}
+
+	return components, healthy
+}
+
+// writeHealthResp encodes components as a HealthResp, using statusCode as the HTTP status
+func (h Handler) writeHealthResp(w http.ResponseWriter, r *http.Request, components map[string]ComponentHealth, healthy bool, statusCode int) {
+	status := "ok"
+	if !healthy {
+		status = "error"
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(statusCode)
+	if err := json.NewEncoder(w).Encode(HealthResp{Status: status, Components: components}); err != nil {
+		h.reqLog(r).Debug().Msgf("error encoding health response: %v", err)
+	}
+}
+
+// healthz used in /healthz GET request; it reports the health of the active repository
+// backend and the asynchronous delete queue, so an operator or alerting system can see which
+// component is degraded instead of just a bare up/down signal
+func (h Handler) healthz(w http.ResponseWriter, r *http.Request) {
+	components, healthy := h.healthComponents(r.Context())
+
+	statusCode := http.StatusOK
+	if !healthy {
+		statusCode = http.StatusServiceUnavailable
+	}
+	h.writeHealthResp(w, r, components, healthy, statusCode)
+}
+
+// readyz used in /readyz GET request; it reports 503 until the backend (including any
+// database migrations) has finished initializing, so a load balancer does not route traffic
+// to an instance that is still migrating, and thereafter reports the same component breakdown
+// as /healthz
+func (h Handler) readyz(w http.ResponseWriter, r *http.Request) {
+	if !h.readiness.Ready() {
+		h.writeHealthResp(w, r, map[string]ComponentHealth{"migrations": {Status: "pending"}}, false, http.StatusServiceUnavailable)
+		return
+	}
+
+	components, healthy := h.healthComponents(r.Context())
+	statusCode := http.StatusOK
+	if !healthy {
+		statusCode = http.StatusServiceUnavailable
+	}
+	h.writeHealthResp(w, r, components, healthy, statusCode)
+}
+
+// parseIdempotent interprets the idempotent query parameter of POST /api/shorten: an empty
+// value or "0" keeps the default behavior of reporting an already-shortened URL as a 409
+// conflict, while "1" reports it as a 200 with the existing short URL instead, so idempotent
+// clients do not need to treat that case as an error
+func parseIdempotent(value string) (bool, error) {
+	switch value {
+	case "", "0":
+		return false, nil
+	case "1":
+		return true, nil
+	default:
+		return false, fmt.Errorf("unknown idempotent value %q: must be \"0\" or \"1\"", value)
+	}
+}
+
+// parseOnConflict interprets the on_conflict query parameter of POST /api/shorten/batch. Either
+// way, an already-shortened URL in the batch is reported in the response with its existing
+// short URL and conflict set, rather than failing the whole batch: an empty value or "error"
+// discovers the conflict only once the repository is written, while "reuse" checks for it up
+// front, saving the repository a wasted write attempt for rows known to already be taken
+func parseOnConflict(value string) (reuseOnConflict bool, err error) {
+	switch value {
+	case "", "error":
+		return false, nil
+	case "reuse":
+		return true, nil
+	default:
+		return false, fmt.Errorf("unknown on_conflict value %q: must be \"error\" or \"reuse\"", value)
+	}
+}
+
+// parsePagination interprets the limit and offset query parameters of GET /api/user/urls. An
+// absent value for either defaults to 0 (no limit / no offset); a negative or non-numeric
+// value is rejected.
+func parsePagination(r *http.Request) (limit int, offset int, err error) {
+	q := r.URL.Query()
+	if v := q.Get("limit"); v != "" {
+		limit, err = strconv.Atoi(v)
+		if err != nil || limit < 0 {
+			return 0, 0, fmt.Errorf("invalid limit %q: must be a non-negative integer", v)
+		}
+	}
+	if v := q.Get("offset"); v != "" {
+		offset, err = strconv.Atoi(v)
+		if err != nil || offset < 0 {
+			return 0, 0, fmt.Errorf("invalid offset %q: must be a non-negative integer", v)
+		}
+	}
+	return limit, offset, nil
+}
+
+// parseSort interprets the sort query parameter of GET /api/user/urls. An empty value or
+// "short_url" sorts by slug, which is always available and unique per user. "created_at" is
+// accepted ahead of model.URL tracking a real creation timestamp; until then it sorts
+// identically to "short_url".
+func parseSort(value string) (string, error) {
+	switch value {
+	case "":
+		return "short_url", nil
+	case "short_url", "created_at":
+		return value, nil
+	default:
+		return "", fmt.Errorf("unknown sort value %q: must be \"short_url\" or \"created_at\"", value)
+	}
+}
+
 // postURLJSONBatch handles bath request in JSON
 func (h Handler) postURLJSONBatch(w http.ResponseWriter, r *http.Request) {
 	userUUID, err := h.getUserUUIDFromRequest(r)
 	if err != nil {
-		http.Error(w, err.Error(), http.StatusBadRequest)
+		h.writeError(w, r, err, http.StatusBadRequest)
 	}
 
 	if r.Header.Get("Content-Type") != "application/json" {
 		w.WriteHeader(http.StatusBadRequest)
 	}
 
-	buf := new(bytes.Buffer)
+	reuseOnConflict, err := parseOnConflict(r.URL.Query().Get("on_conflict"))
+	if err != nil {
+		h.writeError(w, r, err, http.StatusBadRequest)
+		return
+	}
+
+	buf := getJSONBodyBuffer()
+	defer putJSONBodyBuffer(buf)
 	n, err := buf.ReadFrom(r.Body)
 	if err != nil || n == 0 {
 		w.WriteHeader(http.StatusBadRequest)
 		return
 	}
 
-	h.zlog.Debug().Msg("decoding batch request")
+	h.reqLog(r).Debug().Msg("decoding batch request")
 	var (
 		req  []BatchRequest
 		resp []BatchResponse
 	)
 
 	dec := json.NewDecoder(buf)
-	if err = dec.Decode(&req); err != nil {
-		h.zlog.Debug().Msgf("cannot decode bacth request JSON body: %v", h.zlog.Err(err))
-		http.Error(w, err.Error(), http.StatusBadRequest)
+	if err = decodeJSONStrict(dec, &req); err != nil {
+		h.reqLog(r).Debug().Msgf("cannot decode bacth request JSON body: %v", h.reqLog(r).Err(err))
+		h.writeError(w, r, err, http.StatusBadRequest)
 		return
 	}
-	h.zlog.Debug().Msg("batch request decoded successfully")
+	h.reqLog(r).Debug().Msg("batch request decoded successfully")
 	var URLs []model.URL
 	for i := range req {
 		URLs = append(URLs, model.URL{UUID: req[i].UUID, OriginalURL: req[i].LongURL})
 	}
 
-	serviceResp, err := h.service.SaveBatch(r.Context(), userUUID, URLs)
+	referer, userAgent := h.getCreationSource(r)
+	serviceResp, err := h.service.SaveBatch(r.Context(), userUUID, URLs, referer, userAgent, reuseOnConflict, h.isAnonymousFromRequest(r))
 	if err != nil {
 		statusCode := h.getStatusCode(err)
 		switch statusCode {
 		case http.StatusBadRequest:
-			http.Error(w, err.Error(), statusCode)
+			h.writeError(w, r, err, statusCode)
 			return
 		}
 
-		http.Error(w, err.Error(), http.StatusInternalServerError)
-		h.zlog.Error().Msgf("error handling batch: %v", err)
+		h.writeError(w, r, err, http.StatusInternalServerError)
+		h.reqLog(r).Error().Msgf("error handling batch: %v", err)
 		return
 	}
 	for i := range serviceResp {
-		resp = append(resp, BatchResponse{UUID: serviceResp[i].UUID, ShortURL: h.cfg.GetShortURLTemplate() + "/" + serviceResp[i].ShortURL})
+		resp = append(resp, BatchResponse{UUID: serviceResp[i].UUID, ShortURL: h.shortURLPrefix(r) + serviceResp[i].ShortURL, Conflict: serviceResp[i].Conflict})
 	}
 
 	w.Header().Set("Content-Type", "application/json")
 	w.WriteHeader(http.StatusCreated)
 	enc := json.NewEncoder(w)
 	if err = enc.Encode(resp); err != nil {
-		h.zlog.Debug().Msgf("error encoding batch response: %v", err)
-		http.Error(w, err.Error(), http.StatusInternalServerError)
+		h.reqLog(r).Debug().Msgf("error encoding batch response: %v", err)
+		h.writeError(w, r, err, http.StatusInternalServerError)
+		return
+	}
+}
+
+// readImportLine reads the next newline-terminated line from r using bufio.Reader.ReadLine,
+// which already splits a line longer than the reader's internal buffer across several
+// isPrefix reads; readImportLine reassembles those into a single slice and, once the
+// reassembled line exceeds maxLineBytes, stops appending and reports tooLong so the caller
+// can emit a per-line error instead of aborting the whole stream.
+func readImportLine(r *bufio.Reader, maxLineBytes int) (line []byte, tooLong bool, err error) {
+	total := 0
+	for {
+		chunk, isPrefix, readErr := r.ReadLine()
+		total += len(chunk)
+		if total > maxLineBytes {
+			tooLong = true
+		}
+		if len(chunk) > 0 && !tooLong {
+			line = append(line, chunk...)
+		}
+		if !isPrefix || readErr != nil {
+			return line, tooLong, readErr
+		}
+	}
+}
+
+// postURLImportStream handles POST /api/user/urls/import/stream: an application/x-ndjson body
+// of {"original_url":...,"alias":...} lines, shortened one at a time as they arrive and
+// reported back as NDJSON in the same order, so neither the request nor the response is ever
+// buffered in full. A malformed, oversized, or rate-limited line is reported as a per-line
+// error without aborting the rest of the stream.
+func (h Handler) postURLImportStream(w http.ResponseWriter, r *http.Request) {
+	userUUID, err := h.getUserUUIDFromRequest(r)
+	if err != nil {
+		h.writeError(w, r, err, http.StatusBadRequest)
+		return
+	}
+
+	if r.Header.Get("Content-Type") != "application/x-ndjson" {
+		w.WriteHeader(http.StatusBadRequest)
+		return
+	}
+
+	referer, userAgent := h.getCreationSource(r)
+	anonymous := h.isAnonymousFromRequest(r)
+	shortURLPrefix := h.shortURLPrefix(r)
+	maxLineBytes := h.cfg.GetImportStreamMaxLine()
+
+	w.Header().Set("Content-Type", "application/x-ndjson")
+	w.WriteHeader(http.StatusOK)
+	flusher, _ := w.(http.Flusher)
+	enc := json.NewEncoder(w)
+
+	reader := bufio.NewReader(r.Body)
+	for {
+		line, tooLong, readErr := readImportLine(reader, maxLineBytes)
+		if tooLong || len(bytes.TrimSpace(line)) > 0 {
+			h.importStreamLine(r.Context(), userUUID, line, tooLong, referer, userAgent, anonymous, shortURLPrefix, enc)
+			if flusher != nil {
+				flusher.Flush()
+			}
+		}
+		if readErr != nil {
+			if !errors.Is(readErr, io.EOF) {
+				h.reqLog(r).Debug().Msgf("error reading import stream body: %v", readErr)
+			}
+			return
+		}
+	}
+}
+
+// importStreamLine decodes and shortens a single import-stream line, encoding exactly one
+// ImportStreamLineResp to enc regardless of outcome
+func (h Handler) importStreamLine(ctx context.Context, userUUID uuid.UUID, line []byte, tooLong bool, referer string, userAgent string, anonymous bool, shortURLPrefix string, enc *json.Encoder) {
+	if tooLong {
+		_ = enc.Encode(ImportStreamLineResp{Error: fmt.Sprintf("line exceeds maximum size of %d bytes", h.cfg.GetImportStreamMaxLine())})
+		return
+	}
+
+	if !h.importLimiter.Allow() {
+		_ = enc.Encode(ImportStreamLineResp{Error: "import rate limit exceeded"})
+		return
+	}
+
+	var req ImportStreamLineReq
+	if err := json.Unmarshal(line, &req); err != nil {
+		_ = enc.Encode(ImportStreamLineResp{Error: fmt.Sprintf("invalid JSON: %v", err)})
+		return
+	}
+
+	_ = enc.Encode(h.importRow(ctx, userUUID, req.OriginalURL, req.Alias, referer, userAgent, anonymous, shortURLPrefix))
+}
+
+// importRow shortens a single imported link, preserving alias as its short URL when provided
+// and free, and reports the outcome as an ImportStreamLineResp rather than failing the rest of
+// the import: an already-shortened link resolves to its existing short URL instead of erroring.
+func (h Handler) importRow(ctx context.Context, userUUID uuid.UUID, originalURL string, alias string, referer string, userAgent string, anonymous bool, shortURLPrefix string) ImportStreamLineResp {
+	resp := ImportStreamLineResp{OriginalURL: originalURL}
+
+	if alias != "" && isReservedAlias(alias) {
+		resp.Error = myerrors.ErrAliasReserved.Error()
+		return resp
+	}
+
+	slug, err := h.service.SaveURL(ctx, userUUID, originalURL, referer, userAgent, alias, "", anonymous, 0)
+	if err != nil && !errors.Is(err, myerrors.ErrURLExist) {
+		resp.Error = err.Error()
+		return resp
+	}
+
+	resp.ShortURL = shortURLPrefix + slug
+	return resp
+}
+
+// postURLImport handles POST /api/user/urls/import: a bulk-migration endpoint accepting either
+// application/x-ndjson (the same {"original_url":...,"alias":...} lines postURLImportStream
+// takes) or text/csv (a header row of "original_url,alias" followed by one row per link, alias
+// optional). Either may be gzip-compressed via Content-Encoding: gzip, already decompressed
+// transparently by gzipMiddleware before this handler sees the body. Rows are read
+// incrementally and saved one at a time through the same importRow path as
+// postURLImportStream, rather than in SaveBatch's bulk form: SaveBatch has no way to preserve a
+// caller-provided alias, which this endpoint promises, so per-row SaveURL is used instead. The
+// input is still never buffered in full, so an arbitrarily large migration file streams through
+// in constant memory.
+func (h Handler) postURLImport(w http.ResponseWriter, r *http.Request) {
+	userUUID, err := h.getUserUUIDFromRequest(r)
+	if err != nil {
+		h.writeError(w, r, err, http.StatusBadRequest)
+		return
+	}
+
+	contentType := r.Header.Get("Content-Type")
+	if contentType != "application/x-ndjson" && contentType != "text/csv" {
+		h.writeError(w, r, errUnsupportedImportContentType, http.StatusBadRequest)
+		return
+	}
+
+	var body io.Reader = r.Body
+	referer, userAgent := h.getCreationSource(r)
+	anonymous := h.isAnonymousFromRequest(r)
+	shortURLPrefix := h.shortURLPrefix(r)
+
+	w.Header().Set("Content-Type", "application/x-ndjson")
+	w.WriteHeader(http.StatusOK)
+	flusher, _ := w.(http.Flusher)
+	enc := json.NewEncoder(w)
+
+	if contentType == "text/csv" {
+		h.importCSV(r.Context(), userUUID, body, referer, userAgent, anonymous, shortURLPrefix, enc, flusher)
+		return
+	}
+
+	maxLineBytes := h.cfg.GetImportStreamMaxLine()
+	reader := bufio.NewReader(body)
+	for {
+		line, tooLong, readErr := readImportLine(reader, maxLineBytes)
+		if tooLong || len(bytes.TrimSpace(line)) > 0 {
+			h.importStreamLine(r.Context(), userUUID, line, tooLong, referer, userAgent, anonymous, shortURLPrefix, enc)
+			if flusher != nil {
+				flusher.Flush()
+			}
+		}
+		if readErr != nil {
+			if !errors.Is(readErr, io.EOF) {
+				h.reqLog(r).Debug().Msgf("error reading import body: %v", readErr)
+			}
+			return
+		}
+	}
+}
+
+// importCSV reads a text/csv import body (a header row of "original_url,alias" followed by one
+// row per link, alias optional) and saves each row through importRow, reporting one
+// ImportStreamLineResp per data row in the same order it was read.
+func (h Handler) importCSV(ctx context.Context, userUUID uuid.UUID, body io.Reader, referer string, userAgent string, anonymous bool, shortURLPrefix string, enc *json.Encoder, flusher http.Flusher) {
+	csvReader := csv.NewReader(body)
+	csvReader.FieldsPerRecord = -1
+
+	if _, err := csvReader.Read(); err != nil {
 		return
 	}
+
+	for {
+		row, readErr := csvReader.Read()
+		if readErr != nil {
+			return
+		}
+
+		var originalURL, alias string
+		if len(row) > 0 {
+			originalURL = row[0]
+		}
+		if len(row) > 1 {
+			alias = row[1]
+		}
+
+		if !h.importLimiter.Allow() {
+			_ = enc.Encode(ImportStreamLineResp{OriginalURL: originalURL, Error: "import rate limit exceeded"})
+		} else {
+			_ = enc.Encode(h.importRow(ctx, userUUID, originalURL, alias, referer, userAgent, anonymous, shortURLPrefix))
+		}
+		if flusher != nil {
+			flusher.Flush()
+		}
+	}
 }
 
 // getURL handles get requests and redirects to the URL by provided shortURL if it is found in Repository
 func (h Handler) getUsersShortURL(w http.ResponseWriter, r *http.Request) {
 	userUUID, err := h.getUserUUIDFromRequest(r)
 	if err != nil {
-		http.Error(w, err.Error(), http.StatusBadRequest)
+		h.writeError(w, r, err, http.StatusBadRequest)
 	}
 
-	userSlugs, err := h.service.GetUserShortURLs(r.Context(), userUUID)
+	sortBy, err := parseSort(r.URL.Query().Get("sort"))
+	if err != nil {
+		h.writeError(w, r, err, http.StatusBadRequest)
+		return
+	}
+	limit, offset, err := parsePagination(r)
+	if err != nil {
+		h.writeError(w, r, err, http.StatusBadRequest)
+		return
+	}
+
+	userSlugs, err := h.service.GetUserShortURLs(r.Context(), userUUID, sortBy, limit, offset)
 	if err != nil {
 		statusCode := h.getStatusCode(err)
 		switch statusCode {
 		case http.StatusBadRequest:
-			http.Error(w, err.Error(), statusCode)
+			h.writeError(w, r, err, statusCode)
 			return
 
 		case http.StatusNoContent:
-			http.Error(w, err.Error(), statusCode)
+			h.writeError(w, r, err, statusCode)
 			return
 		}
 
-		http.Error(w, err.Error(), http.StatusInternalServerError)
-		h.zlog.Error().Msgf("error handling request: %v", err)
+		h.writeError(w, r, err, http.StatusInternalServerError)
+		h.reqLog(r).Error().Msgf("error handling request: %v", err)
 		return
 	}
 
-	var resp []UserShortURLs
+	truncated := false
+	if maxResponseURLs := h.cfg.GetMaxResponseURLs(); maxResponseURLs > 0 && len(userSlugs) > maxResponseURLs {
+		userSlugs = userSlugs[:maxResponseURLs]
+		truncated = true
+	}
+
+	var body any
 
-	for k, v := range userSlugs {
-		resp = append(resp, UserShortURLs{ShortURL: h.cfg.GetShortURLTemplate() + "/" + k, LongURL: v})
+	if r.URL.Query().Get("format") == "map" {
+		resp := make(map[string]string, len(userSlugs))
+		for _, u := range userSlugs {
+			resp[h.shortURLPrefix(r)+u.ShortURL] = u.OriginalURL
+		}
+		body = resp
+	} else {
+		var resp []UserShortURLs
+		for _, u := range userSlugs {
+			entry := UserShortURLs{
+				ShortURL:  h.shortURLPrefix(r) + u.ShortURL,
+				LongURL:   u.OriginalURL,
+				Referer:   u.Referer,
+				UserAgent: u.UserAgent,
+			}
+			if !u.CreatedAt.IsZero() {
+				entry.CreatedAt = &u.CreatedAt
+			}
+			if !u.UpdatedAt.IsZero() {
+				entry.UpdatedAt = &u.UpdatedAt
+			}
+			resp = append(resp, entry)
+		}
+		body = resp
 	}
 
 	w.Header().Set("Content-Type", "application/json")
+	if truncated {
+		w.Header().Set("X-Truncated", "true")
+	}
 	w.WriteHeader(http.StatusOK)
 	enc := json.NewEncoder(w)
-	if err = enc.Encode(resp); err != nil {
-		h.zlog.Debug().Msgf("error encoding response: %v", err)
-		http.Error(w, err.Error(), http.StatusInternalServerError)
+	if err = enc.Encode(body); err != nil {
+		h.reqLog(r).Debug().Msgf("error encoding response: %v", err)
+		h.writeError(w, r, err, http.StatusInternalServerError)
+		return
+	}
+}
+
+// exportPageSize bounds how many rows exportUserURLs pulls from Service.GetUserShortURLs per
+// page, so an export never holds more than one page of the caller's URLs in memory at once.
+const exportPageSize = 500
+
+// exportUserURLs handles GET /api/user/urls/export: it streams the caller's short URLs as
+// CSV or NDJSON (format=csv|jsonl), writing the original URL, short URL, created_at and a
+// deleted flag for each one. Rather than loading the whole result set into memory, it pages
+// through Service.GetUserShortURLs exportPageSize rows at a time, flushing each page to the
+// client as soon as it is fetched. The deleted flag is always false, since
+// GetUserShortURLs, like the rest of the /api/user/urls/* endpoints, only returns
+// non-deleted links.
+func (h Handler) exportUserURLs(w http.ResponseWriter, r *http.Request) {
+	userUUID, err := h.getUserUUIDFromRequest(r)
+	if err != nil {
+		h.writeError(w, r, err, http.StatusBadRequest)
+		return
+	}
+
+	format := r.URL.Query().Get("format")
+	if format != "csv" && format != "jsonl" {
+		h.writeError(w, r, errUnsupportedExportFormat, http.StatusBadRequest)
+		return
+	}
+
+	sortBy, err := parseSort(r.URL.Query().Get("sort"))
+	if err != nil {
+		h.writeError(w, r, err, http.StatusBadRequest)
+		return
+	}
+
+	shortURLPrefix := h.shortURLPrefix(r)
+
+	var csvWriter *csv.Writer
+	if format == "csv" {
+		w.Header().Set("Content-Type", "text/csv")
+		w.Header().Set("Content-Disposition", `attachment; filename="urls.csv"`)
+	} else {
+		w.Header().Set("Content-Type", "application/x-ndjson")
+		w.Header().Set("Content-Disposition", `attachment; filename="urls.jsonl"`)
+	}
+	w.WriteHeader(http.StatusOK)
+	flusher, _ := w.(http.Flusher)
+
+	if format == "csv" {
+		csvWriter = csv.NewWriter(w)
+		if err := csvWriter.Write([]string{"original_url", "short_url", "created_at", "deleted"}); err != nil {
+			h.reqLog(r).Debug().Msgf("error writing export header: %v", err)
+			return
+		}
+	}
+	enc := json.NewEncoder(w)
+
+	for offset := 0; ; offset += exportPageSize {
+		page, err := h.service.GetUserShortURLs(r.Context(), userUUID, sortBy, exportPageSize, offset)
+		if err != nil {
+			h.reqLog(r).Debug().Msgf("error exporting URLs: %v", err)
+			return
+		}
+		if len(page) == 0 {
+			return
+		}
+
+		for _, u := range page {
+			var createdAt string
+			if !u.CreatedAt.IsZero() {
+				createdAt = u.CreatedAt.Format(time.RFC3339)
+			}
+
+			if format == "csv" {
+				row := []string{u.OriginalURL, shortURLPrefix + u.ShortURL, createdAt, strconv.FormatBool(u.IsDeleted)}
+				if err := csvWriter.Write(row); err != nil {
+					h.reqLog(r).Debug().Msgf("error writing export row: %v", err)
+					return
+				}
+			} else {
+				row := ExportedURL{OriginalURL: u.OriginalURL, ShortURL: shortURLPrefix + u.ShortURL, CreatedAt: createdAt, Deleted: u.IsDeleted}
+				if err := enc.Encode(row); err != nil {
+					h.reqLog(r).Debug().Msgf("error writing export row: %v", err)
+					return
+				}
+			}
+		}
+
+		if format == "csv" {
+			csvWriter.Flush()
+		}
+		if flusher != nil {
+			flusher.Flush()
+		}
+
+		if len(page) < exportPageSize {
+			return
+		}
+	}
+}
+
+// eventsHeartbeatInterval is how often getEvents sends a comment line to keep an idle SSE
+// connection from being closed by intermediate proxies
+const eventsHeartbeatInterval = 15 * time.Second
+
+// getEvents handles GET /api/events: a Server-Sent Events stream of the caller's create,
+// delete and redirect events, for a dashboard to consume without polling. The connection
+// stays open until the client disconnects; a heartbeat comment is sent every
+// eventsHeartbeatInterval to keep it alive across idle periods.
+func (h Handler) getEvents(w http.ResponseWriter, r *http.Request) {
+	userUUID, err := h.getUserUUIDFromRequest(r)
+	if err != nil {
+		h.writeError(w, r, err, http.StatusBadRequest)
+		return
+	}
+
+	flusher, ok := w.(http.Flusher)
+	if !ok {
+		h.writeError(w, r, errStreamingUnsupported, http.StatusInternalServerError)
+		return
+	}
+
+	events, unsubscribe := h.service.Subscribe(userUUID)
+	defer unsubscribe()
+
+	w.Header().Set("Content-Type", "text/event-stream")
+	w.Header().Set("Cache-Control", "no-cache")
+	w.Header().Set("Connection", "keep-alive")
+	w.WriteHeader(http.StatusOK)
+	flusher.Flush()
+
+	enc := json.NewEncoder(w)
+	heartbeat := time.NewTicker(eventsHeartbeatInterval)
+	defer heartbeat.Stop()
+
+	for {
+		select {
+		case <-r.Context().Done():
+			return
+		case event := <-events:
+			if _, err := io.WriteString(w, "data: "); err != nil {
+				return
+			}
+			if err := enc.Encode(event); err != nil {
+				h.reqLog(r).Debug().Msgf("error writing event: %v", err)
+				return
+			}
+			if _, err := io.WriteString(w, "\n"); err != nil {
+				return
+			}
+			flusher.Flush()
+		case <-heartbeat.C:
+			if _, err := io.WriteString(w, ": keep-alive\n\n"); err != nil {
+				return
+			}
+			flusher.Flush()
+		}
+	}
+}
+
+// putUserShortURL handles PUT /api/user/urls/{id}: the owning user changes id's destination
+// to the url in the request body. An id that exists but is owned by someone else is reported
+// as 403, distinct from the 204 reported for an id that does not exist at all.
+func (h Handler) putUserShortURL(w http.ResponseWriter, r *http.Request) {
+	userUUID, err := h.getUserUUIDFromRequest(r)
+	if err != nil {
+		h.writeError(w, r, err, http.StatusBadRequest)
+		return
+	}
+
+	id := chi.URLParam(r, "id")
+
+	buf := getJSONBodyBuffer()
+	defer putJSONBodyBuffer(buf)
+	n, err := buf.ReadFrom(r.Body)
+	if err != nil || n == 0 {
+		w.WriteHeader(http.StatusBadRequest)
 		return
 	}
+
+	var req LongURLReq
+	dec := json.NewDecoder(buf)
+	if err = decodeJSONStrict(dec, &req); err != nil {
+		h.reqLog(r).Debug().Msgf("cannot decode request JSON body: %v", err)
+		h.writeError(w, r, err, http.StatusBadRequest)
+		return
+	}
+
+	if err = h.service.UpdateURL(r.Context(), userUUID, id, req.LongURL); err != nil {
+		statusCode := h.getStatusCode(err)
+		h.writeError(w, r, err, statusCode)
+		return
+	}
+
+	w.WriteHeader(http.StatusOK)
 }
 
+// deleteQueueRetryAfterSeconds is the Retry-After value sent with a 503 when the async
+// delete backlog is full; callers are expected to back off and retry rather than poll tightly
+const deleteQueueRetryAfterSeconds = "5"
+
 // deleteUsersShortURL handles users short url deletion and places slugs into the channel in service
 func (h Handler) deleteUsersShortURL(w http.ResponseWriter, r *http.Request) {
 	userUUID, err := h.getUserUUIDFromRequest(r)
 	if err != nil {
-		http.Error(w, err.Error(), http.StatusBadRequest)
-		h.zlog.Debug().Msgf("error getting user UUID: %v", err)
+		h.writeError(w, r, err, http.StatusBadRequest)
+		h.reqLog(r).Debug().Msgf("error getting user UUID: %v", err)
 	}
 
 	bodyBytes, err := io.ReadAll(r.Body)
 	if err != nil {
-		http.Error(w, "Error reading request body", http.StatusInternalServerError)
-		h.zlog.Debug().Msgf("Error reading body: %v", err)
+		h.writeError(w, r, errRequestBodyRead, http.StatusInternalServerError)
+		h.reqLog(r).Debug().Msgf("Error reading body: %v", err)
 		return
 	}
 	defer r.Body.Close() // Ensure the body is closed
@@ -386,12 +1792,80 @@ func (h Handler) deleteUsersShortURL(w http.ResponseWriter, r *http.Request) {
 	// Unmarshal the JSON bytes into the Go slice
 	err = json.Unmarshal(bodyBytes, &shortURLs)
 	if err != nil {
-		http.Error(w, "Error unmarshalling JSON", http.StatusBadRequest)
-		h.zlog.Debug().Msgf("Error unmarshalling JSON: %v", err)
+		h.writeError(w, r, errJSONUnmarshal, http.StatusBadRequest)
+		h.reqLog(r).Debug().Msgf("Error unmarshalling JSON: %v", err)
+		return
+	}
+
+	accepted := dedupValidSlugs(shortURLs)
+
+	jobID, err := h.service.SendShortURLForDelete(r.Context(), userUUID, accepted)
+	if err != nil {
+		w.Header().Set("Retry-After", deleteQueueRetryAfterSeconds)
+		h.writeError(w, r, err, http.StatusServiceUnavailable)
 		return
 	}
 
-	h.service.SendShortURLForDelete(r.Context(), userUUID, shortURLs)
+	w.Header().Set("X-Delete-Job-Id", jobID.String())
+
+	if !h.cfg.GetJSONDeleteResponse() {
+		w.WriteHeader(http.StatusAccepted)
+		return
+	}
 
+	w.Header().Set("Content-Type", "application/json")
 	w.WriteHeader(http.StatusAccepted)
+	if err = json.NewEncoder(w).Encode(DeleteAcceptedResp{Accepted: len(accepted), Queued: true, JobID: jobID.String()}); err != nil {
+		h.reqLog(r).Debug().Msgf("error encoding response: %v", err)
+	}
+}
+
+// deleteJobStatus reports whether a job started by deleteUsersShortURL has completed, failed,
+// or is still pending. Restricted to the job's owner, so one user cannot poll another's job.
+func (h Handler) deleteJobStatus(w http.ResponseWriter, r *http.Request) {
+	userUUID, err := h.getUserUUIDFromRequest(r)
+	if err != nil {
+		h.writeError(w, r, err, http.StatusBadRequest)
+		h.reqLog(r).Debug().Msgf("error getting user UUID: %v", err)
+		return
+	}
+
+	jobID, err := uuid.Parse(chi.URLParam(r, "jobID"))
+	if err != nil {
+		h.writeError(w, r, errInvalidJobID, http.StatusBadRequest)
+		return
+	}
+
+	job, err := h.service.DeleteJobStatus(r.Context(), userUUID, jobID)
+	if err != nil {
+		if errors.Is(err, myerrors.ErrNotFound) {
+			h.writeError(w, r, err, http.StatusNotFound)
+			return
+		}
+		h.writeError(w, r, err, http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	if err = json.NewEncoder(w).Encode(job); err != nil {
+		h.reqLog(r).Debug().Msgf("error encoding response: %v", err)
+	}
+}
+
+// dedupValidSlugs filters out empty entries and duplicates, preserving the first occurrence order
+func dedupValidSlugs(shortURLs []string) []string {
+	seen := make(map[string]struct{}, len(shortURLs))
+	accepted := make([]string, 0, len(shortURLs))
+	for _, slug := range shortURLs {
+		if slug == "" {
+			continue
+		}
+		if _, ok := seen[slug]; ok {
+			continue
+		}
+		seen[slug] = struct{}{}
+		accepted = append(accepted, slug)
+	}
+	return accepted
 }
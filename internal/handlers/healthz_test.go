@@ -0,0 +1,123 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/rs/zerolog"
+)
+
+// healthzErrService wraps fakeService, simulating an unreachable backend
+type healthzErrService struct {
+	fakeService
+}
+
+func (healthzErrService) Ping(_ context.Context) error {
+	return errors.New("connection refused")
+}
+
+// healthzQueueFullService wraps fakeService, simulating a delete queue at its configured limit
+type healthzQueueFullService struct {
+	fakeService
+}
+
+func (healthzQueueFullService) PendingDeletes() int {
+	return 10
+}
+
+func TestHealthz_HealthyBackend(t *testing.T) {
+	h := NewHandler(fakeService{}, fakeConfig{}, fakeAuth{}, zerolog.Nop(), fakeReadiness{})
+	router := h.newRouter()
+
+	req := httptest.NewRequest(http.MethodGet, "/healthz", nil)
+	rec := httptest.NewRecorder()
+	router.ServeHTTP(rec, req)
+
+	if rec.Code != http.StatusOK {
+		t.Errorf("got status %d, want %d", rec.Code, http.StatusOK)
+	}
+
+	var resp HealthResp
+	if err := json.Unmarshal(rec.Body.Bytes(), &resp); err != nil {
+		t.Fatalf("failed to decode response: %v", err)
+	}
+	if resp.Status != "ok" {
+		t.Errorf("resp.Status = %q, want %q", resp.Status, "ok")
+	}
+	if resp.Components["repository"].Status != "ok" {
+		t.Errorf("resp.Components[\"repository\"].Status = %q, want %q", resp.Components["repository"].Status, "ok")
+	}
+	if resp.Components["delete_queue"].Status != "ok" {
+		t.Errorf("resp.Components[\"delete_queue\"].Status = %q, want %q", resp.Components["delete_queue"].Status, "ok")
+	}
+}
+
+func TestHealthz_UnreachableRepository(t *testing.T) {
+	h := NewHandler(healthzErrService{}, fakeConfig{}, fakeAuth{}, zerolog.Nop(), fakeReadiness{})
+	router := h.newRouter()
+
+	req := httptest.NewRequest(http.MethodGet, "/healthz", nil)
+	rec := httptest.NewRecorder()
+	router.ServeHTTP(rec, req)
+
+	if rec.Code != http.StatusServiceUnavailable {
+		t.Errorf("got status %d, want %d", rec.Code, http.StatusServiceUnavailable)
+	}
+
+	var resp HealthResp
+	if err := json.Unmarshal(rec.Body.Bytes(), &resp); err != nil {
+		t.Fatalf("failed to decode response: %v", err)
+	}
+	if resp.Status != "error" {
+		t.Errorf("resp.Status = %q, want %q", resp.Status, "error")
+	}
+	if resp.Components["repository"].Status != "error" {
+		t.Errorf("resp.Components[\"repository\"].Status = %q, want %q", resp.Components["repository"].Status, "error")
+	}
+}
+
+func TestHealthz_DeleteQueueAtLimit(t *testing.T) {
+	h := NewHandler(healthzQueueFullService{}, fakeConfig{deleteQueueLimit: 10}, fakeAuth{}, zerolog.Nop(), fakeReadiness{})
+	router := h.newRouter()
+
+	req := httptest.NewRequest(http.MethodGet, "/healthz", nil)
+	rec := httptest.NewRecorder()
+	router.ServeHTTP(rec, req)
+
+	if rec.Code != http.StatusServiceUnavailable {
+		t.Errorf("got status %d, want %d", rec.Code, http.StatusServiceUnavailable)
+	}
+
+	var resp HealthResp
+	if err := json.Unmarshal(rec.Body.Bytes(), &resp); err != nil {
+		t.Fatalf("failed to decode response: %v", err)
+	}
+	if resp.Components["delete_queue"].Status != "error" {
+		t.Errorf("resp.Components[\"delete_queue\"].Status = %q, want %q", resp.Components["delete_queue"].Status, "error")
+	}
+}
+
+func TestReadyz_ReportsComponentsOnceReady(t *testing.T) {
+	h := NewHandler(fakeService{}, fakeConfig{}, fakeAuth{}, zerolog.Nop(), fakeReadiness{})
+	router := h.newRouter()
+
+	req := httptest.NewRequest(http.MethodGet, "/readyz", nil)
+	rec := httptest.NewRecorder()
+	router.ServeHTTP(rec, req)
+
+	if rec.Code != http.StatusOK {
+		t.Errorf("got status %d, want %d", rec.Code, http.StatusOK)
+	}
+
+	var resp HealthResp
+	if err := json.Unmarshal(rec.Body.Bytes(), &resp); err != nil {
+		t.Fatalf("failed to decode response: %v", err)
+	}
+	if _, ok := resp.Components["repository"]; !ok {
+		t.Error("resp.Components missing \"repository\" entry")
+	}
+}
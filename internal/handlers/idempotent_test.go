@@ -0,0 +1,79 @@
+package handlers
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/rs/zerolog"
+)
+
+func TestPostURLJSON_Idempotent_ExistingURLReturns200(t *testing.T) {
+	h := NewHandler(conflictService{}, fakeConfig{}, fakeAuth{}, zerolog.Nop(), fakeReadiness{})
+	router := h.newRouter()
+
+	body := `{"url":"https://example.com"}`
+	req := httptest.NewRequest(http.MethodPost, "/api/shorten?idempotent=1", strings.NewReader(body))
+	req.Header.Set("Content-Type", "application/json")
+	rec := httptest.NewRecorder()
+	router.ServeHTTP(rec, req)
+
+	if rec.Code != http.StatusOK {
+		t.Fatalf("status = %d, want %d", rec.Code, http.StatusOK)
+	}
+
+	var resp ShortURLResp
+	if err := json.NewDecoder(rec.Body).Decode(&resp); err != nil {
+		t.Fatalf("failed to decode response: %v", err)
+	}
+	if resp.ShortURL != "http://localhost:8080/abc123" {
+		t.Errorf("ShortURL = %q, want %q", resp.ShortURL, "http://localhost:8080/abc123")
+	}
+}
+
+func TestPostURLJSON_Idempotent_NewURLReturns201(t *testing.T) {
+	h := newTestHandler(fakeConfig{})
+	router := h.newRouter()
+
+	body := `{"url":"https://example.com"}`
+	req := httptest.NewRequest(http.MethodPost, "/api/shorten?idempotent=1", strings.NewReader(body))
+	req.Header.Set("Content-Type", "application/json")
+	rec := httptest.NewRecorder()
+	router.ServeHTTP(rec, req)
+
+	if rec.Code != http.StatusCreated {
+		t.Fatalf("status = %d, want %d", rec.Code, http.StatusCreated)
+	}
+}
+
+func TestPostURLJSON_DefaultNonIdempotent_ExistingURLReturns409(t *testing.T) {
+	h := NewHandler(conflictService{}, fakeConfig{}, fakeAuth{}, zerolog.Nop(), fakeReadiness{})
+	router := h.newRouter()
+
+	body := `{"url":"https://example.com"}`
+	req := httptest.NewRequest(http.MethodPost, "/api/shorten", strings.NewReader(body))
+	req.Header.Set("Content-Type", "application/json")
+	rec := httptest.NewRecorder()
+	router.ServeHTTP(rec, req)
+
+	if rec.Code != http.StatusConflict {
+		t.Fatalf("status = %d, want %d", rec.Code, http.StatusConflict)
+	}
+}
+
+func TestPostURLJSON_RejectsUnknownIdempotentValue(t *testing.T) {
+	h := newTestHandler(fakeConfig{})
+	router := h.newRouter()
+
+	body := `{"url":"https://example.com"}`
+	req := httptest.NewRequest(http.MethodPost, "/api/shorten?idempotent=yes", strings.NewReader(body))
+	req.Header.Set("Content-Type", "application/json")
+	rec := httptest.NewRecorder()
+	router.ServeHTTP(rec, req)
+
+	if rec.Code != http.StatusBadRequest {
+		t.Fatalf("status = %d, want %d", rec.Code, http.StatusBadRequest)
+	}
+}
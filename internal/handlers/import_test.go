@@ -0,0 +1,132 @@
+package handlers
+
+import (
+	"bufio"
+	"bytes"
+	"compress/gzip"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/ar4ie13/shortener/internal/myerrors"
+	"github.com/rs/zerolog"
+)
+
+func TestPostURLImport_NDJSON(t *testing.T) {
+	h := NewHandler(importStreamService{}, fakeConfig{}, fakeAuth{}, zerolog.Nop(), fakeReadiness{})
+	router := h.newRouter()
+
+	body := strings.Join([]string{
+		`{"original_url":"https://example.com/one"}`,
+		`{"original_url":"https://example.com/fail"}`,
+	}, "\n")
+
+	req := httptest.NewRequest(http.MethodPost, "/api/user/urls/import", strings.NewReader(body))
+	req.Header.Set("Content-Type", "application/x-ndjson")
+	rec := httptest.NewRecorder()
+	router.ServeHTTP(rec, req)
+
+	if rec.Code != http.StatusOK {
+		t.Fatalf("got status %d, want %d; body: %s", rec.Code, http.StatusOK, rec.Body.String())
+	}
+
+	var lines []string
+	scanner := bufio.NewScanner(strings.NewReader(rec.Body.String()))
+	for scanner.Scan() {
+		lines = append(lines, scanner.Text())
+	}
+	if len(lines) != 2 {
+		t.Fatalf("got %d result lines, want 2; body: %s", len(lines), rec.Body.String())
+	}
+	if !strings.Contains(lines[0], `"short_url"`) {
+		t.Errorf("line 1 = %q, want a successful result", lines[0])
+	}
+	if !strings.Contains(lines[1], `"error"`) {
+		t.Errorf("line 2 = %q, want an error from the failing save", lines[1])
+	}
+}
+
+func TestPostURLImport_CSV(t *testing.T) {
+	h := NewHandler(importStreamService{}, fakeConfig{}, fakeAuth{}, zerolog.Nop(), fakeReadiness{})
+	router := h.newRouter()
+
+	body := "original_url,alias\n" +
+		"https://example.com/one,\n" +
+		"https://example.com/two,api\n"
+
+	req := httptest.NewRequest(http.MethodPost, "/api/user/urls/import", strings.NewReader(body))
+	req.Header.Set("Content-Type", "text/csv")
+	rec := httptest.NewRecorder()
+	router.ServeHTTP(rec, req)
+
+	if rec.Code != http.StatusOK {
+		t.Fatalf("got status %d, want %d; body: %s", rec.Code, http.StatusOK, rec.Body.String())
+	}
+
+	lines := strings.Split(strings.TrimSpace(rec.Body.String()), "\n")
+	if len(lines) != 2 {
+		t.Fatalf("got %d result lines, want 2; body: %s", len(lines), rec.Body.String())
+	}
+	if !strings.Contains(lines[0], `"short_url"`) {
+		t.Errorf("line 1 = %q, want a successful result", lines[0])
+	}
+	if !strings.Contains(lines[1], myerrors.ErrAliasReserved.Error()) {
+		t.Errorf("line 2 = %q, want the reserved-alias error for alias %q", lines[1], "api")
+	}
+}
+
+func TestPostURLImport_GzipBody(t *testing.T) {
+	h := NewHandler(importStreamService{}, fakeConfig{}, fakeAuth{}, zerolog.Nop(), fakeReadiness{})
+	router := h.newRouter()
+
+	var buf bytes.Buffer
+	gzWriter := gzip.NewWriter(&buf)
+	if _, err := gzWriter.Write([]byte(`{"original_url":"https://example.com/one"}` + "\n")); err != nil {
+		t.Fatalf("gzWriter.Write() unexpected error = %v", err)
+	}
+	if err := gzWriter.Close(); err != nil {
+		t.Fatalf("gzWriter.Close() unexpected error = %v", err)
+	}
+
+	req := httptest.NewRequest(http.MethodPost, "/api/user/urls/import", &buf)
+	req.Header.Set("Content-Type", "application/x-ndjson")
+	req.Header.Set("Content-Encoding", "gzip")
+	rec := httptest.NewRecorder()
+	router.ServeHTTP(rec, req)
+
+	if rec.Code != http.StatusOK {
+		t.Fatalf("got status %d, want %d; body: %s", rec.Code, http.StatusOK, rec.Body.String())
+	}
+	if !strings.Contains(rec.Body.String(), `"short_url"`) {
+		t.Errorf("body = %q, want a successful result decoded from the gzipped body", rec.Body.String())
+	}
+}
+
+func TestPostURLImport_RejectsWrongContentType(t *testing.T) {
+	h := NewHandler(importStreamService{}, fakeConfig{}, fakeAuth{}, zerolog.Nop(), fakeReadiness{})
+	router := h.newRouter()
+
+	req := httptest.NewRequest(http.MethodPost, "/api/user/urls/import", strings.NewReader(`{}`))
+	req.Header.Set("Content-Type", "application/json")
+	rec := httptest.NewRecorder()
+	router.ServeHTTP(rec, req)
+
+	if rec.Code != http.StatusBadRequest {
+		t.Errorf("got status %d, want %d", rec.Code, http.StatusBadRequest)
+	}
+}
+
+func TestPostURLImport_NotFoundWhenImportStreamDisabled(t *testing.T) {
+	h := NewHandler(importStreamService{}, fakeConfig{disableImportStream: true}, fakeAuth{}, zerolog.Nop(), fakeReadiness{})
+	router := h.newRouter()
+
+	req := httptest.NewRequest(http.MethodPost, "/api/user/urls/import", strings.NewReader(`{"original_url":"https://example.com"}`))
+	req.Header.Set("Content-Type", "application/x-ndjson")
+	rec := httptest.NewRecorder()
+	router.ServeHTTP(rec, req)
+
+	if rec.Code != http.StatusNotFound {
+		t.Errorf("got status %d, want %d", rec.Code, http.StatusNotFound)
+	}
+}
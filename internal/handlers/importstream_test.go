@@ -0,0 +1,129 @@
+package handlers
+
+import (
+	"bufio"
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/ar4ie13/shortener/internal/myerrors"
+	"github.com/google/uuid"
+	"github.com/rs/zerolog"
+)
+
+// importStreamService wraps fakeService, failing SaveURL for a URL containing "fail" so tests
+// can observe a per-line error alongside successful lines in the same stream
+type importStreamService struct {
+	fakeService
+}
+
+func (importStreamService) SaveURL(_ context.Context, _ uuid.UUID, url string, _ string, _ string, _ string, _ string, _ bool, _ int) (string, error) {
+	if strings.Contains(url, "fail") {
+		return "", myerrors.ErrInvalidURLFormat
+	}
+	return "abc123", nil
+}
+
+func TestPostURLImportStream_StreamsPerLineResults(t *testing.T) {
+	h := NewHandler(importStreamService{}, fakeConfig{}, fakeAuth{}, zerolog.Nop(), fakeReadiness{})
+	router := h.newRouter()
+
+	body := strings.Join([]string{
+		`{"original_url":"https://example.com/one"}`,
+		`not json`,
+		`{"original_url":"https://example.com/fail"}`,
+		``,
+		`{"original_url":"https://example.com/two","alias":"api"}`,
+	}, "\n")
+
+	req := httptest.NewRequest(http.MethodPost, "/api/user/urls/import/stream", strings.NewReader(body))
+	req.Header.Set("Content-Type", "application/x-ndjson")
+	rec := httptest.NewRecorder()
+	router.ServeHTTP(rec, req)
+
+	if rec.Code != http.StatusOK {
+		t.Fatalf("got status %d, want %d; body: %s", rec.Code, http.StatusOK, rec.Body.String())
+	}
+
+	var lines []string
+	scanner := bufio.NewScanner(strings.NewReader(rec.Body.String()))
+	for scanner.Scan() {
+		lines = append(lines, scanner.Text())
+	}
+
+	if len(lines) != 4 {
+		t.Fatalf("got %d result lines, want 4 (blank input line skipped); body: %s", len(lines), rec.Body.String())
+	}
+	if !strings.Contains(lines[0], `"short_url"`) || strings.Contains(lines[0], `"error"`) {
+		t.Errorf("line 1 = %q, want a successful result", lines[0])
+	}
+	if !strings.Contains(lines[1], `"error"`) {
+		t.Errorf("line 2 = %q, want an error for invalid JSON", lines[1])
+	}
+	if !strings.Contains(lines[2], `"error"`) {
+		t.Errorf("line 3 = %q, want an error from the failing save", lines[2])
+	}
+	if !strings.Contains(lines[3], myerrors.ErrAliasReserved.Error()) {
+		t.Errorf("line 4 = %q, want the reserved-alias error", lines[3])
+	}
+}
+
+func TestPostURLImportStream_RejectsWrongContentType(t *testing.T) {
+	h := NewHandler(importStreamService{}, fakeConfig{}, fakeAuth{}, zerolog.Nop(), fakeReadiness{})
+	router := h.newRouter()
+
+	req := httptest.NewRequest(http.MethodPost, "/api/user/urls/import/stream", strings.NewReader(`{"original_url":"https://example.com"}`))
+	req.Header.Set("Content-Type", "application/json")
+	rec := httptest.NewRecorder()
+	router.ServeHTTP(rec, req)
+
+	if rec.Code != http.StatusBadRequest {
+		t.Errorf("got status %d, want %d", rec.Code, http.StatusBadRequest)
+	}
+}
+
+func TestPostURLImportStream_OversizedLineReportsError(t *testing.T) {
+	h := NewHandler(importStreamService{}, fakeConfig{importStreamMaxLine: 16}, fakeAuth{}, zerolog.Nop(), fakeReadiness{})
+	router := h.newRouter()
+
+	body := `{"original_url":"https://example.com/this-line-is-too-long-to-fit"}` + "\n"
+	req := httptest.NewRequest(http.MethodPost, "/api/user/urls/import/stream", strings.NewReader(body))
+	req.Header.Set("Content-Type", "application/x-ndjson")
+	rec := httptest.NewRecorder()
+	router.ServeHTTP(rec, req)
+
+	if rec.Code != http.StatusOK {
+		t.Fatalf("got status %d, want %d", rec.Code, http.StatusOK)
+	}
+	if !strings.Contains(rec.Body.String(), "exceeds maximum size") {
+		t.Errorf("body = %q, want a line-too-long error", rec.Body.String())
+	}
+}
+
+func TestPostURLImportStream_RateLimited(t *testing.T) {
+	h := NewHandler(importStreamService{}, fakeConfig{importStreamRateLimit: 1}, fakeAuth{}, zerolog.Nop(), fakeReadiness{})
+	router := h.newRouter()
+
+	body := strings.Join([]string{
+		`{"original_url":"https://example.com/one"}`,
+		`{"original_url":"https://example.com/two"}`,
+	}, "\n") + "\n"
+
+	req := httptest.NewRequest(http.MethodPost, "/api/user/urls/import/stream", strings.NewReader(body))
+	req.Header.Set("Content-Type", "application/x-ndjson")
+	rec := httptest.NewRecorder()
+	router.ServeHTTP(rec, req)
+
+	lines := strings.Split(strings.TrimSpace(rec.Body.String()), "\n")
+	if len(lines) != 2 {
+		t.Fatalf("got %d result lines, want 2; body: %s", len(lines), rec.Body.String())
+	}
+	if strings.Contains(lines[0], "error") {
+		t.Errorf("line 1 = %q, want the first line to pass the single-token burst", lines[0])
+	}
+	if !strings.Contains(lines[1], "rate limit exceeded") {
+		t.Errorf("line 2 = %q, want the second line to be rate limited", lines[1])
+	}
+}
@@ -0,0 +1,51 @@
+package handlers
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+// TestPostURLJSON_PooledBufferDecodesRepeatedRequests confirms that reusing the pooled
+// bytes.Buffer across requests does not leak data from one decode into the next.
+func TestPostURLJSON_PooledBufferDecodesRepeatedRequests(t *testing.T) {
+	h := newTestHandler(fakeConfig{})
+	router := h.newRouter()
+
+	bodies := []string{
+		`{"url":"https://example.com/one"}`,
+		`{"url":"https://example.com/two"}`,
+		`{"url":"https://example.com/three"}`,
+	}
+	for _, body := range bodies {
+		req := httptest.NewRequest(http.MethodPost, "/api/shorten", strings.NewReader(body))
+		req.Header.Set("Content-Type", "application/json")
+		rec := httptest.NewRecorder()
+
+		router.ServeHTTP(rec, req)
+
+		if rec.Code != http.StatusCreated {
+			t.Fatalf("body %q: got status %d, want %d, response %q", body, rec.Code, http.StatusCreated, rec.Body.String())
+		}
+	}
+}
+
+// BenchmarkPostURLJSON measures per-request allocations with the pooled request-body buffer
+// in place.
+func BenchmarkPostURLJSON(b *testing.B) {
+	h := newTestHandler(fakeConfig{})
+	router := h.newRouter()
+	body := `{"url":"https://example.com/bench"}`
+
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		req := httptest.NewRequest(http.MethodPost, "/api/shorten", strings.NewReader(body))
+		req.Header.Set("Content-Type", "application/json")
+		rec := httptest.NewRecorder()
+		router.ServeHTTP(rec, req)
+		if rec.Code != http.StatusCreated {
+			b.Fatalf("got status %d, want %d", rec.Code, http.StatusCreated)
+		}
+	}
+}
@@ -3,8 +3,44 @@ package handlers
 import (
 	"net/http"
 	"time"
+
+	"github.com/ar4ie13/shortener/internal/requestlog"
+	"github.com/google/uuid"
+	"github.com/rs/zerolog"
 )
 
+// sensitiveQueryParams lists query parameter names whose values must never reach the access
+// log in plaintext, e.g. the password submitted to GET /{id} to unlock a password-protected
+// link
+var sensitiveQueryParams = []string{"password"}
+
+// redactedRequestURI returns r.RequestURI with any sensitiveQueryParams value replaced by
+// "REDACTED", so logging the URL of every incoming request doesn't also log a plaintext
+// password
+func redactedRequestURI(r *http.Request) string {
+	if r.URL.RawQuery == "" {
+		return r.RequestURI
+	}
+
+	q := r.URL.Query()
+	redacted := false
+	for _, key := range sensitiveQueryParams {
+		if q.Has(key) {
+			q.Set(key, "REDACTED")
+			redacted = true
+		}
+	}
+	if !redacted {
+		return r.RequestURI
+	}
+
+	uri := r.URL.Path + "?" + q.Encode()
+	if r.URL.Fragment != "" {
+		uri += "#" + r.URL.Fragment
+	}
+	return uri
+}
+
 // loggingResponseWriter structure for logging size and status code of responses
 type (
 	responseData struct {
@@ -31,12 +67,39 @@ func (r *loggingResponseWriter) WriteHeader(statusCode int) {
 	r.responseData.status = statusCode
 }
 
-// requestLogger is middleware logger using zerolog
+// Flush forwards to the wrapped ResponseWriter's Flush when it supports it, so a streaming
+// handler further down the chain (e.g. getEvents) can still flush through this wrapper.
+func (r *loggingResponseWriter) Flush() {
+	if f, ok := r.ResponseWriter.(http.Flusher); ok {
+		f.Flush()
+	}
+}
+
+// requestID returns the caller-supplied X-Request-Id header, generating a new one if the
+// caller did not send it, so every request can be traced end to end across handler, service,
+// and repository log lines
+func requestID(r *http.Request) string {
+	if id := r.Header.Get("X-Request-Id"); id != "" {
+		return id
+	}
+
+	return uuid.NewString()
+}
+
+// requestLogger is middleware logger using zerolog. It stamps the request with an
+// X-Request-Id (generating one if the caller did not send it) and attaches a logger carrying
+// that ID to the request context via requestlog, so h.reqLog(r) and any ctx-aware service or
+// repository call picks it up for the rest of the request's lifecycle.
 func (h Handler) requestLogger(next http.Handler) http.Handler {
 	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 
 		start := time.Now()
 
+		reqID := requestID(r)
+		w.Header().Set("X-Request-Id", reqID)
+		reqLogger := h.zlog.With().Str("request_id", reqID).Logger()
+		r = r.WithContext(reqLogger.WithContext(r.Context()))
+
 		responseData := &responseData{
 			status: 0,
 			size:   0,
@@ -48,10 +111,10 @@ func (h Handler) requestLogger(next http.Handler) http.Handler {
 
 		next.ServeHTTP(&lw, r)
 
-		h.zlog.
+		reqLogger.
 			Info().
 			Str("method", r.Method).
-			Str("url", r.RequestURI).
+			Str("url", redactedRequestURI(r)).
 			Str("user_agent", r.UserAgent()).
 			Int("size", responseData.size).
 			Dur("elapsed_ms", time.Since(start)).
@@ -59,3 +122,9 @@ func (h Handler) requestLogger(next http.Handler) http.Handler {
 			Msg("incoming request")
 	})
 }
+
+// reqLog returns the request-scoped logger carrying r's request ID, falling back to h.zlog if
+// r was not routed through requestLogger (e.g. in a unit test)
+func (h Handler) reqLog(r *http.Request) *zerolog.Logger {
+	return requestlog.FromContext(r.Context(), h.zlog)
+}
@@ -0,0 +1,69 @@
+package handlers
+
+import (
+	"bytes"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/rs/zerolog"
+)
+
+func TestRedactedRequestURI(t *testing.T) {
+	tests := []struct {
+		name string
+		uri  string
+		want string
+	}{
+		{
+			name: "no query string is unchanged",
+			uri:  "/abc123",
+			want: "/abc123",
+		},
+		{
+			name: "unrelated query params are unchanged",
+			uri:  "/api/user/urls?sort=created_at",
+			want: "/api/user/urls?sort=created_at",
+		},
+		{
+			name: "password value is redacted",
+			uri:  "/abc123?password=s3cret",
+			want: "/abc123?password=REDACTED",
+		},
+		{
+			name: "password alongside other params is redacted in place",
+			uri:  "/abc123?password=s3cret&foo=bar",
+			want: "/abc123?foo=bar&password=REDACTED",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodGet, tt.uri, nil)
+
+			if got := redactedRequestURI(req); got != tt.want {
+				t.Errorf("redactedRequestURI() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestRequestLogger_RedactsPasswordFromAccessLog(t *testing.T) {
+	var buf bytes.Buffer
+	zlog := zerolog.New(&buf)
+	h := NewHandler(fakeService{}, fakeConfig{}, fakeAuth{}, zlog, fakeReadiness{})
+	router := h.newRouter()
+
+	req := httptest.NewRequest(http.MethodGet, "/abc123?password=s3cret", nil)
+	rec := httptest.NewRecorder()
+	router.ServeHTTP(rec, req)
+
+	logged := buf.String()
+	if strings.Contains(logged, "s3cret") {
+		t.Errorf("access log leaked plaintext password, got: %s", logged)
+	}
+	if !strings.Contains(logged, "password=REDACTED") {
+		t.Errorf("access log missing redacted password marker, got: %s", logged)
+	}
+}
@@ -0,0 +1,35 @@
+package handlers
+
+import (
+	"encoding/json"
+	"net/http"
+	"strings"
+)
+
+// wantsJSON reports whether the caller asked for a JSON representation via the Accept header,
+// so a route that otherwise responds with plain text or a raw redirect can return a JSON
+// envelope instead. Disabled entirely when GetEnableJSONNegotiation is false.
+func (h Handler) wantsJSON(r *http.Request) bool {
+	return h.cfg.GetEnableJSONNegotiation() && strings.Contains(r.Header.Get("Accept"), "application/json")
+}
+
+// writeError writes err as the response body, honoring the caller's Accept header: a JSON
+// envelope carrying a stable error code for a client that asked for application/json, or plain
+// text otherwise. It centralizes that choice so every handler reports errors in the same shape.
+func (h Handler) writeError(w http.ResponseWriter, r *http.Request, err error, statusCode int) {
+	if h.wantsJSON(r) {
+		if statusCode == http.StatusNoContent {
+			// 204 No Content cannot carry a body, so a JSON-accepting client would get an
+			// empty response instead of the envelope it asked for; report 404 instead.
+			statusCode = http.StatusNotFound
+		}
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(statusCode)
+		resp := ErrorResp{Code: h.getErrorCode(err), Message: err.Error()}
+		if encErr := json.NewEncoder(w).Encode(resp); encErr != nil {
+			h.reqLog(r).Debug().Msgf("error encoding error response: %v", encErr)
+		}
+		return
+	}
+	http.Error(w, err.Error(), statusCode)
+}
@@ -0,0 +1,198 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/ar4ie13/shortener/internal/myerrors"
+	"github.com/google/uuid"
+	"github.com/rs/zerolog"
+)
+
+// notFoundService wraps fakeService, returning myerrors.ErrNotFound as Service.GetURL does
+// for a slug that was never saved
+type notFoundService struct {
+	fakeService
+}
+
+func (notFoundService) GetURL(_ context.Context, _ uuid.UUID, _ string) (string, error) {
+	return "", myerrors.ErrNotFound
+}
+
+// conflictService wraps fakeService, returning myerrors.ErrURLExist alongside the existing
+// slug as Service.SaveURL does when the submitted URL was already shortened
+type conflictService struct {
+	fakeService
+}
+
+// internalErrorService wraps fakeService, returning an error with no entry in errorCatalog, as
+// an unexpected repository failure would
+type internalErrorService struct {
+	fakeService
+}
+
+func (internalErrorService) GetURL(_ context.Context, _ uuid.UUID, _ string) (string, error) {
+	return "", errors.New("boom")
+}
+
+func (conflictService) SaveURL(_ context.Context, _ uuid.UUID, _ string, _ string, _ string, _ string, _ string, _ bool, _ int) (string, error) {
+	return "abc123", myerrors.ErrURLExist
+}
+
+func TestContentNegotiation_Redirect(t *testing.T) {
+	h := newTestHandler(fakeConfig{})
+	router := h.newRouter()
+
+	req := httptest.NewRequest(http.MethodGet, "/abc123", nil)
+	req.Header.Set("Accept", "application/json")
+	rec := httptest.NewRecorder()
+	router.ServeHTTP(rec, req)
+
+	if rec.Code != http.StatusTemporaryRedirect {
+		t.Fatalf("status = %d, want %d", rec.Code, http.StatusTemporaryRedirect)
+	}
+	if rec.Header().Get("Location") != "https://example.com" {
+		t.Errorf("Location header = %q, want %q", rec.Header().Get("Location"), "https://example.com")
+	}
+	if got := rec.Header().Get("Content-Type"); got != "application/json" {
+		t.Errorf("Content-Type = %q, want application/json", got)
+	}
+
+	var body RedirectResp
+	if err := json.NewDecoder(rec.Body).Decode(&body); err != nil {
+		t.Fatalf("failed to decode response: %v", err)
+	}
+	want := RedirectResp{Location: "https://example.com", Status: http.StatusTemporaryRedirect}
+	if body != want {
+		t.Errorf("body = %+v, want %+v", body, want)
+	}
+}
+
+func TestContentNegotiation_Conflict(t *testing.T) {
+	h := NewHandler(conflictService{}, fakeConfig{}, fakeAuth{}, zerolog.Nop(), fakeReadiness{})
+	router := h.newRouter()
+
+	req := httptest.NewRequest(http.MethodPost, "/", strings.NewReader("https://example.com"))
+	req.Header.Set("Accept", "application/json")
+	rec := httptest.NewRecorder()
+	router.ServeHTTP(rec, req)
+
+	if rec.Code != http.StatusConflict {
+		t.Fatalf("status = %d, want %d", rec.Code, http.StatusConflict)
+	}
+	if got := rec.Header().Get("Content-Type"); got != "application/json" {
+		t.Errorf("Content-Type = %q, want application/json", got)
+	}
+
+	var body ShortURLResp
+	if err := json.NewDecoder(rec.Body).Decode(&body); err != nil {
+		t.Fatalf("failed to decode response: %v", err)
+	}
+	want := ShortURLResp{ShortURL: "http://localhost:8080/abc123"}
+	if body != want {
+		t.Errorf("body = %+v, want %+v", body, want)
+	}
+}
+
+func TestContentNegotiation_NotFound(t *testing.T) {
+	h := NewHandler(notFoundService{}, fakeConfig{}, fakeAuth{}, zerolog.Nop(), fakeReadiness{})
+	router := h.newRouter()
+
+	req := httptest.NewRequest(http.MethodGet, "/missing", nil)
+	req.Header.Set("Accept", "application/json")
+	rec := httptest.NewRecorder()
+	router.ServeHTTP(rec, req)
+
+	// the underlying error maps to 204 No Content, which cannot carry a body; a JSON-accepting
+	// client gets 404 instead so it actually receives the envelope
+	if rec.Code != http.StatusNotFound {
+		t.Fatalf("status = %d, want %d", rec.Code, http.StatusNotFound)
+	}
+	if got := rec.Header().Get("Content-Type"); got != "application/json" {
+		t.Errorf("Content-Type = %q, want application/json", got)
+	}
+
+	var body ErrorResp
+	if err := json.NewDecoder(rec.Body).Decode(&body); err != nil {
+		t.Fatalf("failed to decode response: %v", err)
+	}
+	if body.Code != "not_found" {
+		t.Errorf("body.Code = %q, want %q", body.Code, "not_found")
+	}
+	if body.Message == "" {
+		t.Errorf("body.Message is empty, want an error message")
+	}
+}
+
+func TestContentNegotiation_InternalError(t *testing.T) {
+	h := NewHandler(internalErrorService{}, fakeConfig{}, fakeAuth{}, zerolog.Nop(), fakeReadiness{})
+	router := h.newRouter()
+
+	req := httptest.NewRequest(http.MethodGet, "/missing", nil)
+	req.Header.Set("Accept", "application/json")
+	rec := httptest.NewRecorder()
+	router.ServeHTTP(rec, req)
+
+	if rec.Code != http.StatusInternalServerError {
+		t.Fatalf("status = %d, want %d", rec.Code, http.StatusInternalServerError)
+	}
+
+	var body ErrorResp
+	if err := json.NewDecoder(rec.Body).Decode(&body); err != nil {
+		t.Fatalf("failed to decode response: %v", err)
+	}
+	if body.Code != defaultErrorCode {
+		t.Errorf("body.Code = %q, want %q", body.Code, defaultErrorCode)
+	}
+	if body.Message == "" {
+		t.Errorf("body.Message is empty, want an error message")
+	}
+}
+
+func TestContentNegotiation_Forbidden(t *testing.T) {
+	h := NewHandler(statsService{}, fakeConfig{trustedSubnet: "192.168.1.0/24"}, fakeAuth{}, zerolog.Nop(), fakeReadiness{})
+	router := h.newRouter()
+
+	req := httptest.NewRequest(http.MethodGet, "/api/internal/stats", nil)
+	req.RemoteAddr = "10.0.0.5:12345"
+	req.Header.Set("Accept", "application/json")
+	rec := httptest.NewRecorder()
+	router.ServeHTTP(rec, req)
+
+	if rec.Code != http.StatusForbidden {
+		t.Fatalf("status = %d, want %d", rec.Code, http.StatusForbidden)
+	}
+	if got := rec.Header().Get("Content-Type"); got != "application/json" {
+		t.Errorf("Content-Type = %q, want application/json", got)
+	}
+
+	var body ErrorResp
+	if err := json.NewDecoder(rec.Body).Decode(&body); err != nil {
+		t.Fatalf("failed to decode response: %v", err)
+	}
+	if body.Code != "untrusted_subnet" {
+		t.Errorf("body.Code = %q, want %q", body.Code, "untrusted_subnet")
+	}
+}
+
+func TestContentNegotiation_DisabledFallsBackToPlainText(t *testing.T) {
+	h := newTestHandler(fakeConfig{disableJSONNegotiation: true})
+	router := h.newRouter()
+
+	req := httptest.NewRequest(http.MethodGet, "/abc123", nil)
+	req.Header.Set("Accept", "application/json")
+	rec := httptest.NewRecorder()
+	router.ServeHTTP(rec, req)
+
+	if rec.Code != http.StatusTemporaryRedirect {
+		t.Fatalf("status = %d, want %d", rec.Code, http.StatusTemporaryRedirect)
+	}
+	if got := rec.Header().Get("Content-Type"); got == "application/json" {
+		t.Errorf("Content-Type = %q, want negotiation disabled to leave it unset", got)
+	}
+}
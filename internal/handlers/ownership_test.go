@@ -0,0 +1,61 @@
+package handlers
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/ar4ie13/shortener/internal/myerrors"
+	"github.com/google/uuid"
+	"github.com/rs/zerolog"
+)
+
+// cookieUUIDAuth wraps fakeAuth, treating the raw cookie value as the user's UUID string
+// instead of a signed JWT, so tests can pin the identity behind a request
+type cookieUUIDAuth struct {
+	fakeAuth
+}
+
+func (cookieUUIDAuth) ValidateUserUUID(cookie string) (uuid.UUID, bool, error) {
+	return uuid.MustParse(cookie), false, nil
+}
+
+// ownerOnlyService wraps fakeService, returning myerrors.ErrNotFound for any userUUID other
+// than owner, the way Service.GetURL behaves with private links enabled
+type ownerOnlyService struct {
+	fakeService
+	owner uuid.UUID
+}
+
+func (s ownerOnlyService) GetURL(_ context.Context, userUUID uuid.UUID, _ string) (string, error) {
+	if userUUID != s.owner {
+		return "", myerrors.ErrNotFound
+	}
+	return "https://example.com", nil
+}
+
+// TestGetURL_OwnershipIsWiredThroughTheRouter confirms the userUUID the router extracts from
+// the request's cookie reaches Service.GetURL unchanged, end to end.
+func TestGetURL_OwnershipIsWiredThroughTheRouter(t *testing.T) {
+	owner := uuid.New()
+	other := uuid.New()
+	h := NewHandler(ownerOnlyService{owner: owner}, fakeConfig{}, cookieUUIDAuth{}, zerolog.Nop(), fakeReadiness{})
+	router := h.newRouter()
+
+	req := httptest.NewRequest(http.MethodGet, "/abc123", nil)
+	req.AddCookie(&http.Cookie{Name: "user_id", Value: owner.String()})
+	rec := httptest.NewRecorder()
+	router.ServeHTTP(rec, req)
+	if rec.Code != http.StatusTemporaryRedirect {
+		t.Errorf("owner request status = %d, want %d", rec.Code, http.StatusTemporaryRedirect)
+	}
+
+	req = httptest.NewRequest(http.MethodGet, "/abc123", nil)
+	req.AddCookie(&http.Cookie{Name: "user_id", Value: other.String()})
+	rec = httptest.NewRecorder()
+	router.ServeHTTP(rec, req)
+	if rec.Code != http.StatusNoContent {
+		t.Errorf("non-owner request status = %d, want %d", rec.Code, http.StatusNoContent)
+	}
+}
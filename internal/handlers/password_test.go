@@ -0,0 +1,75 @@
+package handlers
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/rs/zerolog"
+)
+
+// protectedService wraps fakeService, simulating a slug guarded by the given password
+type protectedService struct {
+	fakeService
+	password string
+}
+
+func (s protectedService) VerifyPassword(_ context.Context, _ string, password string) (bool, error) {
+	return password == s.password, nil
+}
+
+func TestGetURL_PasswordProtected_CorrectPasswordRedirects(t *testing.T) {
+	h := NewHandler(protectedService{password: "secret"}, fakeConfig{}, fakeAuth{}, zerolog.Nop(), fakeReadiness{})
+	router := h.newRouter()
+
+	req := httptest.NewRequest(http.MethodGet, "/abc123?password=secret", nil)
+	rec := httptest.NewRecorder()
+	router.ServeHTTP(rec, req)
+
+	if rec.Code != http.StatusTemporaryRedirect {
+		t.Fatalf("got status %d, want %d", rec.Code, http.StatusTemporaryRedirect)
+	}
+	if rec.Header().Get("Location") != "https://example.com" {
+		t.Errorf("Location header = %q, want %q", rec.Header().Get("Location"), "https://example.com")
+	}
+}
+
+func TestGetURL_PasswordProtected_WrongPasswordIsUnauthorized(t *testing.T) {
+	h := NewHandler(protectedService{password: "secret"}, fakeConfig{}, fakeAuth{}, zerolog.Nop(), fakeReadiness{})
+	router := h.newRouter()
+
+	req := httptest.NewRequest(http.MethodGet, "/abc123?password=wrong", nil)
+	rec := httptest.NewRecorder()
+	router.ServeHTTP(rec, req)
+
+	if rec.Code != http.StatusUnauthorized {
+		t.Fatalf("got status %d, want %d", rec.Code, http.StatusUnauthorized)
+	}
+}
+
+func TestGetURL_PasswordProtected_MissingPasswordIsUnauthorized(t *testing.T) {
+	h := NewHandler(protectedService{password: "secret"}, fakeConfig{}, fakeAuth{}, zerolog.Nop(), fakeReadiness{})
+	router := h.newRouter()
+
+	req := httptest.NewRequest(http.MethodGet, "/abc123", nil)
+	rec := httptest.NewRecorder()
+	router.ServeHTTP(rec, req)
+
+	if rec.Code != http.StatusUnauthorized {
+		t.Fatalf("got status %d, want %d", rec.Code, http.StatusUnauthorized)
+	}
+}
+
+func TestGetURL_NotPasswordProtected_RedirectsWithoutPassword(t *testing.T) {
+	h := newTestHandler(fakeConfig{})
+	router := h.newRouter()
+
+	req := httptest.NewRequest(http.MethodGet, "/abc123", nil)
+	rec := httptest.NewRecorder()
+	router.ServeHTTP(rec, req)
+
+	if rec.Code != http.StatusTemporaryRedirect {
+		t.Fatalf("got status %d, want %d", rec.Code, http.StatusTemporaryRedirect)
+	}
+}
@@ -0,0 +1,46 @@
+package handlers
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/rs/zerolog"
+)
+
+// pingErrService wraps fakeService, simulating an unreachable backend
+type pingErrService struct {
+	fakeService
+}
+
+func (pingErrService) Ping(_ context.Context) error {
+	return errors.New("connection refused")
+}
+
+func TestPing_HealthyBackend(t *testing.T) {
+	h := NewHandler(fakeService{}, fakeConfig{}, fakeAuth{}, zerolog.Nop(), fakeReadiness{})
+	router := h.newRouter()
+
+	req := httptest.NewRequest(http.MethodGet, "/ping", nil)
+	rec := httptest.NewRecorder()
+	router.ServeHTTP(rec, req)
+
+	if rec.Code != http.StatusOK {
+		t.Errorf("got status %d, want %d", rec.Code, http.StatusOK)
+	}
+}
+
+func TestPing_UnreachableBackend(t *testing.T) {
+	h := NewHandler(pingErrService{}, fakeConfig{}, fakeAuth{}, zerolog.Nop(), fakeReadiness{})
+	router := h.newRouter()
+
+	req := httptest.NewRequest(http.MethodGet, "/ping", nil)
+	rec := httptest.NewRecorder()
+	router.ServeHTTP(rec, req)
+
+	if rec.Code != http.StatusInternalServerError {
+		t.Errorf("got status %d, want %d", rec.Code, http.StatusInternalServerError)
+	}
+}
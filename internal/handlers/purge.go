@@ -0,0 +1,64 @@
+package handlers
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"time"
+)
+
+// errInvalidOlderThan is returned when the older_than field of the admin purge request body
+// cannot be parsed as a duration
+var errInvalidOlderThan = errors.New("invalid older_than duration")
+
+// purgeDeletedReq is the request body for the admin purge-deleted endpoint. OlderThan, if
+// set, overrides the configured purge retention window for this run.
+type purgeDeletedReq struct {
+	OlderThan string `json:"older_than"`
+}
+
+// purgeDeletedResp reports how many soft-deleted rows an admin purge run removed
+type purgeDeletedResp struct {
+	Purged int `json:"purged"`
+}
+
+// purgeDeleted permanently removes soft-deleted rows older than the configured (or
+// request-supplied) retention window, in batches with a pause between them so a large
+// purge does not lock the table for its full duration, and is cancellable via the request
+// context. Restricted to the trusted subnet, like setReadOnly.
+func (h Handler) purgeDeleted(w http.ResponseWriter, r *http.Request) {
+	if !h.isTrustedSubnet(r) {
+		h.writeError(w, r, errUntrustedSubnet, http.StatusForbidden)
+		return
+	}
+
+	olderThan := h.cfg.GetPurgeRetention()
+	if r.ContentLength != 0 {
+		var req purgeDeletedReq
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			h.writeError(w, r, err, http.StatusBadRequest)
+			return
+		}
+		if req.OlderThan != "" {
+			d, err := time.ParseDuration(req.OlderThan)
+			if err != nil {
+				h.writeError(w, r, fmt.Errorf("%w: %v", errInvalidOlderThan, err), http.StatusBadRequest)
+				return
+			}
+			olderThan = d
+		}
+	}
+
+	purged, err := h.service.PurgeDeleted(r.Context(), time.Now().Add(-olderThan), h.cfg.GetPurgeBatchSize(), h.cfg.GetPurgeBatchPause())
+	if err != nil {
+		h.writeError(w, r, err, http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	if err = json.NewEncoder(w).Encode(purgeDeletedResp{Purged: purged}); err != nil {
+		h.reqLog(r).Debug().Msgf("error encoding response: %v", err)
+	}
+}
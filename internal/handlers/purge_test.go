@@ -0,0 +1,117 @@
+package handlers
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/rs/zerolog"
+)
+
+// purgeCountingService wraps fakeService, recording the before cutoff it was called with and
+// returning a fixed purged count
+type purgeCountingService struct {
+	fakeService
+	gotBefore time.Time
+	purged    int
+}
+
+func (s *purgeCountingService) PurgeDeleted(_ context.Context, before time.Time, _ int, _ time.Duration) (int, error) {
+	s.gotBefore = before
+	return s.purged, nil
+}
+
+// purgeErrService wraps fakeService, simulating a repository failure during purge
+type purgeErrService struct {
+	fakeService
+}
+
+func (purgeErrService) PurgeDeleted(_ context.Context, _ time.Time, _ int, _ time.Duration) (int, error) {
+	return 0, errors.New("purge failed")
+}
+
+func TestPurgeDeleted(t *testing.T) {
+	tests := []struct {
+		name          string
+		trustedSubnet string
+		remoteAddr    string
+		body          string
+		wantStatus    int
+		wantBody      string
+	}{
+		{
+			name:          "trusted caller triggers a purge",
+			trustedSubnet: "192.168.1.0/24",
+			remoteAddr:    "192.168.1.10:12345",
+			body:          "",
+			wantStatus:    http.StatusOK,
+			wantBody:      `{"purged":3}`,
+		},
+		{
+			name:          "trusted caller can override the retention window",
+			trustedSubnet: "192.168.1.0/24",
+			remoteAddr:    "192.168.1.10:12345",
+			body:          `{"older_than":"1h"}`,
+			wantStatus:    http.StatusOK,
+			wantBody:      `{"purged":3}`,
+		},
+		{
+			name:          "untrusted caller is forbidden",
+			trustedSubnet: "192.168.1.0/24",
+			remoteAddr:    "10.0.0.5:12345",
+			body:          "",
+			wantStatus:    http.StatusForbidden,
+		},
+		{
+			name:          "malformed older_than is a bad request",
+			trustedSubnet: "192.168.1.0/24",
+			remoteAddr:    "192.168.1.10:12345",
+			body:          `{"older_than":"not-a-duration"}`,
+			wantStatus:    http.StatusBadRequest,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			svc := &purgeCountingService{purged: 3}
+			h := NewHandler(svc, fakeConfig{trustedSubnet: tt.trustedSubnet}, fakeAuth{}, zerolog.Nop(), fakeReadiness{})
+
+			var body *strings.Reader
+			if tt.body != "" {
+				body = strings.NewReader(tt.body)
+			} else {
+				body = strings.NewReader("")
+			}
+			req := httptest.NewRequest(http.MethodPost, "/api/internal/purge-deleted", body)
+			req.RemoteAddr = tt.remoteAddr
+			rec := httptest.NewRecorder()
+
+			h.purgeDeleted(rec, req)
+
+			if rec.Code != tt.wantStatus {
+				t.Errorf("purgeDeleted() status = %d, want %d", rec.Code, tt.wantStatus)
+			}
+			if tt.wantBody != "" && strings.TrimSpace(rec.Body.String()) != tt.wantBody {
+				t.Errorf("purgeDeleted() body = %q, want %q", rec.Body.String(), tt.wantBody)
+			}
+		})
+	}
+}
+
+func TestPurgeDeleted_RepositoryError(t *testing.T) {
+	h := NewHandler(purgeErrService{}, fakeConfig{trustedSubnet: "192.168.1.0/24"}, fakeAuth{}, zerolog.Nop(), fakeReadiness{})
+
+	req := httptest.NewRequest(http.MethodPost, "/api/internal/purge-deleted", strings.NewReader(""))
+	req.RemoteAddr = "192.168.1.10:12345"
+	rec := httptest.NewRecorder()
+
+	h.purgeDeleted(rec, req)
+
+	if rec.Code != http.StatusInternalServerError {
+		t.Errorf("purgeDeleted() status = %d, want %d", rec.Code, http.StatusInternalServerError)
+	}
+}
@@ -0,0 +1,75 @@
+package handlers
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestRedirectRateLimitMiddleware(t *testing.T) {
+	h := newTestHandler(fakeConfig{redirectRateLimitRPS: 1})
+
+	next := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	})
+	mw := h.redirectRateLimitMiddleware(next)
+
+	req := httptest.NewRequest(http.MethodGet, "/abc123", nil)
+	rec := httptest.NewRecorder()
+	mw.ServeHTTP(rec, req)
+	if rec.Code != http.StatusOK {
+		t.Fatalf("first redirect: got status %d, want %d", rec.Code, http.StatusOK)
+	}
+
+	rec = httptest.NewRecorder()
+	mw.ServeHTTP(rec, req)
+	if rec.Code != http.StatusTooManyRequests {
+		t.Fatalf("second redirect within the same second: got status %d, want %d", rec.Code, http.StatusTooManyRequests)
+	}
+}
+
+func TestRedirectRateLimitMiddleware_UnlimitedWhenUnset(t *testing.T) {
+	h := newTestHandler(fakeConfig{redirectRateLimitRPS: 0})
+
+	next := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	})
+	mw := h.redirectRateLimitMiddleware(next)
+
+	req := httptest.NewRequest(http.MethodGet, "/abc123", nil)
+	for i := 0; i < 100; i++ {
+		rec := httptest.NewRecorder()
+		mw.ServeHTTP(rec, req)
+		if rec.Code != http.StatusOK {
+			t.Fatalf("redirect %d with no configured limit: got status %d, want %d", i, rec.Code, http.StatusOK)
+		}
+	}
+}
+
+func TestRedirectRateLimit_DoesNotThrottleCreation(t *testing.T) {
+	h := newTestHandler(fakeConfig{redirectRateLimitRPS: 1})
+	router := h.newRouter()
+
+	getReq := httptest.NewRequest(http.MethodGet, "/abc123", nil)
+	rec := httptest.NewRecorder()
+	router.ServeHTTP(rec, getReq)
+	if rec.Code != http.StatusTemporaryRedirect {
+		t.Fatalf("first redirect: got status %d, want %d", rec.Code, http.StatusTemporaryRedirect)
+	}
+
+	// Exhaust the redirect limiter.
+	rec = httptest.NewRecorder()
+	router.ServeHTTP(rec, getReq)
+	if rec.Code != http.StatusTooManyRequests {
+		t.Fatalf("second redirect within the same second: got status %d, want %d", rec.Code, http.StatusTooManyRequests)
+	}
+
+	// Creation requests share no state with the redirect limiter and must stay unaffected.
+	postReq := httptest.NewRequest(http.MethodPost, "/", strings.NewReader("https://example.com"))
+	rec = httptest.NewRecorder()
+	router.ServeHTTP(rec, postReq)
+	if rec.Code != http.StatusCreated {
+		t.Fatalf("creation request after exhausting the redirect limiter: got status %d, want %d", rec.Code, http.StatusCreated)
+	}
+}
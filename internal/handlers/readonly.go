@@ -0,0 +1,102 @@
+package handlers
+
+import (
+	"encoding/json"
+	"errors"
+	"net"
+	"net/http"
+	"strings"
+)
+
+// writeOnlyErrMsg is returned to clients when a write request hits a read-only instance
+const writeOnlyErrMsg = "service is in read-only mode, writes are temporarily disabled"
+
+// errReadOnly wraps writeOnlyErrMsg so readOnlyMiddleware can report it through writeError
+var errReadOnly = errors.New(writeOnlyErrMsg)
+
+// errUntrustedSubnet is returned by an admin/diagnostic endpoint when the caller is outside
+// the configured trusted subnet
+var errUntrustedSubnet = errors.New("forbidden: caller is not in the trusted subnet")
+
+// setReadOnlyReq is the request body for the admin read-only toggle endpoint
+type setReadOnlyReq struct {
+	Enabled bool `json:"enabled"`
+}
+
+// isWriteRequest reports whether the request targets one of the mutating endpoints
+func isWriteRequest(r *http.Request) bool {
+	switch r.Method {
+	case http.MethodPost, http.MethodDelete, http.MethodPut, http.MethodPatch:
+		return true
+	default:
+		return false
+	}
+}
+
+// readOnlyMiddleware rejects write requests with 503 while the service is in read-only mode
+func (h Handler) readOnlyMiddleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if h.readOnly.Load() && isWriteRequest(r) && !strings.HasPrefix(r.URL.Path, "/api/internal") {
+			h.writeError(w, r, errReadOnly, http.StatusServiceUnavailable)
+			return
+		}
+		next.ServeHTTP(w, r)
+	})
+}
+
+// isTrustedSubnet reports whether the request originates from the configured trusted subnet
+func (h Handler) isTrustedSubnet(r *http.Request) bool {
+	subnet := h.cfg.GetTrustedSubnet()
+	if subnet == "" {
+		return false
+	}
+
+	_, cidr, err := net.ParseCIDR(subnet)
+	if err != nil {
+		h.reqLog(r).Error().Err(err).Msg("cannot parse trusted subnet")
+		return false
+	}
+
+	// use RemoteAddr, not clientIP: the trusted-subnet check gates irreversible admin actions
+	// (setReadOnly, purgeDeleted), and clientIP's X-Real-IP is client-supplied, so honoring it
+	// here would let any external caller claim to be inside the trusted subnet
+	host, _, err := net.SplitHostPort(r.RemoteAddr)
+	if err != nil {
+		host = r.RemoteAddr
+	}
+
+	ip := net.ParseIP(host)
+	return ip != nil && cidr.Contains(ip)
+}
+
+// clientIP extracts the client IP from the X-Real-IP header or RemoteAddr. It is suitable for
+// best-effort per-client rate limiting but must not be used for authorization: the header is
+// client-supplied and trivially spoofable.
+func clientIP(r *http.Request) string {
+	if realIP := r.Header.Get("X-Real-IP"); realIP != "" {
+		return realIP
+	}
+
+	host, _, err := net.SplitHostPort(r.RemoteAddr)
+	if err != nil {
+		return r.RemoteAddr
+	}
+	return host
+}
+
+// setReadOnly toggles the read-only flag at runtime; restricted to the trusted subnet
+func (h Handler) setReadOnly(w http.ResponseWriter, r *http.Request) {
+	if !h.isTrustedSubnet(r) {
+		h.writeError(w, r, errUntrustedSubnet, http.StatusForbidden)
+		return
+	}
+
+	var req setReadOnlyReq
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		h.writeError(w, r, err, http.StatusBadRequest)
+		return
+	}
+
+	h.readOnly.Store(req.Enabled)
+	w.WriteHeader(http.StatusOK)
+}
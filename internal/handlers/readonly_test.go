@@ -0,0 +1,365 @@
+package handlers
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/ar4ie13/shortener/internal/metrics"
+	"github.com/ar4ie13/shortener/internal/model"
+	"github.com/google/uuid"
+	"github.com/rs/zerolog"
+)
+
+// fakeService implements Service for handler tests
+type fakeService struct{}
+
+func (fakeService) GetURL(_ context.Context, _ uuid.UUID, _ string) (string, error) {
+	return "https://example.com", nil
+}
+func (fakeService) InspectURL(_ context.Context, _ uuid.UUID, _ string) (string, bool, time.Time, error) {
+	return "https://example.com", false, time.Time{}, nil
+}
+func (fakeService) InspectURLs(_ context.Context, _ uuid.UUID, shortURLs []string) ([]model.URLExpansion, error) {
+	result := make([]model.URLExpansion, len(shortURLs))
+	for i, shortURL := range shortURLs {
+		result[i] = model.URLExpansion{ShortURL: shortURL, OriginalURL: "https://example.com"}
+	}
+	return result, nil
+}
+func (fakeService) SaveURL(_ context.Context, _ uuid.UUID, _ string, _ string, _ string, _ string, _ string, _ bool, _ int) (string, error) {
+	return "abc123", nil
+}
+func (fakeService) SaveBatch(_ context.Context, _ uuid.UUID, batch []model.URL, _ string, _ string, _ bool, _ bool) ([]model.URL, error) {
+	return batch, nil
+}
+func (fakeService) GetUserShortURLs(_ context.Context, _ uuid.UUID, _ string, _ int, _ int) ([]model.URL, error) {
+	return nil, nil
+}
+func (fakeService) UpdateURL(_ context.Context, _ uuid.UUID, _ string, _ string) error {
+	return nil
+}
+func (fakeService) SendShortURLForDelete(_ context.Context, _ uuid.UUID, _ []string) (uuid.UUID, error) {
+	return uuid.New(), nil
+}
+func (fakeService) DeleteJobStatus(_ context.Context, _ uuid.UUID, _ uuid.UUID) (model.DeleteJob, error) {
+	return model.DeleteJob{}, nil
+}
+func (fakeService) PurgeDeleted(_ context.Context, _ time.Time, _ int, _ time.Duration) (int, error) {
+	return 0, nil
+}
+func (fakeService) Ping(_ context.Context) error {
+	return nil
+}
+func (fakeService) VerifyPassword(_ context.Context, _ string, _ string) (bool, error) {
+	return true, nil
+}
+func (fakeService) GetRedirectStatus(_ context.Context, _ string) (int, error) {
+	return 0, nil
+}
+func (fakeService) TopHosts(_ context.Context, _ int) ([]model.HostCount, error) {
+	return nil, nil
+}
+func (fakeService) DedupStats() metrics.DedupSnapshot {
+	return metrics.DedupSnapshot{}
+}
+func (fakeService) CacheStats() metrics.CacheSnapshot {
+	return metrics.CacheSnapshot{}
+}
+func (fakeService) Stats(_ context.Context) (model.Stats, error) {
+	return model.Stats{}, nil
+}
+func (fakeService) CreateAPIKey(_ context.Context, _ uuid.UUID, _ string) error {
+	return nil
+}
+func (fakeService) ResolveAPIKey(_ context.Context, _ string) (uuid.UUID, error) {
+	return uuid.New(), nil
+}
+func (fakeService) RevokeAPIKey(_ context.Context, _ uuid.UUID, _ string) error {
+	return nil
+}
+func (fakeService) PendingDeletes() int {
+	return 0
+}
+func (fakeService) Subscribe(_ uuid.UUID) (<-chan model.Event, func()) {
+	return make(chan model.Event), func() {}
+}
+
+// fakeAuth implements Auth for handler tests
+type fakeAuth struct{}
+
+func (fakeAuth) GenerateUserUUID() uuid.UUID { return uuid.New() }
+func (fakeAuth) BuildJWTString(_ uuid.UUID, _ bool) (string, error) {
+	return "token", nil
+}
+func (fakeAuth) ValidateUserUUID(_ string) (uuid.UUID, bool, error) {
+	return uuid.New(), false, nil
+}
+func (fakeAuth) GenerateAPIKey() (string, error) {
+	return "sk_test", nil
+}
+func (fakeAuth) HashAPIKey(key string) string {
+	return key
+}
+
+// fakeConfig implements Config for handler tests
+type fakeConfig struct {
+	readOnly                    bool
+	trustedSubnet               string
+	jsonDeleteResponse          bool
+	trackCreationSrc            bool
+	avoidRecentSlugs            bool
+	redirectRateLimitRPS        float64
+	creationRateLimitPerUserRPS float64
+	creationRateLimitPerIPRPS   float64
+	cookieDomain                string
+	cookieMaxAge                time.Duration
+	disableBatch                bool
+	disableUserURLs             bool
+	disableDelete               bool
+	redirectBody                bool
+	redirectStatus              int
+	rootBehavior                string
+	rootRedirectURL             string
+	logBodies                   bool
+	purgeBatchSize              int
+	purgeBatchPause             time.Duration
+	purgeRetention              time.Duration
+	disableJSONNegotiation      bool
+	disableImportStream         bool
+	importStreamMaxLine         int
+	importStreamRateLimit       float64
+	disableEvents               bool
+	maxExpandBatch              int
+	anonymousLinkTTL            time.Duration
+	forceShortURLScheme         string
+	maxResponseURLs             int
+	adminAddress                string
+	shutdownTimeout             time.Duration
+	localAddr                   string
+	enableHTTPS                 bool
+	tlsCertPath                 string
+	tlsKeyPath                  string
+	deleteQueueLimit            int
+}
+
+func (c fakeConfig) GetLocalServerAddr() string {
+	if c.localAddr != "" {
+		return c.localAddr
+	}
+	return "localhost:8080"
+}
+func (fakeConfig) GetShortURLTemplate() string    { return "http://localhost:8080" }
+func (fakeConfig) GetLogLevel() zerolog.Level     { return zerolog.InfoLevel }
+func (c fakeConfig) GetTrackCreationSource() bool { return c.trackCreationSrc }
+func (c fakeConfig) GetReadOnly() bool            { return c.readOnly }
+func (c fakeConfig) GetTrustedSubnet() string     { return c.trustedSubnet }
+func (c fakeConfig) GetJSONDeleteResponse() bool  { return c.jsonDeleteResponse }
+func (c fakeConfig) GetAvoidRecentSlugs() bool    { return c.avoidRecentSlugs }
+func (c fakeConfig) GetRedirectRateLimitRPS() float64 {
+	return c.redirectRateLimitRPS
+}
+func (c fakeConfig) GetCreationRateLimitPerUserRPS() float64 { return c.creationRateLimitPerUserRPS }
+func (c fakeConfig) GetCreationRateLimitPerIPRPS() float64   { return c.creationRateLimitPerIPRPS }
+func (c fakeConfig) GetCookieDomain() string                 { return c.cookieDomain }
+func (c fakeConfig) GetCookieMaxAge() time.Duration          { return c.cookieMaxAge }
+func (c fakeConfig) GetEnableBatch() bool                    { return !c.disableBatch }
+func (c fakeConfig) GetEnableUserURLs() bool                 { return !c.disableUserURLs }
+func (c fakeConfig) GetEnableDelete() bool                   { return !c.disableDelete }
+func (c fakeConfig) GetRedirectBody() bool                   { return c.redirectBody }
+func (c fakeConfig) GetRedirectStatus() int                  { return c.redirectStatus }
+func (c fakeConfig) GetRootBehavior() string                 { return c.rootBehavior }
+func (c fakeConfig) GetRootRedirectURL() string              { return c.rootRedirectURL }
+func (c fakeConfig) GetLogBodies() bool                      { return c.logBodies }
+func (c fakeConfig) GetPurgeBatchSize() int                  { return c.purgeBatchSize }
+func (c fakeConfig) GetPurgeBatchPause() time.Duration {
+	return c.purgeBatchPause
+}
+func (c fakeConfig) GetPurgeRetention() time.Duration {
+	return c.purgeRetention
+}
+func (c fakeConfig) GetEnableJSONNegotiation() bool { return !c.disableJSONNegotiation }
+func (c fakeConfig) GetEnableImportStream() bool    { return !c.disableImportStream }
+func (c fakeConfig) GetImportStreamMaxLine() int {
+	if c.importStreamMaxLine == 0 {
+		return 65536
+	}
+	return c.importStreamMaxLine
+}
+func (c fakeConfig) GetImportStreamRateLimit() float64  { return c.importStreamRateLimit }
+func (c fakeConfig) GetMaxExpandBatch() int             { return c.maxExpandBatch }
+func (c fakeConfig) GetAnonymousLinkTTL() time.Duration { return c.anonymousLinkTTL }
+func (c fakeConfig) GetForceShortURLScheme() string     { return c.forceShortURLScheme }
+func (c fakeConfig) GetMaxResponseURLs() int            { return c.maxResponseURLs }
+func (c fakeConfig) GetAdminAddress() string            { return c.adminAddress }
+func (c fakeConfig) GetShutdownTimeout() time.Duration  { return c.shutdownTimeout }
+func (c fakeConfig) GetEnableHTTPS() bool               { return c.enableHTTPS }
+func (c fakeConfig) GetTLSCertPath() string             { return c.tlsCertPath }
+func (c fakeConfig) GetTLSKeyPath() string              { return c.tlsKeyPath }
+func (c fakeConfig) GetDeleteQueueLimit() int           { return c.deleteQueueLimit }
+func (c fakeConfig) GetEnableEvents() bool              { return !c.disableEvents }
+
+// fakeReadiness implements Readiness for handler tests; a nil ready reports ready by default
+// so tests unrelated to startup readiness are unaffected
+type fakeReadiness struct {
+	ready *atomic.Bool
+}
+
+func (f fakeReadiness) Ready() bool {
+	if f.ready == nil {
+		return true
+	}
+	return f.ready.Load()
+}
+
+func newTestHandler(cfg fakeConfig) *Handler {
+	return NewHandler(fakeService{}, cfg, fakeAuth{}, zerolog.Nop(), fakeReadiness{})
+}
+
+func TestReadOnlyMiddleware(t *testing.T) {
+	tests := []struct {
+		name       string
+		readOnly   bool
+		method     string
+		path       string
+		wantStatus int
+	}{
+		{
+			name:       "write blocked in read-only mode",
+			readOnly:   true,
+			method:     http.MethodPost,
+			path:       "/",
+			wantStatus: http.StatusServiceUnavailable,
+		},
+		{
+			name:       "delete blocked in read-only mode",
+			readOnly:   true,
+			method:     http.MethodDelete,
+			path:       "/api/user/urls",
+			wantStatus: http.StatusServiceUnavailable,
+		},
+		{
+			name:       "read allowed in read-only mode",
+			readOnly:   true,
+			method:     http.MethodGet,
+			path:       "/abc123",
+			wantStatus: http.StatusOK,
+		},
+		{
+			name:       "write allowed when not read-only",
+			readOnly:   false,
+			method:     http.MethodPost,
+			path:       "/",
+			wantStatus: http.StatusOK,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			h := newTestHandler(fakeConfig{readOnly: tt.readOnly})
+
+			next := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				w.WriteHeader(http.StatusOK)
+			})
+
+			req := httptest.NewRequest(tt.method, tt.path, nil)
+			rec := httptest.NewRecorder()
+
+			h.readOnlyMiddleware(next).ServeHTTP(rec, req)
+
+			if rec.Code != tt.wantStatus {
+				t.Errorf("readOnlyMiddleware() status = %d, want %d", rec.Code, tt.wantStatus)
+			}
+		})
+	}
+}
+
+func TestSetReadOnly(t *testing.T) {
+	tests := []struct {
+		name           string
+		trustedSubnet  string
+		remoteAddr     string
+		body           string
+		wantStatus     int
+		wantReadOnlyAt bool
+	}{
+		{
+			name:           "trusted caller flips the flag on",
+			trustedSubnet:  "192.168.1.0/24",
+			remoteAddr:     "192.168.1.10:12345",
+			body:           `{"enabled":true}`,
+			wantStatus:     http.StatusOK,
+			wantReadOnlyAt: true,
+		},
+		{
+			name:          "untrusted caller is forbidden",
+			trustedSubnet: "192.168.1.0/24",
+			remoteAddr:    "10.0.0.5:12345",
+			body:          `{"enabled":true}`,
+			wantStatus:    http.StatusForbidden,
+		},
+		{
+			name:          "no trusted subnet configured is forbidden",
+			trustedSubnet: "",
+			remoteAddr:    "192.168.1.10:12345",
+			body:          `{"enabled":true}`,
+			wantStatus:    http.StatusForbidden,
+		},
+		{
+			name:           "trusted IPv6 caller flips the flag on",
+			trustedSubnet:  "2001:db8::/32",
+			remoteAddr:     "[2001:db8::1]:12345",
+			body:           `{"enabled":true}`,
+			wantStatus:     http.StatusOK,
+			wantReadOnlyAt: true,
+		},
+		{
+			name:          "untrusted IPv6 caller is forbidden",
+			trustedSubnet: "2001:db8::/32",
+			remoteAddr:    "[2001:db9::1]:12345",
+			body:          `{"enabled":true}`,
+			wantStatus:    http.StatusForbidden,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			h := newTestHandler(fakeConfig{trustedSubnet: tt.trustedSubnet})
+
+			req := httptest.NewRequest(http.MethodPost, "/api/internal/read-only", strings.NewReader(tt.body))
+			req.RemoteAddr = tt.remoteAddr
+			rec := httptest.NewRecorder()
+
+			h.setReadOnly(rec, req)
+
+			if rec.Code != tt.wantStatus {
+				t.Errorf("setReadOnly() status = %d, want %d", rec.Code, tt.wantStatus)
+			}
+			if tt.wantStatus == http.StatusOK && h.readOnly.Load() != tt.wantReadOnlyAt {
+				t.Errorf("readOnly flag = %v, want %v", h.readOnly.Load(), tt.wantReadOnlyAt)
+			}
+		})
+	}
+}
+
+// TestIsTrustedSubnet_IgnoresXRealIP guards against regressing to trusting a client-supplied
+// X-Real-IP header for the trusted-subnet check: an untrusted caller must not be able to spoof
+// its way into the admin-gated endpoints by sending an X-Real-IP inside the trusted CIDR.
+func TestIsTrustedSubnet_IgnoresXRealIP(t *testing.T) {
+	h := newTestHandler(fakeConfig{trustedSubnet: "192.168.1.0/24"})
+
+	req := httptest.NewRequest(http.MethodPost, "/api/internal/read-only", strings.NewReader(`{"enabled":true}`))
+	req.RemoteAddr = "10.0.0.5:12345"
+	req.Header.Set("X-Real-IP", "192.168.1.10")
+	rec := httptest.NewRecorder()
+
+	h.setReadOnly(rec, req)
+
+	if rec.Code != http.StatusForbidden {
+		t.Errorf("setReadOnly() status = %d, want %d (X-Real-IP must not grant trust)", rec.Code, http.StatusForbidden)
+	}
+}
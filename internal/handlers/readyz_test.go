@@ -0,0 +1,43 @@
+package handlers
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/rs/zerolog"
+)
+
+func TestReadyz_NotReadyDuringMigration_ThenReadyAfter(t *testing.T) {
+	var ready atomic.Bool
+	h := NewHandler(fakeService{}, fakeConfig{}, fakeAuth{}, zerolog.Nop(), fakeReadiness{ready: &ready})
+	router := h.newRouter()
+
+	req := httptest.NewRequest(http.MethodGet, "/readyz", nil)
+	rec := httptest.NewRecorder()
+	router.ServeHTTP(rec, req)
+
+	if rec.Code != http.StatusServiceUnavailable {
+		t.Errorf("got status %d, want %d while migration is still running", rec.Code, http.StatusServiceUnavailable)
+	}
+
+	// simulate a slow migrator finishing in the background
+	go func() {
+		time.Sleep(20 * time.Millisecond)
+		ready.Store(true)
+	}()
+
+	deadline := time.Now().Add(time.Second)
+	for time.Now().Before(deadline) {
+		req = httptest.NewRequest(http.MethodGet, "/readyz", nil)
+		rec = httptest.NewRecorder()
+		router.ServeHTTP(rec, req)
+		if rec.Code == http.StatusOK {
+			return
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+	t.Fatalf("readyz did not report ready within deadline, last status = %d", rec.Code)
+}
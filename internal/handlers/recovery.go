@@ -0,0 +1,34 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+	"runtime/debug"
+)
+
+// errRecoveredPanic is reported to the caller when recoveryMiddleware catches a panic; its
+// message deliberately says nothing about the panic's actual cause
+var errRecoveredPanic = errors.New("internal server error")
+
+// recoveryMiddleware recovers from a panic anywhere further down the chain, logging it with
+// its stack trace and request ID before responding 500, so a single bad request cannot take
+// down the whole server. It is registered outermost so it also covers requestLogger and every
+// route, including the redirect path.
+func (h Handler) recoveryMiddleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		defer func() {
+			if err := recover(); err != nil {
+				h.zlog.Error().
+					Str("request_id", requestID(r)).
+					Str("method", r.Method).
+					Str("url", r.RequestURI).
+					Interface("panic", err).
+					Str("stack", string(debug.Stack())).
+					Msg("recovered from panic")
+				h.writeError(w, r, errRecoveredPanic, http.StatusInternalServerError)
+			}
+		}()
+
+		next.ServeHTTP(w, r)
+	})
+}
@@ -0,0 +1,43 @@
+package handlers
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/rs/zerolog"
+)
+
+func TestRecoveryMiddleware_ConvertsPanicTo500(t *testing.T) {
+	h := NewHandler(fakeService{}, fakeConfig{}, fakeAuth{}, zerolog.Nop(), fakeReadiness{})
+
+	next := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		panic("boom")
+	})
+
+	req := httptest.NewRequest(http.MethodGet, "/anything", nil)
+	rec := httptest.NewRecorder()
+
+	h.recoveryMiddleware(next).ServeHTTP(rec, req)
+
+	if rec.Code != http.StatusInternalServerError {
+		t.Errorf("recoveryMiddleware() status = %d, want %d", rec.Code, http.StatusInternalServerError)
+	}
+}
+
+func TestRecoveryMiddleware_DoesNotInterfereWithoutPanic(t *testing.T) {
+	h := NewHandler(fakeService{}, fakeConfig{}, fakeAuth{}, zerolog.Nop(), fakeReadiness{})
+
+	next := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusTeapot)
+	})
+
+	req := httptest.NewRequest(http.MethodGet, "/anything", nil)
+	rec := httptest.NewRecorder()
+
+	h.recoveryMiddleware(next).ServeHTTP(rec, req)
+
+	if rec.Code != http.StatusTeapot {
+		t.Errorf("recoveryMiddleware() status = %d, want %d", rec.Code, http.StatusTeapot)
+	}
+}
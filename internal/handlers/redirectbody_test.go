@@ -0,0 +1,39 @@
+package handlers
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestGetURL_RedirectBody(t *testing.T) {
+	tests := []struct {
+		name         string
+		redirectBody bool
+		wantBody     bool
+	}{
+		{"enabled writes a body", true, true},
+		{"disabled leaves the body empty", false, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			h := newTestHandler(fakeConfig{redirectBody: tt.redirectBody})
+			router := h.newRouter()
+
+			req := httptest.NewRequest(http.MethodGet, "/abc123", nil)
+			rec := httptest.NewRecorder()
+			router.ServeHTTP(rec, req)
+
+			if rec.Code != http.StatusTemporaryRedirect {
+				t.Fatalf("got status %d, want %d", rec.Code, http.StatusTemporaryRedirect)
+			}
+			if rec.Header().Get("Location") != "https://example.com" {
+				t.Errorf("Location header = %q, want %q", rec.Header().Get("Location"), "https://example.com")
+			}
+			if hasBody := rec.Body.Len() > 0; hasBody != tt.wantBody {
+				t.Errorf("body present = %v, want %v (body: %q)", hasBody, tt.wantBody, rec.Body.String())
+			}
+		})
+	}
+}
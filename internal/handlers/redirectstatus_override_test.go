@@ -0,0 +1,88 @@
+package handlers
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/ar4ie13/shortener/internal/myerrors"
+	"github.com/google/uuid"
+	"github.com/rs/zerolog"
+)
+
+// redirectStatusService wraps fakeService, recording the redirectStatus SaveURL was called
+// with and reporting it back from GetRedirectStatus, so the per-link override logic in getURL
+// can be exercised without a real Service
+type redirectStatusService struct {
+	fakeService
+	saved int
+}
+
+func (s *redirectStatusService) SaveURL(_ context.Context, _ uuid.UUID, _ string, _ string, _ string, _ string, _ string, _ bool, redirectStatus int) (string, error) {
+	switch redirectStatus {
+	case 0, http.StatusMovedPermanently, http.StatusFound, http.StatusTemporaryRedirect, http.StatusPermanentRedirect:
+		s.saved = redirectStatus
+		return "abc123", nil
+	default:
+		return "", myerrors.ErrInvalidRedirectStatus
+	}
+}
+
+func (s *redirectStatusService) GetRedirectStatus(_ context.Context, _ string) (int, error) {
+	return s.saved, nil
+}
+
+func TestPostURLJSON_RedirectStatus_InvalidIsBadRequest(t *testing.T) {
+	h := NewHandler(&redirectStatusService{}, fakeConfig{}, fakeAuth{}, zerolog.Nop(), fakeReadiness{})
+	router := h.newRouter()
+
+	body := `{"url":"https://example.com","redirect_status":418}`
+	req := httptest.NewRequest(http.MethodPost, "/api/shorten", strings.NewReader(body))
+	req.Header.Set("Content-Type", "application/json")
+	rec := httptest.NewRecorder()
+	router.ServeHTTP(rec, req)
+
+	if rec.Code != http.StatusBadRequest {
+		t.Fatalf("got status %d, want %d", rec.Code, http.StatusBadRequest)
+	}
+}
+
+func TestGetURL_RedirectStatus_PerLinkOverridesConfiguredDefault(t *testing.T) {
+	svc := &redirectStatusService{}
+	h := NewHandler(svc, fakeConfig{}, fakeAuth{}, zerolog.Nop(), fakeReadiness{})
+	router := h.newRouter()
+
+	body := `{"url":"https://example.com","redirect_status":301}`
+	req := httptest.NewRequest(http.MethodPost, "/api/shorten", strings.NewReader(body))
+	req.Header.Set("Content-Type", "application/json")
+	rec := httptest.NewRecorder()
+	router.ServeHTTP(rec, req)
+
+	if rec.Code != http.StatusCreated {
+		t.Fatalf("got status %d, want %d", rec.Code, http.StatusCreated)
+	}
+
+	req = httptest.NewRequest(http.MethodGet, "/abc123", nil)
+	rec = httptest.NewRecorder()
+	router.ServeHTTP(rec, req)
+
+	if rec.Code != http.StatusMovedPermanently {
+		t.Errorf("got status %d, want %d", rec.Code, http.StatusMovedPermanently)
+	}
+}
+
+func TestGetURL_RedirectStatus_FallsBackToConfiguredDefaultWhenUnset(t *testing.T) {
+	svc := &redirectStatusService{}
+	h := NewHandler(svc, fakeConfig{redirectStatus: http.StatusMovedPermanently}, fakeAuth{}, zerolog.Nop(), fakeReadiness{})
+	router := h.newRouter()
+
+	req := httptest.NewRequest(http.MethodGet, "/abc123", nil)
+	rec := httptest.NewRecorder()
+	router.ServeHTTP(rec, req)
+
+	if rec.Code != http.StatusMovedPermanently {
+		t.Fatalf("got status %d, want %d", rec.Code, http.StatusMovedPermanently)
+	}
+}
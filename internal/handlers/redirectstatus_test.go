@@ -0,0 +1,79 @@
+package handlers
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestRedirectStatusFor(t *testing.T) {
+	tests := []struct {
+		name       string
+		method     string
+		configured int
+		want       int
+	}{
+		{"unset default on GET", http.MethodGet, 0, http.StatusTemporaryRedirect},
+		{"configured 301 on GET is kept as-is", http.MethodGet, http.StatusMovedPermanently, http.StatusMovedPermanently},
+		{"configured 301 on HEAD swaps to 308", http.MethodHead, http.StatusMovedPermanently, http.StatusPermanentRedirect},
+		{"configured 302 on HEAD swaps to 307", http.MethodHead, http.StatusFound, http.StatusTemporaryRedirect},
+		{"configured 307 on HEAD is already method-safe", http.MethodHead, http.StatusTemporaryRedirect, http.StatusTemporaryRedirect},
+		{"configured 308 on HEAD is already method-safe", http.MethodHead, http.StatusPermanentRedirect, http.StatusPermanentRedirect},
+		{"unset default on HEAD", http.MethodHead, 0, http.StatusTemporaryRedirect},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := redirectStatusFor(tt.method, tt.configured); got != tt.want {
+				t.Errorf("redirectStatusFor(%q, %d) = %d, want %d", tt.method, tt.configured, got, tt.want)
+			}
+		})
+	}
+}
+
+// TestGetURL_HEADIsNotMethodNotAllowed guards against GET /{id} being registered without a
+// matching HEAD route, which would make chi answer HEAD with 405 and break link checkers and
+// messenger preview bots that probe a link with HEAD before following it.
+func TestGetURL_HEADIsNotMethodNotAllowed(t *testing.T) {
+	h := newTestHandler(fakeConfig{})
+	router := h.newRouter()
+
+	req := httptest.NewRequest(http.MethodHead, "/abc123", nil)
+	rec := httptest.NewRecorder()
+	router.ServeHTTP(rec, req)
+
+	if rec.Code == http.StatusMethodNotAllowed {
+		t.Fatalf("got status %d, want HEAD routed to the same handler as GET", rec.Code)
+	}
+	if got := rec.Header().Get("Location"); got == "" {
+		t.Error("Location header not set on HEAD response")
+	}
+}
+
+func TestGetURL_ConfiguredRedirectStatus(t *testing.T) {
+	tests := []struct {
+		name       string
+		method     string
+		configured int
+		wantStatus int
+	}{
+		{"GET with no override gets 307", http.MethodGet, 0, http.StatusTemporaryRedirect},
+		{"GET with 301 configured gets 301", http.MethodGet, http.StatusMovedPermanently, http.StatusMovedPermanently},
+		{"HEAD with 301 configured gets method-safe 308", http.MethodHead, http.StatusMovedPermanently, http.StatusPermanentRedirect},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			h := newTestHandler(fakeConfig{redirectStatus: tt.configured})
+			router := h.newRouter()
+
+			req := httptest.NewRequest(tt.method, "/abc123", nil)
+			rec := httptest.NewRecorder()
+			router.ServeHTTP(rec, req)
+
+			if rec.Code != tt.wantStatus {
+				t.Fatalf("got status %d, want %d", rec.Code, tt.wantStatus)
+			}
+		})
+	}
+}
@@ -0,0 +1,41 @@
+package handlers
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/google/uuid"
+)
+
+func TestRequestLogger_EchoesRequestID(t *testing.T) {
+	h := newTestHandler(fakeConfig{})
+	router := h.newRouter()
+
+	req := httptest.NewRequest(http.MethodPost, "/", strings.NewReader("https://example.com"))
+	req.Header.Set("X-Request-Id", "caller-supplied-id")
+	rec := httptest.NewRecorder()
+	router.ServeHTTP(rec, req)
+
+	if got := rec.Header().Get("X-Request-Id"); got != "caller-supplied-id" {
+		t.Errorf("X-Request-Id = %q, want %q", got, "caller-supplied-id")
+	}
+}
+
+func TestRequestLogger_GeneratesRequestIDWhenAbsent(t *testing.T) {
+	h := newTestHandler(fakeConfig{})
+	router := h.newRouter()
+
+	req := httptest.NewRequest(http.MethodPost, "/", strings.NewReader("https://example.com"))
+	rec := httptest.NewRecorder()
+	router.ServeHTTP(rec, req)
+
+	got := rec.Header().Get("X-Request-Id")
+	if got == "" {
+		t.Fatal("X-Request-Id is empty, want a generated value")
+	}
+	if _, err := uuid.Parse(got); err != nil {
+		t.Errorf("X-Request-Id = %q, want a valid UUID: %v", got, err)
+	}
+}
@@ -0,0 +1,53 @@
+package handlers
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestRootBehavior(t *testing.T) {
+	tests := []struct {
+		name            string
+		rootBehavior    string
+		rootRedirectURL string
+		wantStatus      int
+		wantLocation    string
+	}{
+		{
+			name:         "default keeps 405",
+			rootBehavior: "",
+			wantStatus:   http.StatusMethodNotAllowed,
+		},
+		{
+			name:         "landing serves 200",
+			rootBehavior: "landing",
+			wantStatus:   http.StatusOK,
+		},
+		{
+			name:            "redirect sends 302 to configured url",
+			rootBehavior:    "redirect",
+			rootRedirectURL: "https://example.com/home",
+			wantStatus:      http.StatusFound,
+			wantLocation:    "https://example.com/home",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			h := newTestHandler(fakeConfig{rootBehavior: tt.rootBehavior, rootRedirectURL: tt.rootRedirectURL})
+			router := h.newRouter()
+
+			req := httptest.NewRequest(http.MethodGet, "/", nil)
+			rec := httptest.NewRecorder()
+			router.ServeHTTP(rec, req)
+
+			if rec.Code != tt.wantStatus {
+				t.Fatalf("got status %d, want %d", rec.Code, tt.wantStatus)
+			}
+			if tt.wantLocation != "" && rec.Header().Get("Location") != tt.wantLocation {
+				t.Errorf("Location header = %q, want %q", rec.Header().Get("Location"), tt.wantLocation)
+			}
+		})
+	}
+}
@@ -0,0 +1,47 @@
+package handlers
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+// TestPostURL_ComposedURLUnchangedByPrefixCaching confirms the precomputed
+// shortURLPrefixStatic still produces the same short URL as computing it per request.
+func TestPostURL_ComposedURLUnchangedByPrefixCaching(t *testing.T) {
+	cfg := fakeConfig{}
+	h := newTestHandler(cfg)
+	router := h.newRouter()
+
+	for i := 0; i < 3; i++ {
+		req := httptest.NewRequest(http.MethodPost, "/", strings.NewReader("https://example.com/prefix"))
+		rec := httptest.NewRecorder()
+		router.ServeHTTP(rec, req)
+
+		if rec.Code != http.StatusCreated {
+			t.Fatalf("got status %d, want %d", rec.Code, http.StatusCreated)
+		}
+		wantPrefix := cfg.GetShortURLTemplate() + "/"
+		if got := rec.Body.String(); !strings.HasPrefix(got, wantPrefix) {
+			t.Errorf("response %q does not start with %q", got, wantPrefix)
+		}
+	}
+}
+
+// BenchmarkPostURL measures per-request allocations on the short-URL creation endpoint
+// with the precomputed prefix in place.
+func BenchmarkPostURL(b *testing.B) {
+	h := newTestHandler(fakeConfig{})
+	router := h.newRouter()
+
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		req := httptest.NewRequest(http.MethodPost, "/", strings.NewReader("https://example.com/bench"))
+		rec := httptest.NewRecorder()
+		router.ServeHTTP(rec, req)
+		if rec.Code != http.StatusCreated {
+			b.Fatalf("got status %d, want %d", rec.Code, http.StatusCreated)
+		}
+	}
+}
@@ -0,0 +1,79 @@
+package handlers
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestPostURL_ForceShortURLScheme(t *testing.T) {
+	tests := []struct {
+		name                string
+		forceShortURLScheme string
+		trustedSubnet       string
+		remoteAddr          string
+		forwardedProto      string
+		wantPrefix          string
+	}{
+		{
+			name:                "forced https overrides template scheme",
+			forceShortURLScheme: "https",
+			wantPrefix:          "https://",
+		},
+		{
+			name:                "forced http overrides template scheme",
+			forceShortURLScheme: "http",
+			wantPrefix:          "http://",
+		},
+		{
+			name:                "auto from trusted subnet honors X-Forwarded-Proto",
+			forceShortURLScheme: "auto",
+			trustedSubnet:       "192.168.1.0/24",
+			remoteAddr:          "192.168.1.10:12345",
+			forwardedProto:      "https",
+			wantPrefix:          "https://",
+		},
+		{
+			name:                "auto from untrusted source ignores X-Forwarded-Proto",
+			forceShortURLScheme: "auto",
+			trustedSubnet:       "192.168.1.0/24",
+			remoteAddr:          "10.0.0.5:12345",
+			forwardedProto:      "https",
+			wantPrefix:          "http://",
+		},
+		{
+			name:                "empty scheme leaves template untouched",
+			forceShortURLScheme: "",
+			wantPrefix:          "http://",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			h := newTestHandler(fakeConfig{
+				forceShortURLScheme: tt.forceShortURLScheme,
+				trustedSubnet:       tt.trustedSubnet,
+			})
+
+			req := httptest.NewRequest(http.MethodPost, "/", strings.NewReader("https://example.com"))
+			if tt.remoteAddr != "" {
+				req.RemoteAddr = tt.remoteAddr
+			}
+			if tt.forwardedProto != "" {
+				req.Header.Set("X-Forwarded-Proto", tt.forwardedProto)
+			}
+			rec := httptest.NewRecorder()
+
+			h.newRouter().ServeHTTP(rec, req)
+
+			if rec.Code != http.StatusCreated {
+				t.Fatalf("got status %d, want %d", rec.Code, http.StatusCreated)
+			}
+			got := rec.Body.String()
+			if !strings.HasPrefix(got, tt.wantPrefix) {
+				t.Errorf("postURL() body = %q, want prefix %q", got, tt.wantPrefix)
+			}
+		})
+	}
+}
@@ -0,0 +1,52 @@
+package handlers
+
+import (
+	"net/http"
+	"os"
+	"syscall"
+	"testing"
+	"time"
+
+	"github.com/rs/zerolog"
+)
+
+// waitForListener polls addr until a TCP connection succeeds or timeout elapses
+func waitForListener(t *testing.T, addr string, timeout time.Duration) {
+	t.Helper()
+	deadline := time.Now().Add(timeout)
+	for time.Now().Before(deadline) {
+		resp, err := http.Get("http://" + addr + "/ping")
+		if err == nil {
+			_ = resp.Body.Close()
+			return
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+	t.Fatalf("server at %s never started accepting connections", addr)
+}
+
+func TestListenAndServe_GracefulShutdownOnSIGINT(t *testing.T) {
+	h := NewHandler(fakeService{}, fakeConfig{localAddr: "127.0.0.1:18123", shutdownTimeout: time.Second}, fakeAuth{}, zerolog.Nop(), fakeReadiness{})
+
+	errCh := make(chan error, 1)
+	go func() { errCh <- h.ListenAndServe() }()
+
+	waitForListener(t, "127.0.0.1:18123", time.Second)
+
+	proc, err := os.FindProcess(os.Getpid())
+	if err != nil {
+		t.Fatalf("failed to find own process: %v", err)
+	}
+	if err := proc.Signal(syscall.SIGINT); err != nil {
+		t.Fatalf("failed to send SIGINT: %v", err)
+	}
+
+	select {
+	case err := <-errCh:
+		if err != nil {
+			t.Errorf("ListenAndServe() error = %v, want nil after a graceful shutdown", err)
+		}
+	case <-time.After(2 * time.Second):
+		t.Fatal("ListenAndServe did not return after SIGINT")
+	}
+}
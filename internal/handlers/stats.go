@@ -0,0 +1,27 @@
+package handlers
+
+import (
+	"encoding/json"
+	"net/http"
+)
+
+// stats reports the total number of non-deleted short URLs and the total number of distinct
+// users that have ever saved one. Restricted to the trusted subnet, like topHosts.
+func (h Handler) stats(w http.ResponseWriter, r *http.Request) {
+	if !h.isTrustedSubnet(r) {
+		h.writeError(w, r, errUntrustedSubnet, http.StatusForbidden)
+		return
+	}
+
+	stats, err := h.service.Stats(r.Context())
+	if err != nil {
+		h.writeError(w, r, err, http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	if err = json.NewEncoder(w).Encode(stats); err != nil {
+		h.reqLog(r).Debug().Msgf("error encoding response: %v", err)
+	}
+}
@@ -0,0 +1,66 @@
+package handlers
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/ar4ie13/shortener/internal/model"
+	"github.com/rs/zerolog"
+)
+
+// statsService wraps fakeService, returning a fixed stats report
+type statsService struct {
+	fakeService
+	stats model.Stats
+}
+
+func (s statsService) Stats(_ context.Context) (model.Stats, error) {
+	return s.stats, nil
+}
+
+func TestStats(t *testing.T) {
+	tests := []struct {
+		name          string
+		trustedSubnet string
+		remoteAddr    string
+		wantStatus    int
+		wantBody      string
+	}{
+		{
+			name:          "trusted caller gets the report",
+			trustedSubnet: "192.168.1.0/24",
+			remoteAddr:    "192.168.1.10:12345",
+			wantStatus:    http.StatusOK,
+			wantBody:      `{"urls":5,"users":2}`,
+		},
+		{
+			name:          "untrusted caller is forbidden",
+			trustedSubnet: "192.168.1.0/24",
+			remoteAddr:    "10.0.0.5:12345",
+			wantStatus:    http.StatusForbidden,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			svc := statsService{stats: model.Stats{URLs: 5, Users: 2}}
+			h := NewHandler(svc, fakeConfig{trustedSubnet: tt.trustedSubnet}, fakeAuth{}, zerolog.Nop(), fakeReadiness{})
+
+			req := httptest.NewRequest(http.MethodGet, "/api/internal/stats", nil)
+			req.RemoteAddr = tt.remoteAddr
+			rec := httptest.NewRecorder()
+
+			h.stats(rec, req)
+
+			if rec.Code != tt.wantStatus {
+				t.Errorf("stats() status = %d, want %d", rec.Code, tt.wantStatus)
+			}
+			if tt.wantStatus == http.StatusOK && strings.TrimSpace(rec.Body.String()) != tt.wantBody {
+				t.Errorf("stats() body = %q, want %q", rec.Body.String(), tt.wantBody)
+			}
+		})
+	}
+}
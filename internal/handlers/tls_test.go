@@ -0,0 +1,69 @@
+package handlers
+
+import (
+	"crypto/tls"
+	"net/http"
+	"os"
+	"path/filepath"
+	"syscall"
+	"testing"
+	"time"
+
+	"github.com/ar4ie13/shortener/internal/tlscert"
+	"github.com/rs/zerolog"
+)
+
+// waitForTLSListener polls an HTTPS addr until a TLS connection succeeds or timeout elapses
+func waitForTLSListener(t *testing.T, client *http.Client, addr string, timeout time.Duration) {
+	t.Helper()
+	deadline := time.Now().Add(timeout)
+	for time.Now().Before(deadline) {
+		resp, err := client.Get("https://" + addr + "/ping")
+		if err == nil {
+			_ = resp.Body.Close()
+			return
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+	t.Fatalf("server at %s never started accepting TLS connections", addr)
+}
+
+func TestListenAndServe_ServesHTTPSWithSelfSignedCert(t *testing.T) {
+	dir := t.TempDir()
+	certPath := filepath.Join(dir, "server.crt")
+	keyPath := filepath.Join(dir, "server.key")
+	if err := tlscert.EnsureSelfSigned(certPath, keyPath); err != nil {
+		t.Fatalf("EnsureSelfSigned() error = %v", err)
+	}
+
+	h := NewHandler(fakeService{}, fakeConfig{
+		localAddr:       "127.0.0.1:18124",
+		enableHTTPS:     true,
+		tlsCertPath:     certPath,
+		tlsKeyPath:      keyPath,
+		shutdownTimeout: time.Second,
+	}, fakeAuth{}, zerolog.Nop(), fakeReadiness{})
+
+	errCh := make(chan error, 1)
+	go func() { errCh <- h.ListenAndServe() }()
+
+	client := &http.Client{Transport: &http.Transport{TLSClientConfig: &tls.Config{InsecureSkipVerify: true}}}
+	waitForTLSListener(t, client, "127.0.0.1:18124", time.Second)
+
+	proc, err := os.FindProcess(os.Getpid())
+	if err != nil {
+		t.Fatalf("failed to find own process: %v", err)
+	}
+	if err := proc.Signal(syscall.SIGINT); err != nil {
+		t.Fatalf("failed to send SIGINT: %v", err)
+	}
+
+	select {
+	case err := <-errCh:
+		if err != nil {
+			t.Errorf("ListenAndServe() error = %v, want nil after a graceful shutdown", err)
+		}
+	case <-time.After(2 * time.Second):
+		t.Fatal("ListenAndServe did not return after SIGINT")
+	}
+}
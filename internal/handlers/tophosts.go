@@ -0,0 +1,45 @@
+package handlers
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+)
+
+// errInvalidN is returned when the "n" query parameter of GET /api/admin/top-hosts cannot be
+// parsed as an integer
+var errInvalidN = errors.New("invalid n")
+
+// topHosts reports the n hosts with the most non-deleted short URLs, ordered by count
+// descending. n is read from the optional "n" query parameter; a missing or invalid value
+// falls back to the service's default. Restricted to the trusted subnet, like setReadOnly.
+func (h Handler) topHosts(w http.ResponseWriter, r *http.Request) {
+	if !h.isTrustedSubnet(r) {
+		h.writeError(w, r, errUntrustedSubnet, http.StatusForbidden)
+		return
+	}
+
+	var n int
+	if v := r.URL.Query().Get("n"); v != "" {
+		parsed, err := strconv.Atoi(v)
+		if err != nil {
+			h.writeError(w, r, fmt.Errorf("%w: %v", errInvalidN, err), http.StatusBadRequest)
+			return
+		}
+		n = parsed
+	}
+
+	hostCounts, err := h.service.TopHosts(r.Context(), n)
+	if err != nil {
+		h.writeError(w, r, err, http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	if err = json.NewEncoder(w).Encode(hostCounts); err != nil {
+		h.reqLog(r).Debug().Msgf("error encoding response: %v", err)
+	}
+}
@@ -0,0 +1,95 @@
+package handlers
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/ar4ie13/shortener/internal/model"
+	"github.com/rs/zerolog"
+)
+
+// topHostsRecordingService wraps fakeService, recording the n it was called with and
+// returning a fixed set of host counts
+type topHostsRecordingService struct {
+	fakeService
+	gotN  int
+	hosts []model.HostCount
+}
+
+func (s *topHostsRecordingService) TopHosts(_ context.Context, n int) ([]model.HostCount, error) {
+	s.gotN = n
+	return s.hosts, nil
+}
+
+func TestTopHosts(t *testing.T) {
+	tests := []struct {
+		name          string
+		trustedSubnet string
+		remoteAddr    string
+		query         string
+		wantStatus    int
+		wantN         int
+		wantBody      string
+	}{
+		{
+			name:          "trusted caller gets the report",
+			trustedSubnet: "192.168.1.0/24",
+			remoteAddr:    "192.168.1.10:12345",
+			query:         "?n=5",
+			wantStatus:    http.StatusOK,
+			wantN:         5,
+			wantBody:      `[{"host":"spam.example.com","count":3}]`,
+		},
+		{
+			name:          "missing n defaults to zero, left to the service",
+			trustedSubnet: "192.168.1.0/24",
+			remoteAddr:    "192.168.1.10:12345",
+			query:         "",
+			wantStatus:    http.StatusOK,
+			wantN:         0,
+			wantBody:      `[{"host":"spam.example.com","count":3}]`,
+		},
+		{
+			name:          "untrusted caller is forbidden",
+			trustedSubnet: "192.168.1.0/24",
+			remoteAddr:    "10.0.0.5:12345",
+			query:         "?n=5",
+			wantStatus:    http.StatusForbidden,
+		},
+		{
+			name:          "malformed n is a bad request",
+			trustedSubnet: "192.168.1.0/24",
+			remoteAddr:    "192.168.1.10:12345",
+			query:         "?n=notanumber",
+			wantStatus:    http.StatusBadRequest,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			svc := &topHostsRecordingService{hosts: []model.HostCount{{Host: "spam.example.com", Count: 3}}}
+			h := NewHandler(svc, fakeConfig{trustedSubnet: tt.trustedSubnet}, fakeAuth{}, zerolog.Nop(), fakeReadiness{})
+
+			req := httptest.NewRequest(http.MethodGet, "/api/internal/top-hosts"+tt.query, nil)
+			req.RemoteAddr = tt.remoteAddr
+			rec := httptest.NewRecorder()
+
+			h.topHosts(rec, req)
+
+			if rec.Code != tt.wantStatus {
+				t.Errorf("topHosts() status = %d, want %d", rec.Code, tt.wantStatus)
+			}
+			if tt.wantStatus == http.StatusOK {
+				if svc.gotN != tt.wantN {
+					t.Errorf("topHosts() n = %d, want %d", svc.gotN, tt.wantN)
+				}
+				if strings.TrimSpace(rec.Body.String()) != tt.wantBody {
+					t.Errorf("topHosts() body = %q, want %q", rec.Body.String(), tt.wantBody)
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,162 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/ar4ie13/shortener/internal/model"
+	"github.com/google/uuid"
+	"github.com/rs/zerolog"
+)
+
+// urlsService wraps fakeService, returning a fixed set of user short URLs
+type urlsService struct {
+	fakeService
+}
+
+func (urlsService) GetUserShortURLs(_ context.Context, _ uuid.UUID, _ string, _ int, _ int) ([]model.URL, error) {
+	return []model.URL{
+		{ShortURL: "abc12345", OriginalURL: "https://example.com/one"},
+	}, nil
+}
+
+func TestGetUsersShortURL_ArrayFormat(t *testing.T) {
+	h := NewHandler(urlsService{}, fakeConfig{}, fakeAuth{}, zerolog.Nop(), fakeReadiness{})
+	router := h.newRouter()
+
+	req := httptest.NewRequest(http.MethodGet, "/api/user/urls", nil)
+	rec := httptest.NewRecorder()
+	router.ServeHTTP(rec, req)
+
+	if rec.Code != http.StatusOK {
+		t.Fatalf("got status %d, want %d", rec.Code, http.StatusOK)
+	}
+
+	var resp []UserShortURLs
+	if err := json.Unmarshal(rec.Body.Bytes(), &resp); err != nil {
+		t.Fatalf("failed to unmarshal response: %v", err)
+	}
+	if len(resp) != 1 {
+		t.Fatalf("got %d entries, want 1", len(resp))
+	}
+	if resp[0].ShortURL != "http://localhost:8080/abc12345" || resp[0].LongURL != "https://example.com/one" {
+		t.Errorf("got %+v, want {ShortURL: http://localhost:8080/abc12345, LongURL: https://example.com/one}", resp[0])
+	}
+}
+
+// manyURLsService wraps fakeService, returning n fixed user short URLs
+type manyURLsService struct {
+	fakeService
+	n int
+}
+
+func (s manyURLsService) GetUserShortURLs(_ context.Context, _ uuid.UUID, _ string, _ int, _ int) ([]model.URL, error) {
+	urls := make([]model.URL, s.n)
+	for i := range urls {
+		urls[i] = model.URL{ShortURL: fmt.Sprintf("slug%04d", i), OriginalURL: fmt.Sprintf("https://example.com/%d", i)}
+	}
+	return urls, nil
+}
+
+func TestGetUsersShortURL_TruncatesAboveMaxResponseURLs(t *testing.T) {
+	h := NewHandler(manyURLsService{n: 5}, fakeConfig{maxResponseURLs: 3}, fakeAuth{}, zerolog.Nop(), fakeReadiness{})
+	router := h.newRouter()
+
+	req := httptest.NewRequest(http.MethodGet, "/api/user/urls", nil)
+	rec := httptest.NewRecorder()
+	router.ServeHTTP(rec, req)
+
+	if rec.Code != http.StatusOK {
+		t.Fatalf("got status %d, want %d", rec.Code, http.StatusOK)
+	}
+	if got := rec.Header().Get("X-Truncated"); got != "true" {
+		t.Errorf("X-Truncated header = %q, want %q", got, "true")
+	}
+
+	var resp []UserShortURLs
+	if err := json.Unmarshal(rec.Body.Bytes(), &resp); err != nil {
+		t.Fatalf("failed to unmarshal response: %v", err)
+	}
+	if len(resp) != 3 {
+		t.Fatalf("got %d entries, want 3", len(resp))
+	}
+}
+
+func TestGetUsersShortURL_NoTruncationBelowMaxResponseURLs(t *testing.T) {
+	h := NewHandler(manyURLsService{n: 2}, fakeConfig{maxResponseURLs: 3}, fakeAuth{}, zerolog.Nop(), fakeReadiness{})
+	router := h.newRouter()
+
+	req := httptest.NewRequest(http.MethodGet, "/api/user/urls", nil)
+	rec := httptest.NewRecorder()
+	router.ServeHTTP(rec, req)
+
+	if rec.Code != http.StatusOK {
+		t.Fatalf("got status %d, want %d", rec.Code, http.StatusOK)
+	}
+	if got := rec.Header().Get("X-Truncated"); got != "" {
+		t.Errorf("X-Truncated header = %q, want empty", got)
+	}
+
+	var resp []UserShortURLs
+	if err := json.Unmarshal(rec.Body.Bytes(), &resp); err != nil {
+		t.Fatalf("failed to unmarshal response: %v", err)
+	}
+	if len(resp) != 2 {
+		t.Fatalf("got %d entries, want 2", len(resp))
+	}
+}
+
+func TestGetUsersShortURL_RejectsUnknownSort(t *testing.T) {
+	h := NewHandler(urlsService{}, fakeConfig{}, fakeAuth{}, zerolog.Nop(), fakeReadiness{})
+	router := h.newRouter()
+
+	req := httptest.NewRequest(http.MethodGet, "/api/user/urls?sort=bogus", nil)
+	rec := httptest.NewRecorder()
+	router.ServeHTTP(rec, req)
+
+	if rec.Code != http.StatusBadRequest {
+		t.Fatalf("got status %d, want %d", rec.Code, http.StatusBadRequest)
+	}
+}
+
+func TestGetUsersShortURL_RejectsNegativeLimit(t *testing.T) {
+	h := NewHandler(urlsService{}, fakeConfig{}, fakeAuth{}, zerolog.Nop(), fakeReadiness{})
+	router := h.newRouter()
+
+	req := httptest.NewRequest(http.MethodGet, "/api/user/urls?limit=-1", nil)
+	rec := httptest.NewRecorder()
+	router.ServeHTTP(rec, req)
+
+	if rec.Code != http.StatusBadRequest {
+		t.Fatalf("got status %d, want %d", rec.Code, http.StatusBadRequest)
+	}
+}
+
+func TestGetUsersShortURL_MapFormat(t *testing.T) {
+	h := NewHandler(urlsService{}, fakeConfig{}, fakeAuth{}, zerolog.Nop(), fakeReadiness{})
+	router := h.newRouter()
+
+	req := httptest.NewRequest(http.MethodGet, "/api/user/urls?format=map", nil)
+	rec := httptest.NewRecorder()
+	router.ServeHTTP(rec, req)
+
+	if rec.Code != http.StatusOK {
+		t.Fatalf("got status %d, want %d", rec.Code, http.StatusOK)
+	}
+
+	var resp map[string]string
+	if err := json.Unmarshal(rec.Body.Bytes(), &resp); err != nil {
+		t.Fatalf("failed to unmarshal response: %v", err)
+	}
+	want := "https://example.com/one"
+	if got := resp["http://localhost:8080/abc12345"]; got != want {
+		t.Errorf("resp[%q] = %q, want %q", "http://localhost:8080/abc12345", got, want)
+	}
+	if len(resp) != 1 {
+		t.Errorf("got %d entries, want 1", len(resp))
+	}
+}
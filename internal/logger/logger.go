@@ -1,6 +1,8 @@
 package logger
 
 import (
+	"fmt"
+	"io"
 	"os"
 	"time"
 
@@ -12,8 +14,16 @@ type Logger struct {
 	zerolog.Logger
 }
 
-// NewLogger creates a new Logger with the given zerolog level
-func NewLogger(level zerolog.Level) *Logger {
+// NewLogger creates a new Logger with the given zerolog level. format selects the output
+// encoding: "json" emits plain JSON lines suitable for a log pipeline, anything else
+// (including "console") keeps the human-readable ConsoleWriter output.
+func NewLogger(level zerolog.Level, format string) *Logger {
+	if format == "json" {
+		return &Logger{
+			Logger: zerolog.New(os.Stdout).With().Timestamp().Logger().Level(level),
+		}
+	}
+
 	return &Logger{
 		Logger: zerolog.New(zerolog.ConsoleWriter{
 			Out:        os.Stdout,
@@ -21,3 +31,21 @@ func NewLogger(level zerolog.Level) *Logger {
 		}).With().Timestamp().Logger().Level(level),
 	}
 }
+
+// NewAuditLogger creates a Logger for compliance audit entries (e.g. deletions and purges).
+// It writes plain JSON lines, unlike NewLogger's human-readable console output, to path, or
+// to stdout when path is empty, so entries can be tailed or shipped to a log pipeline.
+func NewAuditLogger(path string) (*Logger, error) {
+	out := io.Writer(os.Stdout)
+	if path != "" {
+		f, err := os.OpenFile(path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0644)
+		if err != nil {
+			return nil, fmt.Errorf("failed to open audit log file: %w", err)
+		}
+		out = f
+	}
+
+	return &Logger{
+		Logger: zerolog.New(out).With().Timestamp().Logger(),
+	}, nil
+}
@@ -0,0 +1,76 @@
+// Package metrics holds lightweight in-process counters for capacity planning, kept separate
+// from the service and handlers packages so they stay free of metrics bookkeeping.
+package metrics
+
+import "sync/atomic"
+
+// Dedup counts how shorten requests were resolved: a brand-new slug was created, an
+// already-shortened URL's existing slug was reused, or a generated slug collided with one
+// already in use and had to be retried.
+type Dedup struct {
+	created          int64
+	reused           int64
+	collisionRetries int64
+}
+
+// IncCreated records a shorten request that created a brand-new slug
+func (d *Dedup) IncCreated() {
+	atomic.AddInt64(&d.created, 1)
+}
+
+// IncReused records a shorten request resolved to an already-shortened URL's existing slug
+func (d *Dedup) IncReused() {
+	atomic.AddInt64(&d.reused, 1)
+}
+
+// IncCollisionRetry records a generated slug colliding with one already in use, forcing a retry
+func (d *Dedup) IncCollisionRetry() {
+	atomic.AddInt64(&d.collisionRetries, 1)
+}
+
+// Cache counts how GetURL lookups were resolved: served from the in-process cache without
+// touching the repository, or missed and fetched from the repository
+type Cache struct {
+	hits   int64
+	misses int64
+}
+
+// IncHit records a lookup served from the cache
+func (c *Cache) IncHit() {
+	atomic.AddInt64(&c.hits, 1)
+}
+
+// IncMiss records a lookup that the cache could not serve
+func (c *Cache) IncMiss() {
+	atomic.AddInt64(&c.misses, 1)
+}
+
+// CacheSnapshot is a point-in-time read of Cache's counters
+type CacheSnapshot struct {
+	Hits   int64 `json:"hits"`
+	Misses int64 `json:"misses"`
+}
+
+// Snapshot returns the current counter values
+func (c *Cache) Snapshot() CacheSnapshot {
+	return CacheSnapshot{
+		Hits:   atomic.LoadInt64(&c.hits),
+		Misses: atomic.LoadInt64(&c.misses),
+	}
+}
+
+// DedupSnapshot is a point-in-time read of Dedup's counters
+type DedupSnapshot struct {
+	Created          int64 `json:"created"`
+	Reused           int64 `json:"reused"`
+	CollisionRetries int64 `json:"collision_retries"`
+}
+
+// Snapshot returns the current counter values
+func (d *Dedup) Snapshot() DedupSnapshot {
+	return DedupSnapshot{
+		Created:          atomic.LoadInt64(&d.created),
+		Reused:           atomic.LoadInt64(&d.reused),
+		CollisionRetries: atomic.LoadInt64(&d.collisionRetries),
+	}
+}
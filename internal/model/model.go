@@ -1,6 +1,10 @@
 package model
 
-import "github.com/google/uuid"
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
 
 // URL struct is a main struct used by service
 type URL struct {
@@ -9,4 +13,92 @@ type URL struct {
 	ShortURL    string    `json:"short_url" db:"short_url"`
 	OriginalURL string    `json:"original_url" db:"original_url"`
 	IsDeleted   bool      `json:"is_deleted" db:"is_deleted"`
+	Referer     string    `json:"referer,omitempty" db:"referer"`
+	UserAgent   string    `json:"user_agent,omitempty" db:"user_agent"`
+	// ExpiresAt is the zero time when the link has no TTL and never expires
+	ExpiresAt time.Time `json:"expires_at,omitempty" db:"expires_at"`
+	// DeletedAt is the zero time when the link has not been soft-deleted
+	DeletedAt time.Time `json:"deleted_at,omitempty" db:"deleted_at"`
+	// PasswordHash is the bcrypt hash guarding this link, empty when it is not password-protected
+	PasswordHash string `json:"password_hash,omitempty" db:"password_hash"`
+	// RedirectStatus is the per-link override of the HTTP status used when redirecting to
+	// OriginalURL, 0 when the link uses the configured default instead
+	RedirectStatus int `json:"redirect_status,omitempty" db:"redirect_status"`
+	// Host is the hostname parsed out of OriginalURL, populated at save time
+	Host string `json:"host,omitempty" db:"host"`
+	// CreatedAt is the zero time for rows saved before this field was introduced
+	CreatedAt time.Time `json:"created_at,omitempty" db:"created_at"`
+	// UpdatedAt is the time OriginalURL was last changed; it equals CreatedAt for a link that
+	// has never been updated, and is the zero time for rows saved before this field was introduced
+	UpdatedAt time.Time `json:"updated_at,omitempty" db:"updated_at"`
+	// Conflict is set by Service.SaveBatch's response to report that ShortURL is the slug
+	// OriginalURL already existed under, rather than the one requested for this entry
+	Conflict bool `json:"conflict,omitempty" db:"-"`
+}
+
+// URLExpansion is one result of Repository.GetURLs: either shortURL's destination, or why it
+// could not be resolved (not found, or soft-deleted with its deletion time)
+type URLExpansion struct {
+	ShortURL    string    `json:"short_url"`
+	OriginalURL string    `json:"original_url,omitempty"`
+	IsDeleted   bool      `json:"is_deleted,omitempty"`
+	DeletedAt   time.Time `json:"deleted_at,omitempty"`
+	// Err is empty when OriginalURL was resolved successfully
+	Err string `json:"error,omitempty"`
+}
+
+// HostCount is one row of a top-hosts report: how many short URLs point at Host
+type HostCount struct {
+	Host  string `json:"host" db:"host"`
+	Count int    `json:"count" db:"count"`
+}
+
+// Stats is a summary report of how many short URLs and distinct users a repository holds
+type Stats struct {
+	URLs  int `json:"urls" db:"urls"`
+	Users int `json:"users" db:"users"`
+}
+
+// DeleteJobStatus is the lifecycle state of an asynchronous deletion job started by
+// Service.SendShortURLForDelete
+type DeleteJobStatus string
+
+const (
+	DeleteJobPending   DeleteJobStatus = "pending"
+	DeleteJobCompleted DeleteJobStatus = "completed"
+	DeleteJobFailed    DeleteJobStatus = "failed"
+)
+
+// DeleteJob reports the current state of an asynchronous delete job, as returned by
+// Service.DeleteJobStatus
+type DeleteJob struct {
+	Status DeleteJobStatus `json:"status"`
+	Error  string          `json:"error,omitempty"`
+}
+
+// APIKey is an API key issued to a user for Authorization: Bearer authentication. ID is the
+// sha256 hex digest of the plaintext key, which is shown to the caller only once, at creation
+type APIKey struct {
+	ID        string    `json:"id" db:"id"`
+	UserUUID  uuid.UUID `json:"user_uuid" db:"user_uuid"`
+	CreatedAt time.Time `json:"created_at" db:"created_at"`
+	Revoked   bool      `json:"revoked" db:"revoked"`
+}
+
+// EventType identifies the kind of link lifecycle transition carried by an Event
+type EventType string
+
+const (
+	EventCreated  EventType = "created"
+	EventDeleted  EventType = "deleted"
+	EventRedirect EventType = "redirect"
+)
+
+// Event is one entry of the stream returned by Service.Subscribe: a single lifecycle
+// transition of one of UserUUID's short URLs
+type Event struct {
+	Type        EventType `json:"type"`
+	UserUUID    uuid.UUID `json:"user_uuid"`
+	ShortURL    string    `json:"short_url"`
+	OriginalURL string    `json:"original_url,omitempty"`
 }
@@ -1,6 +1,9 @@
 package myerrors
 
-import "errors"
+import (
+	"errors"
+	"time"
+)
 
 var (
 	ErrNotFound           = errors.New("not found")
@@ -17,4 +20,48 @@ var (
 
 	ErrEmptyID        = errors.New("short url cannot be empty")
 	ErrShortURLLength = errors.New("short url length is too small")
+
+	// ErrInvalidRedirectStatus is returned when a caller-supplied redirect status is not one
+	// of 301, 302, 307 or 308
+	ErrInvalidRedirectStatus = errors.New("redirect status must be 301, 302, 307 or 308")
+
+	ErrAliasReserved = errors.New("alias conflicts with a reserved path")
+
+	// ErrShortURLGracePeriod is returned alongside the original URL by Service.GetURL when
+	// a soft-deleted slug is still within its configured delete grace period
+	ErrShortURLGracePeriod = errors.New("short URL is deleted but within grace period")
+
+	// ErrDeleteQueueFull is returned by Service.SendShortURLForDelete when the asynchronous
+	// delete backlog is at its configured limit and cannot accept more work
+	ErrDeleteQueueFull = errors.New("delete queue backlog limit exceeded")
+
+	// ErrRepositoryNotReady is returned by a Repository method called before its backend
+	// finished initializing (e.g. a filestorage constructed without NewFileStorage, or a
+	// postgres DB whose connection pool is nil), instead of letting the call panic
+	ErrRepositoryNotReady = errors.New("repository not ready")
+
+	// ErrForbidden is returned when a resource exists but is not owned by the caller
+	// attempting to modify it (a short URL or an API key)
+	ErrForbidden = errors.New("caller does not own this resource")
+
+	// ErrURLBlocked is returned by Service.SaveURL and Service.SaveBatch when a configured
+	// Validator rejects a URL as unsafe (e.g. it matches a blocklist entry or is flagged by
+	// the Google Safe Browsing API)
+	ErrURLBlocked = errors.New("URL is blocked by safety policy")
 )
+
+// DeletedURLError wraps ErrShortURLIsDeleted with the data a caller needs to decide whether
+// a configured grace period still applies to the deletion: the original URL to redirect to,
+// and when the slug was deleted.
+type DeletedURLError struct {
+	OriginalURL string
+	DeletedAt   time.Time
+}
+
+func (e *DeletedURLError) Error() string {
+	return ErrShortURLIsDeleted.Error()
+}
+
+func (e *DeletedURLError) Unwrap() error {
+	return ErrShortURLIsDeleted
+}
@@ -0,0 +1,61 @@
+package ratelimit
+
+import (
+	"sync"
+
+	"github.com/ar4ie13/shortener/internal/clock"
+)
+
+// keyedLimiterCapacity bounds the number of distinct keys a KeyedLimiter tracks at once,
+// so a flood of distinct callers (user UUIDs, IPs) cannot grow its memory without bound.
+const keyedLimiterCapacity = 10000
+
+// KeyedLimiter maintains an independent token-bucket Limiter per key, so each caller is
+// throttled against its own budget instead of sharing one global bucket. A non-positive
+// rps disables limiting: Allow always returns true.
+type KeyedLimiter struct {
+	mu       sync.Mutex
+	clock    clock.Clock
+	rps      float64
+	limiters map[string]*Limiter
+	order    []string
+}
+
+// NewKeyedLimiter constructs a KeyedLimiter allowing up to rps requests per second for
+// each distinct key. clk provides the current time; a nil clk falls back to clock.RealClock.
+func NewKeyedLimiter(rps float64, clk clock.Clock) *KeyedLimiter {
+	if clk == nil {
+		clk = clock.RealClock{}
+	}
+
+	return &KeyedLimiter{
+		clock:    clk,
+		rps:      rps,
+		limiters: make(map[string]*Limiter),
+	}
+}
+
+// Allow reports whether a request for key is permitted under the current rate, consuming
+// a token from that key's own bucket if so. Keys are created lazily on first use and
+// evicted oldest-first once keyedLimiterCapacity is exceeded.
+func (k *KeyedLimiter) Allow(key string) bool {
+	if k.rps <= 0 {
+		return true
+	}
+
+	k.mu.Lock()
+	l, ok := k.limiters[key]
+	if !ok {
+		if len(k.order) >= keyedLimiterCapacity {
+			oldest := k.order[0]
+			k.order = k.order[1:]
+			delete(k.limiters, oldest)
+		}
+		l = NewLimiter(k.rps, k.clock)
+		k.limiters[key] = l
+		k.order = append(k.order, key)
+	}
+	k.mu.Unlock()
+
+	return l.Allow()
+}
@@ -0,0 +1,51 @@
+package ratelimit
+
+import (
+	"testing"
+	"time"
+
+	"github.com/ar4ie13/shortener/internal/clock"
+)
+
+func TestKeyedLimiter_Allow_UnlimitedWhenRPSNonPositive(t *testing.T) {
+	k := NewKeyedLimiter(0, nil)
+	for i := 0; i < 1000; i++ {
+		if !k.Allow("same-key") {
+			t.Fatalf("Allow() = false with non-positive rps, want always true")
+		}
+	}
+}
+
+func TestKeyedLimiter_Allow_ThrottlesPerKeyIndependently(t *testing.T) {
+	fakeClock := clock.NewFakeClock(time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC))
+	k := NewKeyedLimiter(1, fakeClock)
+
+	if !k.Allow("a") {
+		t.Fatalf("Allow(a) first call = false, want true")
+	}
+	if k.Allow("a") {
+		t.Fatalf("Allow(a) second call within the same second = true, want false")
+	}
+	if !k.Allow("b") {
+		t.Fatalf("Allow(b) first call = false, want true; keys must not share a bucket")
+	}
+}
+
+func TestKeyedLimiter_Allow_EvictsOldestKeyOverCapacity(t *testing.T) {
+	k := NewKeyedLimiter(1, nil)
+
+	for i := 0; i < keyedLimiterCapacity; i++ {
+		k.Allow(string(rune(i)))
+	}
+	if len(k.limiters) != keyedLimiterCapacity {
+		t.Fatalf("got %d tracked keys, want %d", len(k.limiters), keyedLimiterCapacity)
+	}
+
+	k.Allow("one-more")
+	if len(k.limiters) != keyedLimiterCapacity {
+		t.Fatalf("got %d tracked keys after eviction, want %d", len(k.limiters), keyedLimiterCapacity)
+	}
+	if _, ok := k.limiters[string(rune(0))]; ok {
+		t.Fatalf("oldest key was not evicted")
+	}
+}
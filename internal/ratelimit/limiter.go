@@ -0,0 +1,61 @@
+package ratelimit
+
+import (
+	"sync"
+	"time"
+
+	"github.com/ar4ie13/shortener/internal/clock"
+)
+
+// Limiter is a token-bucket rate limiter allowing up to rps requests per second, with
+// bursts bounded by the same rps. A non-positive rps disables limiting: Allow always
+// returns true.
+type Limiter struct {
+	mu         sync.Mutex
+	clock      clock.Clock
+	rps        float64
+	tokens     float64
+	lastRefill time.Time
+}
+
+// NewLimiter constructs a Limiter allowing up to rps requests per second. clk provides
+// the current time; a nil clk falls back to clock.RealClock.
+func NewLimiter(rps float64, clk clock.Clock) *Limiter {
+	if clk == nil {
+		clk = clock.RealClock{}
+	}
+
+	return &Limiter{
+		clock:      clk,
+		rps:        rps,
+		tokens:     rps,
+		lastRefill: clk.Now(),
+	}
+}
+
+// Allow reports whether a request is permitted under the current rate, consuming a token
+// if so.
+func (l *Limiter) Allow() bool {
+	if l.rps <= 0 {
+		return true
+	}
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	now := l.clock.Now()
+	elapsed := now.Sub(l.lastRefill).Seconds()
+	l.lastRefill = now
+
+	l.tokens += elapsed * l.rps
+	if l.tokens > l.rps {
+		l.tokens = l.rps
+	}
+
+	if l.tokens < 1 {
+		return false
+	}
+
+	l.tokens--
+	return true
+}
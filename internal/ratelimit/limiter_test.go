@@ -0,0 +1,34 @@
+package ratelimit
+
+import (
+	"testing"
+	"time"
+
+	"github.com/ar4ie13/shortener/internal/clock"
+)
+
+func TestLimiter_Allow_UnlimitedWhenRPSNonPositive(t *testing.T) {
+	l := NewLimiter(0, nil)
+	for i := 0; i < 1000; i++ {
+		if !l.Allow() {
+			t.Fatalf("Allow() = false with non-positive rps, want always true")
+		}
+	}
+}
+
+func TestLimiter_Allow_ThrottlesAboveRate(t *testing.T) {
+	fakeClock := clock.NewFakeClock(time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC))
+	l := NewLimiter(2, fakeClock)
+
+	if !l.Allow() || !l.Allow() {
+		t.Fatalf("Allow() = false within initial burst, want true")
+	}
+	if l.Allow() {
+		t.Fatalf("Allow() = true after burst exhausted, want false")
+	}
+
+	fakeClock.Advance(time.Second)
+	if !l.Allow() {
+		t.Fatalf("Allow() = false after tokens replenished, want true")
+	}
+}
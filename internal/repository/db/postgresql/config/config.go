@@ -1,6 +1,30 @@
 package config
 
+import "time"
+
 // Config contain DSN for postgres database connection
 type Config struct {
 	DatabaseDSN string
+	// ReadDSN, when set, points at a read replica used for GetURL, GetShortURL and
+	// GetUserShortURLs. Writes and every other method always use DatabaseDSN. A read query
+	// falls back to DatabaseDSN if the replica itself is unreachable.
+	ReadDSN string
+	// HashOriginalURL, when true, dedups and looks up rows by sha256(original_url) instead of
+	// original_url itself, so a URL too long for a btree index entry can still be saved
+	HashOriginalURL bool
+	// MaxConns is the maximum number of connections the pool keeps open. 0 uses pgxpool's
+	// own default (4x GOMAXPROCS).
+	MaxConns int32
+	// MinConns is the minimum number of idle connections the pool keeps warm. 0 uses
+	// pgxpool's own default (0).
+	MinConns int32
+	// MaxConnLifetime is the maximum age of a connection before it is closed and replaced.
+	// 0 uses pgxpool's own default (1 hour).
+	MaxConnLifetime time.Duration
+	// MaxConnIdleTime is the maximum time a connection may sit idle before it is closed.
+	// 0 uses pgxpool's own default (30 minutes).
+	MaxConnIdleTime time.Duration
+	// HealthCheckPeriod is how often idle connections are health-checked. 0 uses pgxpool's
+	// own default (1 minute).
+	HealthCheckPeriod time.Duration
 }
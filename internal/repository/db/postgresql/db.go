@@ -2,47 +2,97 @@ package postgresql
 
 import (
 	"context"
+	"crypto/sha256"
+	"database/sql"
+	"encoding/hex"
 	"errors"
 	"fmt"
-	"strings"
+	"net/url"
+	"sort"
 	"time"
 
 	"github.com/ar4ie13/shortener/internal/model"
 	"github.com/ar4ie13/shortener/internal/myerrors"
 	"github.com/ar4ie13/shortener/internal/repository/db/postgresql/config"
+	"github.com/ar4ie13/shortener/internal/requestlog"
 	"github.com/google/uuid"
 	"github.com/jackc/pgerrcode"
 	"github.com/jackc/pgx/v5"
 	"github.com/jackc/pgx/v5/pgconn"
 	"github.com/jackc/pgx/v5/pgxpool"
 	_ "github.com/jackc/pgx/v5/stdlib"
+	"github.com/jackc/pgx/v5/tracelog"
 	"github.com/rs/zerolog"
 )
 
+// defaultBackfillLimit bounds a single BackfillHosts call when the caller passes no limit
+const defaultBackfillLimit = 1000
+
 // DB is a main postgres repository object
 type DB struct {
-	pool *pgxpool.Pool
-	zlog zerolog.Logger
+	pool            *pgxpool.Pool
+	replicaPool     *pgxpool.Pool
+	zlog            zerolog.Logger
+	hashOriginalURL bool
 }
 
 // NewDB construct postgres DB object
 func NewDB(ctx context.Context, cfg config.Config, zlog zerolog.Logger) (*DB, error) {
-	pool, err := initPool(ctx, cfg)
+	pool, err := initPool(ctx, cfg, zlog)
 	if err != nil {
 		return nil, fmt.Errorf("failed to initialize a connection pool: %w", err)
 	}
+
+	var replicaPool *pgxpool.Pool
+	if cfg.ReadDSN != "" {
+		replicaCfg := cfg
+		replicaCfg.DatabaseDSN = cfg.ReadDSN
+		replicaPool, err = initPool(ctx, replicaCfg, zlog)
+		if err != nil {
+			pool.Close()
+			return nil, fmt.Errorf("failed to initialize a read replica connection pool: %w", err)
+		}
+	}
+
 	return &DB{
-		pool: pool,
-		zlog: zlog,
+		pool:            pool,
+		replicaPool:     replicaPool,
+		zlog:            zlog,
+		hashOriginalURL: cfg.HashOriginalURL,
 	}, nil
 }
 
-// initPool initializes pgx connection pool
-func initPool(ctx context.Context, cfg config.Config) (*pgxpool.Pool, error) {
+// initPool initializes pgx connection pool, applying the pool tuning settings from cfg and
+// attaching a query tracer that logs through zlog when debug logging is enabled
+func initPool(ctx context.Context, cfg config.Config, zlog zerolog.Logger) (*pgxpool.Pool, error) {
 	poolCfg, err := pgxpool.ParseConfig(cfg.DatabaseDSN)
 	if err != nil {
 		return nil, fmt.Errorf("failed to parse the DSN: %w", err)
 	}
+
+	if cfg.MaxConns > 0 {
+		poolCfg.MaxConns = cfg.MaxConns
+	}
+	if cfg.MinConns > 0 {
+		poolCfg.MinConns = cfg.MinConns
+	}
+	if cfg.MaxConnLifetime > 0 {
+		poolCfg.MaxConnLifetime = cfg.MaxConnLifetime
+	}
+	if cfg.MaxConnIdleTime > 0 {
+		poolCfg.MaxConnIdleTime = cfg.MaxConnIdleTime
+	}
+	if cfg.HealthCheckPeriod > 0 {
+		poolCfg.HealthCheckPeriod = cfg.HealthCheckPeriod
+	}
+
+	if zlog.GetLevel() <= zerolog.DebugLevel {
+		poolCfg.ConnConfig.Tracer = &tracelog.TraceLog{
+			Logger:   zerologTracer{zlog: zlog},
+			LogLevel: tracelog.LogLevelDebug,
+		}
+	}
+
 	pool, err := pgxpool.NewWithConfig(ctx, poolCfg)
 	if err != nil {
 		return nil, fmt.Errorf("failed to initialize a connection pool: %w", err)
@@ -53,26 +103,126 @@ func initPool(ctx context.Context, cfg config.Config) (*pgxpool.Pool, error) {
 	return pool, nil
 }
 
-// Close closes pgx pool
+// zerologTracer adapts zerolog to tracelog.Logger so pgx query tracing, when enabled, flows
+// through the same structured logger as the rest of the application
+type zerologTracer struct {
+	zlog zerolog.Logger
+}
+
+// Log implements tracelog.Logger
+func (t zerologTracer) Log(_ context.Context, level tracelog.LogLevel, msg string, data map[string]any) {
+	var event *zerolog.Event
+	switch level {
+	case tracelog.LogLevelTrace, tracelog.LogLevelDebug:
+		event = t.zlog.Debug()
+	case tracelog.LogLevelInfo:
+		event = t.zlog.Info()
+	case tracelog.LogLevelWarn:
+		event = t.zlog.Warn()
+	case tracelog.LogLevelError:
+		event = t.zlog.Error()
+	default:
+		event = t.zlog.Debug()
+	}
+	event.Fields(data).Msg(msg)
+}
+
+// Close closes the primary pool and, if configured, the read replica pool
 func (db *DB) Close() error {
 	db.pool.Close()
+	if db.replicaPool != nil {
+		db.replicaPool.Close()
+	}
 	return nil
 }
 
+// ready reports myerrors.ErrRepositoryNotReady if db was constructed without NewDB (e.g. a
+// zero-value DB{}), so callers get a typed error instead of a nil-pointer panic
+func (db *DB) ready() error {
+	if db.pool == nil {
+		return myerrors.ErrRepositoryNotReady
+	}
+	return nil
+}
+
+// isReplicaDown reports whether err indicates the replica connection itself is unusable
+// (e.g. it is unreachable or the context deadline expired), as opposed to a normal
+// query-level outcome like pgx.ErrNoRows or a constraint violation, which the replica
+// answering at all rules out
+func isReplicaDown(err error) bool {
+	if errors.Is(err, pgx.ErrNoRows) {
+		return false
+	}
+	var pgErr *pgconn.PgError
+	return !errors.As(err, &pgErr)
+}
+
+// readPool returns the pool that read-only queries should use: the replica pool, if one is
+// configured, otherwise the primary pool
+func (db *DB) readPool() *pgxpool.Pool {
+	if db.replicaPool != nil {
+		return db.replicaPool
+	}
+	return db.pool
+}
+
+// queryRowRead runs queryStmt as a QueryRow against the replica pool (if configured) and
+// scans the result via scan, retrying against the primary pool if the replica itself
+// appears to be down
+func (db *DB) queryRowRead(ctx context.Context, scan func(pgx.Row) error, queryStmt string, args ...any) error {
+	pool := db.readPool()
+	err := scan(pool.QueryRow(ctx, queryStmt, args...))
+	if err != nil && pool == db.replicaPool && isReplicaDown(err) {
+		requestlog.FromContext(ctx, db.zlog).Warn().Err(err).Msg("read replica unreachable, falling back to primary")
+		err = scan(db.pool.QueryRow(ctx, queryStmt, args...))
+	}
+	return err
+}
+
+// queryRead runs queryStmt as a Query against the replica pool (if configured), retrying
+// against the primary pool if the replica itself appears to be down
+func (db *DB) queryRead(ctx context.Context, queryStmt string, args ...any) (pgx.Rows, error) {
+	pool := db.readPool()
+	rows, err := pool.Query(ctx, queryStmt, args...)
+	if err != nil && pool == db.replicaPool && isReplicaDown(err) {
+		requestlog.FromContext(ctx, db.zlog).Warn().Err(err).Msg("read replica unreachable, falling back to primary")
+		rows, err = db.pool.Query(ctx, queryStmt, args...)
+	}
+	return rows, err
+}
+
+// sha256Hex returns the hex-encoded sha256 digest of s, used as a fixed-width stand-in for
+// original_url so dedup and lookup stay on a btree index regardless of how long the URL is
+func sha256Hex(s string) string {
+	sum := sha256.Sum256([]byte(s))
+	return hex.EncodeToString(sum[:])
+}
+
 // GetShortURL gets short_url from db by provided URL
 func (db *DB) GetShortURL(ctx context.Context, originalURL string) (shortURL string, err error) {
+	if err := db.ready(); err != nil {
+		return "", err
+	}
 
-	const queryStmt = `SELECT short_url FROM urls WHERE original_url = $1`
+	var (
+		queryStmt string
+		arg       string
+	)
+	if db.hashOriginalURL {
+		queryStmt = `SELECT short_url FROM urls WHERE original_url_hash = $1`
+		arg = sha256Hex(originalURL)
+	} else {
+		queryStmt = `SELECT short_url FROM urls WHERE original_url = $1`
+		arg = originalURL
+	}
 
 	start := time.Now()
 	defer func() {
 		elapsed := time.Since(start)
-		db.zlog.Debug().Msgf("request execution duration: %s", elapsed)
+		requestlog.FromContext(ctx, db.zlog).Debug().Msgf("request execution duration: %s", elapsed)
 	}()
 
-	row := db.pool.QueryRow(ctx, queryStmt, originalURL)
-
-	err = row.Scan(&shortURL)
+	err = db.queryRowRead(ctx, func(row pgx.Row) error { return row.Scan(&shortURL) }, queryStmt, arg)
 	if err != nil {
 		switch {
 		case errors.Is(err, pgx.ErrNoRows):
@@ -85,20 +235,27 @@ func (db *DB) GetShortURL(ctx context.Context, originalURL string) (shortURL str
 	return shortURL, nil
 }
 
-// GetURL gets URL by provided shortURL
-func (db *DB) GetURL(ctx context.Context, shortURL string) (originalURL string, err error) {
+// GetURL gets URL by provided shortURL. When requireOwnership is true, a shortURL not owned
+// by userUUID is reported as myerrors.ErrNotFound, same as a slug that does not exist.
+func (db *DB) GetURL(ctx context.Context, userUUID uuid.UUID, shortURL string, requireOwnership bool) (originalURL string, err error) {
+	if err := db.ready(); err != nil {
+		return "", err
+	}
+
 	var isDeleted bool
-	const queryStmt = `SELECT original_url, is_deleted FROM urls WHERE short_url = $1`
+	var deletedAt *time.Time
+	var owner uuid.UUID
+	const queryStmt = `SELECT original_url, is_deleted, deleted_at, user_uuid FROM urls WHERE short_url = $1`
 
 	start := time.Now()
 	defer func() {
 		elapsed := time.Since(start)
-		db.zlog.Debug().Msgf("request execution duration: %s", elapsed)
+		requestlog.FromContext(ctx, db.zlog).Debug().Msgf("request execution duration: %s", elapsed)
 	}()
 
-	row := db.pool.QueryRow(ctx, queryStmt, shortURL)
-
-	err = row.Scan(&originalURL, &isDeleted)
+	err = db.queryRowRead(ctx, func(row pgx.Row) error {
+		return row.Scan(&originalURL, &isDeleted, &deletedAt, &owner)
+	}, queryStmt, shortURL)
 	if err != nil {
 		switch {
 		case errors.Is(err, pgx.ErrNoRows):
@@ -107,52 +264,177 @@ func (db *DB) GetURL(ctx context.Context, shortURL string) (originalURL string,
 			return "", fmt.Errorf("failed to scan a response row: %w", err)
 		}
 	}
+	if requireOwnership && owner != userUUID {
+		return "", myerrors.ErrNotFound
+	}
 	if isDeleted {
-		return "", myerrors.ErrShortURLIsDeleted
+		return "", &myerrors.DeletedURLError{OriginalURL: originalURL, DeletedAt: timeOrZero(deletedAt)}
 	}
 
 	return originalURL, nil
 }
 
-// Save saves tuple with shortURL, URL and UUID
-func (db *DB) Save(ctx context.Context, userUUID uuid.UUID, shortURL string, originalURL string) error {
+// GetURLs resolves many slugs in one round trip using a single SQL query against the urls
+// table, returning one model.URLExpansion per entry in shortURLs, in the same order. It
+// prefers the read replica the same way GetURL does.
+func (db *DB) GetURLs(ctx context.Context, userUUID uuid.UUID, shortURLs []string, requireOwnership bool) ([]model.URLExpansion, error) {
+	if err := db.ready(); err != nil {
+		return nil, err
+	}
+
+	result := make([]model.URLExpansion, len(shortURLs))
+	for i, shortURL := range shortURLs {
+		result[i] = model.URLExpansion{ShortURL: shortURL}
+	}
+	if len(shortURLs) == 0 {
+		return result, nil
+	}
+
+	const queryStmt = `SELECT short_url, original_url, is_deleted, deleted_at, user_uuid FROM urls WHERE short_url = ANY($1)`
+
+	start := time.Now()
+	defer func() {
+		elapsed := time.Since(start)
+		requestlog.FromContext(ctx, db.zlog).Debug().Msgf("request execution duration: %s", elapsed)
+	}()
+
+	rows, err := db.queryRead(ctx, queryStmt, shortURLs)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query URLs: %w", err)
+	}
+	defer rows.Close()
+
+	byShortURL := make(map[string]model.URLExpansion, len(shortURLs))
+	for rows.Next() {
+		var shortURL, originalURL string
+		var isDeleted bool
+		var deletedAt *time.Time
+		var owner uuid.UUID
+		if err = rows.Scan(&shortURL, &originalURL, &isDeleted, &deletedAt, &owner); err != nil {
+			return nil, fmt.Errorf("failed to scan a response row: %w", err)
+		}
+		if requireOwnership && owner != userUUID {
+			continue
+		}
+		exp := model.URLExpansion{ShortURL: shortURL, OriginalURL: originalURL}
+		if isDeleted {
+			exp.IsDeleted = true
+			exp.DeletedAt = timeOrZero(deletedAt)
+		}
+		byShortURL[shortURL] = exp
+	}
+	if err = rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to query URLs: %w", err)
+	}
+
+	for i, shortURL := range shortURLs {
+		if exp, ok := byShortURL[shortURL]; ok {
+			result[i] = exp
+			continue
+		}
+		result[i].Err = myerrors.ErrNotFound.Error()
+	}
+
+	return result, nil
+}
+
+// timeOrZero converts a nullable timestamp into a time.Time, returning the zero time for NULL
+func timeOrZero(t *time.Time) time.Time {
+	if t == nil {
+		return time.Time{}
+	}
+	return *t
+}
+
+// Save saves tuple with shortURL, URL and UUID. When hashOriginalURL is enabled, a duplicate
+// originalURL is resolved as an upsert: the INSERT is retried as a no-op against the
+// original_url_hash unique index and the slug already holding originalURL is fetched in the
+// same round-trip, so the caller does not need a separate GetShortURL lookup. Without
+// hashOriginalURL there is no unique constraint on original_url to upsert against, so a
+// duplicate original URL is simply not detected by this method, as before.
+func (db *DB) Save(ctx context.Context, userUUID uuid.UUID, shortURL string, originalURL string, referer string, userAgent string, expiresAt time.Time, createdAt time.Time) (string, error) {
+	if err := db.ready(); err != nil {
+		return "", err
+	}
 
 	if shortURL == "" || originalURL == "" {
-		return myerrors.ErrEmptyShortURLorURL
+		return "", myerrors.ErrEmptyShortURLorURL
 	}
 
 	const (
-		queryStmtInsert = `INSERT INTO urls(short_url, original_url, user_uuid) VALUES ($1, $2, $3)`
+		queryStmtInsert     = `INSERT INTO urls(short_url, original_url, user_uuid, referer, user_agent, expires_at, host, created_at, updated_at) VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $8)`
+		queryStmtUpsertHash = `WITH ins AS (
+			INSERT INTO urls(short_url, original_url, original_url_hash, user_uuid, referer, user_agent, expires_at, host, created_at, updated_at)
+			VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $9)
+			ON CONFLICT (original_url_hash) WHERE original_url_hash IS NOT NULL DO NOTHING
+			RETURNING short_url
+		)
+		SELECT short_url FROM ins
+		UNION ALL
+		SELECT short_url FROM urls WHERE original_url_hash = $3 AND NOT EXISTS (SELECT 1 FROM ins)`
 	)
 
 	start := time.Now()
 	defer func() {
 		elapsed := time.Since(start)
-		db.zlog.Debug().Msgf("request execution duration: %s", elapsed)
+		requestlog.FromContext(ctx, db.zlog).Debug().Msgf("request execution duration: %s", elapsed)
 	}()
 
-	_, err := db.pool.Exec(ctx, queryStmtInsert, shortURL, originalURL, userUUID)
+	var err error
+	var existingShortURL string
+	if db.hashOriginalURL {
+		err = db.pool.QueryRow(ctx, queryStmtUpsertHash, shortURL, originalURL, sha256Hex(originalURL), userUUID, referer, userAgent, pgTimestamp(expiresAt), hostOf(originalURL), pgTimestamp(createdAt)).Scan(&existingShortURL)
+	} else {
+		_, err = db.pool.Exec(ctx, queryStmtInsert, shortURL, originalURL, userUUID, referer, userAgent, pgTimestamp(expiresAt), hostOf(originalURL), pgTimestamp(createdAt))
+	}
 	if err != nil {
 		var pgErr *pgconn.PgError
 		if errors.As(err, &pgErr) && pgErr.Code == pgerrcode.UniqueViolation {
-			switch {
-			case strings.Contains(err.Error(), "urls_original_url_key"):
-				return fmt.Errorf("error while saving URL %s: %w", originalURL, myerrors.ErrURLExist)
-			case strings.Contains(err.Error(), "urls_short_url_key"):
-				return fmt.Errorf("error while saving URL %s: %w", shortURL, myerrors.ErrShortURLExist)
-			}
+			return "", fmt.Errorf("error while saving URL %s: %w", shortURL, myerrors.ErrShortURLExist)
 		}
-		return fmt.Errorf("failed to save URL: %w", err)
+		return "", fmt.Errorf("failed to save URL: %w", err)
 	}
 
-	db.zlog.Debug().Msgf("saved URL: %s", shortURL)
+	if db.hashOriginalURL && existingShortURL != shortURL {
+		return existingShortURL, fmt.Errorf("error while saving URL %s: %w", originalURL, myerrors.ErrURLExist)
+	}
 
-	return nil
+	requestlog.FromContext(ctx, db.zlog).Debug().Msgf("saved URL: %s", shortURL)
+
+	return "", nil
 }
 
-// SaveBatch performs bulk insert to postgres database
-func (db *DB) SaveBatch(ctx context.Context, userUUID uuid.UUID, batch []model.URL) error {
-	query := `INSERT INTO urls (uuid, short_url, original_url, user_uuid) VALUES (@uuid, @shortURL, @originalURL, @userUUID)`
+// SaveBatch performs a bulk insert to postgres inside a single transaction, so a row that
+// fails to insert leaves none of the batch committed, rather than the earlier pgx.Batch
+// behavior of leaving rows before the failure committed and rows after it unreported. When
+// hashOriginalURL is enabled, each row is upserted the same way Save is: a row whose
+// original_url was already saved under a different slug is reported as a conflict in the
+// returned slice instead of aborting the rest of the batch. Without hashOriginalURL there is no
+// unique constraint on original_url to upsert against, so every entry in the returned slice is
+// empty, as before.
+func (db *DB) SaveBatch(ctx context.Context, userUUID uuid.UUID, batch []model.URL) ([]string, error) {
+	if err := db.ready(); err != nil {
+		return nil, err
+	}
+
+	const (
+		queryStmtInsert = `INSERT INTO urls (uuid, short_url, original_url, user_uuid, referer, user_agent, expires_at, host, created_at, updated_at) VALUES (@uuid, @shortURL, @originalURL, @userUUID, @referer, @userAgent, @expiresAt, @host, @createdAt, @updatedAt)`
+		queryStmtUpsertHash = `WITH ins AS (
+			INSERT INTO urls (uuid, short_url, original_url, original_url_hash, user_uuid, referer, user_agent, expires_at, host, created_at, updated_at)
+			VALUES (@uuid, @shortURL, @originalURL, @originalURLHash, @userUUID, @referer, @userAgent, @expiresAt, @host, @createdAt, @updatedAt)
+			ON CONFLICT (original_url_hash) WHERE original_url_hash IS NOT NULL DO NOTHING
+			RETURNING short_url
+		)
+		SELECT short_url FROM ins
+		UNION ALL
+		SELECT short_url FROM urls WHERE original_url_hash = @originalURLHash AND NOT EXISTS (SELECT 1 FROM ins)`
+	)
+
+	tx, err := db.pool.Begin(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to begin batch transaction: %w", err)
+	}
+	defer tx.Rollback(ctx)
 
 	insertBatch := &pgx.Batch{}
 	for _, v := range batch {
@@ -161,56 +443,89 @@ func (db *DB) SaveBatch(ctx context.Context, userUUID uuid.UUID, batch []model.U
 			"shortURL":    v.ShortURL,
 			"originalURL": v.OriginalURL,
 			"userUUID":    userUUID,
+			"referer":     v.Referer,
+			"userAgent":   v.UserAgent,
+			"expiresAt":   pgTimestamp(v.ExpiresAt),
+			"host":        hostOf(v.OriginalURL),
+			"createdAt":   pgTimestamp(v.CreatedAt),
+			"updatedAt":   pgTimestamp(v.UpdatedAt),
 		}
-		insertBatch.Queue(query, args)
+		if db.hashOriginalURL {
+			args["originalURLHash"] = sha256Hex(v.OriginalURL)
+			insertBatch.Queue(queryStmtUpsertHash, args)
+			continue
+		}
+		insertBatch.Queue(queryStmtInsert, args)
 	}
 
-	results := db.pool.SendBatch(ctx, insertBatch)
-	defer results.Close()
+	results := tx.SendBatch(ctx, insertBatch)
 
-	for _, v := range batch {
-		_, err := results.Exec()
-		if err != nil {
+	existing := make([]string, len(batch))
+	for i, v := range batch {
+		if db.hashOriginalURL {
+			var existingShortURL string
+			if err = results.QueryRow().Scan(&existingShortURL); err != nil {
+				_ = results.Close()
+				return nil, fmt.Errorf("unable to insert row: %w", err)
+			}
+			if existingShortURL != v.ShortURL {
+				existing[i] = existingShortURL
+			}
+			continue
+		}
+		if _, err = results.Exec(); err != nil {
+			_ = results.Close()
 			var pgErr *pgconn.PgError
 			if errors.As(err, &pgErr) && pgErr.Code == pgerrcode.UniqueViolation {
-				return fmt.Errorf("error while saving URL %s: %w", v.OriginalURL, err)
+				return nil, fmt.Errorf("error while saving URL %s: %w", v.OriginalURL, err)
 			}
-			return fmt.Errorf("unable to insert row: %w", err)
+			return nil, fmt.Errorf("unable to insert row: %w", err)
 		}
 	}
 
-	return results.Close()
+	if err = results.Close(); err != nil {
+		return nil, fmt.Errorf("unable to insert row: %w", err)
+	}
+
+	if err = tx.Commit(ctx); err != nil {
+		return nil, fmt.Errorf("failed to commit batch transaction: %w", err)
+	}
+
+	return existing, nil
 }
 
 // GetUserShortURLs get slugs from db for the provider userUUID
-func (db *DB) GetUserShortURLs(ctx context.Context, userUUID uuid.UUID) (map[string]string, error) {
-	const queryStmt = `SELECT short_url, original_url FROM urls WHERE user_uuid = $1 and is_deleted = false`
+func (db *DB) GetUserShortURLs(ctx context.Context, userUUID uuid.UUID) ([]model.URL, error) {
+	if err := db.ready(); err != nil {
+		return nil, err
+	}
+
+	const queryStmt = `SELECT short_url, original_url, referer, user_agent, host, created_at, updated_at FROM urls WHERE user_uuid = $1 and is_deleted = false`
 
 	start := time.Now()
 	defer func() {
 		elapsed := time.Since(start)
-		db.zlog.Debug().Msgf("request execution duration: %s", elapsed)
+		requestlog.FromContext(ctx, db.zlog).Debug().Msgf("request execution duration: %s", elapsed)
 	}()
 
-	rows, err := db.pool.Query(ctx, queryStmt, userUUID)
+	rows, err := db.queryRead(ctx, queryStmt, userUUID)
 	if err != nil {
 		return nil, err
 	}
 
-	//if !rows.Next() {
-	//	return nil, service.ErrNotFound
-	//}
-
-	userShortURLs := make(map[string]string)
+	var userShortURLs []model.URL
 	for rows.Next() {
-		var shortURL string
-		var originalURL string
+		var u model.URL
+		var createdAt, updatedAt *time.Time
 
-		err = rows.Scan(&shortURL, &originalURL)
+		err = rows.Scan(&u.ShortURL, &u.OriginalURL, &u.Referer, &u.UserAgent, &u.Host, &createdAt, &updatedAt)
 		if err != nil {
 			return nil, err
 		}
-		userShortURLs[shortURL] = originalURL
+		u.UserUUID = userUUID
+		u.CreatedAt = timeOrZero(createdAt)
+		u.UpdatedAt = timeOrZero(updatedAt)
+		userShortURLs = append(userShortURLs, u)
 	}
 
 	err = rows.Err()
@@ -225,8 +540,12 @@ func (db *DB) GetUserShortURLs(ctx context.Context, userUUID uuid.UUID) (map[str
 	return userShortURLs, nil
 }
 
-// DeleteUserShortURLs prepares batch for update IsDeleted field in db from false to true if exists
-func (db *DB) DeleteUserShortURLs(ctx context.Context, shortURLsToDelete map[uuid.UUID][]string) error {
+// DeleteUserShortURLs prepares batch for update IsDeleted field in db from false to true if
+// exists, stamping deletedAt as the deletion time
+func (db *DB) DeleteUserShortURLs(ctx context.Context, shortURLsToDelete map[uuid.UUID][]string, deletedAt time.Time) error {
+	if err := db.ready(); err != nil {
+		return err
+	}
 
 	ctx, cancel := context.WithTimeout(ctx, 15*time.Second)
 	defer cancel()
@@ -235,13 +554,14 @@ func (db *DB) DeleteUserShortURLs(ctx context.Context, shortURLsToDelete map[uui
 		return nil
 	}
 
-	query := `UPDATE urls SET is_deleted = true WHERE short_url = @shortURL AND user_uuid = @userUUID`
+	query := `UPDATE urls SET is_deleted = true, deleted_at = @deletedAt WHERE short_url = @shortURL AND user_uuid = @userUUID`
 	insertBatch := &pgx.Batch{}
 	for k, v := range shortURLsToDelete {
 		for i := range v {
 			args := pgx.NamedArgs{
-				"userUUID": k,
-				"shortURL": v[i],
+				"userUUID":  k,
+				"shortURL":  v[i],
+				"deletedAt": deletedAt,
 			}
 			insertBatch.Queue(query, args)
 		}
@@ -259,3 +579,561 @@ func (db *DB) DeleteUserShortURLs(ctx context.Context, shortURLsToDelete map[uui
 
 	return results.Close()
 }
+
+// UpdateURL changes the destination for shortURL, owned by userUUID, to newURL. It returns
+// myerrors.ErrNotFound if shortURL does not exist, myerrors.ErrForbidden if it exists but is
+// not owned by userUUID, myerrors.ErrShortURLIsDeleted if it has been soft-deleted, or
+// myerrors.ErrURLExist if another short URL already points at newURL.
+func (db *DB) UpdateURL(ctx context.Context, userUUID uuid.UUID, shortURL string, newURL string, updatedAt time.Time) error {
+	if err := db.ready(); err != nil {
+		return err
+	}
+
+	const (
+		queryStmt     = `UPDATE urls SET original_url = $1, host = $2, updated_at = $3 WHERE short_url = $4 AND user_uuid = $5 AND is_deleted = false`
+		queryStmtHash = `UPDATE urls SET original_url = $1, original_url_hash = $2, host = $3, updated_at = $4 WHERE short_url = $5 AND user_uuid = $6 AND is_deleted = false`
+		ownerStmt     = `SELECT user_uuid, is_deleted FROM urls WHERE short_url = $1`
+	)
+
+	start := time.Now()
+	defer func() {
+		elapsed := time.Since(start)
+		requestlog.FromContext(ctx, db.zlog).Debug().Msgf("request execution duration: %s", elapsed)
+	}()
+
+	var tag pgconn.CommandTag
+	var err error
+	if db.hashOriginalURL {
+		tag, err = db.pool.Exec(ctx, queryStmtHash, newURL, sha256Hex(newURL), hostOf(newURL), pgTimestamp(updatedAt), shortURL, userUUID)
+	} else {
+		tag, err = db.pool.Exec(ctx, queryStmt, newURL, hostOf(newURL), pgTimestamp(updatedAt), shortURL, userUUID)
+	}
+	if err != nil {
+		var pgErr *pgconn.PgError
+		if errors.As(err, &pgErr) && pgErr.Code == pgerrcode.UniqueViolation {
+			return fmt.Errorf("error while updating URL %s: %w", shortURL, myerrors.ErrURLExist)
+		}
+		return fmt.Errorf("failed to update URL: %w", err)
+	}
+
+	if tag.RowsAffected() == 0 {
+		var owner uuid.UUID
+		var isDeleted bool
+		row := db.pool.QueryRow(ctx, ownerStmt, shortURL)
+		if scanErr := row.Scan(&owner, &isDeleted); scanErr != nil {
+			if errors.Is(scanErr, pgx.ErrNoRows) {
+				return myerrors.ErrNotFound
+			}
+			return fmt.Errorf("failed to update URL: %w", scanErr)
+		}
+		if isDeleted {
+			return myerrors.ErrShortURLIsDeleted
+		}
+		return myerrors.ErrForbidden
+	}
+
+	requestlog.FromContext(ctx, db.zlog).Debug().Msgf("updated URL: %s", shortURL)
+
+	return nil
+}
+
+// pgTimestamp converts a zero time.Time into a nil so that expires_at is stored as NULL
+// for links that never expire
+func pgTimestamp(t time.Time) *time.Time {
+	if t.IsZero() {
+		return nil
+	}
+	return &t
+}
+
+// SweepExpired marks up to limit not-yet-deleted rows as deleted if their expires_at
+// is before the given time, returning how many rows were affected
+func (db *DB) SweepExpired(ctx context.Context, before time.Time, limit int) (int, error) {
+	if err := db.ready(); err != nil {
+		return 0, err
+	}
+
+	const queryStmt = `
+		UPDATE urls SET is_deleted = true, deleted_at = $1
+		WHERE short_url IN (
+			SELECT short_url FROM urls
+			WHERE expires_at IS NOT NULL AND expires_at < $1 AND is_deleted = false
+			LIMIT $2
+		)`
+
+	start := time.Now()
+	defer func() {
+		elapsed := time.Since(start)
+		requestlog.FromContext(ctx, db.zlog).Debug().Msgf("request execution duration: %s", elapsed)
+	}()
+
+	tag, err := db.pool.Exec(ctx, queryStmt, before, limit)
+	if err != nil {
+		return 0, fmt.Errorf("failed to sweep expired URLs: %w", err)
+	}
+
+	return int(tag.RowsAffected()), nil
+}
+
+// PurgeDeleted permanently removes up to limit soft-deleted rows whose deleted_at is
+// before the given time, returning how many rows were removed
+func (db *DB) PurgeDeleted(ctx context.Context, before time.Time, limit int) (int, error) {
+	if err := db.ready(); err != nil {
+		return 0, err
+	}
+
+	const queryStmt = `
+		DELETE FROM urls
+		WHERE short_url IN (
+			SELECT short_url FROM urls
+			WHERE is_deleted = true AND deleted_at < $1
+			LIMIT $2
+		)`
+
+	start := time.Now()
+	defer func() {
+		elapsed := time.Since(start)
+		requestlog.FromContext(ctx, db.zlog).Debug().Msgf("request execution duration: %s", elapsed)
+	}()
+
+	tag, err := db.pool.Exec(ctx, queryStmt, before, limit)
+	if err != nil {
+		return 0, fmt.Errorf("failed to purge deleted URLs: %w", err)
+	}
+
+	return int(tag.RowsAffected()), nil
+}
+
+// SetPasswordedSlug stores passwordHash as the bcrypt hash guarding shortURL
+func (db *DB) SetPasswordedSlug(ctx context.Context, shortURL string, passwordHash string) error {
+	if err := db.ready(); err != nil {
+		return err
+	}
+
+	const queryStmt = `UPDATE urls SET password_hash = $1 WHERE short_url = $2`
+
+	start := time.Now()
+	defer func() {
+		elapsed := time.Since(start)
+		requestlog.FromContext(ctx, db.zlog).Debug().Msgf("request execution duration: %s", elapsed)
+	}()
+
+	tag, err := db.pool.Exec(ctx, queryStmt, passwordHash, shortURL)
+	if err != nil {
+		return fmt.Errorf("failed to set password hash for %s: %w", shortURL, err)
+	}
+	if tag.RowsAffected() == 0 {
+		return fmt.Errorf("failed to set password hash for %s: %w", shortURL, myerrors.ErrNotFound)
+	}
+
+	return nil
+}
+
+// GetPasswordHash returns the bcrypt hash guarding shortURL, or "" if it is not password-protected
+func (db *DB) GetPasswordHash(ctx context.Context, shortURL string) (string, error) {
+	if err := db.ready(); err != nil {
+		return "", err
+	}
+
+	const queryStmt = `SELECT password_hash FROM urls WHERE short_url = $1`
+
+	start := time.Now()
+	defer func() {
+		elapsed := time.Since(start)
+		requestlog.FromContext(ctx, db.zlog).Debug().Msgf("request execution duration: %s", elapsed)
+	}()
+
+	var passwordHash sql.NullString
+	row := db.pool.QueryRow(ctx, queryStmt, shortURL)
+	if err := row.Scan(&passwordHash); err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return "", myerrors.ErrNotFound
+		}
+		return "", fmt.Errorf("failed to get password hash for %s: %w", shortURL, err)
+	}
+
+	return passwordHash.String, nil
+}
+
+// SetRedirectStatus stores status as the per-link override of the HTTP status used when
+// redirecting shortURL
+func (db *DB) SetRedirectStatus(ctx context.Context, shortURL string, status int) error {
+	if err := db.ready(); err != nil {
+		return err
+	}
+
+	const queryStmt = `UPDATE urls SET redirect_status = $1 WHERE short_url = $2`
+
+	start := time.Now()
+	defer func() {
+		elapsed := time.Since(start)
+		requestlog.FromContext(ctx, db.zlog).Debug().Msgf("request execution duration: %s", elapsed)
+	}()
+
+	tag, err := db.pool.Exec(ctx, queryStmt, status, shortURL)
+	if err != nil {
+		return fmt.Errorf("failed to set redirect status for %s: %w", shortURL, err)
+	}
+	if tag.RowsAffected() == 0 {
+		return fmt.Errorf("failed to set redirect status for %s: %w", shortURL, myerrors.ErrNotFound)
+	}
+
+	return nil
+}
+
+// GetRedirectStatus returns the per-link redirect status override for shortURL, or 0 if it
+// has none
+func (db *DB) GetRedirectStatus(ctx context.Context, shortURL string) (int, error) {
+	if err := db.ready(); err != nil {
+		return 0, err
+	}
+
+	const queryStmt = `SELECT redirect_status FROM urls WHERE short_url = $1`
+
+	start := time.Now()
+	defer func() {
+		elapsed := time.Since(start)
+		requestlog.FromContext(ctx, db.zlog).Debug().Msgf("request execution duration: %s", elapsed)
+	}()
+
+	var redirectStatus sql.NullInt32
+	row := db.pool.QueryRow(ctx, queryStmt, shortURL)
+	if err := row.Scan(&redirectStatus); err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return 0, myerrors.ErrNotFound
+		}
+		return 0, fmt.Errorf("failed to get redirect status for %s: %w", shortURL, err)
+	}
+
+	return int(redirectStatus.Int32), nil
+}
+
+// SaveAPIKey inserts a new API key record, keyed by the sha256 hash of its plaintext form
+func (db *DB) SaveAPIKey(ctx context.Context, userUUID uuid.UUID, keyHash string, createdAt time.Time) error {
+	if err := db.ready(); err != nil {
+		return err
+	}
+
+	const queryStmt = `INSERT INTO api_keys(id, user_uuid, created_at) VALUES ($1, $2, $3)`
+
+	start := time.Now()
+	defer func() {
+		elapsed := time.Since(start)
+		requestlog.FromContext(ctx, db.zlog).Debug().Msgf("request execution duration: %s", elapsed)
+	}()
+
+	if _, err := db.pool.Exec(ctx, queryStmt, keyHash, userUUID, createdAt); err != nil {
+		return fmt.Errorf("failed to save API key: %w", err)
+	}
+
+	return nil
+}
+
+// ResolveAPIKey returns the UserUUID owning keyHash, or myerrors.ErrNotFound if it does not
+// exist or has been revoked
+func (db *DB) ResolveAPIKey(ctx context.Context, keyHash string) (uuid.UUID, error) {
+	if err := db.ready(); err != nil {
+		return uuid.Nil, err
+	}
+
+	const queryStmt = `SELECT user_uuid FROM api_keys WHERE id = $1 AND revoked = false`
+
+	start := time.Now()
+	defer func() {
+		elapsed := time.Since(start)
+		requestlog.FromContext(ctx, db.zlog).Debug().Msgf("request execution duration: %s", elapsed)
+	}()
+
+	var userUUID uuid.UUID
+	row := db.pool.QueryRow(ctx, queryStmt, keyHash)
+	if err := row.Scan(&userUUID); err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return uuid.Nil, myerrors.ErrNotFound
+		}
+		return uuid.Nil, fmt.Errorf("failed to resolve API key: %w", err)
+	}
+
+	return userUUID, nil
+}
+
+// RevokeAPIKey marks keyHash as revoked, if it exists and is owned by userUUID
+func (db *DB) RevokeAPIKey(ctx context.Context, userUUID uuid.UUID, keyHash string) error {
+	if err := db.ready(); err != nil {
+		return err
+	}
+
+	const (
+		queryStmt  = `UPDATE api_keys SET revoked = true WHERE id = $1 AND user_uuid = $2`
+		existsStmt = `SELECT 1 FROM api_keys WHERE id = $1`
+	)
+
+	start := time.Now()
+	defer func() {
+		elapsed := time.Since(start)
+		requestlog.FromContext(ctx, db.zlog).Debug().Msgf("request execution duration: %s", elapsed)
+	}()
+
+	tag, err := db.pool.Exec(ctx, queryStmt, keyHash, userUUID)
+	if err != nil {
+		return fmt.Errorf("failed to revoke API key: %w", err)
+	}
+
+	if tag.RowsAffected() == 0 {
+		var exists int
+		if err = db.pool.QueryRow(ctx, existsStmt, keyHash).Scan(&exists); err != nil {
+			if errors.Is(err, pgx.ErrNoRows) {
+				return myerrors.ErrNotFound
+			}
+			return fmt.Errorf("failed to revoke API key: %w", err)
+		}
+		return myerrors.ErrForbidden
+	}
+
+	return nil
+}
+
+// TopHosts returns the n hosts with the most non-deleted short URLs, ordered by count descending
+func (db *DB) TopHosts(ctx context.Context, n int) ([]model.HostCount, error) {
+	if err := db.ready(); err != nil {
+		return nil, err
+	}
+
+	const queryStmt = `
+		SELECT host, COUNT(*) AS count FROM urls
+		WHERE is_deleted = false
+		GROUP BY host
+		ORDER BY count DESC
+		LIMIT $1`
+
+	start := time.Now()
+	defer func() {
+		elapsed := time.Since(start)
+		requestlog.FromContext(ctx, db.zlog).Debug().Msgf("request execution duration: %s", elapsed)
+	}()
+
+	rows, err := db.pool.Query(ctx, queryStmt, n)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query top hosts: %w", err)
+	}
+
+	var hostCounts []model.HostCount
+	for rows.Next() {
+		var hc model.HostCount
+		if err = rows.Scan(&hc.Host, &hc.Count); err != nil {
+			return nil, fmt.Errorf("failed to scan top hosts row: %w", err)
+		}
+		hostCounts = append(hostCounts, hc)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to query top hosts: %w", err)
+	}
+
+	return hostCounts, nil
+}
+
+// Stats returns the total number of non-deleted short URLs and the total number of distinct
+// users that have ever saved one
+func (db *DB) Stats(ctx context.Context) (model.Stats, error) {
+	if err := db.ready(); err != nil {
+		return model.Stats{}, err
+	}
+
+	const queryStmt = `
+		SELECT COUNT(*) FILTER (WHERE is_deleted = false), COUNT(DISTINCT user_uuid) FROM urls`
+
+	start := time.Now()
+	defer func() {
+		elapsed := time.Since(start)
+		requestlog.FromContext(ctx, db.zlog).Debug().Msgf("request execution duration: %s", elapsed)
+	}()
+
+	var stats model.Stats
+	row := db.pool.QueryRow(ctx, queryStmt)
+	if err := row.Scan(&stats.URLs, &stats.Users); err != nil {
+		return model.Stats{}, fmt.Errorf("failed to query stats: %w", err)
+	}
+
+	return stats, nil
+}
+
+// NextSequence returns the next value of the slug_sequence database sequence, shared by every
+// instance pointed at the same database, starting at 1.
+func (db *DB) NextSequence(ctx context.Context) (int64, error) {
+	if err := db.ready(); err != nil {
+		return 0, err
+	}
+
+	const queryStmt = `SELECT nextval('slug_sequence')`
+
+	var n int64
+	row := db.pool.QueryRow(ctx, queryStmt)
+	if err := row.Scan(&n); err != nil {
+		return 0, fmt.Errorf("failed to query slug sequence: %w", err)
+	}
+
+	return n, nil
+}
+
+// BackfillHosts computes and persists the host for up to limit rows whose host is still
+// empty (e.g. rows inserted before the host column existed), returning how many rows were
+// backfilled. A non-positive limit backfills every row missing a host.
+func (db *DB) BackfillHosts(ctx context.Context, limit int) (int, error) {
+	if err := db.ready(); err != nil {
+		return 0, err
+	}
+
+	if limit <= 0 {
+		limit = defaultBackfillLimit
+	}
+
+	const selectStmt = `SELECT short_url, original_url FROM urls WHERE host = '' LIMIT $1`
+
+	start := time.Now()
+	defer func() {
+		elapsed := time.Since(start)
+		requestlog.FromContext(ctx, db.zlog).Debug().Msgf("request execution duration: %s", elapsed)
+	}()
+
+	rows, err := db.pool.Query(ctx, selectStmt, limit)
+	if err != nil {
+		return 0, fmt.Errorf("failed to query rows missing a host: %w", err)
+	}
+
+	type pendingRow struct {
+		shortURL string
+		host     string
+	}
+	var pending []pendingRow
+	for rows.Next() {
+		var shortURL, originalURL string
+		if err = rows.Scan(&shortURL, &originalURL); err != nil {
+			return 0, fmt.Errorf("failed to scan row missing a host: %w", err)
+		}
+		pending = append(pending, pendingRow{shortURL: shortURL, host: hostOf(originalURL)})
+	}
+	if err = rows.Err(); err != nil {
+		return 0, fmt.Errorf("failed to query rows missing a host: %w", err)
+	}
+	if len(pending) == 0 {
+		return 0, nil
+	}
+
+	const updateStmt = `UPDATE urls SET host = @host WHERE short_url = @shortURL`
+	updateBatch := &pgx.Batch{}
+	for _, p := range pending {
+		updateBatch.Queue(updateStmt, pgx.NamedArgs{"host": p.host, "shortURL": p.shortURL})
+	}
+
+	results := db.pool.SendBatch(ctx, updateBatch)
+	defer results.Close()
+
+	for range pending {
+		if _, err = results.Exec(); err != nil {
+			return 0, fmt.Errorf("unable to backfill host: %w", err)
+		}
+	}
+
+	return len(pending), results.Close()
+}
+
+// hostOf extracts the hostname from originalURL, returning "" if it cannot be parsed
+func hostOf(originalURL string) string {
+	u, err := url.Parse(originalURL)
+	if err != nil {
+		return ""
+	}
+	return u.Hostname()
+}
+
+// Ping checks that the primary database is reachable. The read replica, if configured, is
+// deliberately not checked here: a down replica degrades reads to the primary rather than
+// making the service unready.
+func (db *DB) Ping(ctx context.Context) error {
+	if err := db.ready(); err != nil {
+		return err
+	}
+
+	if err := db.pool.Ping(ctx); err != nil {
+		return fmt.Errorf("failed to ping the DB: %w", err)
+	}
+
+	return nil
+}
+
+// Verify checks the urls table for corruption that should be structurally impossible given its
+// unique constraints (e.g. from a bulk load that bypassed them) and for rows whose soft-delete
+// state is inconsistent, returning a human-readable description of each one found. The
+// original_url check is skipped in favor of original_url_hash when hashOriginalURL is enabled,
+// since that is the column actually carrying a uniqueness guarantee in that mode.
+func (db *DB) Verify(ctx context.Context) ([]string, error) {
+	if err := db.ready(); err != nil {
+		return nil, err
+	}
+
+	var violations []string
+
+	dupes, err := db.duplicateColumnViolations(ctx, "short_url")
+	if err != nil {
+		return nil, err
+	}
+	violations = append(violations, dupes...)
+
+	if db.hashOriginalURL {
+		dupes, err = db.duplicateColumnViolations(ctx, "original_url_hash")
+	} else {
+		dupes, err = db.duplicateColumnViolations(ctx, "original_url")
+	}
+	if err != nil {
+		return nil, err
+	}
+	violations = append(violations, dupes...)
+
+	const inconsistentDeleteStmt = `SELECT short_url FROM urls WHERE is_deleted != (deleted_at IS NOT NULL)`
+	rows, err := db.pool.Query(ctx, inconsistentDeleteStmt)
+	if err != nil {
+		return nil, fmt.Errorf("failed to check deleted state consistency: %w", err)
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var shortURL string
+		if err = rows.Scan(&shortURL); err != nil {
+			return nil, fmt.Errorf("failed to scan inconsistent row: %w", err)
+		}
+		violations = append(violations, fmt.Sprintf("short_url %q has inconsistent is_deleted/deleted_at state", shortURL))
+	}
+	if err = rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to check deleted state consistency: %w", err)
+	}
+
+	sort.Strings(violations)
+	return violations, nil
+}
+
+// duplicateColumnViolations reports, for the given column, every value that appears in the urls
+// table more than once despite its unique constraint
+func (db *DB) duplicateColumnViolations(ctx context.Context, column string) ([]string, error) {
+	query := fmt.Sprintf(`SELECT %[1]s, COUNT(*) FROM urls GROUP BY %[1]s HAVING COUNT(*) > 1`, column)
+
+	rows, err := db.pool.Query(ctx, query)
+	if err != nil {
+		return nil, fmt.Errorf("failed to check duplicate %s: %w", column, err)
+	}
+	defer rows.Close()
+
+	var violations []string
+	for rows.Next() {
+		var value string
+		var count int
+		if err = rows.Scan(&value, &count); err != nil {
+			return nil, fmt.Errorf("failed to scan duplicate %s: %w", column, err)
+		}
+		violations = append(violations, fmt.Sprintf("%s %q appears %d times", column, value, count))
+	}
+	if err = rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to check duplicate %s: %w", column, err)
+	}
+
+	return violations, nil
+}
@@ -0,0 +1,38 @@
+package postgresql
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/ar4ie13/shortener/internal/myerrors"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+)
+
+// TestDB_NotReady confirms a DB built without NewDB (e.g. a zero-value struct literal)
+// reports myerrors.ErrRepositoryNotReady instead of panicking on a nil pool.
+func TestDB_NotReady(t *testing.T) {
+	db := &DB{}
+
+	_, err := db.GetURL(context.Background(), uuid.New(), "slug", false)
+	assert.ErrorIs(t, err, myerrors.ErrRepositoryNotReady)
+
+	_, err = db.Save(context.Background(), uuid.New(), "slug", "https://example.com", "", "", time.Time{}, time.Time{})
+	assert.ErrorIs(t, err, myerrors.ErrRepositoryNotReady)
+
+	_, err = db.Stats(context.Background())
+	assert.ErrorIs(t, err, myerrors.ErrRepositoryNotReady)
+}
+
+// TestSha256Hex confirms the hash used to dedup and look up huge URLs is deterministic,
+// fixed-width, and sensitive to the input.
+func TestSha256Hex(t *testing.T) {
+	a := sha256Hex("https://example.com/one")
+	b := sha256Hex("https://example.com/one")
+	c := sha256Hex("https://example.com/two")
+
+	assert.Equal(t, a, b)
+	assert.NotEqual(t, a, c)
+	assert.Len(t, a, 64)
+}
@@ -1,6 +1,18 @@
 package config
 
+import "time"
+
 // Config contains filepath to file storage
 type Config struct {
 	FilePath string
+	// OperationTimeout bounds how long a single file storage operation (load, save, or
+	// delete) may run before it abandons the request's context; 0 disables the timeout
+	OperationTimeout time.Duration
+	// CompactionThreshold is the file size, in bytes, above which FileStorage compacts its
+	// append-only log down to one record per short URL; 0 disables size-triggered compaction
+	CompactionThreshold int64
+	// Durable trades write throughput for crash safety: full-file rewrites go through a
+	// temp-file-then-rename so a crash mid-write never leaves a partially written log, and
+	// appends are fsynced before the call returns. false favors throughput instead.
+	Durable bool
 }
@@ -1,14 +1,17 @@
 package filestorage
 
 import (
+	"context"
 	"errors"
 	"os"
 	"reflect"
+	"strconv"
 	"sync"
 	"testing"
 	"time"
 
 	"github.com/ar4ie13/shortener/internal/model"
+	"github.com/ar4ie13/shortener/internal/myerrors"
 	fileconf "github.com/ar4ie13/shortener/internal/repository/filestorage/config"
 	"github.com/ar4ie13/shortener/internal/repository/memory"
 	"github.com/google/uuid"
@@ -73,8 +76,7 @@ func TestNewFileStorage(t *testing.T) {
 				mu: sync.Mutex{},
 			},
 			want: &FileStorage{
-				m:          memory.NewMemStorage(),
-				urlMapping: model.URL{},
+				m: memory.NewMemStorage(),
 				filePath: fileconf.Config{
 					FilePath: "",
 				}, zlog: zerolog.New(zerolog.ConsoleWriter{
@@ -175,13 +177,8 @@ func TestFileStorage_Store(t *testing.T) {
 				filePath: fileconf.Config{
 					FilePath: "test.jsonl",
 				},
-				urlMapping: model.URL{
-					UUID: uuid.UUID{},
-					
-					ShortURL:    "",
-					OriginalURL: "",
-				},
 				mu: sync.RWMutex{},
+				m:  memory.NewMemStorage(),
 			}
 
 			// Replace os.OpenFile with our mock
@@ -195,7 +192,7 @@ func TestFileStorage_Store(t *testing.T) {
 
 				// Should not panic
 				assert.NotPanics(t, func() {
-					err := storage.Store(tt.shortURL, uuid.New(), tt.url)
+					err := storage.Store(context.Background(), tt.shortURL, uuid.New(), tt.url, "", "", time.Time{}, time.Time{})
 					assert.NoError(t, err)
 				})
 
@@ -227,9 +224,275 @@ func TestRepository_Load(t *testing.T) {
 			repo := &FileStorage{
 				m: tt.fields.m,
 			}
-			if err := repo.Load(); (err != nil) != tt.wantErr {
+			if err := repo.Load(context.Background()); (err != nil) != tt.wantErr {
 				t.Errorf("Load() error = %v, wantErr %v", err, tt.wantErr)
 			}
 		})
 	}
 }
+
+func TestFileStorage_ConcurrentLoadSaveGet(t *testing.T) {
+	dir := t.TempDir()
+	storage := NewFileStorage(fileconf.Config{FilePath: dir + "/urls.jsonl"}, zerolog.Nop())
+
+	var wg sync.WaitGroup
+	wg.Add(3)
+
+	go func() {
+		defer wg.Done()
+		_ = storage.Load(context.Background())
+	}()
+	go func() {
+		defer wg.Done()
+		for i := 0; i < 50; i++ {
+			_, _ = storage.Save(context.Background(), uuid.New(), "short", "https://example.com", "", "", time.Time{}, time.Time{})
+		}
+	}()
+	go func() {
+		defer wg.Done()
+		for i := 0; i < 50; i++ {
+			_, _ = storage.GetURL(context.Background(), uuid.New(), "short", false)
+		}
+	}()
+
+	wg.Wait()
+}
+
+// TestFileStorage_ConcurrentSaveNoCrossContamination stores many records for distinct users
+// concurrently and confirms each record is persisted with its own fields intact, which would
+// fail if the mutable urlMapping field were still shared across calls.
+func TestFileStorage_ConcurrentSaveNoCrossContamination(t *testing.T) {
+	dir := t.TempDir()
+	storage := NewFileStorage(fileconf.Config{FilePath: dir + "/urls.jsonl"}, zerolog.Nop())
+
+	const n = 50
+	var wg sync.WaitGroup
+	wg.Add(n)
+	for i := 0; i < n; i++ {
+		go func(i int) {
+			defer wg.Done()
+			shortURL := "slug" + strconv.Itoa(i)
+			longURL := "https://example.com/" + strconv.Itoa(i)
+			_, err := storage.Save(context.Background(), uuid.New(), shortURL, longURL, "", "", time.Time{}, time.Time{})
+			assert.NoError(t, err)
+		}(i)
+	}
+	wg.Wait()
+
+	for i := 0; i < n; i++ {
+		shortURL := "slug" + strconv.Itoa(i)
+		wantLongURL := "https://example.com/" + strconv.Itoa(i)
+		gotLongURL, err := storage.GetURL(context.Background(), uuid.New(), shortURL, false)
+		assert.NoError(t, err)
+		assert.Equal(t, wantLongURL, gotLongURL, "record for %s was corrupted by a concurrent Save", shortURL)
+	}
+}
+
+// TestFileStorage_NotReady confirms a FileStorage built without NewFileStorage (e.g. a
+// zero-value struct literal, as some of the tests above construct directly) reports
+// myerrors.ErrRepositoryNotReady instead of panicking on a nil map.
+func TestFileStorage_NotReady(t *testing.T) {
+	storage := &FileStorage{}
+
+	_, err := storage.GetURL(context.Background(), uuid.New(), "slug", false)
+	assert.ErrorIs(t, err, myerrors.ErrRepositoryNotReady)
+
+	_, err = storage.Save(context.Background(), uuid.New(), "slug", "https://example.com", "", "", time.Time{}, time.Time{})
+	assert.ErrorIs(t, err, myerrors.ErrRepositoryNotReady)
+
+	_, err = storage.Stats(context.Background())
+	assert.ErrorIs(t, err, myerrors.ErrRepositoryNotReady)
+}
+
+// TestFileStorage_StopsOnCancelledContext confirms Load, Store, SaveBatch and
+// DeleteUserShortURLs abandon a cancelled context instead of completing their file I/O.
+func TestFileStorage_StopsOnCancelledContext(t *testing.T) {
+	dir := t.TempDir()
+	storage := NewFileStorage(fileconf.Config{FilePath: dir + "/urls.jsonl"}, zerolog.Nop())
+
+	userUUID := uuid.New()
+	_, err := storage.Save(context.Background(), userUUID, "slug", "https://example.com", "", "", time.Time{}, time.Time{})
+	assert.NoError(t, err)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	err = storage.Load(ctx)
+	assert.ErrorIs(t, err, context.Canceled)
+
+	err = storage.Store(ctx, "other", uuid.New(), "https://example.org", "", "", time.Time{}, time.Time{})
+	assert.ErrorIs(t, err, context.Canceled)
+
+	_, err = storage.SaveBatch(ctx, userUUID, []model.URL{{ShortURL: "batch1", OriginalURL: "https://example.com/batch"}})
+	assert.ErrorIs(t, err, context.Canceled)
+
+	err = storage.DeleteUserShortURLs(ctx, map[uuid.UUID][]string{userUUID: {"slug"}}, time.Now())
+	assert.ErrorIs(t, err, context.Canceled)
+}
+
+// TestFileStorage_OperationTimeout confirms a configured OperationTimeout aborts a file
+// storage operation even when the caller's own context has no deadline.
+func TestFileStorage_OperationTimeout(t *testing.T) {
+	dir := t.TempDir()
+	storage := NewFileStorage(fileconf.Config{FilePath: dir + "/urls.jsonl", OperationTimeout: time.Nanosecond}, zerolog.Nop())
+
+	err := storage.Load(context.Background())
+	assert.ErrorIs(t, err, context.DeadlineExceeded)
+}
+
+// TestFileStorage_DeleteUserShortURLs_AppendsTombstoneInsteadOfRewriting confirms deletion
+// appends a tombstone record rather than rewriting the whole log, and that the deletion
+// survives a reload.
+func TestFileStorage_DeleteUserShortURLs_AppendsTombstoneInsteadOfRewriting(t *testing.T) {
+	dir := t.TempDir()
+	filePath := dir + "/urls.jsonl"
+	storage := NewFileStorage(fileconf.Config{FilePath: filePath}, zerolog.Nop())
+
+	userUUID := uuid.New()
+	_, err := storage.Save(context.Background(), userUUID, "slug1", "https://example.com/1", "", "", time.Time{}, time.Time{})
+	assert.NoError(t, err)
+	_, err = storage.Save(context.Background(), userUUID, "slug2", "https://example.com/2", "", "", time.Time{}, time.Time{})
+	assert.NoError(t, err)
+
+	sizeBeforeDelete, err := os.Stat(filePath)
+	assert.NoError(t, err)
+
+	err = storage.DeleteUserShortURLs(context.Background(), map[uuid.UUID][]string{userUUID: {"slug1"}}, time.Now())
+	assert.NoError(t, err)
+
+	sizeAfterDelete, err := os.Stat(filePath)
+	assert.NoError(t, err)
+	assert.Greater(t, sizeAfterDelete.Size(), sizeBeforeDelete.Size(), "deletion should append a tombstone, growing the file rather than rewriting it")
+
+	reloaded := NewFileStorage(fileconf.Config{FilePath: filePath}, zerolog.Nop())
+	err = reloaded.Load(context.Background())
+	assert.NoError(t, err)
+
+	_, err = reloaded.GetURL(context.Background(), userUUID, "slug1", false)
+	assert.ErrorIs(t, err, myerrors.ErrShortURLIsDeleted)
+
+	url, err := reloaded.GetURL(context.Background(), userUUID, "slug2", false)
+	assert.NoError(t, err)
+	assert.Equal(t, "https://example.com/2", url)
+}
+
+// TestFileStorage_Compact confirms compact collapses the log down to one record per short
+// URL while preserving every surviving field.
+func TestFileStorage_Compact(t *testing.T) {
+	dir := t.TempDir()
+	filePath := dir + "/urls.jsonl"
+	storage := NewFileStorage(fileconf.Config{FilePath: filePath}, zerolog.Nop())
+
+	userUUID := uuid.New()
+	_, err := storage.Save(context.Background(), userUUID, "slug1", "https://example.com/1", "", "", time.Time{}, time.Time{})
+	assert.NoError(t, err)
+	err = storage.UpdateURL(context.Background(), userUUID, "slug1", "https://example.com/1-updated", time.Now())
+	assert.NoError(t, err)
+	_, err = storage.Save(context.Background(), userUUID, "slug2", "https://example.com/2", "", "", time.Time{}, time.Time{})
+	assert.NoError(t, err)
+	err = storage.DeleteUserShortURLs(context.Background(), map[uuid.UUID][]string{userUUID: {"slug2"}}, time.Now())
+	assert.NoError(t, err)
+
+	storage.mu.Lock()
+	err = storage.compact()
+	storage.mu.Unlock()
+	assert.NoError(t, err)
+
+	file, err := os.ReadFile(filePath)
+	assert.NoError(t, err)
+	lines := 0
+	for _, b := range file {
+		if b == '\n' {
+			lines++
+		}
+	}
+	assert.Equal(t, 2, lines, "compact should keep exactly one record per short URL")
+
+	reloaded := NewFileStorage(fileconf.Config{FilePath: filePath}, zerolog.Nop())
+	err = reloaded.Load(context.Background())
+	assert.NoError(t, err)
+
+	url, err := reloaded.GetURL(context.Background(), userUUID, "slug1", false)
+	assert.NoError(t, err)
+	assert.Equal(t, "https://example.com/1-updated", url)
+
+	_, err = reloaded.GetURL(context.Background(), userUUID, "slug2", false)
+	assert.ErrorIs(t, err, myerrors.ErrShortURLIsDeleted)
+}
+
+// TestFileStorage_CompactIfOversized_RunsAtConfiguredThreshold confirms DeleteUserShortURLs
+// triggers compaction once the log exceeds CompactionThreshold, and leaves it untouched below
+// the threshold.
+func TestFileStorage_CompactIfOversized_RunsAtConfiguredThreshold(t *testing.T) {
+	dir := t.TempDir()
+	filePath := dir + "/urls.jsonl"
+	storage := NewFileStorage(fileconf.Config{FilePath: filePath, CompactionThreshold: 1}, zerolog.Nop())
+
+	userUUID := uuid.New()
+	_, err := storage.Save(context.Background(), userUUID, "slug1", "https://example.com/1", "", "", time.Time{}, time.Time{})
+	assert.NoError(t, err)
+	_, err = storage.Save(context.Background(), userUUID, "slug2", "https://example.com/2", "", "", time.Time{}, time.Time{})
+	assert.NoError(t, err)
+
+	err = storage.DeleteUserShortURLs(context.Background(), map[uuid.UUID][]string{userUUID: {"slug1"}}, time.Now())
+	assert.NoError(t, err)
+
+	file, err := os.ReadFile(filePath)
+	assert.NoError(t, err)
+	lines := 0
+	for _, b := range file {
+		if b == '\n' {
+			lines++
+		}
+	}
+	assert.Equal(t, 2, lines, "a threshold of 1 byte should trigger compaction down to one record per short URL")
+}
+
+// TestFileStorage_Durable_RewriteGoesThroughTempFile confirms that with Durable set, a
+// full-file rewrite (here triggered via UpdateURL) round-trips correctly and leaves no
+// leftover temp file behind.
+func TestFileStorage_Durable_RewriteGoesThroughTempFile(t *testing.T) {
+	dir := t.TempDir()
+	filePath := dir + "/urls.jsonl"
+	storage := NewFileStorage(fileconf.Config{FilePath: filePath, Durable: true}, zerolog.Nop())
+
+	userUUID := uuid.New()
+	_, err := storage.Save(context.Background(), userUUID, "slug1", "https://example.com/1", "", "", time.Time{}, time.Time{})
+	assert.NoError(t, err)
+	err = storage.UpdateURL(context.Background(), userUUID, "slug1", "https://example.com/1-updated", time.Now())
+	assert.NoError(t, err)
+
+	entries, err := os.ReadDir(dir)
+	assert.NoError(t, err)
+	for _, entry := range entries {
+		assert.NotContains(t, entry.Name(), ".tmp-", "no temp file should remain after a successful durable rewrite")
+	}
+
+	reloaded := NewFileStorage(fileconf.Config{FilePath: filePath}, zerolog.Nop())
+	err = reloaded.Load(context.Background())
+	assert.NoError(t, err)
+
+	url, err := reloaded.GetURL(context.Background(), userUUID, "slug1", false)
+	assert.NoError(t, err)
+	assert.Equal(t, "https://example.com/1-updated", url)
+}
+
+// TestFileStorage_NonDurable_StoreUnaffected confirms the default, non-durable path still
+// appends and reloads correctly now that Durable gates the extra fsync call.
+func TestFileStorage_NonDurable_StoreUnaffected(t *testing.T) {
+	dir := t.TempDir()
+	filePath := dir + "/urls.jsonl"
+	storage := NewFileStorage(fileconf.Config{FilePath: filePath}, zerolog.Nop())
+
+	userUUID := uuid.New()
+	_, err := storage.Save(context.Background(), userUUID, "slug1", "https://example.com/1", "", "", time.Time{}, time.Time{})
+	assert.NoError(t, err)
+
+	reloaded := NewFileStorage(fileconf.Config{FilePath: filePath}, zerolog.Nop())
+	err = reloaded.Load(context.Background())
+	assert.NoError(t, err)
+
+	url, err := reloaded.GetURL(context.Background(), userUUID, "slug1", false)
+	assert.NoError(t, err)
+	assert.Equal(t, "https://example.com/1", url)
+}
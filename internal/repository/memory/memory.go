@@ -1,12 +1,22 @@
 package memory
 
 import (
+	"bytes"
 	"context"
+	"encoding/json"
+	"errors"
 	"fmt"
+	"net/url"
+	"os"
+	"sort"
+	"sync"
+	"sync/atomic"
+	"time"
 
 	"github.com/ar4ie13/shortener/internal/model"
 	"github.com/ar4ie13/shortener/internal/myerrors"
 	"github.com/google/uuid"
+	"github.com/rs/zerolog"
 )
 
 // SlugMemStore stores slug:URL
@@ -26,6 +36,48 @@ type UserUUIDSlugMemStore map[uuid.UUID]SlugMemStore
 
 type IsSlugDeletedMemStore map[string]bool
 
+// RefererMemStore stores slug:referer of the request that created the short URL
+type RefererMemStore map[string]string
+
+// UserAgentMemStore stores slug:user-agent of the request that created the short URL
+type UserAgentMemStore map[string]string
+
+// ExpiresAtMemStore stores slug:expiry time; a zero time means the slug never expires
+type ExpiresAtMemStore map[string]time.Time
+
+// DeletedAtMemStore stores slug:deletion time; only meaningful while IsSlugDeletedMemStore
+// is true for that slug
+type DeletedAtMemStore map[string]time.Time
+
+// PasswordHashMemStore stores slug:bcrypt hash; an absent entry means the slug is not
+// password-protected
+type PasswordHashMemStore map[string]string
+
+// RedirectStatusMemStore stores slug:redirect status override; an absent entry means the
+// slug uses the operator's configured default
+type RedirectStatusMemStore map[string]int
+
+// HostMemStore stores slug:hostname, parsed out of the original URL at save time
+type HostMemStore map[string]string
+
+// CreatedAtMemStore stores slug:creation time; a zero time means the slug was saved before
+// this field was introduced
+type CreatedAtMemStore map[string]time.Time
+
+// UpdatedAtMemStore stores slug:time OriginalURL was last changed, equal to CreatedAt for a
+// slug that has never been updated
+type UpdatedAtMemStore map[string]time.Time
+
+// APIKeyUserUUIDMemStore stores keyHash:UserUUID for issued API keys
+type APIKeyUserUUIDMemStore map[string]uuid.UUID
+
+// APIKeyCreatedAtMemStore stores keyHash:creation time for issued API keys
+type APIKeyCreatedAtMemStore map[string]time.Time
+
+// APIKeyRevokedMemStore stores keyHash:revoked for issued API keys; an absent entry means
+// the key has not been revoked
+type APIKeyRevokedMemStore map[string]bool
+
 // MemStorage is the main object for the package repository
 type MemStorage struct {
 	SlugMemStore
@@ -34,35 +86,119 @@ type MemStorage struct {
 	UUIDMemStore
 	UserUUIDSlugMemStore
 	IsSlugDeletedMemStore
+	RefererMemStore
+	UserAgentMemStore
+	ExpiresAtMemStore
+	DeletedAtMemStore
+	PasswordHashMemStore
+	RedirectStatusMemStore
+	HostMemStore
+	APIKeyUserUUIDMemStore
+	APIKeyCreatedAtMemStore
+	APIKeyRevokedMemStore
+	CreatedAtMemStore
+	UpdatedAtMemStore
+	sequence int64
+	// mu guards every map above against concurrent access between HTTP-request-driven calls
+	// and RunSnapshotLoop's background goroutine, which would otherwise range over a map while
+	// a request is writing to it.
+	mu sync.RWMutex
 }
 
 // NewMemStorage is a constructor for MemStorage object
 func NewMemStorage() *MemStorage {
 	return &MemStorage{
-		SlugMemStore:          make(map[string]string),
-		URLMemStore:           make(map[string]string),
-		UserUUIDURLMemStore:   make(map[uuid.UUID]URLMemStore),
-		UUIDMemStore:          make(map[uuid.UUID]string),
-		UserUUIDSlugMemStore:  make(map[uuid.UUID]SlugMemStore),
-		IsSlugDeletedMemStore: make(map[string]bool),
+		SlugMemStore:            make(map[string]string),
+		URLMemStore:             make(map[string]string),
+		UserUUIDURLMemStore:     make(map[uuid.UUID]URLMemStore),
+		UUIDMemStore:            make(map[uuid.UUID]string),
+		UserUUIDSlugMemStore:    make(map[uuid.UUID]SlugMemStore),
+		IsSlugDeletedMemStore:   make(map[string]bool),
+		RefererMemStore:         make(map[string]string),
+		UserAgentMemStore:       make(map[string]string),
+		ExpiresAtMemStore:       make(map[string]time.Time),
+		DeletedAtMemStore:       make(map[string]time.Time),
+		PasswordHashMemStore:    make(map[string]string),
+		RedirectStatusMemStore:  make(map[string]int),
+		APIKeyUserUUIDMemStore:  make(map[string]uuid.UUID),
+		APIKeyCreatedAtMemStore: make(map[string]time.Time),
+		APIKeyRevokedMemStore:   make(map[string]bool),
+		HostMemStore:            make(map[string]string),
+		CreatedAtMemStore:       make(map[string]time.Time),
+		UpdatedAtMemStore:       make(map[string]time.Time),
 	}
 }
 
-// GetURL method is used to get URL (link) from the repository map
-func (repo *MemStorage) GetURL(_ context.Context, shortURL string) (string, error) {
-	if v, ok := repo.SlugMemStore[shortURL]; ok {
-		if repo.IsSlugDeletedMemStore[shortURL] {
-			return "", myerrors.ErrShortURLIsDeleted
-		} else {
-			return v, nil
+// HostOf extracts the hostname from originalURL, returning "" if it cannot be parsed
+func HostOf(originalURL string) string {
+	u, err := url.Parse(originalURL)
+	if err != nil {
+		return ""
+	}
+	return u.Hostname()
+}
+
+// getURL is the unlocked core of GetURL; callers must hold at least repo.mu.RLock.
+func (repo *MemStorage) getURL(userUUID uuid.UUID, shortURL string, requireOwnership bool) (string, error) {
+	v, ok := repo.SlugMemStore[shortURL]
+	if !ok {
+		return "", myerrors.ErrNotFound
+	}
+
+	if requireOwnership {
+		if _, owns := repo.UserUUIDSlugMemStore[userUUID][shortURL]; !owns {
+			return "", myerrors.ErrNotFound
 		}
 	}
 
-	return "", myerrors.ErrNotFound
+	if repo.IsSlugDeletedMemStore[shortURL] {
+		return "", &myerrors.DeletedURLError{OriginalURL: v, DeletedAt: repo.DeletedAtMemStore[shortURL]}
+	}
+
+	return v, nil
+}
+
+// GetURL method is used to get URL (link) from the repository map. When requireOwnership is
+// true, a shortURL not owned by userUUID is reported as myerrors.ErrNotFound, same as a slug
+// that does not exist.
+func (repo *MemStorage) GetURL(_ context.Context, userUUID uuid.UUID, shortURL string, requireOwnership bool) (string, error) {
+	repo.mu.RLock()
+	defer repo.mu.RUnlock()
+
+	return repo.getURL(userUUID, shortURL, requireOwnership)
+}
+
+// GetURLs resolves many slugs in one call, returning one model.URLExpansion per entry in
+// shortURLs, in the same order
+func (repo *MemStorage) GetURLs(_ context.Context, userUUID uuid.UUID, shortURLs []string, requireOwnership bool) ([]model.URLExpansion, error) {
+	repo.mu.RLock()
+	defer repo.mu.RUnlock()
+
+	result := make([]model.URLExpansion, len(shortURLs))
+	for i, shortURL := range shortURLs {
+		result[i] = model.URLExpansion{ShortURL: shortURL}
+		originalURL, err := repo.getURL(userUUID, shortURL, requireOwnership)
+		if err != nil {
+			var deletedErr *myerrors.DeletedURLError
+			if errors.As(err, &deletedErr) {
+				result[i].OriginalURL = deletedErr.OriginalURL
+				result[i].IsDeleted = true
+				result[i].DeletedAt = deletedErr.DeletedAt
+				continue
+			}
+			result[i].Err = err.Error()
+			continue
+		}
+		result[i].OriginalURL = originalURL
+	}
+	return result, nil
 }
 
 // GetShortURL method is used to get shortURL from the repository map
 func (repo *MemStorage) GetShortURL(_ context.Context, originalURL string) (string, error) {
+	repo.mu.RLock()
+	defer repo.mu.RUnlock()
+
 	if v, ok := repo.URLMemStore[originalURL]; ok {
 		if !repo.IsSlugDeletedMemStore[v] {
 			return v, nil
@@ -97,18 +233,20 @@ func (repo *MemStorage) existsShortURL(shortURL string) bool {
 }
 
 // Save saves shortURL, URL and UUID to the correlated maps
-func (repo *MemStorage) Save(_ context.Context, userUUID uuid.UUID, shortURL string, url string) error {
+func (repo *MemStorage) Save(_ context.Context, userUUID uuid.UUID, shortURL string, url string, referer string, userAgent string, expiresAt time.Time, createdAt time.Time) (string, error) {
+	repo.mu.Lock()
+	defer repo.mu.Unlock()
 
 	if shortURL == "" || url == "" {
-		return myerrors.ErrEmptyShortURLorURL
+		return "", myerrors.ErrEmptyShortURLorURL
 	}
 
 	if repo.existsURL(url) {
-		return fmt.Errorf("%w :%s", myerrors.ErrURLExist, url)
+		return "", fmt.Errorf("%w :%s", myerrors.ErrURLExist, url)
 	}
 
 	if repo.existsShortURL(shortURL) {
-		return fmt.Errorf("%w :%s", myerrors.ErrShortURLExist, shortURL)
+		return "", fmt.Errorf("%w :%s", myerrors.ErrShortURLExist, shortURL)
 	}
 
 	repo.SlugMemStore[shortURL] = url
@@ -123,61 +261,145 @@ func (repo *MemStorage) Save(_ context.Context, userUUID uuid.UUID, shortURL str
 	}
 	repo.UserUUIDSlugMemStore[userUUID][shortURL] = url
 	repo.IsSlugDeletedMemStore[shortURL] = false
-
-	return nil
+	repo.RefererMemStore[shortURL] = referer
+	repo.UserAgentMemStore[shortURL] = userAgent
+	repo.ExpiresAtMemStore[shortURL] = expiresAt
+	repo.HostMemStore[shortURL] = HostOf(url)
+	repo.CreatedAtMemStore[shortURL] = createdAt
+	repo.UpdatedAtMemStore[shortURL] = createdAt
+
+	return "", nil
 }
 
-// SaveBatch saves slice of shortURL, URL and UUID to the correlated maps
-func (repo *MemStorage) SaveBatch(_ context.Context, userUUID uuid.UUID, batch []model.URL) error {
+// SaveBatch saves slice of shortURL, URL and UUID to the correlated maps. An entry whose
+// OriginalURL is already saved, either from before this call or from an earlier entry in the
+// same batch, is not written; its existing short URL is returned in the same position instead,
+// so the caller can report it as a conflict rather than the whole batch failing. A genuine
+// empty field or a shortURL collision still fails the whole call, since neither is a condition
+// a caller can resolve by reusing an existing slug.
+func (repo *MemStorage) SaveBatch(_ context.Context, userUUID uuid.UUID, batch []model.URL) ([]string, error) {
+	repo.mu.Lock()
+	defer repo.mu.Unlock()
+
+	existing := make([]string, len(batch))
+	seen := make(map[string]string, len(batch))
+	toWrite := make([]model.URL, 0, len(batch))
 
-	result := make([]model.URL, len(batch))
 	for i := range batch {
 		switch {
 		case batch[i].ShortURL == "" || batch[i].OriginalURL == "":
-			return myerrors.ErrEmptyShortURLorURL
-		case repo.existsURL(batch[i].OriginalURL):
-			return fmt.Errorf("%w: %s", myerrors.ErrURLExist, batch[i].OriginalURL)
+			return nil, myerrors.ErrEmptyShortURLorURL
 		case repo.existsShortURL(batch[i].ShortURL):
-			return fmt.Errorf("%w: %s", myerrors.ErrShortURLExist, batch[i].ShortURL)
+			return nil, fmt.Errorf("%w: %s", myerrors.ErrShortURLExist, batch[i].ShortURL)
+		}
+		if shortURL, ok := seen[batch[i].OriginalURL]; ok {
+			existing[i] = shortURL
+			continue
+		}
+		if repo.existsURL(batch[i].OriginalURL) {
+			existing[i] = repo.URLMemStore[batch[i].OriginalURL]
+			continue
 		}
-		result[i] = batch[i]
+		seen[batch[i].OriginalURL] = batch[i].ShortURL
+		toWrite = append(toWrite, batch[i])
 	}
+
 	if repo.UserUUIDURLMemStore[userUUID] == nil {
 		repo.UserUUIDURLMemStore[userUUID] = make(URLMemStore)
 	}
 	if repo.UserUUIDSlugMemStore[userUUID] == nil {
 		repo.UserUUIDSlugMemStore[userUUID] = make(SlugMemStore)
 	}
-	for i := range result {
-		repo.URLMemStore[result[i].OriginalURL] = result[i].ShortURL
-		repo.SlugMemStore[result[i].ShortURL] = batch[i].OriginalURL
-		repo.UserUUIDSlugMemStore[userUUID][result[i].ShortURL] = batch[i].OriginalURL
-		repo.UserUUIDURLMemStore[userUUID][batch[i].OriginalURL] = batch[i].ShortURL
-		repo.UUIDMemStore[batch[i].UUID] = batch[i].ShortURL
-		repo.IsSlugDeletedMemStore[batch[i].ShortURL] = false
+	for i := range toWrite {
+		repo.URLMemStore[toWrite[i].OriginalURL] = toWrite[i].ShortURL
+		repo.SlugMemStore[toWrite[i].ShortURL] = toWrite[i].OriginalURL
+		repo.UserUUIDSlugMemStore[userUUID][toWrite[i].ShortURL] = toWrite[i].OriginalURL
+		repo.UserUUIDURLMemStore[userUUID][toWrite[i].OriginalURL] = toWrite[i].ShortURL
+		repo.UUIDMemStore[toWrite[i].UUID] = toWrite[i].ShortURL
+		repo.IsSlugDeletedMemStore[toWrite[i].ShortURL] = false
+		repo.RefererMemStore[toWrite[i].ShortURL] = toWrite[i].Referer
+		repo.UserAgentMemStore[toWrite[i].ShortURL] = toWrite[i].UserAgent
+		repo.ExpiresAtMemStore[toWrite[i].ShortURL] = toWrite[i].ExpiresAt
+		repo.HostMemStore[toWrite[i].ShortURL] = HostOf(toWrite[i].OriginalURL)
+		repo.CreatedAtMemStore[toWrite[i].ShortURL] = toWrite[i].CreatedAt
+		repo.UpdatedAtMemStore[toWrite[i].ShortURL] = toWrite[i].UpdatedAt
 	}
 
-	return nil
+	return existing, nil
 }
 
 // GetUserShortURLs return short URLs for specified user
-func (repo *MemStorage) GetUserShortURLs(_ context.Context, userUUID uuid.UUID) (map[string]string, error) {
-	result := make(SlugMemStore)
+func (repo *MemStorage) GetUserShortURLs(_ context.Context, userUUID uuid.UUID) ([]model.URL, error) {
+	repo.mu.RLock()
+	defer repo.mu.RUnlock()
+
 	if _, ok := repo.UserUUIDSlugMemStore[userUUID]; !ok {
 		return nil, myerrors.ErrNotFound
 	}
 
+	var result []model.URL
 	for slug, url := range repo.UserUUIDSlugMemStore[userUUID] {
 		if !repo.IsSlugDeletedMemStore[slug] {
-			result[slug] = url
+			result = append(result, model.URL{
+				UserUUID:    userUUID,
+				ShortURL:    slug,
+				OriginalURL: url,
+				Referer:     repo.RefererMemStore[slug],
+				UserAgent:   repo.UserAgentMemStore[slug],
+				Host:        repo.HostMemStore[slug],
+				CreatedAt:   repo.CreatedAtMemStore[slug],
+				UpdatedAt:   repo.UpdatedAtMemStore[slug],
+			})
 		}
 	}
 
 	return result, nil
 }
 
-// DeleteUserShortURLs mark short URLs as Deleted in storage
-func (repo *MemStorage) DeleteUserShortURLs(_ context.Context, shortURLsToDelete map[uuid.UUID][]string) error {
+// UpdateURL changes the destination for shortURL, owned by userUUID, to newURL. It returns
+// myerrors.ErrNotFound if shortURL does not exist, myerrors.ErrForbidden if it exists but is
+// not owned by userUUID, a *myerrors.DeletedURLError if it has been soft-deleted, or
+// myerrors.ErrURLExist if another non-deleted short URL already points at newURL.
+func (repo *MemStorage) UpdateURL(_ context.Context, userUUID uuid.UUID, shortURL string, newURL string, updatedAt time.Time) error {
+	repo.mu.Lock()
+	defer repo.mu.Unlock()
+
+	oldURL, ok := repo.SlugMemStore[shortURL]
+	if !ok {
+		return myerrors.ErrNotFound
+	}
+
+	if _, owns := repo.UserUUIDSlugMemStore[userUUID][shortURL]; !owns {
+		return myerrors.ErrForbidden
+	}
+
+	if repo.IsSlugDeletedMemStore[shortURL] {
+		return &myerrors.DeletedURLError{OriginalURL: oldURL, DeletedAt: repo.DeletedAtMemStore[shortURL]}
+	}
+
+	if repo.existsURL(newURL) {
+		return fmt.Errorf("%w :%s", myerrors.ErrURLExist, newURL)
+	}
+
+	delete(repo.URLMemStore, oldURL)
+	delete(repo.UserUUIDURLMemStore[userUUID], oldURL)
+
+	repo.SlugMemStore[shortURL] = newURL
+	repo.URLMemStore[newURL] = shortURL
+	repo.UserUUIDURLMemStore[userUUID][newURL] = shortURL
+	repo.UserUUIDSlugMemStore[userUUID][shortURL] = newURL
+	repo.HostMemStore[shortURL] = HostOf(newURL)
+	repo.UpdatedAtMemStore[shortURL] = updatedAt
+
+	return nil
+}
+
+// DeleteUserShortURLs mark short URLs as Deleted in storage, stamping deletedAt as their
+// deletion time
+func (repo *MemStorage) DeleteUserShortURLs(_ context.Context, shortURLsToDelete map[uuid.UUID][]string, deletedAt time.Time) error {
+	repo.mu.Lock()
+	defer repo.mu.Unlock()
+
 	for userUUID, slugs := range shortURLsToDelete {
 
 		if _, ok := repo.UserUUIDSlugMemStore[userUUID]; !ok {
@@ -187,6 +409,7 @@ func (repo *MemStorage) DeleteUserShortURLs(_ context.Context, shortURLsToDelete
 			if repo.UserUUIDSlugMemStore[userUUID][slug] != "" {
 				if _, ok := repo.UserUUIDSlugMemStore[userUUID][slug]; ok {
 					repo.IsSlugDeletedMemStore[slug] = true
+					repo.DeletedAtMemStore[slug] = deletedAt
 				}
 			}
 		}
@@ -194,3 +417,414 @@ func (repo *MemStorage) DeleteUserShortURLs(_ context.Context, shortURLsToDelete
 
 	return nil
 }
+
+// SweepExpired marks up to limit not-yet-deleted slugs as deleted if their expiry is
+// before the given time, returning how many were swept. Swept slugs are stamped with
+// before as their deletion time, since before is always the current time at the real
+// call site.
+func (repo *MemStorage) SweepExpired(_ context.Context, before time.Time, limit int) (int, error) {
+	repo.mu.Lock()
+	defer repo.mu.Unlock()
+
+	swept := 0
+	for slug, expiresAt := range repo.ExpiresAtMemStore {
+		if swept >= limit {
+			break
+		}
+		if expiresAt.IsZero() || !expiresAt.Before(before) {
+			continue
+		}
+		if repo.IsSlugDeletedMemStore[slug] {
+			continue
+		}
+		repo.IsSlugDeletedMemStore[slug] = true
+		repo.DeletedAtMemStore[slug] = before
+		swept++
+	}
+
+	return swept, nil
+}
+
+// PurgeDeleted permanently removes up to limit already soft-deleted slugs whose deletedAt
+// is before the given time, returning how many were removed
+func (repo *MemStorage) PurgeDeleted(_ context.Context, before time.Time, limit int) (int, error) {
+	repo.mu.Lock()
+	defer repo.mu.Unlock()
+
+	purged := 0
+	for slug, deletedAt := range repo.DeletedAtMemStore {
+		if purged >= limit {
+			break
+		}
+		if !repo.IsSlugDeletedMemStore[slug] || !deletedAt.Before(before) {
+			continue
+		}
+
+		url := repo.SlugMemStore[slug]
+		delete(repo.SlugMemStore, slug)
+		delete(repo.URLMemStore, url)
+		delete(repo.IsSlugDeletedMemStore, slug)
+		delete(repo.RefererMemStore, slug)
+		delete(repo.UserAgentMemStore, slug)
+		delete(repo.ExpiresAtMemStore, slug)
+		delete(repo.DeletedAtMemStore, slug)
+		delete(repo.PasswordHashMemStore, slug)
+		delete(repo.RedirectStatusMemStore, slug)
+		delete(repo.HostMemStore, slug)
+		delete(repo.CreatedAtMemStore, slug)
+		delete(repo.UpdatedAtMemStore, slug)
+		for userUUID, slugs := range repo.UserUUIDSlugMemStore {
+			if _, ok := slugs[slug]; ok {
+				delete(slugs, slug)
+				delete(repo.UserUUIDURLMemStore[userUUID], url)
+			}
+		}
+		for id, s := range repo.UUIDMemStore {
+			if s == slug {
+				delete(repo.UUIDMemStore, id)
+			}
+		}
+		purged++
+	}
+
+	return purged, nil
+}
+
+// TopHosts returns up to n hosts with the most non-deleted short URLs pointing at them,
+// ordered by count descending, using the host recorded for each slug at save time.
+func (repo *MemStorage) TopHosts(_ context.Context, n int) ([]model.HostCount, error) {
+	repo.mu.RLock()
+	defer repo.mu.RUnlock()
+
+	counts := make(map[string]int)
+	for shortURL := range repo.SlugMemStore {
+		if repo.IsSlugDeletedMemStore[shortURL] {
+			continue
+		}
+		counts[repo.HostMemStore[shortURL]]++
+	}
+
+	hostCounts := make([]model.HostCount, 0, len(counts))
+	for host, count := range counts {
+		hostCounts = append(hostCounts, model.HostCount{Host: host, Count: count})
+	}
+
+	sort.Slice(hostCounts, func(i, j int) bool {
+		if hostCounts[i].Count != hostCounts[j].Count {
+			return hostCounts[i].Count > hostCounts[j].Count
+		}
+		return hostCounts[i].Host < hostCounts[j].Host
+	})
+
+	if n > 0 && n < len(hostCounts) {
+		hostCounts = hostCounts[:n]
+	}
+
+	return hostCounts, nil
+}
+
+// Stats returns the total number of non-deleted short URLs and the total number of distinct
+// users that have ever saved one
+func (repo *MemStorage) Stats(_ context.Context) (model.Stats, error) {
+	repo.mu.RLock()
+	defer repo.mu.RUnlock()
+
+	urls := 0
+	for shortURL := range repo.SlugMemStore {
+		if repo.IsSlugDeletedMemStore[shortURL] {
+			continue
+		}
+		urls++
+	}
+
+	return model.Stats{URLs: urls, Users: len(repo.UserUUIDSlugMemStore)}, nil
+}
+
+// BackfillHosts computes and stores HostMemStore for up to limit slugs missing it (e.g. rows
+// loaded from a file written before the host column existed), returning how many slugs were
+// backfilled. A non-positive limit backfills every slug missing a host.
+func (repo *MemStorage) BackfillHosts(_ context.Context, limit int) (int, error) {
+	repo.mu.Lock()
+	defer repo.mu.Unlock()
+
+	backfilled := 0
+	for shortURL, originalURL := range repo.SlugMemStore {
+		if limit > 0 && backfilled >= limit {
+			break
+		}
+		if repo.HostMemStore[shortURL] != "" {
+			continue
+		}
+		repo.HostMemStore[shortURL] = HostOf(originalURL)
+		backfilled++
+	}
+
+	return backfilled, nil
+}
+
+// SetPasswordedSlug stores passwordHash as the bcrypt hash guarding shortURL
+func (repo *MemStorage) SetPasswordedSlug(_ context.Context, shortURL string, passwordHash string) error {
+	repo.mu.Lock()
+	defer repo.mu.Unlock()
+
+	if _, ok := repo.SlugMemStore[shortURL]; !ok {
+		return myerrors.ErrNotFound
+	}
+
+	repo.PasswordHashMemStore[shortURL] = passwordHash
+	return nil
+}
+
+// GetPasswordHash returns the bcrypt hash guarding shortURL, or "" if it is not password-protected
+func (repo *MemStorage) GetPasswordHash(_ context.Context, shortURL string) (string, error) {
+	repo.mu.RLock()
+	defer repo.mu.RUnlock()
+
+	return repo.PasswordHashMemStore[shortURL], nil
+}
+
+// SetRedirectStatus stores status as shortURL's per-link redirect status override
+func (repo *MemStorage) SetRedirectStatus(_ context.Context, shortURL string, status int) error {
+	repo.mu.Lock()
+	defer repo.mu.Unlock()
+
+	if _, ok := repo.SlugMemStore[shortURL]; !ok {
+		return myerrors.ErrNotFound
+	}
+
+	repo.RedirectStatusMemStore[shortURL] = status
+	return nil
+}
+
+// GetRedirectStatus returns shortURL's per-link redirect status override, or 0 if it has none
+func (repo *MemStorage) GetRedirectStatus(_ context.Context, shortURL string) (int, error) {
+	repo.mu.RLock()
+	defer repo.mu.RUnlock()
+
+	return repo.RedirectStatusMemStore[shortURL], nil
+}
+
+// NextSequence returns the next value in a process-local, monotonically increasing sequence,
+// starting at 1. It is not persisted, so it resets to 1 on restart.
+func (repo *MemStorage) NextSequence(_ context.Context) (int64, error) {
+	return atomic.AddInt64(&repo.sequence, 1), nil
+}
+
+// SaveAPIKey stores a new API key record, keyed by the sha256 hash of its plaintext form
+func (repo *MemStorage) SaveAPIKey(_ context.Context, userUUID uuid.UUID, keyHash string, createdAt time.Time) error {
+	repo.mu.Lock()
+	defer repo.mu.Unlock()
+
+	repo.APIKeyUserUUIDMemStore[keyHash] = userUUID
+	repo.APIKeyCreatedAtMemStore[keyHash] = createdAt
+	return nil
+}
+
+// ResolveAPIKey returns the UserUUID owning keyHash, or myerrors.ErrNotFound if it does not
+// exist or has been revoked
+func (repo *MemStorage) ResolveAPIKey(_ context.Context, keyHash string) (uuid.UUID, error) {
+	repo.mu.RLock()
+	defer repo.mu.RUnlock()
+
+	userUUID, ok := repo.APIKeyUserUUIDMemStore[keyHash]
+	if !ok || repo.APIKeyRevokedMemStore[keyHash] {
+		return uuid.Nil, myerrors.ErrNotFound
+	}
+	return userUUID, nil
+}
+
+// RevokeAPIKey marks keyHash as revoked, if it exists and is owned by userUUID
+func (repo *MemStorage) RevokeAPIKey(_ context.Context, userUUID uuid.UUID, keyHash string) error {
+	repo.mu.Lock()
+	defer repo.mu.Unlock()
+
+	owner, ok := repo.APIKeyUserUUIDMemStore[keyHash]
+	if !ok {
+		return myerrors.ErrNotFound
+	}
+	if owner != userUUID {
+		return myerrors.ErrForbidden
+	}
+	repo.APIKeyRevokedMemStore[keyHash] = true
+	return nil
+}
+
+// Ping reports that the in-memory backend is always reachable
+func (repo *MemStorage) Ping(_ context.Context) error {
+	return nil
+}
+
+// Verify checks that the maps making up the in-memory store agree with each other, returning a
+// human-readable description of each inconsistency found. A nil result means the store is
+// consistent. It exists to catch bugs in the code that maintains these maps, not user errors.
+func (repo *MemStorage) Verify(_ context.Context) ([]string, error) {
+	repo.mu.RLock()
+	defer repo.mu.RUnlock()
+
+	var violations []string
+
+	for shortURL, originalURL := range repo.SlugMemStore {
+		if repo.URLMemStore[originalURL] != shortURL {
+			violations = append(violations, fmt.Sprintf("slug %q maps to url %q, but url maps back to slug %q", shortURL, originalURL, repo.URLMemStore[originalURL]))
+		}
+	}
+	for originalURL, shortURL := range repo.URLMemStore {
+		if _, ok := repo.SlugMemStore[shortURL]; !ok {
+			violations = append(violations, fmt.Sprintf("url %q maps to slug %q, which has no entry in SlugMemStore", originalURL, shortURL))
+		}
+	}
+
+	for shortURL, deleted := range repo.IsSlugDeletedMemStore {
+		hasDeletedAt := !repo.DeletedAtMemStore[shortURL].IsZero()
+		if deleted != hasDeletedAt {
+			violations = append(violations, fmt.Sprintf("slug %q has inconsistent deleted state: IsSlugDeletedMemStore=%t, DeletedAtMemStore set=%t", shortURL, deleted, hasDeletedAt))
+		}
+	}
+
+	for userUUID, slugs := range repo.UserUUIDSlugMemStore {
+		for shortURL, originalURL := range slugs {
+			if canonical, ok := repo.SlugMemStore[shortURL]; !ok || canonical != originalURL {
+				violations = append(violations, fmt.Sprintf("user %s has slug %q mapped to url %q, but SlugMemStore has %q", userUUID, shortURL, originalURL, canonical))
+			}
+			if repo.UserUUIDURLMemStore[userUUID][originalURL] != shortURL {
+				violations = append(violations, fmt.Sprintf("user %s has slug %q for url %q, but UserUUIDURLMemStore maps that url back to slug %q", userUUID, shortURL, originalURL, repo.UserUUIDURLMemStore[userUUID][originalURL]))
+			}
+		}
+	}
+
+	sort.Strings(violations)
+	return violations, nil
+}
+
+// Close is a no-op; the in-memory backend holds no resources to release
+func (repo *MemStorage) Close() error {
+	return nil
+}
+
+// Snapshot writes one record per known short URL to filePath, in the same JSON-lines format
+// FileStorage persists to, overwriting any previous snapshot
+func (repo *MemStorage) Snapshot(filePath string) error {
+	repo.mu.RLock()
+	defer repo.mu.RUnlock()
+
+	var buf bytes.Buffer
+	for shortURL, longURL := range repo.SlugMemStore {
+		record := model.URL{
+			ShortURL:       shortURL,
+			OriginalURL:    longURL,
+			IsDeleted:      repo.IsSlugDeletedMemStore[shortURL],
+			Referer:        repo.RefererMemStore[shortURL],
+			UserAgent:      repo.UserAgentMemStore[shortURL],
+			ExpiresAt:      repo.ExpiresAtMemStore[shortURL],
+			DeletedAt:      repo.DeletedAtMemStore[shortURL],
+			PasswordHash:   repo.PasswordHashMemStore[shortURL],
+			RedirectStatus: repo.RedirectStatusMemStore[shortURL],
+			Host:           repo.HostMemStore[shortURL],
+			CreatedAt:      repo.CreatedAtMemStore[shortURL],
+			UpdatedAt:      repo.UpdatedAtMemStore[shortURL],
+		}
+		for id, slug := range repo.UUIDMemStore {
+			if slug == shortURL {
+				record.UUID = id
+				break
+			}
+		}
+		for userUUID, slugs := range repo.UserUUIDSlugMemStore {
+			if _, ok := slugs[shortURL]; ok {
+				record.UserUUID = userUUID
+				break
+			}
+		}
+
+		jsonLine, err := json.Marshal(record)
+		if err != nil {
+			return fmt.Errorf("cannot marshal json: %w", err)
+		}
+		buf.Write(jsonLine)
+		buf.WriteByte('\n')
+	}
+
+	if err := os.WriteFile(filePath, buf.Bytes(), 0666); err != nil {
+		return fmt.Errorf("cannot write snapshot file: %w", err)
+	}
+
+	return nil
+}
+
+// RestoreSnapshot loads a file written by Snapshot back into the maps, so a restart without
+// Postgres, Redis, S3, or file storage configured doesn't lose everything saved since the
+// last snapshot. A missing file is not an error, since the first run has nothing to restore.
+func (repo *MemStorage) RestoreSnapshot(filePath string) error {
+	file, err := os.ReadFile(filePath)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil
+		}
+		return err
+	}
+	if len(file) == 0 {
+		return nil
+	}
+
+	repo.mu.Lock()
+	defer repo.mu.Unlock()
+
+	decoder := json.NewDecoder(bytes.NewReader(file))
+	for {
+		var urlMapping model.URL
+		err := decoder.Decode(&urlMapping)
+		if err != nil {
+			if err.Error() == "EOF" {
+				break
+			}
+			return err
+		}
+
+		if repo.UserUUIDURLMemStore[urlMapping.UserUUID] == nil {
+			repo.UserUUIDURLMemStore[urlMapping.UserUUID] = make(URLMemStore)
+		}
+		if repo.UserUUIDSlugMemStore[urlMapping.UserUUID] == nil {
+			repo.UserUUIDSlugMemStore[urlMapping.UserUUID] = make(SlugMemStore)
+		}
+
+		repo.SlugMemStore[urlMapping.ShortURL] = urlMapping.OriginalURL
+		repo.URLMemStore[urlMapping.OriginalURL] = urlMapping.ShortURL
+		repo.UserUUIDURLMemStore[urlMapping.UserUUID][urlMapping.OriginalURL] = urlMapping.ShortURL
+		repo.UserUUIDSlugMemStore[urlMapping.UserUUID][urlMapping.ShortURL] = urlMapping.OriginalURL
+		repo.UUIDMemStore[urlMapping.UUID] = urlMapping.ShortURL
+		repo.IsSlugDeletedMemStore[urlMapping.ShortURL] = urlMapping.IsDeleted
+		repo.RefererMemStore[urlMapping.ShortURL] = urlMapping.Referer
+		repo.UserAgentMemStore[urlMapping.ShortURL] = urlMapping.UserAgent
+		repo.ExpiresAtMemStore[urlMapping.ShortURL] = urlMapping.ExpiresAt
+		repo.DeletedAtMemStore[urlMapping.ShortURL] = urlMapping.DeletedAt
+		repo.CreatedAtMemStore[urlMapping.ShortURL] = urlMapping.CreatedAt
+		repo.UpdatedAtMemStore[urlMapping.ShortURL] = urlMapping.UpdatedAt
+		if urlMapping.PasswordHash != "" {
+			repo.PasswordHashMemStore[urlMapping.ShortURL] = urlMapping.PasswordHash
+		}
+		if urlMapping.RedirectStatus != 0 {
+			repo.RedirectStatusMemStore[urlMapping.ShortURL] = urlMapping.RedirectStatus
+		}
+		repo.HostMemStore[urlMapping.ShortURL] = urlMapping.Host
+	}
+
+	return nil
+}
+
+// RunSnapshotLoop calls Snapshot on filePath every interval until ctx is done, logging (but
+// not stopping on) any error a snapshot attempt returns
+func (repo *MemStorage) RunSnapshotLoop(ctx context.Context, filePath string, interval time.Duration, zlog zerolog.Logger) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			if err := repo.Snapshot(filePath); err != nil {
+				zlog.Error().Err(err).Msg("failed to snapshot memory repository")
+			}
+		}
+	}
+}
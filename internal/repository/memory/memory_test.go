@@ -4,10 +4,17 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"os"
+	"reflect"
+	"strconv"
+	"sync"
 	"testing"
+	"time"
 
+	"github.com/ar4ie13/shortener/internal/model"
 	"github.com/ar4ie13/shortener/internal/myerrors"
 	"github.com/google/uuid"
+	"github.com/rs/zerolog"
 )
 
 func TestNewMemStorage(t *testing.T) {
@@ -109,7 +116,7 @@ func TestMemory_Get(t *testing.T) {
 				SlugMemStore: tt.fields.slugMemStore,
 				URLMemStore:  tt.fields.urlMemStore,
 			}
-			got, err := repo.GetURL(context.Background(), tt.args.slug)
+			got, err := repo.GetURL(context.Background(), uuid.New(), tt.args.slug, false)
 			if got != tt.want {
 				t.Errorf("GetURL() got = %v, want %v", got, tt.want)
 			}
@@ -131,6 +138,10 @@ func TestMemory_Save(t *testing.T) {
 		UserUUIDURLMemStore   map[uuid.UUID]URLMemStore
 		UserUUIDSlugMemStore  map[uuid.UUID]SlugMemStore
 		IsSlugDeletedMemStore IsSlugDeletedMemStore
+		RefererMemStore       RefererMemStore
+		UserAgentMemStore     UserAgentMemStore
+		ExpiresAtMemStore     ExpiresAtMemStore
+		HostMemStore          HostMemStore
 	}
 	type args struct {
 		slug string
@@ -156,6 +167,10 @@ func TestMemory_Save(t *testing.T) {
 				UserUUIDURLMemStore:   map[uuid.UUID]URLMemStore{},
 				UserUUIDSlugMemStore:  map[uuid.UUID]SlugMemStore{},
 				IsSlugDeletedMemStore: IsSlugDeletedMemStore{},
+				RefererMemStore:       RefererMemStore{},
+				UserAgentMemStore:     UserAgentMemStore{},
+				ExpiresAtMemStore:     ExpiresAtMemStore{},
+				HostMemStore:          HostMemStore{},
 			},
 			args: args{
 				slug: "abc12",
@@ -177,6 +192,10 @@ func TestMemory_Save(t *testing.T) {
 				UserUUIDURLMemStore:   map[uuid.UUID]URLMemStore{},
 				UserUUIDSlugMemStore:  map[uuid.UUID]SlugMemStore{},
 				IsSlugDeletedMemStore: IsSlugDeletedMemStore{},
+				RefererMemStore:       RefererMemStore{},
+				UserAgentMemStore:     UserAgentMemStore{},
+				ExpiresAtMemStore:     ExpiresAtMemStore{},
+				HostMemStore:          HostMemStore{},
 			},
 			args: args{
 				slug: "abc12",
@@ -198,6 +217,10 @@ func TestMemory_Save(t *testing.T) {
 				UserUUIDURLMemStore:   map[uuid.UUID]URLMemStore{},
 				UserUUIDSlugMemStore:  map[uuid.UUID]SlugMemStore{},
 				IsSlugDeletedMemStore: IsSlugDeletedMemStore{},
+				RefererMemStore:       RefererMemStore{},
+				UserAgentMemStore:     UserAgentMemStore{},
+				ExpiresAtMemStore:     ExpiresAtMemStore{},
+				HostMemStore:          HostMemStore{},
 			},
 			args: args{
 				slug: "",
@@ -219,6 +242,10 @@ func TestMemory_Save(t *testing.T) {
 				UserUUIDURLMemStore:   map[uuid.UUID]URLMemStore{},
 				UserUUIDSlugMemStore:  map[uuid.UUID]SlugMemStore{},
 				IsSlugDeletedMemStore: IsSlugDeletedMemStore{},
+				RefererMemStore:       RefererMemStore{},
+				UserAgentMemStore:     UserAgentMemStore{},
+				ExpiresAtMemStore:     ExpiresAtMemStore{},
+				HostMemStore:          HostMemStore{},
 			},
 			args: args{
 				slug: "abc",
@@ -237,9 +264,15 @@ func TestMemory_Save(t *testing.T) {
 				UserUUIDSlugMemStore:  tt.fields.UserUUIDSlugMemStore,
 				UserUUIDURLMemStore:   tt.fields.UserUUIDURLMemStore,
 				IsSlugDeletedMemStore: tt.fields.IsSlugDeletedMemStore,
+				RefererMemStore:       tt.fields.RefererMemStore,
+				UserAgentMemStore:     tt.fields.UserAgentMemStore,
+				ExpiresAtMemStore:     tt.fields.ExpiresAtMemStore,
+				HostMemStore:          tt.fields.HostMemStore,
+				CreatedAtMemStore:     make(map[string]time.Time),
+				UpdatedAtMemStore:     make(map[string]time.Time),
 			}
 
-			if err := repo.Save(context.Background(), uuid.Nil, tt.args.slug, tt.args.url); (err != nil) != tt.wantErr || !errors.Is(err, tt.wantErrName) {
+			if _, err := repo.Save(context.Background(), uuid.Nil, tt.args.slug, tt.args.url, "", "", time.Time{}, time.Time{}); (err != nil) != tt.wantErr || !errors.Is(err, tt.wantErrName) {
 				fmt.Println(err, tt.wantErrName)
 				t.Errorf("Save() error = %s, wantErr %s", err, tt.wantErrName)
 			}
@@ -247,6 +280,56 @@ func TestMemory_Save(t *testing.T) {
 	}
 }
 
+func TestMemory_Save_CreationSource(t *testing.T) {
+	tests := []struct {
+		name          string
+		referer       string
+		userAgent     string
+		wantReferer   string
+		wantUserAgent string
+	}{
+		{
+			name:          "tracking enabled values are persisted",
+			referer:       "https://example.com/page",
+			userAgent:     "curl/8.0",
+			wantReferer:   "https://example.com/page",
+			wantUserAgent: "curl/8.0",
+		},
+		{
+			name:          "tracking disabled values are omitted",
+			referer:       "",
+			userAgent:     "",
+			wantReferer:   "",
+			wantUserAgent: "",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			repo := NewMemStorage()
+			userUUID := uuid.New()
+
+			if _, err := repo.Save(context.Background(), userUUID, "abc123", "https://example.com", tt.referer, tt.userAgent, time.Time{}, time.Time{}); err != nil {
+				t.Fatalf("Save() unexpected error = %v", err)
+			}
+
+			if got := repo.RefererMemStore["abc123"]; got != tt.wantReferer {
+				t.Errorf("RefererMemStore[abc123] = %v, want %v", got, tt.wantReferer)
+			}
+			if got := repo.UserAgentMemStore["abc123"]; got != tt.wantUserAgent {
+				t.Errorf("UserAgentMemStore[abc123] = %v, want %v", got, tt.wantUserAgent)
+			}
+
+			urls, err := repo.GetUserShortURLs(context.Background(), userUUID)
+			if err != nil {
+				t.Fatalf("GetUserShortURLs() unexpected error = %v", err)
+			}
+			if len(urls) != 1 || urls[0].Referer != tt.wantReferer || urls[0].UserAgent != tt.wantUserAgent {
+				t.Errorf("GetUserShortURLs() = %+v, want Referer=%v UserAgent=%v", urls, tt.wantReferer, tt.wantUserAgent)
+			}
+		})
+	}
+}
+
 func TestMemory_existsURL(t *testing.T) {
 	type fields struct {
 		SlugMemStore map[string]string
@@ -363,3 +446,374 @@ func TestMemory_existsShortURL(t *testing.T) {
 		})
 	}
 }
+
+func TestMemory_TopHosts(t *testing.T) {
+	repo := NewMemStorage()
+	ctx := context.Background()
+
+	for _, u := range []string{
+		"https://spam.example.com/a",
+		"https://spam.example.com/b",
+		"https://spam.example.com/c",
+		"https://legit.example.org/x",
+		"https://deleted.example.net/y",
+	} {
+		slug := u[len(u)-1:]
+		if _, err := repo.Save(ctx, uuid.New(), slug, u, "", "", time.Time{}, time.Time{}); err != nil {
+			t.Fatalf("Save(%q) error = %v", u, err)
+		}
+	}
+	if err := repo.DeleteUserShortURLs(ctx, map[uuid.UUID][]string{}, time.Time{}); err != nil {
+		t.Fatalf("DeleteUserShortURLs() error = %v", err)
+	}
+	repo.IsSlugDeletedMemStore["y"] = true
+
+	got, err := repo.TopHosts(ctx, 2)
+	if err != nil {
+		t.Fatalf("TopHosts() error = %v", err)
+	}
+
+	want := []model.HostCount{
+		{Host: "spam.example.com", Count: 3},
+		{Host: "legit.example.org", Count: 1},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("TopHosts() = %v, want %v", got, want)
+	}
+}
+
+func TestMemory_Stats(t *testing.T) {
+	repo := NewMemStorage()
+	ctx := context.Background()
+
+	userA := uuid.New()
+	userB := uuid.New()
+	if _, err := repo.Save(ctx, userA, "a", "https://example.com/a", "", "", time.Time{}, time.Time{}); err != nil {
+		t.Fatalf("Save() error = %v", err)
+	}
+	if _, err := repo.Save(ctx, userA, "b", "https://example.com/b", "", "", time.Time{}, time.Time{}); err != nil {
+		t.Fatalf("Save() error = %v", err)
+	}
+	if _, err := repo.Save(ctx, userB, "c", "https://example.com/c", "", "", time.Time{}, time.Time{}); err != nil {
+		t.Fatalf("Save() error = %v", err)
+	}
+	if err := repo.DeleteUserShortURLs(ctx, map[uuid.UUID][]string{userB: {"c"}}, time.Time{}); err != nil {
+		t.Fatalf("DeleteUserShortURLs() error = %v", err)
+	}
+
+	got, err := repo.Stats(ctx)
+	if err != nil {
+		t.Fatalf("Stats() error = %v", err)
+	}
+
+	want := model.Stats{URLs: 2, Users: 2}
+	if got != want {
+		t.Errorf("Stats() = %+v, want %+v", got, want)
+	}
+}
+
+func TestMemory_Verify(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("consistent store reports no violations", func(t *testing.T) {
+		repo := NewMemStorage()
+		if _, err := repo.Save(ctx, uuid.New(), "abc123", "https://example.com", "", "", time.Time{}, time.Time{}); err != nil {
+			t.Fatalf("Save() error = %v", err)
+		}
+
+		got, err := repo.Verify(ctx)
+		if err != nil {
+			t.Fatalf("Verify() error = %v", err)
+		}
+		if len(got) != 0 {
+			t.Errorf("Verify() = %v, want no violations", got)
+		}
+	})
+
+	t.Run("dangling url entry is reported", func(t *testing.T) {
+		repo := NewMemStorage()
+		if _, err := repo.Save(ctx, uuid.New(), "abc123", "https://example.com", "", "", time.Time{}, time.Time{}); err != nil {
+			t.Fatalf("Save() error = %v", err)
+		}
+		repo.URLMemStore["https://orphan.example.com"] = "missing-slug"
+
+		got, err := repo.Verify(ctx)
+		if err != nil {
+			t.Fatalf("Verify() error = %v", err)
+		}
+		want := []string{`url "https://orphan.example.com" maps to slug "missing-slug", which has no entry in SlugMemStore`}
+		if !reflect.DeepEqual(got, want) {
+			t.Errorf("Verify() = %v, want %v", got, want)
+		}
+	})
+
+	t.Run("deleted state mismatch is reported", func(t *testing.T) {
+		repo := NewMemStorage()
+		if _, err := repo.Save(ctx, uuid.New(), "abc123", "https://example.com", "", "", time.Time{}, time.Time{}); err != nil {
+			t.Fatalf("Save() error = %v", err)
+		}
+		repo.IsSlugDeletedMemStore["abc123"] = true
+
+		got, err := repo.Verify(ctx)
+		if err != nil {
+			t.Fatalf("Verify() error = %v", err)
+		}
+		want := []string{`slug "abc123" has inconsistent deleted state: IsSlugDeletedMemStore=true, DeletedAtMemStore set=false`}
+		if !reflect.DeepEqual(got, want) {
+			t.Errorf("Verify() = %v, want %v", got, want)
+		}
+	})
+
+	t.Run("user index pointing at stale url is reported", func(t *testing.T) {
+		repo := NewMemStorage()
+		userUUID := uuid.New()
+		if _, err := repo.Save(ctx, userUUID, "abc123", "https://example.com", "", "", time.Time{}, time.Time{}); err != nil {
+			t.Fatalf("Save() error = %v", err)
+		}
+		repo.UserUUIDSlugMemStore[userUUID]["abc123"] = "https://stale.example.com"
+
+		got, err := repo.Verify(ctx)
+		if err != nil {
+			t.Fatalf("Verify() error = %v", err)
+		}
+		want := []string{fmt.Sprintf(`user %s has slug "abc123" for url "https://stale.example.com", but UserUUIDURLMemStore maps that url back to slug ""`, userUUID), fmt.Sprintf(`user %s has slug "abc123" mapped to url "https://stale.example.com", but SlugMemStore has "https://example.com"`, userUUID)}
+		if !reflect.DeepEqual(got, want) {
+			t.Errorf("Verify() = %v, want %v", got, want)
+		}
+	})
+}
+
+func TestMemory_GetURL_RequireOwnership(t *testing.T) {
+	ctx := context.Background()
+	repo := NewMemStorage()
+	owner := uuid.New()
+	other := uuid.New()
+	if _, err := repo.Save(ctx, owner, "abc123", "https://example.com", "", "", time.Time{}, time.Time{}); err != nil {
+		t.Fatalf("Save() error = %v", err)
+	}
+
+	if got, err := repo.GetURL(ctx, owner, "abc123", true); err != nil || got != "https://example.com" {
+		t.Errorf("GetURL() owner = %q, %v, want %q, nil", got, err, "https://example.com")
+	}
+
+	if _, err := repo.GetURL(ctx, other, "abc123", true); !errors.Is(err, myerrors.ErrNotFound) {
+		t.Errorf("GetURL() non-owner error = %v, want %v", err, myerrors.ErrNotFound)
+	}
+
+	if got, err := repo.GetURL(ctx, other, "abc123", false); err != nil || got != "https://example.com" {
+		t.Errorf("GetURL() public mode = %q, %v, want %q, nil", got, err, "https://example.com")
+	}
+}
+
+func TestMemory_UpdateURL(t *testing.T) {
+	ctx := context.Background()
+	owner := uuid.New()
+	other := uuid.New()
+
+	t.Run("owner updates destination", func(t *testing.T) {
+		repo := NewMemStorage()
+		if _, err := repo.Save(ctx, owner, "abc123", "https://example.com", "", "", time.Time{}, time.Time{}); err != nil {
+			t.Fatalf("Save() error = %v", err)
+		}
+		if err := repo.UpdateURL(ctx, owner, "abc123", "https://updated.example.com", time.Time{}); err != nil {
+			t.Fatalf("UpdateURL() error = %v", err)
+		}
+		if got, err := repo.GetURL(ctx, owner, "abc123", false); err != nil || got != "https://updated.example.com" {
+			t.Errorf("GetURL() after update = %q, %v, want %q, nil", got, err, "https://updated.example.com")
+		}
+	})
+
+	t.Run("non-owner is forbidden", func(t *testing.T) {
+		repo := NewMemStorage()
+		if _, err := repo.Save(ctx, owner, "abc123", "https://example.com", "", "", time.Time{}, time.Time{}); err != nil {
+			t.Fatalf("Save() error = %v", err)
+		}
+		if err := repo.UpdateURL(ctx, other, "abc123", "https://updated.example.com", time.Time{}); !errors.Is(err, myerrors.ErrForbidden) {
+			t.Errorf("UpdateURL() non-owner error = %v, want %v", err, myerrors.ErrForbidden)
+		}
+	})
+
+	t.Run("unknown slug is not found", func(t *testing.T) {
+		repo := NewMemStorage()
+		if err := repo.UpdateURL(ctx, owner, "missing", "https://updated.example.com", time.Time{}); !errors.Is(err, myerrors.ErrNotFound) {
+			t.Errorf("UpdateURL() unknown slug error = %v, want %v", err, myerrors.ErrNotFound)
+		}
+	})
+
+	t.Run("new URL already in use", func(t *testing.T) {
+		repo := NewMemStorage()
+		if _, err := repo.Save(ctx, owner, "abc123", "https://example.com", "", "", time.Time{}, time.Time{}); err != nil {
+			t.Fatalf("Save() error = %v", err)
+		}
+		if _, err := repo.Save(ctx, owner, "xyz789", "https://taken.example.com", "", "", time.Time{}, time.Time{}); err != nil {
+			t.Fatalf("Save() error = %v", err)
+		}
+		if err := repo.UpdateURL(ctx, owner, "abc123", "https://taken.example.com", time.Time{}); !errors.Is(err, myerrors.ErrURLExist) {
+			t.Errorf("UpdateURL() duplicate URL error = %v, want %v", err, myerrors.ErrURLExist)
+		}
+	})
+}
+
+func TestMemory_APIKeys(t *testing.T) {
+	ctx := context.Background()
+	owner := uuid.New()
+	other := uuid.New()
+
+	t.Run("owner resolves saved key", func(t *testing.T) {
+		repo := NewMemStorage()
+		if err := repo.SaveAPIKey(ctx, owner, "hash1", time.Now()); err != nil {
+			t.Fatalf("SaveAPIKey() error = %v", err)
+		}
+		if got, err := repo.ResolveAPIKey(ctx, "hash1"); err != nil || got != owner {
+			t.Errorf("ResolveAPIKey() = %v, %v, want %v, nil", got, err, owner)
+		}
+	})
+
+	t.Run("unknown key is not found", func(t *testing.T) {
+		repo := NewMemStorage()
+		if _, err := repo.ResolveAPIKey(ctx, "missing"); !errors.Is(err, myerrors.ErrNotFound) {
+			t.Errorf("ResolveAPIKey() unknown key error = %v, want %v", err, myerrors.ErrNotFound)
+		}
+	})
+
+	t.Run("non-owner cannot revoke", func(t *testing.T) {
+		repo := NewMemStorage()
+		if err := repo.SaveAPIKey(ctx, owner, "hash1", time.Now()); err != nil {
+			t.Fatalf("SaveAPIKey() error = %v", err)
+		}
+		if err := repo.RevokeAPIKey(ctx, other, "hash1"); !errors.Is(err, myerrors.ErrForbidden) {
+			t.Errorf("RevokeAPIKey() non-owner error = %v, want %v", err, myerrors.ErrForbidden)
+		}
+	})
+
+	t.Run("revoked key is no longer resolvable", func(t *testing.T) {
+		repo := NewMemStorage()
+		if err := repo.SaveAPIKey(ctx, owner, "hash1", time.Now()); err != nil {
+			t.Fatalf("SaveAPIKey() error = %v", err)
+		}
+		if err := repo.RevokeAPIKey(ctx, owner, "hash1"); err != nil {
+			t.Fatalf("RevokeAPIKey() error = %v", err)
+		}
+		if _, err := repo.ResolveAPIKey(ctx, "hash1"); !errors.Is(err, myerrors.ErrNotFound) {
+			t.Errorf("ResolveAPIKey() after revoke error = %v, want %v", err, myerrors.ErrNotFound)
+		}
+	})
+}
+
+func TestMemory_Snapshot(t *testing.T) {
+	ctx := context.Background()
+	owner := uuid.New()
+	filePath := t.TempDir() + "/snapshot.jsonl"
+
+	t.Run("round trips saved and deleted state", func(t *testing.T) {
+		repo := NewMemStorage()
+		if _, err := repo.Save(ctx, owner, "abc123", "https://example.com", "", "", time.Time{}, time.Time{}); err != nil {
+			t.Fatalf("Save() error = %v", err)
+		}
+		if _, err := repo.Save(ctx, owner, "xyz789", "https://deleted.example.com", "", "", time.Time{}, time.Time{}); err != nil {
+			t.Fatalf("Save() error = %v", err)
+		}
+		if err := repo.DeleteUserShortURLs(ctx, map[uuid.UUID][]string{owner: {"xyz789"}}, time.Now()); err != nil {
+			t.Fatalf("DeleteUserShortURLs() error = %v", err)
+		}
+
+		if err := repo.Snapshot(filePath); err != nil {
+			t.Fatalf("Snapshot() error = %v", err)
+		}
+
+		restored := NewMemStorage()
+		if err := restored.RestoreSnapshot(filePath); err != nil {
+			t.Fatalf("RestoreSnapshot() error = %v", err)
+		}
+
+		if got, err := restored.GetURL(ctx, owner, "abc123", false); err != nil || got != "https://example.com" {
+			t.Errorf("GetURL() after restore = %q, %v, want %q, nil", got, err, "https://example.com")
+		}
+		if _, err := restored.GetURL(ctx, owner, "xyz789", false); !errors.As(err, new(*myerrors.DeletedURLError)) {
+			t.Errorf("GetURL() after restore for deleted slug error = %v, want *myerrors.DeletedURLError", err)
+		}
+	})
+
+	t.Run("restoring a missing file is a no-op", func(t *testing.T) {
+		repo := NewMemStorage()
+		if err := repo.RestoreSnapshot(t.TempDir() + "/missing.jsonl"); err != nil {
+			t.Errorf("RestoreSnapshot() error = %v, want nil", err)
+		}
+	})
+}
+
+func TestMemory_RunSnapshotLoop(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	repo := NewMemStorage()
+	owner := uuid.New()
+	if _, err := repo.Save(ctx, owner, "abc123", "https://example.com", "", "", time.Time{}, time.Time{}); err != nil {
+		t.Fatalf("Save() error = %v", err)
+	}
+
+	filePath := t.TempDir() + "/snapshot.jsonl"
+	loopCtx, loopCancel := context.WithCancel(context.Background())
+	done := make(chan struct{})
+	go func() {
+		repo.RunSnapshotLoop(loopCtx, filePath, time.Millisecond, zerolog.Nop())
+		close(done)
+	}()
+
+	deadline := time.After(time.Second)
+	for {
+		if _, err := os.Stat(filePath); err == nil {
+			break
+		}
+		select {
+		case <-deadline:
+			loopCancel()
+			t.Fatal("RunSnapshotLoop() did not write a snapshot in time")
+		case <-time.After(time.Millisecond):
+		}
+	}
+
+	loopCancel()
+	<-done
+
+	restored := NewMemStorage()
+	if err := restored.RestoreSnapshot(filePath); err != nil {
+		t.Fatalf("RestoreSnapshot() error = %v", err)
+	}
+	if got, err := restored.GetURL(ctx, owner, "abc123", false); err != nil || got != "https://example.com" {
+		t.Errorf("GetURL() after snapshot loop = %q, %v, want %q, nil", got, err, "https://example.com")
+	}
+}
+
+// TestMemory_RunSnapshotLoop_ConcurrentWithSave saves many URLs concurrently while
+// RunSnapshotLoop is ranging over the same maps on its own goroutine, which would crash with
+// "concurrent map iteration and map write" if the two were not serialized against each other.
+func TestMemory_RunSnapshotLoop_ConcurrentWithSave(t *testing.T) {
+	repo := NewMemStorage()
+	filePath := t.TempDir() + "/snapshot.jsonl"
+
+	loopCtx, loopCancel := context.WithCancel(context.Background())
+	done := make(chan struct{})
+	go func() {
+		repo.RunSnapshotLoop(loopCtx, filePath, time.Millisecond, zerolog.Nop())
+		close(done)
+	}()
+
+	const n = 50
+	var wg sync.WaitGroup
+	wg.Add(n)
+	for i := 0; i < n; i++ {
+		go func(i int) {
+			defer wg.Done()
+			shortURL := "slug" + strconv.Itoa(i)
+			longURL := "https://example.com/" + strconv.Itoa(i)
+			if _, err := repo.Save(context.Background(), uuid.New(), shortURL, longURL, "", "", time.Time{}, time.Time{}); err != nil {
+				t.Errorf("Save() error = %v", err)
+			}
+		}(i)
+	}
+	wg.Wait()
+
+	loopCancel()
+	<-done
+}
@@ -0,0 +1,83 @@
+package repository
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/rs/zerolog"
+)
+
+// togglingPool implements pingable, flipping between success and failure each time Ping is
+// called so runPingLoop's transition tracking can be exercised deterministically
+type togglingPool struct {
+	mu      sync.Mutex
+	healthy bool
+}
+
+func (p *togglingPool) Ping(_ context.Context) error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if p.healthy {
+		return nil
+	}
+	return errors.New("connection refused")
+}
+
+func (p *togglingPool) setHealthy(healthy bool) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.healthy = healthy
+}
+
+func TestRunPingLoop_TracksHealthAndLogsTransitions(t *testing.T) {
+	pool := &togglingPool{healthy: true}
+	readiness := NewReadiness()
+	readiness.markReady()
+
+	var logBuf bytes.Buffer
+	zlog := zerolog.New(&logBuf)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	go runPingLoop(ctx, pool, readiness, 5*time.Millisecond, zlog)
+
+	if !waitFor(func() bool { return readiness.Ready() }, time.Second) {
+		t.Fatal("readiness never reported healthy while the pool was healthy")
+	}
+
+	pool.setHealthy(false)
+	if !waitFor(func() bool { return !readiness.Ready() }, time.Second) {
+		t.Fatal("readiness never reported unhealthy after the pool went down")
+	}
+
+	pool.setHealthy(true)
+	if !waitFor(func() bool { return readiness.Ready() }, time.Second) {
+		t.Fatal("readiness never recovered after the pool came back")
+	}
+
+	logs := logBuf.String()
+	if !strings.Contains(logs, "unhealthy") {
+		t.Errorf("log output = %q, want it to mention the unhealthy transition", logs)
+	}
+	if !strings.Contains(logs, "recovered") {
+		t.Errorf("log output = %q, want it to mention the recovered transition", logs)
+	}
+}
+
+// waitFor polls cond until it returns true or timeout elapses
+func waitFor(cond func() bool, timeout time.Duration) bool {
+	deadline := time.Now().Add(timeout)
+	for time.Now().Before(deadline) {
+		if cond() {
+			return true
+		}
+		time.Sleep(time.Millisecond)
+	}
+	return cond()
+}
@@ -0,0 +1,6 @@
+package config
+
+// Config contains the DSN for a redis connection
+type Config struct {
+	RedisDSN string
+}
@@ -0,0 +1,957 @@
+// Package redis implements service.Repository against a redis server, for deployments where
+// persistence across restarts is needed but running a full postgres instance is overkill.
+package redis
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"net/url"
+	"sort"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/ar4ie13/shortener/internal/model"
+	"github.com/ar4ie13/shortener/internal/myerrors"
+	"github.com/ar4ie13/shortener/internal/repository/redis/config"
+	"github.com/ar4ie13/shortener/internal/requestlog"
+	"github.com/google/uuid"
+	"github.com/redis/go-redis/v9"
+	"github.com/rs/zerolog"
+)
+
+// defaultBackfillLimit bounds a single BackfillHosts call when the caller passes no limit
+const defaultBackfillLimit = 1000
+
+// expiringKey is the sorted set of slugs with a TTL, scored by their expiry time, so
+// SweepExpired can find due slugs without scanning every key
+const expiringKey = "expiring"
+
+// deletedKey is the sorted set of soft-deleted slugs, scored by their deletion time, so
+// PurgeDeleted can find due slugs without scanning every key
+const deletedKey = "deleted"
+
+// hostCountsKey is the sorted set of hostnames scored by how many non-deleted short URLs
+// point at them, maintained incrementally so TopHosts doesn't have to scan every key
+const hostCountsKey = "hostcounts"
+
+// sequenceKey counts toward NextSequence, shared by every instance pointed at the same redis
+const sequenceKey = "slugseq"
+
+// DB is a main redis repository object
+type DB struct {
+	client *redis.Client
+	zlog   zerolog.Logger
+	// mu serializes Save and SaveBatch's check-then-act dedup/collision checks against their
+	// writes, since redis has no transaction spanning them otherwise: two concurrent Save calls
+	// for the same originalURL or shortURL could both pass the existence check and then both
+	// write, silently clobbering one another.
+	mu sync.Mutex
+}
+
+// NewDB connects to redis using cfg.RedisDSN and pings it once so a misconfigured DSN fails
+// fast instead of surfacing on the first request
+func NewDB(ctx context.Context, cfg config.Config, zlog zerolog.Logger) (*DB, error) {
+	opts, err := redis.ParseURL(cfg.RedisDSN)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse the redis DSN: %w", err)
+	}
+
+	client := redis.NewClient(opts)
+	if err := client.Ping(ctx).Err(); err != nil {
+		return nil, fmt.Errorf("failed to ping redis: %w", err)
+	}
+
+	return &DB{client: client, zlog: zlog}, nil
+}
+
+// Close closes the underlying redis client
+func (db *DB) Close() error {
+	return db.client.Close()
+}
+
+// ready reports myerrors.ErrRepositoryNotReady if db was constructed without NewDB (e.g. a
+// zero-value DB{}), so callers get a typed error instead of a nil-pointer panic
+func (db *DB) ready() error {
+	if db.client == nil {
+		return myerrors.ErrRepositoryNotReady
+	}
+	return nil
+}
+
+// urlKey is the hash holding every field for shortURL: original_url, user_uuid, referer,
+// user_agent, expires_at, deleted_at, is_deleted, host and password_hash
+func urlKey(shortURL string) string {
+	return "url:" + shortURL
+}
+
+// origKey is the dedup index from an original URL to the shortURL it was saved under. It is
+// keyed by sha256(originalURL) rather than originalURL itself so arbitrarily long URLs don't
+// end up as arbitrarily long redis keys.
+func origKey(originalURL string) string {
+	sum := sha256.Sum256([]byte(originalURL))
+	return "orig:" + hex.EncodeToString(sum[:])
+}
+
+// userKey is the set of every shortURL ever saved by userUUID, including soft-deleted ones
+func userKey(userUUID uuid.UUID) string {
+	return "user:" + userUUID.String()
+}
+
+// apiKeyKey is the hash holding one API key's metadata, keyed by the sha256 hash of its
+// plaintext form
+func apiKeyKey(keyHash string) string {
+	return "apikey:" + keyHash
+}
+
+// timeToField renders t as a hash field value, empty for the zero time
+func timeToField(t time.Time) string {
+	if t.IsZero() {
+		return ""
+	}
+	return t.Format(time.RFC3339Nano)
+}
+
+// fieldToTime parses a hash field written by timeToField, returning the zero time for ""
+func fieldToTime(s string) time.Time {
+	if s == "" {
+		return time.Time{}
+	}
+	t, err := time.Parse(time.RFC3339Nano, s)
+	if err != nil {
+		return time.Time{}
+	}
+	return t
+}
+
+// hostOf extracts the hostname from originalURL, returning "" if it cannot be parsed
+func hostOf(originalURL string) string {
+	u, err := url.Parse(originalURL)
+	if err != nil {
+		return ""
+	}
+	return u.Hostname()
+}
+
+// urlRecord is the decoded form of a urlKey hash
+type urlRecord struct {
+	originalURL  string
+	userUUID     uuid.UUID
+	referer      string
+	userAgent    string
+	expiresAt    time.Time
+	deletedAt    time.Time
+	isDeleted    bool
+	host         string
+	passwordHash string
+	createdAt    time.Time
+	updatedAt    time.Time
+}
+
+// getRecord fetches and decodes the hash at urlKey(shortURL), returning myerrors.ErrNotFound
+// if it does not exist
+func (db *DB) getRecord(ctx context.Context, shortURL string) (urlRecord, error) {
+	fields, err := db.client.HGetAll(ctx, urlKey(shortURL)).Result()
+	if err != nil {
+		return urlRecord{}, fmt.Errorf("failed to get %s: %w", shortURL, err)
+	}
+	if len(fields) == 0 {
+		return urlRecord{}, myerrors.ErrNotFound
+	}
+
+	userUUID, _ := uuid.Parse(fields["user_uuid"])
+	return urlRecord{
+		originalURL:  fields["original_url"],
+		userUUID:     userUUID,
+		referer:      fields["referer"],
+		userAgent:    fields["user_agent"],
+		expiresAt:    fieldToTime(fields["expires_at"]),
+		deletedAt:    fieldToTime(fields["deleted_at"]),
+		isDeleted:    fields["is_deleted"] == "1",
+		host:         fields["host"],
+		passwordHash: fields["password_hash"],
+		createdAt:    fieldToTime(fields["created_at"]),
+		updatedAt:    fieldToTime(fields["updated_at"]),
+	}, nil
+}
+
+// GetURL returns the destination for shortURL. When requireOwnership is true, it returns
+// myerrors.ErrNotFound unless userUUID owns shortURL.
+func (db *DB) GetURL(ctx context.Context, userUUID uuid.UUID, shortURL string, requireOwnership bool) (string, error) {
+	if err := db.ready(); err != nil {
+		return "", err
+	}
+
+	rec, err := db.getRecord(ctx, shortURL)
+	if err != nil {
+		return "", err
+	}
+
+	if requireOwnership && rec.userUUID != userUUID {
+		return "", myerrors.ErrNotFound
+	}
+	if rec.isDeleted {
+		return "", &myerrors.DeletedURLError{OriginalURL: rec.originalURL, DeletedAt: rec.deletedAt}
+	}
+
+	return rec.originalURL, nil
+}
+
+// GetURLs resolves many slugs in one call, returning one model.URLExpansion per entry in
+// shortURLs, in the same order
+func (db *DB) GetURLs(ctx context.Context, userUUID uuid.UUID, shortURLs []string, requireOwnership bool) ([]model.URLExpansion, error) {
+	if err := db.ready(); err != nil {
+		return nil, err
+	}
+
+	result := make([]model.URLExpansion, len(shortURLs))
+	for i, shortURL := range shortURLs {
+		result[i] = model.URLExpansion{ShortURL: shortURL}
+		originalURL, err := db.GetURL(ctx, userUUID, shortURL, requireOwnership)
+		if err != nil {
+			var deletedErr *myerrors.DeletedURLError
+			if errors.As(err, &deletedErr) {
+				result[i].OriginalURL = deletedErr.OriginalURL
+				result[i].IsDeleted = true
+				result[i].DeletedAt = deletedErr.DeletedAt
+				continue
+			}
+			result[i].Err = err.Error()
+			continue
+		}
+		result[i].OriginalURL = originalURL
+	}
+	return result, nil
+}
+
+// GetShortURL returns the shortURL previously saved for originalURL
+func (db *DB) GetShortURL(ctx context.Context, originalURL string) (string, error) {
+	if err := db.ready(); err != nil {
+		return "", err
+	}
+
+	shortURL, err := db.client.Get(ctx, origKey(originalURL)).Result()
+	if errors.Is(err, redis.Nil) {
+		return "", myerrors.ErrNotFound
+	}
+	if err != nil {
+		return "", fmt.Errorf("failed to get short url for %s: %w", originalURL, err)
+	}
+
+	rec, err := db.getRecord(ctx, shortURL)
+	if err != nil || rec.isDeleted {
+		return "", myerrors.ErrNotFound
+	}
+
+	return shortURL, nil
+}
+
+// exists reports whether shortURL is already in use by a non-deleted record
+func (db *DB) exists(ctx context.Context, shortURL string) (bool, error) {
+	rec, err := db.getRecord(ctx, shortURL)
+	if errors.Is(err, myerrors.ErrNotFound) {
+		return false, nil
+	}
+	if err != nil {
+		return false, err
+	}
+	return !rec.isDeleted, nil
+}
+
+// existsURL reports whether originalURL is already saved under a non-deleted slug
+func (db *DB) existsURL(ctx context.Context, originalURL string) (bool, error) {
+	shortURL, err := db.client.Get(ctx, origKey(originalURL)).Result()
+	if errors.Is(err, redis.Nil) {
+		return false, nil
+	}
+	if err != nil {
+		return false, fmt.Errorf("failed to look up %s: %w", originalURL, err)
+	}
+	return db.exists(ctx, shortURL)
+}
+
+// Save saves tuple with shortURL, originalURL and userUUID
+func (db *DB) Save(ctx context.Context, userUUID uuid.UUID, shortURL string, originalURL string, referer string, userAgent string, expiresAt time.Time, createdAt time.Time) (string, error) {
+	if err := db.ready(); err != nil {
+		return "", err
+	}
+
+	if shortURL == "" || originalURL == "" {
+		return "", myerrors.ErrEmptyShortURLorURL
+	}
+
+	db.mu.Lock()
+	defer db.mu.Unlock()
+
+	if dup, err := db.existsURL(ctx, originalURL); err != nil {
+		return "", err
+	} else if dup {
+		return "", fmt.Errorf("%w: %s", myerrors.ErrURLExist, originalURL)
+	}
+
+	if dup, err := db.exists(ctx, shortURL); err != nil {
+		return "", err
+	} else if dup {
+		return "", fmt.Errorf("%w: %s", myerrors.ErrShortURLExist, shortURL)
+	}
+
+	host := hostOf(originalURL)
+
+	_, err := db.client.Pipelined(ctx, func(pipe redis.Pipeliner) error {
+		db.queueSave(pipe, userUUID, shortURL, originalURL, referer, userAgent, expiresAt, createdAt, host)
+		return nil
+	})
+	if err != nil {
+		return "", fmt.Errorf("failed to save URL: %w", err)
+	}
+
+	requestlog.FromContext(ctx, db.zlog).Debug().Msgf("saved URL: %s", shortURL)
+
+	return "", nil
+}
+
+// queueSave queues the writes that make up a single saved URL onto pipe
+func (db *DB) queueSave(pipe redis.Pipeliner, userUUID uuid.UUID, shortURL string, originalURL string, referer string, userAgent string, expiresAt time.Time, createdAt time.Time, host string) {
+	pipe.HSet(context.Background(), urlKey(shortURL), map[string]any{
+		"original_url": originalURL,
+		"user_uuid":    userUUID.String(),
+		"referer":      referer,
+		"user_agent":   userAgent,
+		"expires_at":   timeToField(expiresAt),
+		"deleted_at":   "",
+		"is_deleted":   "0",
+		"host":         host,
+		"created_at":   timeToField(createdAt),
+		"updated_at":   timeToField(createdAt),
+	})
+	pipe.Set(context.Background(), origKey(originalURL), shortURL, 0)
+	pipe.SAdd(context.Background(), userKey(userUUID), shortURL)
+	if !expiresAt.IsZero() {
+		pipe.ZAdd(context.Background(), expiringKey, redis.Z{Score: float64(expiresAt.Unix()), Member: shortURL})
+	}
+	pipe.ZIncrBy(context.Background(), hostCountsKey, 1, host)
+}
+
+// SaveBatch performs a bulk insert to redis. An entry whose OriginalURL is already saved,
+// either from before this call or from an earlier entry in the same batch, is not pipelined;
+// its existing short URL is returned in the same position instead, so the caller can report it
+// as a conflict rather than the whole batch failing. A genuine empty field or a shortURL
+// collision still fails the whole call, since neither is a condition a caller can resolve by
+// reusing an existing slug.
+func (db *DB) SaveBatch(ctx context.Context, userUUID uuid.UUID, batch []model.URL) ([]string, error) {
+	if err := db.ready(); err != nil {
+		return nil, err
+	}
+
+	db.mu.Lock()
+	defer db.mu.Unlock()
+
+	existing := make([]string, len(batch))
+	seen := make(map[string]string, len(batch))
+	toSave := make([]model.URL, 0, len(batch))
+
+	for i, v := range batch {
+		if v.ShortURL == "" || v.OriginalURL == "" {
+			return nil, myerrors.ErrEmptyShortURLorURL
+		}
+		if dup, err := db.exists(ctx, v.ShortURL); err != nil {
+			return nil, err
+		} else if dup {
+			return nil, fmt.Errorf("%w: %s", myerrors.ErrShortURLExist, v.ShortURL)
+		}
+		if shortURL, ok := seen[v.OriginalURL]; ok {
+			existing[i] = shortURL
+			continue
+		}
+		shortURL, err := db.GetShortURL(ctx, v.OriginalURL)
+		if err == nil {
+			existing[i] = shortURL
+			continue
+		}
+		if !errors.Is(err, myerrors.ErrNotFound) {
+			return nil, err
+		}
+		seen[v.OriginalURL] = v.ShortURL
+		toSave = append(toSave, v)
+	}
+
+	_, err := db.client.Pipelined(ctx, func(pipe redis.Pipeliner) error {
+		for _, v := range toSave {
+			db.queueSave(pipe, userUUID, v.ShortURL, v.OriginalURL, v.Referer, v.UserAgent, v.ExpiresAt, v.CreatedAt, hostOf(v.OriginalURL))
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("unable to save batch: %w", err)
+	}
+
+	return existing, nil
+}
+
+// GetUserShortURLs gets the non-deleted short URLs owned by userUUID
+func (db *DB) GetUserShortURLs(ctx context.Context, userUUID uuid.UUID) ([]model.URL, error) {
+	if err := db.ready(); err != nil {
+		return nil, err
+	}
+
+	slugs, err := db.client.SMembers(ctx, userKey(userUUID)).Result()
+	if err != nil {
+		return nil, fmt.Errorf("failed to get user short urls: %w", err)
+	}
+	if len(slugs) == 0 {
+		return nil, myerrors.ErrNotFound
+	}
+
+	var result []model.URL
+	for _, shortURL := range slugs {
+		rec, err := db.getRecord(ctx, shortURL)
+		if errors.Is(err, myerrors.ErrNotFound) {
+			continue
+		}
+		if err != nil {
+			return nil, err
+		}
+		if rec.isDeleted {
+			continue
+		}
+		result = append(result, model.URL{
+			UserUUID:    userUUID,
+			ShortURL:    shortURL,
+			OriginalURL: rec.originalURL,
+			Referer:     rec.referer,
+			UserAgent:   rec.userAgent,
+			Host:        rec.host,
+			CreatedAt:   rec.createdAt,
+			UpdatedAt:   rec.updatedAt,
+		})
+	}
+
+	if len(result) == 0 {
+		return nil, myerrors.ErrNotFound
+	}
+
+	return result, nil
+}
+
+// markDeleted marks shortURL deleted at deletedAt, queuing the index updates that keep
+// expiringKey, deletedKey and hostCountsKey consistent
+func (db *DB) markDeleted(ctx context.Context, shortURL string, host string, deletedAt time.Time) error {
+	_, err := db.client.Pipelined(ctx, func(pipe redis.Pipeliner) error {
+		pipe.HSet(ctx, urlKey(shortURL), map[string]any{
+			"is_deleted": "1",
+			"deleted_at": timeToField(deletedAt),
+		})
+		pipe.ZRem(ctx, expiringKey, shortURL)
+		pipe.ZAdd(ctx, deletedKey, redis.Z{Score: float64(deletedAt.Unix()), Member: shortURL})
+		pipe.ZIncrBy(ctx, hostCountsKey, -1, host)
+		return nil
+	})
+	return err
+}
+
+// DeleteUserShortURLs soft-deletes the given slugs, stamping deletedAt as their deletion time
+func (db *DB) DeleteUserShortURLs(ctx context.Context, shortURLsToDelete map[uuid.UUID][]string, deletedAt time.Time) error {
+	if err := db.ready(); err != nil {
+		return err
+	}
+
+	for userUUID, slugs := range shortURLsToDelete {
+		isOwner, err := db.client.Exists(ctx, userKey(userUUID)).Result()
+		if err != nil {
+			return fmt.Errorf("failed to look up user: %w", err)
+		}
+		if isOwner == 0 {
+			return myerrors.ErrInvalidUserUUID
+		}
+
+		for _, shortURL := range slugs {
+			owns, err := db.client.SIsMember(ctx, userKey(userUUID), shortURL).Result()
+			if err != nil {
+				return fmt.Errorf("failed to check ownership of %s: %w", shortURL, err)
+			}
+			if !owns {
+				continue
+			}
+
+			rec, err := db.getRecord(ctx, shortURL)
+			if errors.Is(err, myerrors.ErrNotFound) || rec.isDeleted {
+				continue
+			}
+			if err != nil {
+				return err
+			}
+
+			if err := db.markDeleted(ctx, shortURL, rec.host, deletedAt); err != nil {
+				return fmt.Errorf("unable to delete %s: %w", shortURL, err)
+			}
+		}
+	}
+
+	return nil
+}
+
+// UpdateURL changes the destination for shortURL, owned by userUUID, to newURL. It returns
+// myerrors.ErrNotFound if shortURL does not exist, myerrors.ErrForbidden if it exists but is
+// not owned by userUUID, a *myerrors.DeletedURLError if it has been soft-deleted, or
+// myerrors.ErrURLExist if another non-deleted slug already points at newURL.
+func (db *DB) UpdateURL(ctx context.Context, userUUID uuid.UUID, shortURL string, newURL string, updatedAt time.Time) error {
+	if err := db.ready(); err != nil {
+		return err
+	}
+
+	rec, err := db.getRecord(ctx, shortURL)
+	if err != nil {
+		return err
+	}
+	if rec.userUUID != userUUID {
+		return myerrors.ErrForbidden
+	}
+	if rec.isDeleted {
+		return &myerrors.DeletedURLError{OriginalURL: rec.originalURL, DeletedAt: rec.deletedAt}
+	}
+
+	if dup, err := db.existsURL(ctx, newURL); err != nil {
+		return err
+	} else if dup {
+		return fmt.Errorf("%w: %s", myerrors.ErrURLExist, newURL)
+	}
+
+	newHost := hostOf(newURL)
+
+	_, err = db.client.Pipelined(ctx, func(pipe redis.Pipeliner) error {
+		pipe.Del(ctx, origKey(rec.originalURL))
+		pipe.Set(ctx, origKey(newURL), shortURL, 0)
+		pipe.HSet(ctx, urlKey(shortURL), map[string]any{
+			"original_url": newURL,
+			"host":         newHost,
+			"updated_at":   timeToField(updatedAt),
+		})
+		if newHost != rec.host {
+			pipe.ZIncrBy(ctx, hostCountsKey, -1, rec.host)
+			pipe.ZIncrBy(ctx, hostCountsKey, 1, newHost)
+		}
+		return nil
+	})
+	if err != nil {
+		return fmt.Errorf("failed to update URL: %w", err)
+	}
+
+	requestlog.FromContext(ctx, db.zlog).Debug().Msgf("updated URL: %s", shortURL)
+
+	return nil
+}
+
+// SweepExpired soft-deletes up to limit links whose expiry is before the given time, returning
+// the number of links swept
+func (db *DB) SweepExpired(ctx context.Context, before time.Time, limit int) (int, error) {
+	if err := db.ready(); err != nil {
+		return 0, err
+	}
+
+	slugs, err := db.client.ZRangeByScore(ctx, expiringKey, &redis.ZRangeBy{
+		Min:   "-inf",
+		Max:   strconv.FormatInt(before.Unix(), 10),
+		Count: int64(limit),
+	}).Result()
+	if err != nil {
+		return 0, fmt.Errorf("failed to find expired URLs: %w", err)
+	}
+
+	swept := 0
+	for _, shortURL := range slugs {
+		rec, err := db.getRecord(ctx, shortURL)
+		if errors.Is(err, myerrors.ErrNotFound) || rec.isDeleted {
+			continue
+		}
+		if err != nil {
+			return swept, err
+		}
+
+		if err := db.markDeleted(ctx, shortURL, rec.host, before); err != nil {
+			return swept, fmt.Errorf("failed to sweep expired URL %s: %w", shortURL, err)
+		}
+		swept++
+	}
+
+	return swept, nil
+}
+
+// PurgeDeleted permanently removes up to limit already soft-deleted rows whose deletion time
+// is before the given time, returning how many rows were removed
+func (db *DB) PurgeDeleted(ctx context.Context, before time.Time, limit int) (int, error) {
+	if err := db.ready(); err != nil {
+		return 0, err
+	}
+
+	slugs, err := db.client.ZRangeByScore(ctx, deletedKey, &redis.ZRangeBy{
+		Min:   "-inf",
+		Max:   strconv.FormatInt(before.Unix(), 10),
+		Count: int64(limit),
+	}).Result()
+	if err != nil {
+		return 0, fmt.Errorf("failed to find purgeable URLs: %w", err)
+	}
+
+	purged := 0
+	for _, shortURL := range slugs {
+		rec, err := db.getRecord(ctx, shortURL)
+		if errors.Is(err, myerrors.ErrNotFound) {
+			continue
+		}
+		if err != nil {
+			return purged, err
+		}
+
+		_, err = db.client.Pipelined(ctx, func(pipe redis.Pipeliner) error {
+			pipe.Del(ctx, urlKey(shortURL))
+			pipe.Del(ctx, origKey(rec.originalURL))
+			pipe.SRem(ctx, userKey(rec.userUUID), shortURL)
+			pipe.ZRem(ctx, deletedKey, shortURL)
+			pipe.ZRem(ctx, expiringKey, shortURL)
+			return nil
+		})
+		if err != nil {
+			return purged, fmt.Errorf("failed to purge %s: %w", shortURL, err)
+		}
+		purged++
+	}
+
+	return purged, nil
+}
+
+// SetPasswordedSlug stores passwordHash as the bcrypt hash guarding shortURL
+func (db *DB) SetPasswordedSlug(ctx context.Context, shortURL string, passwordHash string) error {
+	if err := db.ready(); err != nil {
+		return err
+	}
+
+	exists, err := db.client.Exists(ctx, urlKey(shortURL)).Result()
+	if err != nil {
+		return fmt.Errorf("failed to look up %s: %w", shortURL, err)
+	}
+	if exists == 0 {
+		return fmt.Errorf("failed to set password hash for %s: %w", shortURL, myerrors.ErrNotFound)
+	}
+
+	if err := db.client.HSet(ctx, urlKey(shortURL), "password_hash", passwordHash).Err(); err != nil {
+		return fmt.Errorf("failed to set password hash for %s: %w", shortURL, err)
+	}
+
+	return nil
+}
+
+// GetPasswordHash returns the bcrypt hash guarding shortURL, or "" if it is not
+// password-protected
+func (db *DB) GetPasswordHash(ctx context.Context, shortURL string) (string, error) {
+	if err := db.ready(); err != nil {
+		return "", err
+	}
+
+	passwordHash, err := db.client.HGet(ctx, urlKey(shortURL), "password_hash").Result()
+	if err != nil && !errors.Is(err, redis.Nil) {
+		return "", fmt.Errorf("failed to get password hash for %s: %w", shortURL, err)
+	}
+
+	return passwordHash, nil
+}
+
+// SetRedirectStatus stores status as the per-link override of the HTTP status used when
+// redirecting shortURL
+func (db *DB) SetRedirectStatus(ctx context.Context, shortURL string, status int) error {
+	if err := db.ready(); err != nil {
+		return err
+	}
+
+	exists, err := db.client.Exists(ctx, urlKey(shortURL)).Result()
+	if err != nil {
+		return fmt.Errorf("failed to look up %s: %w", shortURL, err)
+	}
+	if exists == 0 {
+		return fmt.Errorf("failed to set redirect status for %s: %w", shortURL, myerrors.ErrNotFound)
+	}
+
+	if err := db.client.HSet(ctx, urlKey(shortURL), "redirect_status", status).Err(); err != nil {
+		return fmt.Errorf("failed to set redirect status for %s: %w", shortURL, err)
+	}
+
+	return nil
+}
+
+// GetRedirectStatus returns the per-link redirect status override for shortURL, or 0 if it
+// has none
+func (db *DB) GetRedirectStatus(ctx context.Context, shortURL string) (int, error) {
+	if err := db.ready(); err != nil {
+		return 0, err
+	}
+
+	redirectStatus, err := db.client.HGet(ctx, urlKey(shortURL), "redirect_status").Result()
+	if err != nil {
+		if errors.Is(err, redis.Nil) {
+			return 0, nil
+		}
+		return 0, fmt.Errorf("failed to get redirect status for %s: %w", shortURL, err)
+	}
+	if redirectStatus == "" {
+		return 0, nil
+	}
+
+	status, err := strconv.Atoi(redirectStatus)
+	if err != nil {
+		return 0, fmt.Errorf("failed to parse redirect status for %s: %w", shortURL, err)
+	}
+
+	return status, nil
+}
+
+// SaveAPIKey stores a new API key record, keyed by the sha256 hash of its plaintext form
+func (db *DB) SaveAPIKey(ctx context.Context, userUUID uuid.UUID, keyHash string, createdAt time.Time) error {
+	if err := db.ready(); err != nil {
+		return err
+	}
+
+	if err := db.client.HSet(ctx, apiKeyKey(keyHash), map[string]any{
+		"user_uuid":  userUUID.String(),
+		"created_at": createdAt.Format(time.RFC3339Nano),
+		"revoked":    "0",
+	}).Err(); err != nil {
+		return fmt.Errorf("failed to save API key: %w", err)
+	}
+
+	return nil
+}
+
+// ResolveAPIKey returns the UserUUID owning keyHash, or myerrors.ErrNotFound if it does not
+// exist or has been revoked
+func (db *DB) ResolveAPIKey(ctx context.Context, keyHash string) (uuid.UUID, error) {
+	if err := db.ready(); err != nil {
+		return uuid.Nil, err
+	}
+
+	fields, err := db.client.HMGet(ctx, apiKeyKey(keyHash), "user_uuid", "revoked").Result()
+	if err != nil {
+		return uuid.Nil, fmt.Errorf("failed to resolve API key: %w", err)
+	}
+	if fields[0] == nil || fields[1] == "1" {
+		return uuid.Nil, myerrors.ErrNotFound
+	}
+
+	userUUID, err := uuid.Parse(fields[0].(string))
+	if err != nil {
+		return uuid.Nil, fmt.Errorf("failed to resolve API key: %w", err)
+	}
+
+	return userUUID, nil
+}
+
+// RevokeAPIKey marks keyHash as revoked, if it exists and is owned by userUUID
+func (db *DB) RevokeAPIKey(ctx context.Context, userUUID uuid.UUID, keyHash string) error {
+	if err := db.ready(); err != nil {
+		return err
+	}
+
+	owner, err := db.client.HGet(ctx, apiKeyKey(keyHash), "user_uuid").Result()
+	if err != nil {
+		if errors.Is(err, redis.Nil) {
+			return myerrors.ErrNotFound
+		}
+		return fmt.Errorf("failed to revoke API key: %w", err)
+	}
+	if owner != userUUID.String() {
+		return myerrors.ErrForbidden
+	}
+
+	if err := db.client.HSet(ctx, apiKeyKey(keyHash), "revoked", "1").Err(); err != nil {
+		return fmt.Errorf("failed to revoke API key: %w", err)
+	}
+
+	return nil
+}
+
+// Ping checks that redis is reachable
+func (db *DB) Ping(ctx context.Context) error {
+	if err := db.ready(); err != nil {
+		return err
+	}
+
+	if err := db.client.Ping(ctx).Err(); err != nil {
+		return fmt.Errorf("failed to ping redis: %w", err)
+	}
+
+	return nil
+}
+
+// NextSequence returns the next value in a monotonically increasing sequence shared by every
+// instance pointed at the same redis, starting at 1.
+func (db *DB) NextSequence(ctx context.Context) (int64, error) {
+	if err := db.ready(); err != nil {
+		return 0, err
+	}
+
+	n, err := db.client.Incr(ctx, sequenceKey).Result()
+	if err != nil {
+		return 0, fmt.Errorf("failed to increment slug sequence: %w", err)
+	}
+
+	return n, nil
+}
+
+// TopHosts returns the n hosts with the most non-deleted short URLs, ordered by count descending
+func (db *DB) TopHosts(ctx context.Context, n int) ([]model.HostCount, error) {
+	if err := db.ready(); err != nil {
+		return nil, err
+	}
+
+	results, err := db.client.ZRevRangeWithScores(ctx, hostCountsKey, 0, int64(n)-1).Result()
+	if err != nil {
+		return nil, fmt.Errorf("failed to query top hosts: %w", err)
+	}
+
+	hostCounts := make([]model.HostCount, 0, len(results))
+	for _, z := range results {
+		host, _ := z.Member.(string)
+		if host == "" || z.Score <= 0 {
+			continue
+		}
+		hostCounts = append(hostCounts, model.HostCount{Host: host, Count: int(z.Score)})
+	}
+
+	return hostCounts, nil
+}
+
+// Stats returns the total number of non-deleted short URLs and the total number of distinct
+// users that have ever saved one
+func (db *DB) Stats(ctx context.Context) (model.Stats, error) {
+	if err := db.ready(); err != nil {
+		return model.Stats{}, err
+	}
+
+	hostCounts, err := db.client.ZRangeWithScores(ctx, hostCountsKey, 0, -1).Result()
+	if err != nil {
+		return model.Stats{}, fmt.Errorf("failed to query stats: %w", err)
+	}
+
+	urls := 0
+	for _, z := range hostCounts {
+		urls += int(z.Score)
+	}
+
+	users := 0
+	iter := db.client.Scan(ctx, 0, "user:*", 0).Iterator()
+	for iter.Next(ctx) {
+		users++
+	}
+	if err := iter.Err(); err != nil {
+		return model.Stats{}, fmt.Errorf("failed to scan user keys: %w", err)
+	}
+
+	return model.Stats{URLs: urls, Users: users}, nil
+}
+
+// scanURLKeys returns the shortURL suffix of every url:* key in the database
+func (db *DB) scanURLKeys(ctx context.Context) ([]string, error) {
+	var slugs []string
+	iter := db.client.Scan(ctx, 0, "url:*", 0).Iterator()
+	for iter.Next(ctx) {
+		slugs = append(slugs, iter.Val()[len("url:"):])
+	}
+	if err := iter.Err(); err != nil {
+		return nil, fmt.Errorf("failed to scan url keys: %w", err)
+	}
+	return slugs, nil
+}
+
+// BackfillHosts computes and persists the host for up to limit rows missing it, returning how
+// many rows were backfilled. A non-positive limit backfills every row missing a host.
+func (db *DB) BackfillHosts(ctx context.Context, limit int) (int, error) {
+	if err := db.ready(); err != nil {
+		return 0, err
+	}
+
+	if limit <= 0 {
+		limit = defaultBackfillLimit
+	}
+
+	slugs, err := db.scanURLKeys(ctx)
+	if err != nil {
+		return 0, err
+	}
+
+	backfilled := 0
+	for _, shortURL := range slugs {
+		if backfilled >= limit {
+			break
+		}
+
+		rec, err := db.getRecord(ctx, shortURL)
+		if errors.Is(err, myerrors.ErrNotFound) {
+			continue
+		}
+		if err != nil {
+			return backfilled, err
+		}
+		if rec.host != "" {
+			continue
+		}
+
+		if err := db.client.HSet(ctx, urlKey(shortURL), "host", hostOf(rec.originalURL)).Err(); err != nil {
+			return backfilled, fmt.Errorf("unable to backfill host for %s: %w", shortURL, err)
+		}
+		backfilled++
+	}
+
+	return backfilled, nil
+}
+
+// Verify checks the redis keyspace for internal inconsistencies, returning a human-readable
+// description of each one found. A nil or empty slice means no inconsistencies were found.
+func (db *DB) Verify(ctx context.Context) ([]string, error) {
+	if err := db.ready(); err != nil {
+		return nil, err
+	}
+
+	slugs, err := db.scanURLKeys(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	var violations []string
+	for _, shortURL := range slugs {
+		rec, err := db.getRecord(ctx, shortURL)
+		if errors.Is(err, myerrors.ErrNotFound) {
+			continue
+		}
+		if err != nil {
+			return nil, err
+		}
+
+		if rec.isDeleted && rec.deletedAt.IsZero() {
+			violations = append(violations, fmt.Sprintf("short_url %q is deleted but has no deleted_at", shortURL))
+		}
+		if !rec.isDeleted && !rec.deletedAt.IsZero() {
+			violations = append(violations, fmt.Sprintf("short_url %q has a deleted_at but is not marked deleted", shortURL))
+		}
+
+		dedup, err := db.client.Get(ctx, origKey(rec.originalURL)).Result()
+		if errors.Is(err, redis.Nil) {
+			violations = append(violations, fmt.Sprintf("short_url %q has no dedup index entry for its original_url", shortURL))
+		} else if err != nil {
+			return nil, fmt.Errorf("failed to check dedup index for %s: %w", shortURL, err)
+		} else if dedup != shortURL {
+			violations = append(violations, fmt.Sprintf("original_url for %q maps back to %q via the dedup index", shortURL, dedup))
+		}
+
+		owns, err := db.client.SIsMember(ctx, userKey(rec.userUUID), shortURL).Result()
+		if err != nil {
+			return nil, fmt.Errorf("failed to check ownership of %s: %w", shortURL, err)
+		}
+		if !owns {
+			violations = append(violations, fmt.Sprintf("short_url %q is not a member of its owner's user set", shortURL))
+		}
+	}
+
+	sort.Strings(violations)
+	return violations, nil
+}
@@ -0,0 +1,357 @@
+package redis
+
+import (
+	"context"
+	"strconv"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/ar4ie13/shortener/internal/model"
+	"github.com/ar4ie13/shortener/internal/myerrors"
+	"github.com/google/uuid"
+	"github.com/redis/go-redis/v9"
+	"github.com/rs/zerolog"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// newTestDB starts an in-process miniredis server and returns a DB connected to it, along
+// with a cleanup func the caller should defer
+func newTestDB(t *testing.T) *DB {
+	t.Helper()
+
+	mr, err := miniredis.Run()
+	require.NoError(t, err)
+	t.Cleanup(mr.Close)
+
+	return &DB{client: redis.NewClient(&redis.Options{Addr: mr.Addr()}), zlog: zerolog.Nop()}
+}
+
+func TestDB_NotReady(t *testing.T) {
+	db := &DB{}
+
+	_, err := db.GetURL(context.Background(), uuid.New(), "slug", false)
+	assert.ErrorIs(t, err, myerrors.ErrRepositoryNotReady)
+
+	_, err = db.Save(context.Background(), uuid.New(), "slug", "https://example.com", "", "", time.Time{}, time.Time{})
+	assert.ErrorIs(t, err, myerrors.ErrRepositoryNotReady)
+}
+
+func TestDB_SaveAndGetURL(t *testing.T) {
+	db := newTestDB(t)
+	ctx := context.Background()
+	userUUID := uuid.New()
+
+	_, err := db.Save(ctx, userUUID, "abc123", "https://example.com/foo", "ref", "agent", time.Time{}, time.Time{})
+	require.NoError(t, err)
+
+	got, err := db.GetURL(ctx, userUUID, "abc123", false)
+	require.NoError(t, err)
+	assert.Equal(t, "https://example.com/foo", got)
+
+	shortURL, err := db.GetShortURL(ctx, "https://example.com/foo")
+	require.NoError(t, err)
+	assert.Equal(t, "abc123", shortURL)
+}
+
+func TestDB_Save_DuplicateURLAndShortURL(t *testing.T) {
+	db := newTestDB(t)
+	ctx := context.Background()
+	userUUID := uuid.New()
+
+	_, err := db.Save(ctx, userUUID, "abc123", "https://example.com/foo", "", "", time.Time{}, time.Time{})
+	require.NoError(t, err)
+
+	_, err = db.Save(ctx, userUUID, "other", "https://example.com/foo", "", "", time.Time{}, time.Time{})
+	assert.ErrorIs(t, err, myerrors.ErrURLExist)
+
+	_, err = db.Save(ctx, userUUID, "abc123", "https://example.com/bar", "", "", time.Time{}, time.Time{})
+	assert.ErrorIs(t, err, myerrors.ErrShortURLExist)
+}
+
+func TestDB_GetURL_RequireOwnership(t *testing.T) {
+	db := newTestDB(t)
+	ctx := context.Background()
+	owner := uuid.New()
+	other := uuid.New()
+
+	_, err := db.Save(ctx, owner, "abc123", "https://example.com/foo", "", "", time.Time{}, time.Time{})
+	require.NoError(t, err)
+
+	_, err = db.GetURL(ctx, other, "abc123", true)
+	assert.ErrorIs(t, err, myerrors.ErrNotFound)
+
+	got, err := db.GetURL(ctx, owner, "abc123", true)
+	require.NoError(t, err)
+	assert.Equal(t, "https://example.com/foo", got)
+}
+
+func TestDB_GetURL_NotFound(t *testing.T) {
+	db := newTestDB(t)
+
+	_, err := db.GetURL(context.Background(), uuid.New(), "missing", false)
+	assert.ErrorIs(t, err, myerrors.ErrNotFound)
+}
+
+func TestDB_SaveBatch(t *testing.T) {
+	db := newTestDB(t)
+	ctx := context.Background()
+	userUUID := uuid.New()
+
+	batch := []model.URL{
+		{ShortURL: "one", OriginalURL: "https://example.com/1"},
+		{ShortURL: "two", OriginalURL: "https://example.com/2"},
+	}
+	existing, err := db.SaveBatch(ctx, userUUID, batch)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"", ""}, existing)
+
+	urls, err := db.GetUserShortURLs(ctx, userUUID)
+	require.NoError(t, err)
+	assert.Len(t, urls, 2)
+}
+
+func TestDB_SaveBatch_ReportsConflictInsteadOfFailing(t *testing.T) {
+	db := newTestDB(t)
+	ctx := context.Background()
+	userUUID := uuid.New()
+
+	_, err := db.Save(ctx, userUUID, "existing", "https://example.com/dup", "", "", time.Time{}, time.Time{})
+	require.NoError(t, err)
+
+	batch := []model.URL{
+		{ShortURL: "fresh", OriginalURL: "https://example.com/new"},
+		{ShortURL: "dup", OriginalURL: "https://example.com/dup"},
+	}
+	existing, err := db.SaveBatch(ctx, userUUID, batch)
+	require.NoError(t, err)
+	require.Len(t, existing, 2)
+	assert.Equal(t, "", existing[0])
+	assert.Equal(t, "existing", existing[1])
+
+	urls, err := db.GetUserShortURLs(ctx, userUUID)
+	require.NoError(t, err)
+	assert.Len(t, urls, 2)
+}
+
+// TestDB_ConcurrentSaveNoCrossContamination stores many records for distinct users
+// concurrently and confirms each one is persisted with its own fields intact, which would fail
+// if Save's existence checks and writes were not serialized against one another.
+func TestDB_ConcurrentSaveNoCrossContamination(t *testing.T) {
+	db := newTestDB(t)
+	ctx := context.Background()
+
+	const n = 50
+	var wg sync.WaitGroup
+	wg.Add(n)
+	for i := 0; i < n; i++ {
+		go func(i int) {
+			defer wg.Done()
+			shortURL := "slug" + strconv.Itoa(i)
+			longURL := "https://example.com/" + strconv.Itoa(i)
+			_, err := db.Save(ctx, uuid.New(), shortURL, longURL, "", "", time.Time{}, time.Time{})
+			assert.NoError(t, err)
+		}(i)
+	}
+	wg.Wait()
+
+	for i := 0; i < n; i++ {
+		shortURL := "slug" + strconv.Itoa(i)
+		wantLongURL := "https://example.com/" + strconv.Itoa(i)
+		gotLongURL, err := db.GetURL(ctx, uuid.New(), shortURL, false)
+		assert.NoError(t, err)
+		assert.Equal(t, wantLongURL, gotLongURL, "record for %s was corrupted by a concurrent Save", shortURL)
+	}
+}
+
+func TestDB_DeleteUserShortURLs(t *testing.T) {
+	db := newTestDB(t)
+	ctx := context.Background()
+	userUUID := uuid.New()
+
+	_, err := db.Save(ctx, userUUID, "abc123", "https://example.com/foo", "", "", time.Time{}, time.Time{})
+	require.NoError(t, err)
+
+	deletedAt := time.Now()
+	require.NoError(t, db.DeleteUserShortURLs(ctx, map[uuid.UUID][]string{userUUID: {"abc123"}}, deletedAt))
+
+	_, err = db.GetURL(ctx, userUUID, "abc123", false)
+	var deletedErr *myerrors.DeletedURLError
+	require.ErrorAs(t, err, &deletedErr)
+	assert.Equal(t, "https://example.com/foo", deletedErr.OriginalURL)
+
+	err = db.DeleteUserShortURLs(ctx, map[uuid.UUID][]string{uuid.New(): {"abc123"}}, deletedAt)
+	assert.ErrorIs(t, err, myerrors.ErrInvalidUserUUID)
+}
+
+func TestDB_SweepExpiredAndPurgeDeleted(t *testing.T) {
+	db := newTestDB(t)
+	ctx := context.Background()
+	userUUID := uuid.New()
+
+	_, err := db.Save(ctx, userUUID, "abc123", "https://example.com/foo", "", "", time.Now().Add(-time.Minute), time.Time{})
+	require.NoError(t, err)
+
+	swept, err := db.SweepExpired(ctx, time.Now(), 10)
+	require.NoError(t, err)
+	assert.Equal(t, 1, swept)
+
+	purged, err := db.PurgeDeleted(ctx, time.Now().Add(time.Minute), 10)
+	require.NoError(t, err)
+	assert.Equal(t, 1, purged)
+
+	_, err = db.GetURL(ctx, userUUID, "abc123", false)
+	assert.ErrorIs(t, err, myerrors.ErrNotFound)
+}
+
+func TestDB_SetAndGetPasswordHash(t *testing.T) {
+	db := newTestDB(t)
+	ctx := context.Background()
+
+	_, err := db.Save(ctx, uuid.New(), "abc123", "https://example.com/foo", "", "", time.Time{}, time.Time{})
+	require.NoError(t, err)
+
+	hash, err := db.GetPasswordHash(ctx, "abc123")
+	require.NoError(t, err)
+	assert.Equal(t, "", hash)
+
+	require.NoError(t, db.SetPasswordedSlug(ctx, "abc123", "hashed"))
+
+	hash, err = db.GetPasswordHash(ctx, "abc123")
+	require.NoError(t, err)
+	assert.Equal(t, "hashed", hash)
+
+	err = db.SetPasswordedSlug(ctx, "missing", "hashed")
+	assert.ErrorIs(t, err, myerrors.ErrNotFound)
+}
+
+func TestDB_UpdateURL(t *testing.T) {
+	db := newTestDB(t)
+	ctx := context.Background()
+	owner := uuid.New()
+	other := uuid.New()
+
+	_, err := db.Save(ctx, owner, "abc123", "https://example.com/foo", "", "", time.Time{}, time.Time{})
+	require.NoError(t, err)
+
+	require.NoError(t, db.UpdateURL(ctx, owner, "abc123", "https://example.com/bar", time.Time{}))
+
+	got, err := db.GetURL(ctx, owner, "abc123", false)
+	require.NoError(t, err)
+	assert.Equal(t, "https://example.com/bar", got)
+
+	err = db.UpdateURL(ctx, other, "abc123", "https://example.com/baz", time.Time{})
+	assert.ErrorIs(t, err, myerrors.ErrForbidden)
+
+	err = db.UpdateURL(ctx, owner, "missing", "https://example.com/baz", time.Time{})
+	assert.ErrorIs(t, err, myerrors.ErrNotFound)
+}
+
+func TestDB_APIKeys(t *testing.T) {
+	db := newTestDB(t)
+	ctx := context.Background()
+	owner := uuid.New()
+	other := uuid.New()
+
+	require.NoError(t, db.SaveAPIKey(ctx, owner, "hash1", time.Now()))
+
+	got, err := db.ResolveAPIKey(ctx, "hash1")
+	require.NoError(t, err)
+	assert.Equal(t, owner, got)
+
+	_, err = db.ResolveAPIKey(ctx, "missing")
+	assert.ErrorIs(t, err, myerrors.ErrNotFound)
+
+	err = db.RevokeAPIKey(ctx, other, "hash1")
+	assert.ErrorIs(t, err, myerrors.ErrForbidden)
+
+	require.NoError(t, db.RevokeAPIKey(ctx, owner, "hash1"))
+
+	_, err = db.ResolveAPIKey(ctx, "hash1")
+	assert.ErrorIs(t, err, myerrors.ErrNotFound)
+}
+
+func TestDB_TopHosts(t *testing.T) {
+	db := newTestDB(t)
+	ctx := context.Background()
+	userUUID := uuid.New()
+
+	_, err := db.Save(ctx, userUUID, "one", "https://a.com/1", "", "", time.Time{}, time.Time{})
+	require.NoError(t, err)
+	_, err = db.Save(ctx, userUUID, "two", "https://a.com/2", "", "", time.Time{}, time.Time{})
+	require.NoError(t, err)
+	_, err = db.Save(ctx, userUUID, "three", "https://b.com/1", "", "", time.Time{}, time.Time{})
+	require.NoError(t, err)
+
+	hostCounts, err := db.TopHosts(ctx, 10)
+	require.NoError(t, err)
+	require.Len(t, hostCounts, 2)
+	assert.Equal(t, "a.com", hostCounts[0].Host)
+	assert.Equal(t, 2, hostCounts[0].Count)
+}
+
+func TestDB_Stats(t *testing.T) {
+	db := newTestDB(t)
+	ctx := context.Background()
+	userA := uuid.New()
+	userB := uuid.New()
+
+	_, err := db.Save(ctx, userA, "one", "https://a.com/1", "", "", time.Time{}, time.Time{})
+	require.NoError(t, err)
+	_, err = db.Save(ctx, userA, "two", "https://a.com/2", "", "", time.Time{}, time.Time{})
+	require.NoError(t, err)
+	_, err = db.Save(ctx, userB, "three", "https://b.com/1", "", "", time.Time{}, time.Time{})
+	require.NoError(t, err)
+	require.NoError(t, db.DeleteUserShortURLs(ctx, map[uuid.UUID][]string{userB: {"three"}}, time.Now()))
+
+	stats, err := db.Stats(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, model.Stats{URLs: 2, Users: 2}, stats)
+}
+
+func TestDB_BackfillHosts(t *testing.T) {
+	db := newTestDB(t)
+	ctx := context.Background()
+
+	_, err := db.Save(ctx, uuid.New(), "abc123", "https://example.com/foo", "", "", time.Time{}, time.Time{})
+	require.NoError(t, err)
+	require.NoError(t, db.client.HSet(ctx, urlKey("abc123"), "host", "").Err())
+
+	backfilled, err := db.BackfillHosts(ctx, 0)
+	require.NoError(t, err)
+	assert.Equal(t, 1, backfilled)
+
+	host, err := db.client.HGet(ctx, urlKey("abc123"), "host").Result()
+	require.NoError(t, err)
+	assert.Equal(t, "example.com", host)
+}
+
+func TestDB_Verify(t *testing.T) {
+	db := newTestDB(t)
+	ctx := context.Background()
+
+	_, err := db.Save(ctx, uuid.New(), "abc123", "https://example.com/foo", "", "", time.Time{}, time.Time{})
+	require.NoError(t, err)
+
+	violations, err := db.Verify(ctx)
+	require.NoError(t, err)
+	assert.Empty(t, violations)
+
+	require.NoError(t, db.client.HSet(ctx, urlKey("abc123"), "is_deleted", "1").Err())
+
+	violations, err = db.Verify(ctx)
+	require.NoError(t, err)
+	assert.Len(t, violations, 1)
+}
+
+func TestDB_Ping(t *testing.T) {
+	db := newTestDB(t)
+	assert.NoError(t, db.Ping(context.Background()))
+}
+
+func TestDB_Close(t *testing.T) {
+	db := newTestDB(t)
+	assert.NoError(t, db.Close())
+}
@@ -2,12 +2,18 @@ package repository
 
 import (
 	"context"
+	"sync/atomic"
+	"time"
 
 	"github.com/ar4ie13/shortener/internal/repository/db/postgresql"
 	pgconf "github.com/ar4ie13/shortener/internal/repository/db/postgresql/config"
 	"github.com/ar4ie13/shortener/internal/repository/filestorage"
 	fileconf "github.com/ar4ie13/shortener/internal/repository/filestorage/config"
 	"github.com/ar4ie13/shortener/internal/repository/memory"
+	"github.com/ar4ie13/shortener/internal/repository/redis"
+	redisconf "github.com/ar4ie13/shortener/internal/repository/redis/config"
+	"github.com/ar4ie13/shortener/internal/repository/s3storage"
+	s3conf "github.com/ar4ie13/shortener/internal/repository/s3storage/config"
 	"github.com/ar4ie13/shortener/internal/service"
 	"github.com/rs/zerolog"
 )
@@ -19,36 +25,174 @@ type Repository struct {
 	db *postgresql.DB
 }
 
-// NewRepository return the correct interface for service depending on used store method
+// applyMigrations is overridden in tests to exercise Readiness without a real database
+var applyMigrations = postgresql.ApplyMigrations
+
+// Readiness reports whether repository initialization, including any database migrations, has
+// finished, and whether the backend is still reachable afterward. ready starts closed and
+// flips open exactly once; healthy starts true (a backend with no ping loop configured is
+// reported healthy by default) and can flip back and forth as runPingLoop observes the
+// backend going up and down.
+type Readiness struct {
+	ready   atomic.Bool
+	healthy atomic.Bool
+	done    chan struct{}
+}
+
+// NewReadiness returns a Readiness gate that is not yet ready
+func NewReadiness() *Readiness {
+	r := &Readiness{done: make(chan struct{})}
+	r.healthy.Store(true)
+	return r
+}
+
+// Ready reports whether initialization has finished and, if a background health ping loop is
+// running, whether the backend is currently reachable
+func (r *Readiness) Ready() bool {
+	return r.ready.Load() && r.healthy.Load()
+}
+
+// Wait blocks until initialization has finished
+func (r *Readiness) Wait() {
+	<-r.done
+}
+
+// markReady flips the gate open; safe to call more than once
+func (r *Readiness) markReady() {
+	if r.ready.CompareAndSwap(false, true) {
+		close(r.done)
+	}
+}
+
+// markHealthy records that the most recent background health ping succeeded
+func (r *Readiness) markHealthy() {
+	r.healthy.Store(true)
+}
+
+// markUnhealthy records that the most recent background health ping failed
+func (r *Readiness) markUnhealthy() {
+	r.healthy.Store(false)
+}
+
+// pingable is implemented by a repository backend that can be health-checked; satisfied by
+// *postgresql.DB
+type pingable interface {
+	Ping(ctx context.Context) error
+}
+
+// runPingLoop pings db every interval until ctx is done, recording each result on readiness
+// and logging only the transitions between healthy and unhealthy, so an operator gets a clear
+// signal as soon as connectivity changes instead of waiting for a request to fail against a
+// stale pool.
+func runPingLoop(ctx context.Context, db pingable, readiness *Readiness, interval time.Duration, zlog zerolog.Logger) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	wasHealthy := true
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			err := db.Ping(ctx)
+			healthy := err == nil
+			if healthy == wasHealthy {
+				continue
+			}
+			wasHealthy = healthy
+			if healthy {
+				zlog.Info().Msg("database connection recovered")
+				readiness.markHealthy()
+			} else {
+				zlog.Error().Err(err).Msg("database connection unhealthy")
+				readiness.markUnhealthy()
+			}
+		}
+	}
+}
+
+// NewRepository returns the correct interface for service depending on used store method,
+// along with a Readiness gate. For memory, file and redis backends the gate is ready
+// immediately; for postgres, migrations run on a background goroutine and the gate opens once
+// they complete, so callers that must start serving right away (e.g. the HTTP server) don't
+// have to block on migrations first, while callers that need a ready repository can call Wait.
+// pingInterval, if positive, starts a background goroutine that pings the postgres pool on
+// that interval for as long as ctx stays alive, tracking healthy/unhealthy transitions on the
+// returned Readiness; it has no effect for the memory, file, redis and S3 backends. If more
+// than one backend is configured, postgres takes priority, then redis, then S3, then file. For
+// the memory backend, a non-empty memorySnapshotPath is restored from at startup, and if
+// memorySnapshotInterval is positive, a background goroutine periodically overwrites it with
+// the current state for as long as ctx stays alive, so a restart without any other backend
+// configured doesn't lose everything saved since the process started.
 func NewRepository(
 	ctx context.Context,
 	fileconf fileconf.Config,
 	pgcfg pgconf.Config,
+	rediscfg redisconf.Config,
+	s3cfg s3conf.Config,
 	zlog zerolog.Logger,
-) (service.Repository, error) {
+	pingInterval time.Duration,
+	memorySnapshotPath string,
+	memorySnapshotInterval time.Duration,
+) (service.Repository, *Readiness, error) {
+	readiness := NewReadiness()
+
 	switch {
 	case pgcfg.DatabaseDSN != "":
 		db, err := postgresql.NewDB(ctx, pgcfg, zlog)
 		if err != nil {
-			return nil, err
+			return nil, nil, err
 		}
 		zlog.Info().Msg("using PostgreSQL repository")
-		err = postgresql.ApplyMigrations(pgcfg, zlog)
+		go func() {
+			zlog.Info().Msg("applying migrations")
+			if err := applyMigrations(pgcfg, zlog); err != nil {
+				zlog.Error().Err(err).Msg("failed to apply migrations")
+				return
+			}
+			readiness.markReady()
+		}()
+		if pingInterval > 0 {
+			go runPingLoop(ctx, db, readiness, pingInterval, zlog)
+		}
+		return db, readiness, nil
+	case rediscfg.RedisDSN != "":
+		db, err := redis.NewDB(ctx, rediscfg, zlog)
 		if err != nil {
-			return nil, err
+			return nil, nil, err
+		}
+		zlog.Info().Msg("using Redis repository")
+		readiness.markReady()
+		return db, readiness, nil
+	case s3cfg.Bucket != "":
+		s3store := s3storage.NewS3Storage(s3cfg, zlog)
+		if err := s3store.Load(ctx); err != nil {
+			return nil, nil, err
 		}
-		zlog.Info().Msg("applying migrations")
-		return db, nil
+		zlog.Info().Msg("using S3Storage repository")
+		readiness.markReady()
+		return s3store, readiness, nil
 	case fileconf.FilePath != "":
 		filestore := filestorage.NewFileStorage(fileconf, zlog)
-		err := filestore.Load()
+		err := filestore.Load(ctx)
 		if err != nil {
-			return nil, err
+			return nil, nil, err
 		}
 		zlog.Info().Msg("using FileStorage repository")
-		return filestore, nil
+		readiness.markReady()
+		return filestore, readiness, nil
 	default:
 		zlog.Info().Msg("using Memory repository")
-		return memory.NewMemStorage(), nil
+		m := memory.NewMemStorage()
+		if memorySnapshotPath != "" {
+			if err := m.RestoreSnapshot(memorySnapshotPath); err != nil {
+				return nil, nil, err
+			}
+			if memorySnapshotInterval > 0 {
+				go m.RunSnapshotLoop(ctx, memorySnapshotPath, memorySnapshotInterval, zlog)
+			}
+		}
+		readiness.markReady()
+		return m, readiness, nil
 	}
 }
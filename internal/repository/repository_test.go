@@ -10,6 +10,8 @@ import (
 	pgconf "github.com/ar4ie13/shortener/internal/repository/db/postgresql/config"
 	fileconf "github.com/ar4ie13/shortener/internal/repository/filestorage/config"
 	"github.com/ar4ie13/shortener/internal/repository/memory"
+	redisconf "github.com/ar4ie13/shortener/internal/repository/redis/config"
+	s3conf "github.com/ar4ie13/shortener/internal/repository/s3storage/config"
 	"github.com/ar4ie13/shortener/internal/service"
 	"github.com/rs/zerolog"
 )
@@ -19,6 +21,8 @@ func TestNewRepository(t *testing.T) {
 		ctx      context.Context
 		fileconf fileconf.Config
 		pgcfg    pgconf.Config
+		rediscfg redisconf.Config
+		s3cfg    s3conf.Config
 		zlog     zerolog.Logger
 	}
 	tests := []struct {
@@ -48,7 +52,7 @@ func TestNewRepository(t *testing.T) {
 	}
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			got, err := NewRepository(context.Background(), tt.args.fileconf, tt.args.pgcfg, tt.args.zlog)
+			got, readiness, err := NewRepository(context.Background(), tt.args.fileconf, tt.args.pgcfg, tt.args.rediscfg, tt.args.s3cfg, tt.args.zlog, 0, "", 0)
 			if (err != nil) != tt.wantErr {
 				t.Errorf("NewRepository() error = %v, wantErr %v", err, tt.wantErr)
 				return
@@ -62,6 +66,9 @@ func TestNewRepository(t *testing.T) {
 			if got == nil {
 				t.Errorf("NewRepository() Repository struct is nil")
 			}
+			if !readiness.Ready() {
+				t.Errorf("NewRepository() readiness not ready for memory backend")
+			}
 		})
 	}
 
@@ -0,0 +1,26 @@
+package config
+
+import "time"
+
+// Config contains the connection details for an S3-compatible object storage backend (AWS
+// S3, MinIO, or any other implementation of the S3 API)
+type Config struct {
+	// Endpoint overrides the default AWS endpoint, e.g. "http://localhost:9000" for a local
+	// MinIO instance; empty uses the real AWS endpoint for Region
+	Endpoint string
+	Bucket   string
+	Region   string
+	// AccessKeyID and SecretAccessKey are static credentials; empty falls back to the AWS SDK's
+	// default credential chain (environment, shared config, instance role, etc.)
+	AccessKeyID     string
+	SecretAccessKey string
+	// UsePathStyle addresses objects as endpoint/bucket/key instead of bucket.endpoint/key,
+	// required by most non-AWS S3-compatible services such as MinIO
+	UsePathStyle bool
+	// ObjectPrefix namespaces every object this backend writes, so one bucket can be shared
+	// by multiple deployments; empty writes to the bucket root
+	ObjectPrefix string
+	// CompactionInterval is how often the append-only log is folded into a single snapshot
+	// object; 0 disables background compaction, leaving it to run only at startup
+	CompactionInterval time.Duration
+}
@@ -0,0 +1,944 @@
+// Package s3storage is a repository backend that persists the same append-only JSONL log as
+// filestorage, but to an S3-compatible object store (AWS S3, MinIO, or any other
+// implementation of the S3 API) instead of local disk, so the service can run in containers
+// without a persistent volume.
+package s3storage
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/ar4ie13/shortener/internal/model"
+	"github.com/ar4ie13/shortener/internal/myerrors"
+	"github.com/ar4ie13/shortener/internal/repository/memory"
+	s3conf "github.com/ar4ie13/shortener/internal/repository/s3storage/config"
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/credentials"
+	"github.com/aws/aws-sdk-go-v2/service/s3"
+	"github.com/aws/aws-sdk-go-v2/service/s3/types"
+	"github.com/google/uuid"
+	"github.com/rs/zerolog"
+)
+
+// s3Client is the subset of *s3.Client used by S3Storage, narrowed so tests can substitute a
+// hand-written in-memory fake instead of talking to real S3 or MinIO
+type s3Client interface {
+	GetObject(ctx context.Context, params *s3.GetObjectInput, optFns ...func(*s3.Options)) (*s3.GetObjectOutput, error)
+	PutObject(ctx context.Context, params *s3.PutObjectInput, optFns ...func(*s3.Options)) (*s3.PutObjectOutput, error)
+	ListObjectsV2(ctx context.Context, params *s3.ListObjectsV2Input, optFns ...func(*s3.Options)) (*s3.ListObjectsV2Output, error)
+	DeleteObject(ctx context.Context, params *s3.DeleteObjectInput, optFns ...func(*s3.Options)) (*s3.DeleteObjectOutput, error)
+	HeadBucket(ctx context.Context, params *s3.HeadBucketInput, optFns ...func(*s3.Options)) (*s3.HeadBucketOutput, error)
+}
+
+// S3Storage is the main S3 storage object. It keeps the same in-memory cache as filestorage,
+// and persists every mutation as a small object appended under a log/ prefix, rather than
+// rewriting one large object per write, since S3 has no native append operation. A background
+// goroutine periodically compacts the log into a single snapshot object, so the log does not
+// grow without bound under sustained write traffic.
+type S3Storage struct {
+	m      *memory.MemStorage
+	client s3Client
+	cfg    s3conf.Config
+	zlog   zerolog.Logger
+	mu     sync.RWMutex
+
+	urlLogSeq    atomic.Int64
+	apiKeyLogSeq atomic.Int64
+
+	compactStop chan struct{}
+	compactDone chan struct{}
+}
+
+// NewS3Storage constructs the AWS SDK client from cfg and initializes the main S3 storage
+// object. It does not touch the network; call Load to populate the in-memory cache from
+// whatever is already in the bucket.
+func NewS3Storage(cfg s3conf.Config, zlog zerolog.Logger) *S3Storage {
+	client := s3.New(s3.Options{
+		Region:       cfg.Region,
+		Credentials:  credentialsOrNil(cfg.AccessKeyID, cfg.SecretAccessKey),
+		BaseEndpoint: endpointOrNil(cfg.Endpoint),
+		UsePathStyle: cfg.UsePathStyle,
+	})
+
+	return &S3Storage{
+		m:      memory.NewMemStorage(),
+		client: client,
+		cfg:    cfg,
+		zlog:   zlog,
+	}
+}
+
+// endpointOrNil returns nil for an empty endpoint, so the SDK's default endpoint resolution
+// for cfg.Region is used instead of an empty BaseEndpoint override
+func endpointOrNil(endpoint string) *string {
+	if endpoint == "" {
+		return nil
+	}
+	return aws.String(endpoint)
+}
+
+// credentialsOrNil returns a static credentials provider for accessKeyID/secretAccessKey, or
+// nil if accessKeyID is empty, so the SDK's own anonymous/default credential resolution
+// applies instead of erroring out on an explicit-but-empty static credential pair
+func credentialsOrNil(accessKeyID, secretAccessKey string) aws.CredentialsProvider {
+	if accessKeyID == "" {
+		return nil
+	}
+	return credentials.NewStaticCredentialsProvider(accessKeyID, secretAccessKey, "")
+}
+
+// ready reports myerrors.ErrRepositoryNotReady if s was constructed without NewS3Storage
+func (s *S3Storage) ready() error {
+	if s.m == nil {
+		return myerrors.ErrRepositoryNotReady
+	}
+	return nil
+}
+
+// object key layout: a compacted snapshot at the root of cfg.ObjectPrefix, plus an append-only
+// log of incremental writes under a log/ sub-prefix, replayed on top of the snapshot at Load
+// time. API keys use the same layout under a separate prefix, since model.URL and model.APIKey
+// records are not distinguishable from each other once serialized.
+func (s *S3Storage) dataKey() string {
+	return s.cfg.ObjectPrefix + "data.jsonl"
+}
+
+func (s *S3Storage) dataLogKey(seq int64) string {
+	return fmt.Sprintf("%slog/%020d.jsonl", s.cfg.ObjectPrefix, seq)
+}
+
+func (s *S3Storage) dataLogPrefix() string {
+	return s.cfg.ObjectPrefix + "log/"
+}
+
+func (s *S3Storage) apiKeysKey() string {
+	return s.cfg.ObjectPrefix + "apikeys.jsonl"
+}
+
+func (s *S3Storage) apiKeysLogKey(seq int64) string {
+	return fmt.Sprintf("%sapikeys-log/%020d.jsonl", s.cfg.ObjectPrefix, seq)
+}
+
+func (s *S3Storage) apiKeysLogPrefix() string {
+	return s.cfg.ObjectPrefix + "apikeys-log/"
+}
+
+// getObjectLines downloads key and returns its content split into non-empty lines. A missing
+// key is not an error: it means nothing has been written there yet.
+func (s *S3Storage) getObjectLines(ctx context.Context, key string) ([][]byte, error) {
+	out, err := s.client.GetObject(ctx, &s3.GetObjectInput{
+		Bucket: aws.String(s.cfg.Bucket),
+		Key:    aws.String(key),
+	})
+	if err != nil {
+		var nsk *types.NoSuchKey
+		if errors.As(err, &nsk) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("cannot get object %q: %w", key, err)
+	}
+	defer out.Body.Close()
+
+	var lines [][]byte
+	scanner := bufio.NewScanner(out.Body)
+	scanner.Buffer(make([]byte, 0, 64*1024), 1024*1024)
+	for scanner.Scan() {
+		line := scanner.Bytes()
+		if len(line) == 0 {
+			continue
+		}
+		lines = append(lines, append([]byte(nil), line...))
+	}
+	if err = scanner.Err(); err != nil {
+		return nil, fmt.Errorf("cannot read object %q: %w", key, err)
+	}
+
+	return lines, nil
+}
+
+// listLogKeys returns every object key under prefix, in lexicographic (and therefore
+// chronological, given dataLogKey's zero-padded sequence number) order
+func (s *S3Storage) listLogKeys(ctx context.Context, prefix string) ([]string, error) {
+	var keys []string
+	var continuationToken *string
+	for {
+		out, err := s.client.ListObjectsV2(ctx, &s3.ListObjectsV2Input{
+			Bucket:            aws.String(s.cfg.Bucket),
+			Prefix:            aws.String(prefix),
+			ContinuationToken: continuationToken,
+		})
+		if err != nil {
+			return nil, fmt.Errorf("cannot list objects under %q: %w", prefix, err)
+		}
+		for _, obj := range out.Contents {
+			keys = append(keys, aws.ToString(obj.Key))
+		}
+		if out.IsTruncated == nil || !*out.IsTruncated {
+			break
+		}
+		continuationToken = out.NextContinuationToken
+	}
+
+	return keys, nil
+}
+
+// putLines uploads records, one JSON object per line, to key
+func (s *S3Storage) putLines(ctx context.Context, key string, lines [][]byte) error {
+	var buf bytes.Buffer
+	for _, line := range lines {
+		buf.Write(line)
+		buf.WriteByte('\n')
+	}
+
+	_, err := s.client.PutObject(ctx, &s3.PutObjectInput{
+		Bucket: aws.String(s.cfg.Bucket),
+		Key:    aws.String(key),
+		Body:   bytes.NewReader(buf.Bytes()),
+	})
+	if err != nil {
+		return fmt.Errorf("cannot put object %q: %w", key, err)
+	}
+
+	return nil
+}
+
+// marshalLines marshals each value in v to its own JSON line
+func marshalLines[T any](values []T) ([][]byte, error) {
+	lines := make([][]byte, 0, len(values))
+	for _, v := range values {
+		line, err := json.Marshal(v)
+		if err != nil {
+			return nil, fmt.Errorf("cannot marshal json: %w", err)
+		}
+		lines = append(lines, line)
+	}
+	return lines, nil
+}
+
+// appendURLLog writes records as a new log object, so a subsequent Load replays them on top
+// of the last snapshot. Callers must hold s.mu.
+func (s *S3Storage) appendURLLog(ctx context.Context, records []model.URL) error {
+	lines, err := marshalLines(records)
+	if err != nil {
+		return err
+	}
+	return s.putLines(ctx, s.dataLogKey(s.urlLogSeq.Add(1)), lines)
+}
+
+// appendAPIKeyLog writes records as a new API key log object. Callers must hold s.mu.
+func (s *S3Storage) appendAPIKeyLog(ctx context.Context, records []model.APIKey) error {
+	lines, err := marshalLines(records)
+	if err != nil {
+		return err
+	}
+	return s.putLines(ctx, s.apiKeysLogKey(s.apiKeyLogSeq.Add(1)), lines)
+}
+
+// snapshotURLRecords reconstructs every model.URL row currently held in memory, the same way
+// filestorage rebuilds one before a full file rewrite
+func snapshotURLRecords(m *memory.MemStorage) []model.URL {
+	var records []model.URL
+	for userUUID, slugs := range m.UserUUIDSlugMemStore {
+		for shortURL, originalURL := range slugs {
+			record := model.URL{
+				UserUUID:       userUUID,
+				ShortURL:       shortURL,
+				OriginalURL:    originalURL,
+				IsDeleted:      m.IsSlugDeletedMemStore[shortURL],
+				Referer:        m.RefererMemStore[shortURL],
+				UserAgent:      m.UserAgentMemStore[shortURL],
+				ExpiresAt:      m.ExpiresAtMemStore[shortURL],
+				DeletedAt:      m.DeletedAtMemStore[shortURL],
+				PasswordHash:   m.PasswordHashMemStore[shortURL],
+				RedirectStatus: m.RedirectStatusMemStore[shortURL],
+				Host:           m.HostMemStore[shortURL],
+				CreatedAt:      m.CreatedAtMemStore[shortURL],
+				UpdatedAt:      m.UpdatedAtMemStore[shortURL],
+			}
+			for id, slug := range m.UUIDMemStore {
+				if slug == shortURL {
+					record.UUID = id
+					break
+				}
+			}
+			records = append(records, record)
+		}
+	}
+	return records
+}
+
+// snapshotAPIKeyRecords reconstructs every model.APIKey row currently held in memory
+func snapshotAPIKeyRecords(m *memory.MemStorage) []model.APIKey {
+	records := make([]model.APIKey, 0, len(m.APIKeyUserUUIDMemStore))
+	for hash, owner := range m.APIKeyUserUUIDMemStore {
+		records = append(records, model.APIKey{
+			ID:        hash,
+			UserUUID:  owner,
+			CreatedAt: m.APIKeyCreatedAtMemStore[hash],
+			Revoked:   m.APIKeyRevokedMemStore[hash],
+		})
+	}
+	return records
+}
+
+// applyURLRecord replays a single decoded URL record onto the in-memory store, the same way
+// filestorage.LoadFile applies each line it reads
+func applyURLRecord(m *memory.MemStorage, record model.URL) {
+	if m.UserUUIDURLMemStore[record.UserUUID] == nil {
+		m.UserUUIDURLMemStore[record.UserUUID] = make(map[string]string)
+	}
+	if m.UserUUIDSlugMemStore[record.UserUUID] == nil {
+		m.UserUUIDSlugMemStore[record.UserUUID] = make(map[string]string)
+	}
+
+	m.SlugMemStore[record.ShortURL] = record.OriginalURL
+	m.URLMemStore[record.OriginalURL] = record.ShortURL
+	m.UserUUIDURLMemStore[record.UserUUID][record.OriginalURL] = record.ShortURL
+	m.UserUUIDSlugMemStore[record.UserUUID][record.ShortURL] = record.OriginalURL
+	m.UUIDMemStore[record.UUID] = record.ShortURL
+	m.IsSlugDeletedMemStore[record.ShortURL] = record.IsDeleted
+	m.RefererMemStore[record.ShortURL] = record.Referer
+	m.UserAgentMemStore[record.ShortURL] = record.UserAgent
+	m.ExpiresAtMemStore[record.ShortURL] = record.ExpiresAt
+	m.DeletedAtMemStore[record.ShortURL] = record.DeletedAt
+	if record.PasswordHash != "" {
+		m.PasswordHashMemStore[record.ShortURL] = record.PasswordHash
+	}
+	if record.RedirectStatus != 0 {
+		m.RedirectStatusMemStore[record.ShortURL] = record.RedirectStatus
+	}
+	m.HostMemStore[record.ShortURL] = record.Host
+	m.CreatedAtMemStore[record.ShortURL] = record.CreatedAt
+	m.UpdatedAtMemStore[record.ShortURL] = record.UpdatedAt
+}
+
+// applyAPIKeyRecord replays a single decoded API key record onto the in-memory store
+func applyAPIKeyRecord(m *memory.MemStorage, record model.APIKey) {
+	m.APIKeyUserUUIDMemStore[record.ID] = record.UserUUID
+	m.APIKeyCreatedAtMemStore[record.ID] = record.CreatedAt
+	m.APIKeyRevokedMemStore[record.ID] = record.Revoked
+}
+
+// Load populates the in-memory cache from the bucket: the last snapshot, if any, followed by
+// every log object written since, in order. It also starts the background compaction loop, if
+// cfg.CompactionInterval is positive.
+func (s *S3Storage) Load(ctx context.Context) error {
+	if err := s.ready(); err != nil {
+		return err
+	}
+
+	s.mu.Lock()
+	if err := s.loadURLs(ctx); err != nil {
+		s.mu.Unlock()
+		return err
+	}
+	if err := s.loadAPIKeys(ctx); err != nil {
+		s.mu.Unlock()
+		return err
+	}
+	s.mu.Unlock()
+
+	if s.cfg.CompactionInterval > 0 {
+		s.startCompaction()
+	}
+
+	return nil
+}
+
+// loadURLs loads the snapshot object followed by every URL log object. Callers must hold s.mu.
+func (s *S3Storage) loadURLs(ctx context.Context) error {
+	lines, err := s.getObjectLines(ctx, s.dataKey())
+	if err != nil {
+		return err
+	}
+	if err = s.decodeAndApplyURLLines(lines); err != nil {
+		return err
+	}
+
+	keys, err := s.listLogKeys(ctx, s.dataLogPrefix())
+	if err != nil {
+		return err
+	}
+	for _, key := range keys {
+		lines, err = s.getObjectLines(ctx, key)
+		if err != nil {
+			return err
+		}
+		if err = s.decodeAndApplyURLLines(lines); err != nil {
+			return err
+		}
+	}
+	s.urlLogSeq.Store(int64(len(keys)))
+
+	s.zlog.Debug().Msgf("s3storage loaded successfully, map contains %d items", len(s.m.SlugMemStore))
+	return nil
+}
+
+func (s *S3Storage) decodeAndApplyURLLines(lines [][]byte) error {
+	for _, line := range lines {
+		var record model.URL
+		if err := json.Unmarshal(line, &record); err != nil {
+			s.zlog.Debug().Msgf("error decoding JSON: %v\n", err)
+			return err
+		}
+		applyURLRecord(s.m, record)
+	}
+	return nil
+}
+
+// loadAPIKeys loads the API key snapshot object followed by every API key log object. Callers
+// must hold s.mu.
+func (s *S3Storage) loadAPIKeys(ctx context.Context) error {
+	lines, err := s.getObjectLines(ctx, s.apiKeysKey())
+	if err != nil {
+		return err
+	}
+	if err = s.decodeAndApplyAPIKeyLines(lines); err != nil {
+		return err
+	}
+
+	keys, err := s.listLogKeys(ctx, s.apiKeysLogPrefix())
+	if err != nil {
+		return err
+	}
+	for _, key := range keys {
+		lines, err = s.getObjectLines(ctx, key)
+		if err != nil {
+			return err
+		}
+		if err = s.decodeAndApplyAPIKeyLines(lines); err != nil {
+			return err
+		}
+	}
+	s.apiKeyLogSeq.Store(int64(len(keys)))
+
+	return nil
+}
+
+func (s *S3Storage) decodeAndApplyAPIKeyLines(lines [][]byte) error {
+	for _, line := range lines {
+		var record model.APIKey
+		if err := json.Unmarshal(line, &record); err != nil {
+			s.zlog.Debug().Msgf("error decoding JSON: %v\n", err)
+			return err
+		}
+		applyAPIKeyRecord(s.m, record)
+	}
+	return nil
+}
+
+// startCompaction runs compactOnce on cfg.CompactionInterval until Close is called
+func (s *S3Storage) startCompaction() {
+	s.compactStop = make(chan struct{})
+	s.compactDone = make(chan struct{})
+
+	go func() {
+		defer close(s.compactDone)
+
+		ticker := time.NewTicker(s.cfg.CompactionInterval)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-s.compactStop:
+				return
+			case <-ticker.C:
+				if err := s.compactOnce(context.Background()); err != nil {
+					s.zlog.Error().Err(err).Msg("s3storage compaction failed")
+				}
+			}
+		}
+	}()
+}
+
+// compactOnce folds the current in-memory state into a fresh snapshot object, then deletes
+// every log object whose effect is now captured in it, so the log does not grow without bound
+func (s *S3Storage) compactOnce(ctx context.Context) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if err := s.compactURLs(ctx); err != nil {
+		return err
+	}
+	return s.compactAPIKeys(ctx)
+}
+
+func (s *S3Storage) compactURLs(ctx context.Context) error {
+	keys, err := s.listLogKeys(ctx, s.dataLogPrefix())
+	if err != nil {
+		return err
+	}
+
+	lines, err := marshalLines(snapshotURLRecords(s.m))
+	if err != nil {
+		return err
+	}
+	if err = s.putLines(ctx, s.dataKey(), lines); err != nil {
+		return err
+	}
+
+	for _, key := range keys {
+		if _, err = s.client.DeleteObject(ctx, &s3.DeleteObjectInput{
+			Bucket: aws.String(s.cfg.Bucket),
+			Key:    aws.String(key),
+		}); err != nil {
+			return fmt.Errorf("cannot delete compacted log object %q: %w", key, err)
+		}
+	}
+
+	return nil
+}
+
+func (s *S3Storage) compactAPIKeys(ctx context.Context) error {
+	keys, err := s.listLogKeys(ctx, s.apiKeysLogPrefix())
+	if err != nil {
+		return err
+	}
+
+	lines, err := marshalLines(snapshotAPIKeyRecords(s.m))
+	if err != nil {
+		return err
+	}
+	if err = s.putLines(ctx, s.apiKeysKey(), lines); err != nil {
+		return err
+	}
+
+	for _, key := range keys {
+		if _, err = s.client.DeleteObject(ctx, &s3.DeleteObjectInput{
+			Bucket: aws.String(s.cfg.Bucket),
+			Key:    aws.String(key),
+		}); err != nil {
+			return fmt.Errorf("cannot delete compacted log object %q: %w", key, err)
+		}
+	}
+
+	return nil
+}
+
+// GetURL method is used to get URL (link) from the map
+func (s *S3Storage) GetURL(ctx context.Context, userUUID uuid.UUID, shortURL string, requireOwnership bool) (string, error) {
+	if err := s.ready(); err != nil {
+		return "", err
+	}
+
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	return s.m.GetURL(ctx, userUUID, shortURL, requireOwnership)
+}
+
+// GetURLs resolves many slugs in one call, returning one model.URLExpansion per entry in
+// shortURLs, in the same order
+func (s *S3Storage) GetURLs(ctx context.Context, userUUID uuid.UUID, shortURLs []string, requireOwnership bool) ([]model.URLExpansion, error) {
+	if err := s.ready(); err != nil {
+		return nil, err
+	}
+
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	return s.m.GetURLs(ctx, userUUID, shortURLs, requireOwnership)
+}
+
+// GetShortURL method is used to get URL (link) from the map
+func (s *S3Storage) GetShortURL(ctx context.Context, originalURL string) (string, error) {
+	if err := s.ready(); err != nil {
+		return "", err
+	}
+
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	return s.m.GetShortURL(ctx, originalURL)
+}
+
+// Save is a method used to save short url and original url
+func (s *S3Storage) Save(ctx context.Context, userUUID uuid.UUID, shortURL string, url string, referer string, userAgent string, expiresAt time.Time, createdAt time.Time) (string, error) {
+	if err := s.ready(); err != nil {
+		return "", err
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if existingSlug, err := s.m.Save(ctx, userUUID, shortURL, url, referer, userAgent, expiresAt, createdAt); err != nil {
+		return existingSlug, err
+	}
+
+	record := model.URL{
+		UUID:        uuid.New(),
+		UserUUID:    userUUID,
+		ShortURL:    shortURL,
+		OriginalURL: url,
+		IsDeleted:   false,
+		Referer:     referer,
+		UserAgent:   userAgent,
+		ExpiresAt:   expiresAt,
+		Host:        s.m.HostMemStore[shortURL],
+		CreatedAt:   createdAt,
+		UpdatedAt:   createdAt,
+	}
+	s.m.UUIDMemStore[record.UUID] = shortURL
+
+	return "", s.appendURLLog(ctx, []model.URL{record})
+}
+
+// SaveBatch used to save batch of short urls and URL. An entry reported as a conflict by the
+// underlying memory store is not appended to the log, since it was not actually written under
+// its requested short URL.
+func (s *S3Storage) SaveBatch(ctx context.Context, userUUID uuid.UUID, batch []model.URL) ([]string, error) {
+	if err := s.ready(); err != nil {
+		return nil, err
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	existing, err := s.m.SaveBatch(ctx, userUUID, batch)
+	if err != nil {
+		return nil, err
+	}
+
+	records := make([]model.URL, 0, len(batch))
+	for i := range batch {
+		if existing[i] != "" {
+			continue
+		}
+		records = append(records, model.URL{
+			UUID:        batch[i].UUID,
+			UserUUID:    userUUID,
+			ShortURL:    batch[i].ShortURL,
+			OriginalURL: batch[i].OriginalURL,
+			IsDeleted:   false,
+			Referer:     batch[i].Referer,
+			UserAgent:   batch[i].UserAgent,
+			ExpiresAt:   batch[i].ExpiresAt,
+			Host:        s.m.HostMemStore[batch[i].ShortURL],
+			CreatedAt:   batch[i].CreatedAt,
+			UpdatedAt:   batch[i].UpdatedAt,
+		})
+	}
+
+	if len(records) == 0 {
+		return existing, nil
+	}
+
+	if err := s.appendURLLog(ctx, records); err != nil {
+		return nil, err
+	}
+
+	return existing, nil
+}
+
+// GetUserShortURLs return short URLs for specified user
+func (s *S3Storage) GetUserShortURLs(ctx context.Context, userUUID uuid.UUID) ([]model.URL, error) {
+	if err := s.ready(); err != nil {
+		return nil, err
+	}
+
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	return s.m.GetUserShortURLs(ctx, userUUID)
+}
+
+// DeleteUserShortURLs mark short URLs as Deleted in storage, stamping deletedAt as their
+// deletion time, and persists the resulting state as a new log entry
+func (s *S3Storage) DeleteUserShortURLs(ctx context.Context, shortURLsToDelete map[uuid.UUID][]string, deletedAt time.Time) error {
+	if err := s.ready(); err != nil {
+		return err
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if err := s.m.DeleteUserShortURLs(ctx, shortURLsToDelete, deletedAt); err != nil {
+		return err
+	}
+
+	return s.appendURLLog(ctx, snapshotURLRecords(s.m))
+}
+
+// UpdateURL changes the destination for shortURL, owned by userUUID, to newURL and persists
+// the resulting state as a new log entry
+func (s *S3Storage) UpdateURL(ctx context.Context, userUUID uuid.UUID, shortURL string, newURL string, updatedAt time.Time) error {
+	if err := s.ready(); err != nil {
+		return err
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if err := s.m.UpdateURL(ctx, userUUID, shortURL, newURL, updatedAt); err != nil {
+		return err
+	}
+
+	return s.appendURLLog(ctx, snapshotURLRecords(s.m))
+}
+
+// SweepExpired marks expired short URLs as deleted and persists the resulting state as a new
+// log entry
+func (s *S3Storage) SweepExpired(ctx context.Context, before time.Time, limit int) (int, error) {
+	if err := s.ready(); err != nil {
+		return 0, err
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	swept, err := s.m.SweepExpired(ctx, before, limit)
+	if err != nil {
+		return 0, err
+	}
+	if swept == 0 {
+		return 0, nil
+	}
+
+	if err = s.appendURLLog(ctx, snapshotURLRecords(s.m)); err != nil {
+		return 0, err
+	}
+
+	return swept, nil
+}
+
+// PurgeDeleted permanently removes up to limit already soft-deleted rows whose deletion time
+// is before the given time, and persists the resulting state as a new log entry
+func (s *S3Storage) PurgeDeleted(ctx context.Context, before time.Time, limit int) (int, error) {
+	if err := s.ready(); err != nil {
+		return 0, err
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	purged, err := s.m.PurgeDeleted(ctx, before, limit)
+	if err != nil {
+		return 0, err
+	}
+	if purged == 0 {
+		return 0, nil
+	}
+
+	if err = s.appendURLLog(ctx, snapshotURLRecords(s.m)); err != nil {
+		return 0, err
+	}
+
+	return purged, nil
+}
+
+// SetPasswordedSlug stores passwordHash as the bcrypt hash guarding shortURL, and persists the
+// resulting state as a new log entry
+func (s *S3Storage) SetPasswordedSlug(ctx context.Context, shortURL string, passwordHash string) error {
+	if err := s.ready(); err != nil {
+		return err
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if err := s.m.SetPasswordedSlug(ctx, shortURL, passwordHash); err != nil {
+		return err
+	}
+
+	return s.appendURLLog(ctx, snapshotURLRecords(s.m))
+}
+
+// GetPasswordHash returns the bcrypt hash guarding shortURL, or "" if it is not password-protected
+func (s *S3Storage) GetPasswordHash(ctx context.Context, shortURL string) (string, error) {
+	if err := s.ready(); err != nil {
+		return "", err
+	}
+
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	return s.m.GetPasswordHash(ctx, shortURL)
+}
+
+// SetRedirectStatus stores status as the per-link override of the HTTP status used when
+// redirecting shortURL, and persists the resulting state as a new log entry
+func (s *S3Storage) SetRedirectStatus(ctx context.Context, shortURL string, status int) error {
+	if err := s.ready(); err != nil {
+		return err
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if err := s.m.SetRedirectStatus(ctx, shortURL, status); err != nil {
+		return err
+	}
+
+	return s.appendURLLog(ctx, snapshotURLRecords(s.m))
+}
+
+// GetRedirectStatus returns the per-link redirect status override for shortURL, or 0 if it
+// has none
+func (s *S3Storage) GetRedirectStatus(ctx context.Context, shortURL string) (int, error) {
+	if err := s.ready(); err != nil {
+		return 0, err
+	}
+
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	return s.m.GetRedirectStatus(ctx, shortURL)
+}
+
+// SaveAPIKey appends a new API key record to the in-memory store and the API key log
+func (s *S3Storage) SaveAPIKey(ctx context.Context, userUUID uuid.UUID, keyHash string, createdAt time.Time) error {
+	if err := s.ready(); err != nil {
+		return err
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if err := s.m.SaveAPIKey(ctx, userUUID, keyHash, createdAt); err != nil {
+		return err
+	}
+
+	return s.appendAPIKeyLog(ctx, []model.APIKey{{ID: keyHash, UserUUID: userUUID, CreatedAt: createdAt}})
+}
+
+// ResolveAPIKey returns the UserUUID owning keyHash, or myerrors.ErrNotFound if it does not
+// exist or has been revoked
+func (s *S3Storage) ResolveAPIKey(ctx context.Context, keyHash string) (uuid.UUID, error) {
+	if err := s.ready(); err != nil {
+		return uuid.Nil, err
+	}
+
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	return s.m.ResolveAPIKey(ctx, keyHash)
+}
+
+// RevokeAPIKey marks keyHash as revoked, if it exists and is owned by userUUID, and persists
+// the resulting state as a new API key log entry
+func (s *S3Storage) RevokeAPIKey(ctx context.Context, userUUID uuid.UUID, keyHash string) error {
+	if err := s.ready(); err != nil {
+		return err
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if err := s.m.RevokeAPIKey(ctx, userUUID, keyHash); err != nil {
+		return err
+	}
+
+	return s.appendAPIKeyLog(ctx, snapshotAPIKeyRecords(s.m))
+}
+
+// TopHosts returns up to n hosts with the most non-deleted short URLs pointing at them
+func (s *S3Storage) TopHosts(ctx context.Context, n int) ([]model.HostCount, error) {
+	if err := s.ready(); err != nil {
+		return nil, err
+	}
+
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	return s.m.TopHosts(ctx, n)
+}
+
+// Stats returns the total number of non-deleted short URLs and the total number of distinct
+// users that have ever saved one
+func (s *S3Storage) Stats(ctx context.Context) (model.Stats, error) {
+	if err := s.ready(); err != nil {
+		return model.Stats{}, err
+	}
+
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	return s.m.Stats(ctx)
+}
+
+// BackfillHosts computes and persists the host for any slug missing it (e.g. rows written
+// before the host field existed), returning how many slugs were backfilled
+func (s *S3Storage) BackfillHosts(ctx context.Context, limit int) (int, error) {
+	if err := s.ready(); err != nil {
+		return 0, err
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	backfilled, err := s.m.BackfillHosts(ctx, limit)
+	if err != nil {
+		return 0, err
+	}
+	if backfilled == 0 {
+		return 0, nil
+	}
+
+	if err = s.appendURLLog(ctx, snapshotURLRecords(s.m)); err != nil {
+		return 0, err
+	}
+
+	return backfilled, nil
+}
+
+// NextSequence returns the next value in a process-local, monotonically increasing sequence.
+// Like the rest of the in-memory state it wraps, it is not persisted to S3 and resets to 1 on
+// restart.
+func (s *S3Storage) NextSequence(ctx context.Context) (int64, error) {
+	if err := s.ready(); err != nil {
+		return 0, err
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return s.m.NextSequence(ctx)
+}
+
+// Ping checks that cfg.Bucket is reachable, since unlike filestorage's local disk, S3 is a
+// genuine remote dependency that can become unavailable independently of the process
+func (s *S3Storage) Ping(ctx context.Context) error {
+	if err := s.ready(); err != nil {
+		return err
+	}
+
+	_, err := s.client.HeadBucket(ctx, &s3.HeadBucketInput{Bucket: aws.String(s.cfg.Bucket)})
+	if err != nil {
+		return fmt.Errorf("cannot reach bucket %q: %w", s.cfg.Bucket, err)
+	}
+
+	return nil
+}
+
+// Verify checks the in-memory store loaded from S3 for internal inconsistencies, returning a
+// human-readable description of each one found
+func (s *S3Storage) Verify(ctx context.Context) ([]string, error) {
+	if err := s.ready(); err != nil {
+		return nil, err
+	}
+
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	return s.m.Verify(ctx)
+}
+
+// Close stops the background compaction loop, if running, performing one final compaction so
+// the log does not keep growing between now and the next startup's Load
+func (s *S3Storage) Close() error {
+	if s.compactStop == nil {
+		return nil
+	}
+
+	close(s.compactStop)
+	<-s.compactDone
+
+	return s.compactOnce(context.Background())
+}
@@ -0,0 +1,233 @@
+package s3storage
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"os"
+	"sort"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/ar4ie13/shortener/internal/myerrors"
+	s3conf "github.com/ar4ie13/shortener/internal/repository/s3storage/config"
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/s3"
+	"github.com/aws/aws-sdk-go-v2/service/s3/types"
+	"github.com/google/uuid"
+	"github.com/rs/zerolog"
+	"github.com/stretchr/testify/assert"
+)
+
+// fakeS3Client is a minimal map-backed in-memory stand-in for *s3.Client, implementing just
+// the subset of operations s3Client needs. There is no in-process S3/MinIO emulator in the
+// module's dependency graph, so this follows the repo's convention of hand-written test
+// doubles rather than pulling one in.
+type fakeS3Client struct {
+	mu      sync.Mutex
+	objects map[string][]byte
+}
+
+func newFakeS3Client() *fakeS3Client {
+	return &fakeS3Client{objects: make(map[string][]byte)}
+}
+
+func (f *fakeS3Client) GetObject(_ context.Context, params *s3.GetObjectInput, _ ...func(*s3.Options)) (*s3.GetObjectOutput, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	data, ok := f.objects[aws.ToString(params.Key)]
+	if !ok {
+		return nil, &types.NoSuchKey{}
+	}
+	return &s3.GetObjectOutput{Body: io.NopCloser(bytes.NewReader(data))}, nil
+}
+
+func (f *fakeS3Client) PutObject(_ context.Context, params *s3.PutObjectInput, _ ...func(*s3.Options)) (*s3.PutObjectOutput, error) {
+	data, err := io.ReadAll(params.Body)
+	if err != nil {
+		return nil, err
+	}
+
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.objects[aws.ToString(params.Key)] = data
+
+	return &s3.PutObjectOutput{}, nil
+}
+
+func (f *fakeS3Client) ListObjectsV2(_ context.Context, params *s3.ListObjectsV2Input, _ ...func(*s3.Options)) (*s3.ListObjectsV2Output, error) {
+	prefix := aws.ToString(params.Prefix)
+
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	var contents []types.Object
+	for key := range f.objects {
+		if strings.HasPrefix(key, prefix) {
+			contents = append(contents, types.Object{Key: aws.String(key)})
+		}
+	}
+	sort.Slice(contents, func(i, j int) bool { return *contents[i].Key < *contents[j].Key })
+
+	return &s3.ListObjectsV2Output{Contents: contents}, nil
+}
+
+func (f *fakeS3Client) DeleteObject(_ context.Context, params *s3.DeleteObjectInput, _ ...func(*s3.Options)) (*s3.DeleteObjectOutput, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	delete(f.objects, aws.ToString(params.Key))
+
+	return &s3.DeleteObjectOutput{}, nil
+}
+
+func (f *fakeS3Client) HeadBucket(_ context.Context, _ *s3.HeadBucketInput, _ ...func(*s3.Options)) (*s3.HeadBucketOutput, error) {
+	return &s3.HeadBucketOutput{}, nil
+}
+
+func newTestS3Storage() *S3Storage {
+	zlog := zerolog.New(zerolog.ConsoleWriter{Out: os.Stdout, TimeFormat: time.RFC3339}).
+		With().Timestamp().Logger().Level(zerolog.DebugLevel)
+
+	s := NewS3Storage(s3conf.Config{Bucket: "test-bucket"}, zlog)
+	s.client = newFakeS3Client()
+	return s
+}
+
+func TestS3Storage_SaveAndGetURL(t *testing.T) {
+	ctx := context.Background()
+	s := newTestS3Storage()
+	userUUID := uuid.New()
+
+	if _, err := s.Save(ctx, userUUID, "abc123", "https://example.com", "", "", time.Time{}, time.Time{}); err != nil {
+		t.Fatalf("Save() error = %v", err)
+	}
+
+	got, err := s.GetURL(ctx, userUUID, "abc123", false)
+	if err != nil {
+		t.Fatalf("GetURL() error = %v", err)
+	}
+	if got != "https://example.com" {
+		t.Errorf("GetURL() = %q, want %q", got, "https://example.com")
+	}
+}
+
+// TestS3Storage_LoadReplaysSnapshotAndLog verifies that a fresh S3Storage backed by the same
+// bucket recovers every saved row after Load, replaying the log on top of whatever snapshot
+// exists (none, in this case).
+func TestS3Storage_LoadReplaysSnapshotAndLog(t *testing.T) {
+	ctx := context.Background()
+	s := newTestS3Storage()
+	userUUID := uuid.New()
+
+	if _, err := s.Save(ctx, userUUID, "one", "https://one.example.com", "", "", time.Time{}, time.Time{}); err != nil {
+		t.Fatalf("Save() error = %v", err)
+	}
+	if _, err := s.Save(ctx, userUUID, "two", "https://two.example.com", "", "", time.Time{}, time.Time{}); err != nil {
+		t.Fatalf("Save() error = %v", err)
+	}
+
+	restarted := newTestS3Storage()
+	restarted.client = s.client
+	if err := restarted.Load(ctx); err != nil {
+		t.Fatalf("Load() error = %v", err)
+	}
+
+	for slug, want := range map[string]string{"one": "https://one.example.com", "two": "https://two.example.com"} {
+		got, err := restarted.GetURL(ctx, userUUID, slug, false)
+		if err != nil {
+			t.Fatalf("GetURL(%q) error = %v", slug, err)
+		}
+		if got != want {
+			t.Errorf("GetURL(%q) = %q, want %q", slug, got, want)
+		}
+	}
+}
+
+func TestS3Storage_DeleteUserShortURLs(t *testing.T) {
+	ctx := context.Background()
+	s := newTestS3Storage()
+	userUUID := uuid.New()
+
+	if _, err := s.Save(ctx, userUUID, "abc123", "https://example.com", "", "", time.Time{}, time.Time{}); err != nil {
+		t.Fatalf("Save() error = %v", err)
+	}
+
+	deletedAt := time.Now()
+	if err := s.DeleteUserShortURLs(ctx, map[uuid.UUID][]string{userUUID: {"abc123"}}, deletedAt); err != nil {
+		t.Fatalf("DeleteUserShortURLs() error = %v", err)
+	}
+
+	if _, err := s.GetURL(ctx, userUUID, "abc123", false); err == nil {
+		t.Error("GetURL() error = nil, want an error for a deleted slug")
+	}
+}
+
+// TestS3Storage_CompactionFoldsLogIntoSnapshot verifies that compactOnce rewrites every
+// pending log object into a single snapshot object and removes the log objects it folded in,
+// and that a Load afterward still sees the same data.
+func TestS3Storage_CompactionFoldsLogIntoSnapshot(t *testing.T) {
+	ctx := context.Background()
+	s := newTestS3Storage()
+	userUUID := uuid.New()
+
+	if _, err := s.Save(ctx, userUUID, "abc123", "https://example.com", "", "", time.Time{}, time.Time{}); err != nil {
+		t.Fatalf("Save() error = %v", err)
+	}
+	if _, err := s.Save(ctx, userUUID, "def456", "https://other.example.com", "", "", time.Time{}, time.Time{}); err != nil {
+		t.Fatalf("Save() error = %v", err)
+	}
+
+	if err := s.compactOnce(ctx); err != nil {
+		t.Fatalf("compactOnce() error = %v", err)
+	}
+
+	fake := s.client.(*fakeS3Client)
+	fake.mu.Lock()
+	_, hasSnapshot := fake.objects[s.dataKey()]
+	logObjects := 0
+	for key := range fake.objects {
+		if strings.HasPrefix(key, s.dataLogPrefix()) {
+			logObjects++
+		}
+	}
+	fake.mu.Unlock()
+
+	if !hasSnapshot {
+		t.Error("compactOnce() did not write a snapshot object")
+	}
+	if logObjects != 0 {
+		t.Errorf("compactOnce() left %d log objects, want 0", logObjects)
+	}
+
+	restarted := newTestS3Storage()
+	restarted.client = s.client
+	if err := restarted.Load(ctx); err != nil {
+		t.Fatalf("Load() error = %v", err)
+	}
+	if got, err := restarted.GetURL(ctx, userUUID, "abc123", false); err != nil || got != "https://example.com" {
+		t.Errorf("GetURL() after compaction+reload = (%q, %v), want (\"https://example.com\", nil)", got, err)
+	}
+}
+
+func TestS3Storage_Ping(t *testing.T) {
+	s := newTestS3Storage()
+	if err := s.Ping(context.Background()); err != nil {
+		t.Errorf("Ping() error = %v, want nil", err)
+	}
+}
+
+func TestS3Storage_NotReady(t *testing.T) {
+	s := &S3Storage{}
+
+	_, err := s.GetURL(context.Background(), uuid.New(), "slug", false)
+	assert.ErrorIs(t, err, myerrors.ErrRepositoryNotReady)
+
+	_, err = s.Save(context.Background(), uuid.New(), "slug", "https://example.com", "", "", time.Time{}, time.Time{})
+	assert.ErrorIs(t, err, myerrors.ErrRepositoryNotReady)
+
+	_, err = s.Stats(context.Background())
+	assert.ErrorIs(t, err, myerrors.ErrRepositoryNotReady)
+}
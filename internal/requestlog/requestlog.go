@@ -0,0 +1,22 @@
+// Package requestlog carries a request-scoped zerolog.Logger through context.Context, so a
+// single request ID can be attached once by HTTP middleware and picked up by every handler,
+// service, and repository call that logs on behalf of that request.
+package requestlog
+
+import (
+	"context"
+
+	"github.com/rs/zerolog"
+)
+
+// FromContext returns the logger attached to ctx, or fallback if none was attached (e.g. ctx
+// did not originate from an HTTP request, such as a background sweep or startup task). The
+// returned pointer is usable directly as a zerolog.Logger, since zerolog's Debug/Error/etc.
+// methods have pointer receivers.
+func FromContext(ctx context.Context, fallback zerolog.Logger) *zerolog.Logger {
+	if l := zerolog.Ctx(ctx); l.GetLevel() != zerolog.Disabled {
+		return l
+	}
+
+	return &fallback
+}
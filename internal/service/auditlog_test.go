@@ -0,0 +1,93 @@
+package service
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/ar4ie13/shortener/internal/clock"
+	"github.com/ar4ie13/shortener/internal/repository/memory"
+	"github.com/google/uuid"
+	"github.com/rs/zerolog"
+)
+
+// auditEntries decodes buf as newline-delimited JSON audit log entries
+func auditEntries(t *testing.T, buf *bytes.Buffer) []map[string]any {
+	t.Helper()
+	var entries []map[string]any
+	for _, line := range strings.Split(strings.TrimSpace(buf.String()), "\n") {
+		if line == "" {
+			continue
+		}
+		var entry map[string]any
+		if err := json.Unmarshal([]byte(line), &entry); err != nil {
+			t.Fatalf("failed to decode audit log line %q: %v", line, err)
+		}
+		entries = append(entries, entry)
+	}
+	return entries
+}
+
+func TestService_FlushDeletes_WritesAuditEntryPerSlug(t *testing.T) {
+	repo := memory.NewMemStorage()
+	var auditBuf bytes.Buffer
+	auditLog := zerolog.New(&auditBuf)
+	fakeClock := clock.NewFakeClock(time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC))
+	srv := NewService(repo, zerolog.Nop(), false, time.Second, "", 0, fakeClock, 0, 0, 0, 0, nil, 0, false, 0, false, auditLog, 0, "", 0, 0, nil, nil, 0)
+	defer srv.Close()
+
+	userUUID := uuid.New()
+	slugOne, err := srv.SaveURL(context.Background(), userUUID, "https://example.com/one", "", "", "", "", false, 0)
+	if err != nil {
+		t.Fatalf("SaveURL() unexpected error = %v", err)
+	}
+	slugTwo, err := srv.SaveURL(context.Background(), userUUID, "https://example.com/two", "", "", "", "", false, 0)
+	if err != nil {
+		t.Fatalf("SaveURL() unexpected error = %v", err)
+	}
+
+	srv.flushDeletes(context.Background(), map[uuid.UUID][]string{userUUID: {slugOne, slugTwo}}, nil)
+
+	entries := auditEntries(t, &auditBuf)
+	if len(entries) != 2 {
+		t.Fatalf("got %d audit entries, want 2", len(entries))
+	}
+	for _, entry := range entries {
+		if entry["action"] != "delete" {
+			t.Errorf("entry action = %v, want delete", entry["action"])
+		}
+		if entry["user_uuid"] != userUUID.String() {
+			t.Errorf("entry user_uuid = %v, want %v", entry["user_uuid"], userUUID.String())
+		}
+	}
+}
+
+func TestService_PurgeDeleted_WritesAggregateAuditEntry(t *testing.T) {
+	repo := &purgeTrackingRepository{remaining: 5}
+	var auditBuf bytes.Buffer
+	auditLog := zerolog.New(&auditBuf)
+	srv := NewService(repo, zerolog.Nop(), false, time.Second, "", 0, nil, 0, 0, 0, 0, nil, 0, false, 0, false, auditLog, 0, "", 0, 0, nil, nil, 0)
+	defer srv.Close()
+
+	if _, err := srv.PurgeDeleted(context.Background(), time.Now(), 10, 0); err != nil {
+		t.Fatalf("PurgeDeleted() unexpected error = %v", err)
+	}
+
+	entries := auditEntries(t, &auditBuf)
+	if len(entries) != 1 {
+		t.Fatalf("got %d audit entries, want 1", len(entries))
+	}
+	if entries[0]["action"] != "purge" {
+		t.Errorf("entry action = %v, want purge", entries[0]["action"])
+	}
+	if count, _ := entries[0]["count"].(float64); count != 5 {
+		t.Errorf("entry count = %v, want 5", entries[0]["count"])
+	}
+}
+
+// This codebase has no "restore" operation for soft-deleted short URLs — only delete (via
+// SendShortURLForDelete/flushDeletes) and purge (via PurgeDeleted) exist, so those are the
+// only two actions the audit log covers.
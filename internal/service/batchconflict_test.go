@@ -0,0 +1,111 @@
+package service
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/ar4ie13/shortener/internal/model"
+	"github.com/ar4ie13/shortener/internal/repository/memory"
+	"github.com/google/uuid"
+	"github.com/rs/zerolog"
+)
+
+func TestService_SaveBatch_ErrorModeReportsConflictInsteadOfFailing(t *testing.T) {
+	repo := memory.NewMemStorage()
+	srv := NewService(repo, zerolog.Nop(), false, time.Second, "", 0, nil, 0, 0, 0, 0, nil, 0, false, 0, false, zerolog.Nop(), 0, "", 0, 0, nil, nil, 0)
+	defer srv.Close()
+
+	userUUID := uuid.New()
+
+	existingSlug, err := srv.SaveURL(context.Background(), userUUID, "https://example.com/one", "", "", "", "", false, 0)
+	if err != nil {
+		t.Fatalf("SaveURL() unexpected error = %v", err)
+	}
+
+	batch := []model.URL{
+		{UUID: uuid.New(), OriginalURL: "https://example.com/two"},
+		{UUID: uuid.New(), OriginalURL: "https://example.com/one"},
+	}
+
+	result, err := srv.SaveBatch(context.Background(), userUUID, batch, "", "", false, false)
+	if err != nil {
+		t.Fatalf("SaveBatch() unexpected error = %v", err)
+	}
+	if len(result) != 2 {
+		t.Fatalf("SaveBatch() returned %d entries, want 2", len(result))
+	}
+	if result[0].Conflict {
+		t.Errorf("SaveBatch() entry 0 reported Conflict, want a fresh slug")
+	}
+	if !result[1].Conflict || result[1].ShortURL != existingSlug {
+		t.Errorf("SaveBatch() entry 1 = %+v, want Conflict = true with ShortURL %q", result[1], existingSlug)
+	}
+}
+
+// TestService_SaveBatch_DuplicateWithinBatchIsReportedAsConflict confirms that two entries in
+// the same batch pointing at the same original URL do not both get written: the later one is
+// reported as a conflict against the earlier one's slug.
+func TestService_SaveBatch_DuplicateWithinBatchIsReportedAsConflict(t *testing.T) {
+	repo := memory.NewMemStorage()
+	srv := NewService(repo, zerolog.Nop(), false, time.Second, "", 0, nil, 0, 0, 0, 0, nil, 0, false, 0, false, zerolog.Nop(), 0, "", 0, 0, nil, nil, 0)
+	defer srv.Close()
+
+	userUUID := uuid.New()
+
+	batch := []model.URL{
+		{UUID: uuid.New(), OriginalURL: "https://example.com/same"},
+		{UUID: uuid.New(), OriginalURL: "https://example.com/same"},
+	}
+
+	result, err := srv.SaveBatch(context.Background(), userUUID, batch, "", "", false, false)
+	if err != nil {
+		t.Fatalf("SaveBatch() unexpected error = %v", err)
+	}
+	if result[0].Conflict {
+		t.Errorf("SaveBatch() entry 0 reported Conflict, want the first write to succeed")
+	}
+	if !result[1].Conflict || result[1].ShortURL != result[0].ShortURL {
+		t.Errorf("SaveBatch() entry 1 = %+v, want Conflict = true with ShortURL %q", result[1], result[0].ShortURL)
+	}
+}
+
+func TestService_SaveBatch_ReuseOnConflictResolvesExistingSlug(t *testing.T) {
+	repo := memory.NewMemStorage()
+	srv := NewService(repo, zerolog.Nop(), false, time.Second, "", 0, nil, 0, 0, 0, 0, nil, 0, false, 0, false, zerolog.Nop(), 0, "", 0, 0, nil, nil, 0)
+	defer srv.Close()
+
+	userUUID := uuid.New()
+
+	existingSlug, err := srv.SaveURL(context.Background(), userUUID, "https://example.com/one", "", "", "", "", false, 0)
+	if err != nil {
+		t.Fatalf("SaveURL() unexpected error = %v", err)
+	}
+
+	batch := []model.URL{
+		{UUID: uuid.New(), OriginalURL: "https://example.com/two"},
+		{UUID: uuid.New(), OriginalURL: "https://example.com/one"},
+	}
+
+	result, err := srv.SaveBatch(context.Background(), userUUID, batch, "", "", true, false)
+	if err != nil {
+		t.Fatalf("SaveBatch() unexpected error = %v", err)
+	}
+	if len(result) != 2 {
+		t.Fatalf("SaveBatch() returned %d entries, want 2", len(result))
+	}
+	if result[1].ShortURL != existingSlug {
+		t.Errorf("SaveBatch() reused slug = %q, want %q", result[1].ShortURL, existingSlug)
+	}
+	if result[0].ShortURL == "" || result[0].ShortURL == existingSlug {
+		t.Errorf("SaveBatch() new slug = %q, want a fresh non-empty slug", result[0].ShortURL)
+	}
+
+	gotURL, err := repo.GetURL(context.Background(), userUUID, result[0].ShortURL, false)
+	if err != nil {
+		t.Fatalf("repo.GetURL() unexpected error = %v", err)
+	}
+	if gotURL != "https://example.com/two" {
+		t.Errorf("repo.GetURL() = %q, want %q", gotURL, "https://example.com/two")
+	}
+}
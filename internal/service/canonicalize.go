@@ -0,0 +1,44 @@
+package service
+
+import (
+	"net/url"
+	"path"
+	"strings"
+)
+
+// canonicalizeURL normalizes parsedURL so logically-equivalent URLs collapse to the same
+// string before SaveURL dedups and slugs it: the host is lowercased, a port matching the
+// scheme's default (80 for http, 443 for https) is stripped, and the path is cleaned of
+// "." and ".." segments. trackingParams, if non-empty, additionally strips any query
+// parameter whose name appears in it (e.g. "utm_source", "gclid") so links that only differ
+// by a tracking parameter aren't treated as distinct. parsedURL is mutated in place.
+func canonicalizeURL(parsedURL *url.URL, trackingParams map[string]struct{}) string {
+	parsedURL.Host = strings.ToLower(parsedURL.Host)
+	stripDefaultPort(parsedURL)
+
+	if cleaned := path.Clean(parsedURL.Path); cleaned != "." {
+		parsedURL.Path = cleaned
+	}
+
+	if len(trackingParams) > 0 && parsedURL.RawQuery != "" {
+		query := parsedURL.Query()
+		for param := range trackingParams {
+			query.Del(param)
+		}
+		parsedURL.RawQuery = query.Encode()
+	}
+
+	return parsedURL.String()
+}
+
+// stripDefaultPort removes u.Host's port if it is the scheme's default, so
+// "example.com:80" and "example.com" canonicalize to the same host.
+func stripDefaultPort(u *url.URL) {
+	port := u.Port()
+	if port == "" {
+		return
+	}
+	if (u.Scheme == "http" && port == "80") || (u.Scheme == "https" && port == "443") {
+		u.Host = u.Hostname()
+	}
+}
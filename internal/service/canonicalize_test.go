@@ -0,0 +1,114 @@
+package service
+
+import (
+	"context"
+	"net/url"
+	"testing"
+	"time"
+
+	"github.com/ar4ie13/shortener/internal/repository/memory"
+	"github.com/google/uuid"
+	"github.com/rs/zerolog"
+)
+
+func TestCanonicalizeURL(t *testing.T) {
+	tests := []struct {
+		name           string
+		rawURL         string
+		trackingParams map[string]struct{}
+		want           string
+	}{
+		{
+			name:   "lowercases host",
+			rawURL: "https://EXAMPLE.com/path",
+			want:   "https://example.com/path",
+		},
+		{
+			name:   "strips default https port",
+			rawURL: "https://example.com:443/path",
+			want:   "https://example.com/path",
+		},
+		{
+			name:   "strips default http port",
+			rawURL: "http://example.com:80/path",
+			want:   "http://example.com/path",
+		},
+		{
+			name:   "keeps non-default port",
+			rawURL: "https://example.com:8443/path",
+			want:   "https://example.com:8443/path",
+		},
+		{
+			name:   "resolves path traversal",
+			rawURL: "https://example.com/a/../b",
+			want:   "https://example.com/b",
+		},
+		{
+			name:           "strips configured tracking params",
+			rawURL:         "https://example.com/path?utm_source=ad&id=1",
+			trackingParams: map[string]struct{}{"utm_source": {}},
+			want:           "https://example.com/path?id=1",
+		},
+		{
+			name:           "leaves query untouched when no tracking params configured",
+			rawURL:         "https://example.com/path?utm_source=ad",
+			trackingParams: nil,
+			want:           "https://example.com/path?utm_source=ad",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			parsedURL, err := url.Parse(tt.rawURL)
+			if err != nil {
+				t.Fatalf("url.Parse() unexpected error = %v", err)
+			}
+
+			if got := canonicalizeURL(parsedURL, tt.trackingParams); got != tt.want {
+				t.Errorf("canonicalizeURL() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestService_SaveURL_StripsConfiguredTrackingParams(t *testing.T) {
+	repo := memory.NewMemStorage()
+	srv := NewService(repo, zerolog.Nop(), false, time.Second, "", 0, nil, 0, 0, 0, 0, nil, 0, false, 0, false, zerolog.Nop(), 0, "", 0, 0, nil, []string{"utm_source", "utm_campaign"}, 0)
+	defer srv.Close()
+
+	userUUID := uuid.New()
+
+	slug, err := srv.SaveURL(context.Background(), userUUID, "https://example.com/one?utm_source=ad&utm_campaign=spring&id=1", "", "", "", "", false, 0)
+	if err != nil {
+		t.Fatalf("SaveURL() unexpected error = %v", err)
+	}
+
+	gotURL, err := repo.GetURL(context.Background(), userUUID, slug, false)
+	if err != nil {
+		t.Fatalf("repo.GetURL() unexpected error = %v", err)
+	}
+	if gotURL != "https://example.com/one?id=1" {
+		t.Errorf("repo.GetURL() = %q, want %q", gotURL, "https://example.com/one?id=1")
+	}
+}
+
+func TestService_SaveURL_CanonicalizationDedupsVariants(t *testing.T) {
+	repo := memory.NewMemStorage()
+	srv := NewService(repo, zerolog.Nop(), false, time.Second, "", 0, nil, 0, 0, 0, 0, nil, 0, false, 0, false, zerolog.Nop(), 0, "", 0, 0, nil, []string{"utm_source"}, 0)
+	defer srv.Close()
+
+	userUUID := uuid.New()
+
+	slug, err := srv.SaveURL(context.Background(), userUUID, "https://EXAMPLE.com:443/a/../one", "", "", "", "", false, 0)
+	if err != nil {
+		t.Fatalf("SaveURL() unexpected error = %v", err)
+	}
+
+	gotSlug, err := srv.SaveURL(context.Background(), userUUID, "https://example.com/one?utm_source=ad", "", "", "", "", false, 0)
+	if gotSlug != slug {
+		t.Errorf("SaveURL() slug = %q, want %q (same slug as the canonically-equivalent link)", gotSlug, slug)
+	}
+	if err == nil {
+		t.Fatal("SaveURL() expected myerrors.ErrURLExist, got nil")
+	}
+}
@@ -0,0 +1,236 @@
+package service
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/ar4ie13/shortener/internal/model"
+	"github.com/google/uuid"
+	"github.com/rs/zerolog"
+)
+
+// deleteTrackingRepository implements Repository, recording every DeleteUserShortURLs call.
+// err, if set, is returned by DeleteUserShortURLs instead of recording the call.
+type deleteTrackingRepository struct {
+	mu      sync.Mutex
+	deletes []map[uuid.UUID][]string
+	err     error
+}
+
+func (r *deleteTrackingRepository) GetURL(_ context.Context, _ uuid.UUID, _ string, _ bool) (string, error) {
+	return "", nil
+}
+func (r *deleteTrackingRepository) GetURLs(_ context.Context, _ uuid.UUID, shortURLs []string, _ bool) ([]model.URLExpansion, error) {
+	return make([]model.URLExpansion, len(shortURLs)), nil
+}
+func (r *deleteTrackingRepository) GetShortURL(_ context.Context, _ string) (string, error) {
+	return "", nil
+}
+func (r *deleteTrackingRepository) Save(_ context.Context, _ uuid.UUID, _ string, _ string, _ string, _ string, _ time.Time, _ time.Time) (string, error) {
+	return "", nil
+}
+func (r *deleteTrackingRepository) SaveBatch(_ context.Context, _ uuid.UUID, _ []model.URL) ([]string, error) {
+	return nil, nil
+}
+func (r *deleteTrackingRepository) GetUserShortURLs(_ context.Context, _ uuid.UUID) ([]model.URL, error) {
+	return nil, nil
+}
+func (r *deleteTrackingRepository) UpdateURL(_ context.Context, _ uuid.UUID, _ string, _ string, _ time.Time) error {
+	return nil
+}
+func (r *deleteTrackingRepository) DeleteUserShortURLs(_ context.Context, shortURLsToDelete map[uuid.UUID][]string, _ time.Time) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.err != nil {
+		return r.err
+	}
+	r.deletes = append(r.deletes, shortURLsToDelete)
+	return nil
+}
+func (r *deleteTrackingRepository) SweepExpired(_ context.Context, _ time.Time, _ int) (int, error) {
+	return 0, nil
+}
+func (r *deleteTrackingRepository) PurgeDeleted(_ context.Context, _ time.Time, _ int) (int, error) {
+	return 0, nil
+}
+func (r *deleteTrackingRepository) Ping(_ context.Context) error {
+	return nil
+}
+func (r *deleteTrackingRepository) NextSequence(_ context.Context) (int64, error) {
+	return 0, nil
+}
+func (r *deleteTrackingRepository) SetPasswordedSlug(_ context.Context, _ string, _ string) error {
+	return nil
+}
+func (r *deleteTrackingRepository) GetPasswordHash(_ context.Context, _ string) (string, error) {
+	return "", nil
+}
+func (r *deleteTrackingRepository) SetRedirectStatus(_ context.Context, _ string, _ int) error {
+	return nil
+}
+func (r *deleteTrackingRepository) GetRedirectStatus(_ context.Context, _ string) (int, error) {
+	return 0, nil
+}
+func (r *deleteTrackingRepository) SaveAPIKey(_ context.Context, _ uuid.UUID, _ string, _ time.Time) error {
+	return nil
+}
+func (r *deleteTrackingRepository) ResolveAPIKey(_ context.Context, _ string) (uuid.UUID, error) {
+	return uuid.Nil, nil
+}
+func (r *deleteTrackingRepository) RevokeAPIKey(_ context.Context, _ uuid.UUID, _ string) error {
+	return nil
+}
+func (r *deleteTrackingRepository) TopHosts(_ context.Context, _ int) ([]model.HostCount, error) {
+	return nil, nil
+}
+func (r *deleteTrackingRepository) Stats(_ context.Context) (model.Stats, error) {
+	return model.Stats{}, nil
+}
+func (r *deleteTrackingRepository) BackfillHosts(_ context.Context, _ int) (int, error) {
+	return 0, nil
+}
+func (r *deleteTrackingRepository) Verify(_ context.Context) ([]string, error) {
+	return nil, nil
+}
+
+func (r *deleteTrackingRepository) Close() error {
+	return nil
+}
+func (r *deleteTrackingRepository) callCount() int {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return len(r.deletes)
+}
+
+func TestService_Close_PerformsFinalFlush(t *testing.T) {
+	repo := &deleteTrackingRepository{}
+	srv := NewService(repo, zerolog.Nop(), false, time.Second, "", 0, nil, 0, 0, 0, 0, nil, 0, false, 0, false, zerolog.Nop(), 0, "", 0, 0, nil, nil, 0)
+
+	userUUID := uuid.New()
+	srv.SendShortURLForDelete(context.Background(), userUUID, []string{"abc123"})
+
+	done := make(chan struct{})
+	go func() {
+		srv.Close()
+		close(done)
+	}()
+
+	select {
+	case <-done:
+	case <-time.After(2 * time.Second):
+		t.Fatal("Close() did not return, goroutine likely deadlocked")
+	}
+
+	if repo.callCount() != 1 {
+		t.Fatalf("DeleteUserShortURLs call count = %d, want 1 final flush", repo.callCount())
+	}
+}
+
+// cancelAwareRepository implements Repository, recording whether DeleteUserShortURLs
+// observed a context that was already cancelled when called.
+type cancelAwareRepository struct {
+	mu         sync.Mutex
+	sawCancel  bool
+	callCount_ int
+}
+
+func (r *cancelAwareRepository) GetURL(_ context.Context, _ uuid.UUID, _ string, _ bool) (string, error) {
+	return "", nil
+}
+func (r *cancelAwareRepository) GetURLs(_ context.Context, _ uuid.UUID, shortURLs []string, _ bool) ([]model.URLExpansion, error) {
+	return make([]model.URLExpansion, len(shortURLs)), nil
+}
+func (r *cancelAwareRepository) GetShortURL(_ context.Context, _ string) (string, error) {
+	return "", nil
+}
+func (r *cancelAwareRepository) Save(_ context.Context, _ uuid.UUID, _ string, _ string, _ string, _ string, _ time.Time, _ time.Time) (string, error) {
+	return "", nil
+}
+func (r *cancelAwareRepository) SaveBatch(_ context.Context, _ uuid.UUID, _ []model.URL) ([]string, error) {
+	return nil, nil
+}
+func (r *cancelAwareRepository) GetUserShortURLs(_ context.Context, _ uuid.UUID) ([]model.URL, error) {
+	return nil, nil
+}
+func (r *cancelAwareRepository) UpdateURL(_ context.Context, _ uuid.UUID, _ string, _ string, _ time.Time) error {
+	return nil
+}
+func (r *cancelAwareRepository) DeleteUserShortURLs(ctx context.Context, _ map[uuid.UUID][]string, _ time.Time) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.callCount_++
+	select {
+	case <-ctx.Done():
+		r.sawCancel = true
+	default:
+	}
+	return ctx.Err()
+}
+func (r *cancelAwareRepository) SweepExpired(_ context.Context, _ time.Time, _ int) (int, error) {
+	return 0, nil
+}
+func (r *cancelAwareRepository) PurgeDeleted(_ context.Context, _ time.Time, _ int) (int, error) {
+	return 0, nil
+}
+func (r *cancelAwareRepository) Ping(_ context.Context) error {
+	return nil
+}
+func (r *cancelAwareRepository) NextSequence(_ context.Context) (int64, error) {
+	return 0, nil
+}
+func (r *cancelAwareRepository) SetPasswordedSlug(_ context.Context, _ string, _ string) error {
+	return nil
+}
+func (r *cancelAwareRepository) GetPasswordHash(_ context.Context, _ string) (string, error) {
+	return "", nil
+}
+func (r *cancelAwareRepository) SetRedirectStatus(_ context.Context, _ string, _ int) error {
+	return nil
+}
+func (r *cancelAwareRepository) GetRedirectStatus(_ context.Context, _ string) (int, error) {
+	return 0, nil
+}
+func (r *cancelAwareRepository) SaveAPIKey(_ context.Context, _ uuid.UUID, _ string, _ time.Time) error {
+	return nil
+}
+func (r *cancelAwareRepository) ResolveAPIKey(_ context.Context, _ string) (uuid.UUID, error) {
+	return uuid.Nil, nil
+}
+func (r *cancelAwareRepository) RevokeAPIKey(_ context.Context, _ uuid.UUID, _ string) error {
+	return nil
+}
+func (r *cancelAwareRepository) TopHosts(_ context.Context, _ int) ([]model.HostCount, error) {
+	return nil, nil
+}
+func (r *cancelAwareRepository) Stats(_ context.Context) (model.Stats, error) {
+	return model.Stats{}, nil
+}
+func (r *cancelAwareRepository) BackfillHosts(_ context.Context, _ int) (int, error) {
+	return 0, nil
+}
+func (r *cancelAwareRepository) Verify(_ context.Context) ([]string, error) {
+	return nil, nil
+}
+
+func (r *cancelAwareRepository) Close() error {
+	return nil
+}
+
+func TestService_FlushDeletes_AbortsOnCancelledContext(t *testing.T) {
+	repo := &cancelAwareRepository{}
+	srv := NewService(repo, zerolog.Nop(), false, time.Second, "", 0, nil, 0, 0, 0, 0, nil, 0, false, 0, false, zerolog.Nop(), 0, "", 0, 0, nil, nil, 0)
+	srv.cancel()
+
+	srv.flushDeletes(srv.ctx, map[uuid.UUID][]string{uuid.New(): {"abc123"}}, nil)
+
+	repo.mu.Lock()
+	defer repo.mu.Unlock()
+	if repo.callCount_ != 1 {
+		t.Fatalf("DeleteUserShortURLs call count = %d, want 1", repo.callCount_)
+	}
+	if !repo.sawCancel {
+		t.Fatal("flushDeletes did not propagate the cancelled context to the repository")
+	}
+}
@@ -0,0 +1,51 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/ar4ie13/shortener/internal/myerrors"
+	"github.com/ar4ie13/shortener/internal/repository/memory"
+	"github.com/google/uuid"
+	"github.com/rs/zerolog"
+)
+
+func TestService_SaveURL_CustomAlias(t *testing.T) {
+	repo := memory.NewMemStorage()
+	srv := NewService(repo, zerolog.Nop(), false, time.Second, "", 0, nil, 0, 0, 0, 0, nil, 0, false, 0, false, zerolog.Nop(), 0, "", 0, 0, nil, nil, 0)
+	defer srv.Close()
+
+	userUUID := uuid.New()
+
+	slug, err := srv.SaveURL(context.Background(), userUUID, "https://example.com/one", "", "", "mycustom", "", false, 0)
+	if err != nil {
+		t.Fatalf("SaveURL() unexpected error = %v", err)
+	}
+	if slug != "mycustom" {
+		t.Errorf("SaveURL() slug = %q, want %q", slug, "mycustom")
+	}
+
+	gotURL, err := repo.GetURL(context.Background(), userUUID, "mycustom", false)
+	if err != nil || gotURL != "https://example.com/one" {
+		t.Errorf("repo.GetURL(%q) = %q, %v, want %q, nil", "mycustom", gotURL, err, "https://example.com/one")
+	}
+}
+
+func TestService_SaveURL_CustomAliasAlreadyTaken(t *testing.T) {
+	repo := memory.NewMemStorage()
+	srv := NewService(repo, zerolog.Nop(), false, time.Second, "", 0, nil, 0, 0, 0, 0, nil, 0, false, 0, false, zerolog.Nop(), 0, "", 0, 0, nil, nil, 0)
+	defer srv.Close()
+
+	userUUID := uuid.New()
+
+	if _, err := srv.SaveURL(context.Background(), userUUID, "https://example.com/one", "", "", "taken", "", false, 0); err != nil {
+		t.Fatalf("SaveURL() unexpected error = %v", err)
+	}
+
+	_, err := srv.SaveURL(context.Background(), userUUID, "https://example.com/two", "", "", "taken", "", false, 0)
+	if !errors.Is(err, myerrors.ErrShortURLExist) {
+		t.Errorf("SaveURL() error = %v, want %v", err, myerrors.ErrShortURLExist)
+	}
+}
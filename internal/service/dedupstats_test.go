@@ -0,0 +1,73 @@
+package service
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/ar4ie13/shortener/internal/model"
+	"github.com/ar4ie13/shortener/internal/myerrors"
+	"github.com/ar4ie13/shortener/internal/repository/memory"
+	"github.com/ar4ie13/shortener/internal/service/internal/mockery"
+	"github.com/google/uuid"
+	"github.com/rs/zerolog"
+	"github.com/stretchr/testify/mock"
+)
+
+// TestService_DedupStats_CountsCreatedAndReused drives SaveURL and SaveBatch through both the
+// fresh-slug and reuse-existing-slug paths and confirms each is counted under the right bucket.
+func TestService_DedupStats_CountsCreatedAndReused(t *testing.T) {
+	repo := memory.NewMemStorage()
+	srv := NewService(repo, zerolog.Nop(), false, time.Second, "", 0, nil, 0, 0, 0, 0, nil, 0, false, 0, false, zerolog.Nop(), 0, "", 0, 0, nil, nil, 0)
+	defer srv.Close()
+
+	userUUID := uuid.New()
+
+	if _, err := srv.SaveURL(context.Background(), userUUID, "https://example.com/one", "", "", "", "", false, 0); err != nil {
+		t.Fatalf("SaveURL() unexpected error = %v", err)
+	}
+	if _, err := srv.SaveURL(context.Background(), userUUID, "https://example.com/one", "", "", "", "", false, 0); err == nil {
+		t.Fatalf("SaveURL() expected myerrors.ErrURLExist on second save, got none")
+	}
+
+	batch := []model.URL{
+		{UUID: uuid.New(), OriginalURL: "https://example.com/two"},
+		{UUID: uuid.New(), OriginalURL: "https://example.com/one"},
+	}
+	if _, err := srv.SaveBatch(context.Background(), userUUID, batch, "", "", true, false); err != nil {
+		t.Fatalf("SaveBatch() unexpected error = %v", err)
+	}
+
+	stats := srv.DedupStats()
+	if stats.Created != 2 {
+		t.Errorf("DedupStats().Created = %d, want 2", stats.Created)
+	}
+	if stats.Reused != 2 {
+		t.Errorf("DedupStats().Reused = %d, want 2", stats.Reused)
+	}
+}
+
+// TestService_DedupStats_CountsCollisionRetries simulates a generated slug colliding before a
+// second attempt succeeds, and confirms the retry is counted.
+func TestService_DedupStats_CountsCollisionRetries(t *testing.T) {
+	mockRepo := mockery.NewMockRepository(t)
+	mockRepo.On("Save", mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything).
+		Return("", myerrors.ErrShortURLExist).Once()
+	mockRepo.On("Save", mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything).
+		Return("", nil).Once()
+
+	srv := NewService(mockRepo, zerolog.Nop(), false, time.Second, "", 0, nil, 0, 0, 0, 0, nil, 0, false, 0, false, zerolog.Nop(), 0, "", 0, 0, nil, nil, 0)
+	defer srv.Close()
+
+	if _, err := srv.SaveURL(context.Background(), uuid.New(), "https://example.com/one", "", "", "", "", false, 0); err != nil {
+		t.Fatalf("SaveURL() unexpected error = %v", err)
+	}
+
+	stats := srv.DedupStats()
+	if stats.CollisionRetries != 1 {
+		t.Errorf("DedupStats().CollisionRetries = %d, want 1", stats.CollisionRetries)
+	}
+	if stats.Created != 1 {
+		t.Errorf("DedupStats().Created = %d, want 1", stats.Created)
+	}
+}
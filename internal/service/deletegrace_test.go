@@ -0,0 +1,68 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/ar4ie13/shortener/internal/clock"
+	"github.com/ar4ie13/shortener/internal/myerrors"
+	"github.com/ar4ie13/shortener/internal/repository/memory"
+	"github.com/google/uuid"
+	"github.com/rs/zerolog"
+)
+
+func TestService_GetURL_DeleteGracePeriod(t *testing.T) {
+	repo := memory.NewMemStorage()
+	fakeClock := clock.NewFakeClock(time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC))
+	srv := NewService(repo, zerolog.Nop(), false, time.Second, "", 0, fakeClock, 0, 0, 0, time.Minute, nil, 0, false, 0, false, zerolog.Nop(), 0, "", 0, 0, nil, nil, 0)
+	defer srv.Close()
+
+	userUUID := uuid.New()
+	slug, err := srv.SaveURL(context.Background(), userUUID, "https://example.com/one", "", "", "", "", false, 0)
+	if err != nil {
+		t.Fatalf("SaveURL() unexpected error = %v", err)
+	}
+
+	if err := repo.DeleteUserShortURLs(context.Background(), map[uuid.UUID][]string{userUUID: {slug}}, fakeClock.Now()); err != nil {
+		t.Fatalf("DeleteUserShortURLs() unexpected error = %v", err)
+	}
+
+	fakeClock.Advance(30 * time.Second)
+	gotURL, err := srv.GetURL(context.Background(), userUUID, slug)
+	if !errors.Is(err, myerrors.ErrShortURLGracePeriod) {
+		t.Fatalf("GetURL() within grace period error = %v, want %v", err, myerrors.ErrShortURLGracePeriod)
+	}
+	if gotURL != "https://example.com/one" {
+		t.Errorf("GetURL() within grace period url = %q, want the original URL", gotURL)
+	}
+
+	fakeClock.Advance(time.Minute)
+	_, err = srv.GetURL(context.Background(), userUUID, slug)
+	if !errors.Is(err, myerrors.ErrShortURLIsDeleted) {
+		t.Fatalf("GetURL() after grace period error = %v, want %v", err, myerrors.ErrShortURLIsDeleted)
+	}
+}
+
+func TestService_GetURL_NoDeleteGracePeriod(t *testing.T) {
+	repo := memory.NewMemStorage()
+	fakeClock := clock.NewFakeClock(time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC))
+	srv := NewService(repo, zerolog.Nop(), false, time.Second, "", 0, fakeClock, 0, 0, 0, 0, nil, 0, false, 0, false, zerolog.Nop(), 0, "", 0, 0, nil, nil, 0)
+	defer srv.Close()
+
+	userUUID := uuid.New()
+	slug, err := srv.SaveURL(context.Background(), userUUID, "https://example.com/one", "", "", "", "", false, 0)
+	if err != nil {
+		t.Fatalf("SaveURL() unexpected error = %v", err)
+	}
+
+	if err := repo.DeleteUserShortURLs(context.Background(), map[uuid.UUID][]string{userUUID: {slug}}, fakeClock.Now()); err != nil {
+		t.Fatalf("DeleteUserShortURLs() unexpected error = %v", err)
+	}
+
+	_, err = srv.GetURL(context.Background(), userUUID, slug)
+	if !errors.Is(err, myerrors.ErrShortURLIsDeleted) {
+		t.Fatalf("GetURL() with no grace period error = %v, want %v", err, myerrors.ErrShortURLIsDeleted)
+	}
+}
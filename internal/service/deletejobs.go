@@ -0,0 +1,103 @@
+package service
+
+import (
+	"sync"
+
+	"github.com/ar4ie13/shortener/internal/model"
+	"github.com/google/uuid"
+)
+
+// deleteJobTrackerCapacity bounds the memory used by the delete job tracker
+const deleteJobTrackerCapacity = 10000
+
+// deleteJobResult is the per-job bookkeeping for SendShortURLForDelete: enough to answer
+// whether a job finished, and to restrict that answer to the job's owner.
+type deleteJobResult struct {
+	userUUID uuid.UUID
+	status   model.DeleteJobStatus
+	err      string
+}
+
+// deleteJobTracker is a bounded map of delete job outcomes, indexed by job ID. The oldest
+// tracked job is evicted once capacity is reached, so a long-running deployment doesn't grow
+// the map without bound.
+type deleteJobTracker struct {
+	mu       sync.Mutex
+	results  map[uuid.UUID]*deleteJobResult
+	order    []uuid.UUID
+	capacity int
+}
+
+// newDeleteJobTracker constructs a deleteJobTracker bounded to capacity entries
+func newDeleteJobTracker(capacity int) *deleteJobTracker {
+	return &deleteJobTracker{
+		results:  make(map[uuid.UUID]*deleteJobResult),
+		capacity: capacity,
+	}
+}
+
+// start records a new pending job owned by userUUID and returns its ID
+func (t *deleteJobTracker) start(userUUID uuid.UUID) uuid.UUID {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	jobID := uuid.New()
+	if len(t.order) >= t.capacity {
+		oldest := t.order[0]
+		t.order = t.order[1:]
+		delete(t.results, oldest)
+	}
+	t.results[jobID] = &deleteJobResult{userUUID: userUUID, status: model.DeleteJobPending}
+	t.order = append(t.order, jobID)
+	return jobID
+}
+
+// finish records the outcome of jobIDs as model.DeleteJobCompleted (err is nil) or
+// model.DeleteJobFailed. A jobID no longer tracked (e.g. evicted) is silently ignored.
+func (t *deleteJobTracker) finish(jobIDs []uuid.UUID, err error) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	for _, jobID := range jobIDs {
+		result, ok := t.results[jobID]
+		if !ok {
+			continue
+		}
+		if err != nil {
+			result.status = model.DeleteJobFailed
+			result.err = err.Error()
+		} else {
+			result.status = model.DeleteJobCompleted
+		}
+	}
+}
+
+// cancel removes jobID from the tracker, e.g. because its batch could not be enqueued for
+// deletion. A jobID no longer tracked (e.g. already evicted) is silently ignored.
+func (t *deleteJobTracker) cancel(jobID uuid.UUID) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	if _, ok := t.results[jobID]; !ok {
+		return
+	}
+	delete(t.results, jobID)
+	for i, id := range t.order {
+		if id == jobID {
+			t.order = append(t.order[:i], t.order[i+1:]...)
+			break
+		}
+	}
+}
+
+// lookup returns the tracked result for jobID, and whether it was found
+func (t *deleteJobTracker) lookup(jobID uuid.UUID) (deleteJobResult, bool) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	result, ok := t.results[jobID]
+	if !ok {
+		return deleteJobResult{}, false
+	}
+	return *result, true
+}
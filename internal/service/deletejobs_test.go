@@ -0,0 +1,91 @@
+package service
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/ar4ie13/shortener/internal/model"
+	"github.com/ar4ie13/shortener/internal/myerrors"
+	"github.com/google/uuid"
+	"github.com/rs/zerolog"
+)
+
+// TestService_DeleteJobStatus_TracksLifecycle confirms a job starts pending, and reflects the
+// repository's outcome once the delete worker has flushed it.
+func TestService_DeleteJobStatus_TracksLifecycle(t *testing.T) {
+	repo := &deleteTrackingRepository{}
+	srv := NewService(repo, zerolog.Nop(), false, time.Second, "", 0, nil, 0, 0, 0, 0, nil, 0, false, 0, false, zerolog.Nop(), 0, "", 0, 0, nil, nil, 0)
+	defer srv.Close()
+
+	userUUID := uuid.New()
+	jobID, err := srv.SendShortURLForDelete(context.Background(), userUUID, []string{"abc123"})
+	if err != nil {
+		t.Fatalf("SendShortURLForDelete() unexpected error = %v", err)
+	}
+
+	job, err := srv.DeleteJobStatus(context.Background(), userUUID, jobID)
+	if err != nil {
+		t.Fatalf("DeleteJobStatus() unexpected error = %v", err)
+	}
+	if job.Status != model.DeleteJobPending {
+		t.Errorf("Status = %v, want %v", job.Status, model.DeleteJobPending)
+	}
+
+	time.Sleep(timeToCollectDeleted * 2)
+
+	job, err = srv.DeleteJobStatus(context.Background(), userUUID, jobID)
+	if err != nil {
+		t.Fatalf("DeleteJobStatus() unexpected error after flush = %v", err)
+	}
+	if job.Status != model.DeleteJobCompleted {
+		t.Errorf("Status = %v, want %v", job.Status, model.DeleteJobCompleted)
+	}
+}
+
+// TestService_DeleteJobStatus_ReportsFailure confirms a repository error during the flush is
+// surfaced back through DeleteJobStatus instead of only being logged.
+func TestService_DeleteJobStatus_ReportsFailure(t *testing.T) {
+	repo := &deleteTrackingRepository{err: myerrors.ErrRepositoryNotReady}
+	srv := NewService(repo, zerolog.Nop(), false, time.Second, "", 0, nil, 0, 0, 0, 0, nil, 0, false, 0, false, zerolog.Nop(), 0, "", 0, 0, nil, nil, 0)
+	defer srv.Close()
+
+	userUUID := uuid.New()
+	jobID, err := srv.SendShortURLForDelete(context.Background(), userUUID, []string{"abc123"})
+	if err != nil {
+		t.Fatalf("SendShortURLForDelete() unexpected error = %v", err)
+	}
+
+	time.Sleep(timeToCollectDeleted * 2)
+
+	job, err := srv.DeleteJobStatus(context.Background(), userUUID, jobID)
+	if err != nil {
+		t.Fatalf("DeleteJobStatus() unexpected error = %v", err)
+	}
+	if job.Status != model.DeleteJobFailed {
+		t.Errorf("Status = %v, want %v", job.Status, model.DeleteJobFailed)
+	}
+	if job.Error == "" {
+		t.Error("Error = \"\", want the repository's failure reason")
+	}
+}
+
+// TestService_DeleteJobStatus_UnknownOrWrongOwner confirms an unrecognized job ID, and a job
+// ID that belongs to a different user, are both reported the same way.
+func TestService_DeleteJobStatus_UnknownOrWrongOwner(t *testing.T) {
+	repo := &deleteTrackingRepository{}
+	srv := NewService(repo, zerolog.Nop(), false, time.Second, "", 0, nil, 0, 0, 0, 0, nil, 0, false, 0, false, zerolog.Nop(), 0, "", 0, 0, nil, nil, 0)
+	defer srv.Close()
+
+	if _, err := srv.DeleteJobStatus(context.Background(), uuid.New(), uuid.New()); err != myerrors.ErrNotFound {
+		t.Fatalf("DeleteJobStatus() error = %v, want %v", err, myerrors.ErrNotFound)
+	}
+
+	jobID, err := srv.SendShortURLForDelete(context.Background(), uuid.New(), []string{"abc123"})
+	if err != nil {
+		t.Fatalf("SendShortURLForDelete() unexpected error = %v", err)
+	}
+	if _, err := srv.DeleteJobStatus(context.Background(), uuid.New(), jobID); err != myerrors.ErrNotFound {
+		t.Fatalf("DeleteJobStatus() error = %v, want %v", err, myerrors.ErrNotFound)
+	}
+}
@@ -0,0 +1,83 @@
+package service
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/ar4ie13/shortener/internal/myerrors"
+	"github.com/google/uuid"
+	"github.com/rs/zerolog"
+)
+
+func TestService_SendShortURLForDelete_RejectsWhenBacklogFull(t *testing.T) {
+	repo := &deleteTrackingRepository{}
+	srv := NewService(repo, zerolog.Nop(), false, time.Second, "", 0, nil, 0, 0, 0, 0, nil, 2, false, 0, false, zerolog.Nop(), 0, "", 0, 0, nil, nil, 0)
+	defer srv.Close()
+
+	userUUID := uuid.New()
+
+	if _, err := srv.SendShortURLForDelete(context.Background(), userUUID, []string{"abc123", "def456"}); err != nil {
+		t.Fatalf("SendShortURLForDelete() unexpected error = %v", err)
+	}
+
+	if _, err := srv.SendShortURLForDelete(context.Background(), userUUID, []string{"ghi789"}); err != myerrors.ErrDeleteQueueFull {
+		t.Fatalf("SendShortURLForDelete() error = %v, want %v", err, myerrors.ErrDeleteQueueFull)
+	}
+}
+
+func TestService_SendShortURLForDelete_AcceptsAgainAfterFlush(t *testing.T) {
+	repo := &deleteTrackingRepository{}
+	srv := NewService(repo, zerolog.Nop(), false, time.Second, "", 0, nil, 0, 0, 0, 0, nil, 2, false, 0, false, zerolog.Nop(), 0, "", 0, 0, nil, nil, 0)
+	defer srv.Close()
+
+	userUUID := uuid.New()
+
+	if _, err := srv.SendShortURLForDelete(context.Background(), userUUID, []string{"abc123", "def456"}); err != nil {
+		t.Fatalf("SendShortURLForDelete() unexpected error = %v", err)
+	}
+	if _, err := srv.SendShortURLForDelete(context.Background(), userUUID, []string{"ghi789"}); err != myerrors.ErrDeleteQueueFull {
+		t.Fatalf("SendShortURLForDelete() error = %v, want %v", err, myerrors.ErrDeleteQueueFull)
+	}
+
+	time.Sleep(timeToCollectDeleted * 2)
+
+	if _, err := srv.SendShortURLForDelete(context.Background(), userUUID, []string{"ghi789"}); err != nil {
+		t.Fatalf("SendShortURLForDelete() unexpected error after flush = %v", err)
+	}
+}
+
+func TestService_PendingDeletes_ReflectsQueuedAndFlushedWork(t *testing.T) {
+	repo := &deleteTrackingRepository{}
+	srv := NewService(repo, zerolog.Nop(), false, time.Second, "", 0, nil, 0, 0, 0, 0, nil, 0, false, 0, false, zerolog.Nop(), 0, "", 0, 0, nil, nil, 0)
+	defer srv.Close()
+
+	userUUID := uuid.New()
+
+	if _, err := srv.SendShortURLForDelete(context.Background(), userUUID, []string{"abc123", "def456"}); err != nil {
+		t.Fatalf("SendShortURLForDelete() unexpected error = %v", err)
+	}
+	if got := srv.PendingDeletes(); got != 2 {
+		t.Errorf("PendingDeletes() = %d, want 2", got)
+	}
+
+	time.Sleep(timeToCollectDeleted * 2)
+
+	if got := srv.PendingDeletes(); got != 0 {
+		t.Errorf("PendingDeletes() after flush = %d, want 0", got)
+	}
+}
+
+func TestService_SendShortURLForDelete_UnlimitedByDefault(t *testing.T) {
+	repo := &deleteTrackingRepository{}
+	srv := NewService(repo, zerolog.Nop(), false, time.Second, "", 0, nil, 0, 0, 0, 0, nil, 0, false, 0, false, zerolog.Nop(), 0, "", 0, 0, nil, nil, 0)
+	defer srv.Close()
+
+	userUUID := uuid.New()
+
+	for i := 0; i < 1000; i++ {
+		if _, err := srv.SendShortURLForDelete(context.Background(), userUUID, []string{"abc123"}); err != nil {
+			t.Fatalf("SendShortURLForDelete() unexpected error = %v", err)
+		}
+	}
+}
@@ -0,0 +1,81 @@
+package service
+
+import (
+	"sync"
+
+	"github.com/ar4ie13/shortener/internal/model"
+	"github.com/google/uuid"
+)
+
+// defaultEventBufferSize is the per-subscriber channel capacity used when NewService's
+// eventBufferSize argument is non-positive
+const defaultEventBufferSize = 16
+
+// eventBus fans model.Events out to per-user subscriber channels. A subscriber that falls
+// behind has the event dropped rather than blocking the publisher, so one slow GET /api/events
+// consumer can never stall URL creation, deletion, or redirects for anyone else.
+type eventBus struct {
+	mu          sync.Mutex
+	subscribers map[uuid.UUID]map[chan model.Event]struct{}
+	bufferSize  int
+}
+
+func newEventBus(bufferSize int) *eventBus {
+	if bufferSize <= 0 {
+		bufferSize = defaultEventBufferSize
+	}
+	return &eventBus{
+		subscribers: make(map[uuid.UUID]map[chan model.Event]struct{}),
+		bufferSize:  bufferSize,
+	}
+}
+
+// subscribe registers a new subscriber channel for userUUID. The caller must invoke the
+// returned unsubscribe function exactly once, when it stops listening, to release the
+// channel and allow the bus to stop tracking userUUID once its last subscriber leaves. A nil
+// eventBus, as in a Service built without going through NewService, yields a channel that
+// never receives anything and a no-op unsubscribe.
+func (b *eventBus) subscribe(userUUID uuid.UUID) (<-chan model.Event, func()) {
+	if b == nil {
+		return make(chan model.Event), func() {}
+	}
+
+	ch := make(chan model.Event, b.bufferSize)
+
+	b.mu.Lock()
+	if b.subscribers[userUUID] == nil {
+		b.subscribers[userUUID] = make(map[chan model.Event]struct{})
+	}
+	b.subscribers[userUUID][ch] = struct{}{}
+	b.mu.Unlock()
+
+	unsubscribe := func() {
+		b.mu.Lock()
+		delete(b.subscribers[userUUID], ch)
+		if len(b.subscribers[userUUID]) == 0 {
+			delete(b.subscribers, userUUID)
+		}
+		b.mu.Unlock()
+	}
+
+	return ch, unsubscribe
+}
+
+// publish delivers event to every current subscriber of event.UserUUID. A subscriber whose
+// buffer is full has this event dropped for it instead of blocking the publisher. A nil
+// eventBus has no subscribers to deliver to.
+func (b *eventBus) publish(event model.Event) {
+	if b == nil {
+		return
+	}
+
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	for ch := range b.subscribers[event.UserUUID] {
+		select {
+		case ch <- event:
+		default:
+		}
+	}
+}
@@ -0,0 +1,95 @@
+package service
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/ar4ie13/shortener/internal/repository/memory"
+	"github.com/google/uuid"
+	"github.com/rs/zerolog"
+)
+
+func TestService_SaveURL_UpgradesHTTPOnAllowlistedHost(t *testing.T) {
+	repo := memory.NewMemStorage()
+	srv := NewService(repo, zerolog.Nop(), false, time.Second, "", 0, nil, 0, 0, 0, 0, []string{"example.com"}, 0, false, 0, false, zerolog.Nop(), 0, "", 0, 0, nil, nil, 0)
+	defer srv.Close()
+
+	userUUID := uuid.New()
+
+	slug, err := srv.SaveURL(context.Background(), userUUID, "http://example.com/one", "", "", "", "", false, 0)
+	if err != nil {
+		t.Fatalf("SaveURL() unexpected error = %v", err)
+	}
+
+	gotURL, err := repo.GetURL(context.Background(), userUUID, slug, false)
+	if err != nil {
+		t.Fatalf("repo.GetURL() unexpected error = %v", err)
+	}
+	if gotURL != "https://example.com/one" {
+		t.Errorf("repo.GetURL() = %q, want %q", gotURL, "https://example.com/one")
+	}
+}
+
+func TestService_SaveURL_DoesNotUpgradeNonAllowlistedHost(t *testing.T) {
+	repo := memory.NewMemStorage()
+	srv := NewService(repo, zerolog.Nop(), false, time.Second, "", 0, nil, 0, 0, 0, 0, []string{"example.com"}, 0, false, 0, false, zerolog.Nop(), 0, "", 0, 0, nil, nil, 0)
+	defer srv.Close()
+
+	userUUID := uuid.New()
+
+	slug, err := srv.SaveURL(context.Background(), userUUID, "http://other.example/one", "", "", "", "", false, 0)
+	if err != nil {
+		t.Fatalf("SaveURL() unexpected error = %v", err)
+	}
+
+	gotURL, err := repo.GetURL(context.Background(), userUUID, slug, false)
+	if err != nil {
+		t.Fatalf("repo.GetURL() unexpected error = %v", err)
+	}
+	if gotURL != "http://other.example/one" {
+		t.Errorf("repo.GetURL() = %q, want %q", gotURL, "http://other.example/one")
+	}
+}
+
+func TestService_SaveURL_UpgradeDisabledByDefault(t *testing.T) {
+	repo := memory.NewMemStorage()
+	srv := NewService(repo, zerolog.Nop(), false, time.Second, "", 0, nil, 0, 0, 0, 0, nil, 0, false, 0, false, zerolog.Nop(), 0, "", 0, 0, nil, nil, 0)
+	defer srv.Close()
+
+	userUUID := uuid.New()
+
+	slug, err := srv.SaveURL(context.Background(), userUUID, "http://example.com/one", "", "", "", "", false, 0)
+	if err != nil {
+		t.Fatalf("SaveURL() unexpected error = %v", err)
+	}
+
+	gotURL, err := repo.GetURL(context.Background(), userUUID, slug, false)
+	if err != nil {
+		t.Fatalf("repo.GetURL() unexpected error = %v", err)
+	}
+	if gotURL != "http://example.com/one" {
+		t.Errorf("repo.GetURL() = %q, want %q", gotURL, "http://example.com/one")
+	}
+}
+
+func TestService_SaveURL_UpgradeDedupsAgainstExistingHTTPSLink(t *testing.T) {
+	repo := memory.NewMemStorage()
+	srv := NewService(repo, zerolog.Nop(), false, time.Second, "", 0, nil, 0, 0, 0, 0, []string{"example.com"}, 0, false, 0, false, zerolog.Nop(), 0, "", 0, 0, nil, nil, 0)
+	defer srv.Close()
+
+	userUUID := uuid.New()
+
+	slug, err := srv.SaveURL(context.Background(), userUUID, "https://example.com/one", "", "", "", "", false, 0)
+	if err != nil {
+		t.Fatalf("SaveURL() unexpected error = %v", err)
+	}
+
+	gotSlug, err := srv.SaveURL(context.Background(), userUUID, "http://example.com/one", "", "", "", "", false, 0)
+	if gotSlug != slug {
+		t.Errorf("SaveURL() slug = %q, want %q (same slug as the existing https link)", gotSlug, slug)
+	}
+	if err == nil {
+		t.Fatal("SaveURL() expected myerrors.ErrURLExist, got nil")
+	}
+}
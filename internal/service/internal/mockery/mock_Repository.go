@@ -8,6 +8,8 @@ import (
 	model "github.com/ar4ie13/shortener/internal/model"
 	mock "github.com/stretchr/testify/mock"
 
+	time "time"
+
 	uuid "github.com/google/uuid"
 )
 
@@ -24,17 +26,119 @@ func (_m *MockRepository) EXPECT() *MockRepository_Expecter {
 	return &MockRepository_Expecter{mock: &_m.Mock}
 }
 
-// DeleteUserShortURLs provides a mock function with given fields: ctx, shortURLsToDelete
-func (_m *MockRepository) DeleteUserShortURLs(ctx context.Context, shortURLsToDelete map[uuid.UUID][]string) error {
-	ret := _m.Called(ctx, shortURLsToDelete)
+// BackfillHosts provides a mock function with given fields: ctx, limit
+func (_m *MockRepository) BackfillHosts(ctx context.Context, limit int) (int, error) {
+	ret := _m.Called(ctx, limit)
+
+	if len(ret) == 0 {
+		panic("no return value specified for BackfillHosts")
+	}
+
+	var r0 int
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, int) (int, error)); ok {
+		return rf(ctx, limit)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, int) int); ok {
+		r0 = rf(ctx, limit)
+	} else {
+		r0 = ret.Get(0).(int)
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, int) error); ok {
+		r1 = rf(ctx, limit)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// MockRepository_BackfillHosts_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'BackfillHosts'
+type MockRepository_BackfillHosts_Call struct {
+	*mock.Call
+}
+
+// BackfillHosts is a helper method to define mock.On call
+//   - ctx context.Context
+//   - limit int
+func (_e *MockRepository_Expecter) BackfillHosts(ctx interface{}, limit interface{}) *MockRepository_BackfillHosts_Call {
+	return &MockRepository_BackfillHosts_Call{Call: _e.mock.On("BackfillHosts", ctx, limit)}
+}
+
+func (_c *MockRepository_BackfillHosts_Call) Run(run func(ctx context.Context, limit int)) *MockRepository_BackfillHosts_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(context.Context), args[1].(int))
+	})
+	return _c
+}
+
+func (_c *MockRepository_BackfillHosts_Call) Return(_a0 int, _a1 error) *MockRepository_BackfillHosts_Call {
+	_c.Call.Return(_a0, _a1)
+	return _c
+}
+
+func (_c *MockRepository_BackfillHosts_Call) RunAndReturn(run func(context.Context, int) (int, error)) *MockRepository_BackfillHosts_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// Close provides a mock function with no fields
+func (_m *MockRepository) Close() error {
+	ret := _m.Called()
+
+	if len(ret) == 0 {
+		panic("no return value specified for Close")
+	}
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func() error); ok {
+		r0 = rf()
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// MockRepository_Close_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'Close'
+type MockRepository_Close_Call struct {
+	*mock.Call
+}
+
+// Close is a helper method to define mock.On call
+func (_e *MockRepository_Expecter) Close() *MockRepository_Close_Call {
+	return &MockRepository_Close_Call{Call: _e.mock.On("Close")}
+}
+
+func (_c *MockRepository_Close_Call) Run(run func()) *MockRepository_Close_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run()
+	})
+	return _c
+}
+
+func (_c *MockRepository_Close_Call) Return(_a0 error) *MockRepository_Close_Call {
+	_c.Call.Return(_a0)
+	return _c
+}
+
+func (_c *MockRepository_Close_Call) RunAndReturn(run func() error) *MockRepository_Close_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// DeleteUserShortURLs provides a mock function with given fields: ctx, shortURLsToDelete, deletedAt
+func (_m *MockRepository) DeleteUserShortURLs(ctx context.Context, shortURLsToDelete map[uuid.UUID][]string, deletedAt time.Time) error {
+	ret := _m.Called(ctx, shortURLsToDelete, deletedAt)
 
 	if len(ret) == 0 {
 		panic("no return value specified for DeleteUserShortURLs")
 	}
 
 	var r0 error
-	if rf, ok := ret.Get(0).(func(context.Context, map[uuid.UUID][]string) error); ok {
-		r0 = rf(ctx, shortURLsToDelete)
+	if rf, ok := ret.Get(0).(func(context.Context, map[uuid.UUID][]string, time.Time) error); ok {
+		r0 = rf(ctx, shortURLsToDelete, deletedAt)
 	} else {
 		r0 = ret.Error(0)
 	}
@@ -50,13 +154,14 @@ type MockRepository_DeleteUserShortURLs_Call struct {
 // DeleteUserShortURLs is a helper method to define mock.On call
 //   - ctx context.Context
 //   - shortURLsToDelete map[uuid.UUID][]string
-func (_e *MockRepository_Expecter) DeleteUserShortURLs(ctx interface{}, shortURLsToDelete interface{}) *MockRepository_DeleteUserShortURLs_Call {
-	return &MockRepository_DeleteUserShortURLs_Call{Call: _e.mock.On("DeleteUserShortURLs", ctx, shortURLsToDelete)}
+//   - deletedAt time.Time
+func (_e *MockRepository_Expecter) DeleteUserShortURLs(ctx interface{}, shortURLsToDelete interface{}, deletedAt interface{}) *MockRepository_DeleteUserShortURLs_Call {
+	return &MockRepository_DeleteUserShortURLs_Call{Call: _e.mock.On("DeleteUserShortURLs", ctx, shortURLsToDelete, deletedAt)}
 }
 
-func (_c *MockRepository_DeleteUserShortURLs_Call) Run(run func(ctx context.Context, shortURLsToDelete map[uuid.UUID][]string)) *MockRepository_DeleteUserShortURLs_Call {
+func (_c *MockRepository_DeleteUserShortURLs_Call) Run(run func(ctx context.Context, shortURLsToDelete map[uuid.UUID][]string, deletedAt time.Time)) *MockRepository_DeleteUserShortURLs_Call {
 	_c.Call.Run(func(args mock.Arguments) {
-		run(args[0].(context.Context), args[1].(map[uuid.UUID][]string))
+		run(args[0].(context.Context), args[1].(map[uuid.UUID][]string), args[2].(time.Time))
 	})
 	return _c
 }
@@ -66,7 +171,121 @@ func (_c *MockRepository_DeleteUserShortURLs_Call) Return(_a0 error) *MockReposi
 	return _c
 }
 
-func (_c *MockRepository_DeleteUserShortURLs_Call) RunAndReturn(run func(context.Context, map[uuid.UUID][]string) error) *MockRepository_DeleteUserShortURLs_Call {
+func (_c *MockRepository_DeleteUserShortURLs_Call) RunAndReturn(run func(context.Context, map[uuid.UUID][]string, time.Time) error) *MockRepository_DeleteUserShortURLs_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// GetPasswordHash provides a mock function with given fields: ctx, shortURL
+func (_m *MockRepository) GetPasswordHash(ctx context.Context, shortURL string) (string, error) {
+	ret := _m.Called(ctx, shortURL)
+
+	if len(ret) == 0 {
+		panic("no return value specified for GetPasswordHash")
+	}
+
+	var r0 string
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, string) (string, error)); ok {
+		return rf(ctx, shortURL)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, string) string); ok {
+		r0 = rf(ctx, shortURL)
+	} else {
+		r0 = ret.Get(0).(string)
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, string) error); ok {
+		r1 = rf(ctx, shortURL)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// MockRepository_GetPasswordHash_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'GetPasswordHash'
+type MockRepository_GetPasswordHash_Call struct {
+	*mock.Call
+}
+
+// GetPasswordHash is a helper method to define mock.On call
+//   - ctx context.Context
+//   - shortURL string
+func (_e *MockRepository_Expecter) GetPasswordHash(ctx interface{}, shortURL interface{}) *MockRepository_GetPasswordHash_Call {
+	return &MockRepository_GetPasswordHash_Call{Call: _e.mock.On("GetPasswordHash", ctx, shortURL)}
+}
+
+func (_c *MockRepository_GetPasswordHash_Call) Run(run func(ctx context.Context, shortURL string)) *MockRepository_GetPasswordHash_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(context.Context), args[1].(string))
+	})
+	return _c
+}
+
+func (_c *MockRepository_GetPasswordHash_Call) Return(_a0 string, _a1 error) *MockRepository_GetPasswordHash_Call {
+	_c.Call.Return(_a0, _a1)
+	return _c
+}
+
+func (_c *MockRepository_GetPasswordHash_Call) RunAndReturn(run func(context.Context, string) (string, error)) *MockRepository_GetPasswordHash_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// GetRedirectStatus provides a mock function with given fields: ctx, shortURL
+func (_m *MockRepository) GetRedirectStatus(ctx context.Context, shortURL string) (int, error) {
+	ret := _m.Called(ctx, shortURL)
+
+	if len(ret) == 0 {
+		panic("no return value specified for GetRedirectStatus")
+	}
+
+	var r0 int
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, string) (int, error)); ok {
+		return rf(ctx, shortURL)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, string) int); ok {
+		r0 = rf(ctx, shortURL)
+	} else {
+		r0 = ret.Get(0).(int)
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, string) error); ok {
+		r1 = rf(ctx, shortURL)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// MockRepository_GetRedirectStatus_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'GetRedirectStatus'
+type MockRepository_GetRedirectStatus_Call struct {
+	*mock.Call
+}
+
+// GetRedirectStatus is a helper method to define mock.On call
+//   - ctx context.Context
+//   - shortURL string
+func (_e *MockRepository_Expecter) GetRedirectStatus(ctx interface{}, shortURL interface{}) *MockRepository_GetRedirectStatus_Call {
+	return &MockRepository_GetRedirectStatus_Call{Call: _e.mock.On("GetRedirectStatus", ctx, shortURL)}
+}
+
+func (_c *MockRepository_GetRedirectStatus_Call) Run(run func(ctx context.Context, shortURL string)) *MockRepository_GetRedirectStatus_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(context.Context), args[1].(string))
+	})
+	return _c
+}
+
+func (_c *MockRepository_GetRedirectStatus_Call) Return(_a0 int, _a1 error) *MockRepository_GetRedirectStatus_Call {
+	_c.Call.Return(_a0, _a1)
+	return _c
+}
+
+func (_c *MockRepository_GetRedirectStatus_Call) RunAndReturn(run func(context.Context, string) (int, error)) *MockRepository_GetRedirectStatus_Call {
 	_c.Call.Return(run)
 	return _c
 }
@@ -128,9 +347,9 @@ func (_c *MockRepository_GetShortURL_Call) RunAndReturn(run func(context.Context
 	return _c
 }
 
-// GetURL provides a mock function with given fields: ctx, shortURL
-func (_m *MockRepository) GetURL(ctx context.Context, shortURL string) (string, error) {
-	ret := _m.Called(ctx, shortURL)
+// GetURL provides a mock function with given fields: ctx, userUUID, shortURL, requireOwnership
+func (_m *MockRepository) GetURL(ctx context.Context, userUUID uuid.UUID, shortURL string, requireOwnership bool) (string, error) {
+	ret := _m.Called(ctx, userUUID, shortURL, requireOwnership)
 
 	if len(ret) == 0 {
 		panic("no return value specified for GetURL")
@@ -138,17 +357,17 @@ func (_m *MockRepository) GetURL(ctx context.Context, shortURL string) (string,
 
 	var r0 string
 	var r1 error
-	if rf, ok := ret.Get(0).(func(context.Context, string) (string, error)); ok {
-		return rf(ctx, shortURL)
+	if rf, ok := ret.Get(0).(func(context.Context, uuid.UUID, string, bool) (string, error)); ok {
+		return rf(ctx, userUUID, shortURL, requireOwnership)
 	}
-	if rf, ok := ret.Get(0).(func(context.Context, string) string); ok {
-		r0 = rf(ctx, shortURL)
+	if rf, ok := ret.Get(0).(func(context.Context, uuid.UUID, string, bool) string); ok {
+		r0 = rf(ctx, userUUID, shortURL, requireOwnership)
 	} else {
 		r0 = ret.Get(0).(string)
 	}
 
-	if rf, ok := ret.Get(1).(func(context.Context, string) error); ok {
-		r1 = rf(ctx, shortURL)
+	if rf, ok := ret.Get(1).(func(context.Context, uuid.UUID, string, bool) error); ok {
+		r1 = rf(ctx, userUUID, shortURL, requireOwnership)
 	} else {
 		r1 = ret.Error(1)
 	}
@@ -163,14 +382,16 @@ type MockRepository_GetURL_Call struct {
 
 // GetURL is a helper method to define mock.On call
 //   - ctx context.Context
+//   - userUUID uuid.UUID
 //   - shortURL string
-func (_e *MockRepository_Expecter) GetURL(ctx interface{}, shortURL interface{}) *MockRepository_GetURL_Call {
-	return &MockRepository_GetURL_Call{Call: _e.mock.On("GetURL", ctx, shortURL)}
+//   - requireOwnership bool
+func (_e *MockRepository_Expecter) GetURL(ctx interface{}, userUUID interface{}, shortURL interface{}, requireOwnership interface{}) *MockRepository_GetURL_Call {
+	return &MockRepository_GetURL_Call{Call: _e.mock.On("GetURL", ctx, userUUID, shortURL, requireOwnership)}
 }
 
-func (_c *MockRepository_GetURL_Call) Run(run func(ctx context.Context, shortURL string)) *MockRepository_GetURL_Call {
+func (_c *MockRepository_GetURL_Call) Run(run func(ctx context.Context, userUUID uuid.UUID, shortURL string, requireOwnership bool)) *MockRepository_GetURL_Call {
 	_c.Call.Run(func(args mock.Arguments) {
-		run(args[0].(context.Context), args[1].(string))
+		run(args[0].(context.Context), args[1].(uuid.UUID), args[2].(string), args[3].(bool))
 	})
 	return _c
 }
@@ -180,29 +401,90 @@ func (_c *MockRepository_GetURL_Call) Return(_a0 string, _a1 error) *MockReposit
 	return _c
 }
 
-func (_c *MockRepository_GetURL_Call) RunAndReturn(run func(context.Context, string) (string, error)) *MockRepository_GetURL_Call {
+func (_c *MockRepository_GetURL_Call) RunAndReturn(run func(context.Context, uuid.UUID, string, bool) (string, error)) *MockRepository_GetURL_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// GetURLs provides a mock function with given fields: ctx, userUUID, shortURLs, requireOwnership
+func (_m *MockRepository) GetURLs(ctx context.Context, userUUID uuid.UUID, shortURLs []string, requireOwnership bool) ([]model.URLExpansion, error) {
+	ret := _m.Called(ctx, userUUID, shortURLs, requireOwnership)
+
+	if len(ret) == 0 {
+		panic("no return value specified for GetURLs")
+	}
+
+	var r0 []model.URLExpansion
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, uuid.UUID, []string, bool) ([]model.URLExpansion, error)); ok {
+		return rf(ctx, userUUID, shortURLs, requireOwnership)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, uuid.UUID, []string, bool) []model.URLExpansion); ok {
+		r0 = rf(ctx, userUUID, shortURLs, requireOwnership)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]model.URLExpansion)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, uuid.UUID, []string, bool) error); ok {
+		r1 = rf(ctx, userUUID, shortURLs, requireOwnership)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// MockRepository_GetURLs_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'GetURLs'
+type MockRepository_GetURLs_Call struct {
+	*mock.Call
+}
+
+// GetURLs is a helper method to define mock.On call
+//   - ctx context.Context
+//   - userUUID uuid.UUID
+//   - shortURLs []string
+//   - requireOwnership bool
+func (_e *MockRepository_Expecter) GetURLs(ctx interface{}, userUUID interface{}, shortURLs interface{}, requireOwnership interface{}) *MockRepository_GetURLs_Call {
+	return &MockRepository_GetURLs_Call{Call: _e.mock.On("GetURLs", ctx, userUUID, shortURLs, requireOwnership)}
+}
+
+func (_c *MockRepository_GetURLs_Call) Run(run func(ctx context.Context, userUUID uuid.UUID, shortURLs []string, requireOwnership bool)) *MockRepository_GetURLs_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(context.Context), args[1].(uuid.UUID), args[2].([]string), args[3].(bool))
+	})
+	return _c
+}
+
+func (_c *MockRepository_GetURLs_Call) Return(_a0 []model.URLExpansion, _a1 error) *MockRepository_GetURLs_Call {
+	_c.Call.Return(_a0, _a1)
+	return _c
+}
+
+func (_c *MockRepository_GetURLs_Call) RunAndReturn(run func(context.Context, uuid.UUID, []string, bool) ([]model.URLExpansion, error)) *MockRepository_GetURLs_Call {
 	_c.Call.Return(run)
 	return _c
 }
 
 // GetUserShortURLs provides a mock function with given fields: ctx, userUUID
-func (_m *MockRepository) GetUserShortURLs(ctx context.Context, userUUID uuid.UUID) (map[string]string, error) {
+func (_m *MockRepository) GetUserShortURLs(ctx context.Context, userUUID uuid.UUID) ([]model.URL, error) {
 	ret := _m.Called(ctx, userUUID)
 
 	if len(ret) == 0 {
 		panic("no return value specified for GetUserShortURLs")
 	}
 
-	var r0 map[string]string
+	var r0 []model.URL
 	var r1 error
-	if rf, ok := ret.Get(0).(func(context.Context, uuid.UUID) (map[string]string, error)); ok {
+	if rf, ok := ret.Get(0).(func(context.Context, uuid.UUID) ([]model.URL, error)); ok {
 		return rf(ctx, userUUID)
 	}
-	if rf, ok := ret.Get(0).(func(context.Context, uuid.UUID) map[string]string); ok {
+	if rf, ok := ret.Get(0).(func(context.Context, uuid.UUID) []model.URL); ok {
 		r0 = rf(ctx, userUUID)
 	} else {
 		if ret.Get(0) != nil {
-			r0 = ret.Get(0).(map[string]string)
+			r0 = ret.Get(0).([]model.URL)
 		}
 	}
 
@@ -234,76 +516,83 @@ func (_c *MockRepository_GetUserShortURLs_Call) Run(run func(ctx context.Context
 	return _c
 }
 
-func (_c *MockRepository_GetUserShortURLs_Call) Return(_a0 map[string]string, _a1 error) *MockRepository_GetUserShortURLs_Call {
+func (_c *MockRepository_GetUserShortURLs_Call) Return(_a0 []model.URL, _a1 error) *MockRepository_GetUserShortURLs_Call {
 	_c.Call.Return(_a0, _a1)
 	return _c
 }
 
-func (_c *MockRepository_GetUserShortURLs_Call) RunAndReturn(run func(context.Context, uuid.UUID) (map[string]string, error)) *MockRepository_GetUserShortURLs_Call {
+func (_c *MockRepository_GetUserShortURLs_Call) RunAndReturn(run func(context.Context, uuid.UUID) ([]model.URL, error)) *MockRepository_GetUserShortURLs_Call {
 	_c.Call.Return(run)
 	return _c
 }
 
-// Save provides a mock function with given fields: ctx, userUUID, shortURL, url
-func (_m *MockRepository) Save(ctx context.Context, userUUID uuid.UUID, shortURL string, url string) error {
-	ret := _m.Called(ctx, userUUID, shortURL, url)
+// NextSequence provides a mock function with given fields: ctx
+func (_m *MockRepository) NextSequence(ctx context.Context) (int64, error) {
+	ret := _m.Called(ctx)
 
 	if len(ret) == 0 {
-		panic("no return value specified for Save")
+		panic("no return value specified for NextSequence")
 	}
 
-	var r0 error
-	if rf, ok := ret.Get(0).(func(context.Context, uuid.UUID, string, string) error); ok {
-		r0 = rf(ctx, userUUID, shortURL, url)
+	var r0 int64
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context) (int64, error)); ok {
+		return rf(ctx)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context) int64); ok {
+		r0 = rf(ctx)
 	} else {
-		r0 = ret.Error(0)
+		r0 = ret.Get(0).(int64)
 	}
 
-	return r0
+	if rf, ok := ret.Get(1).(func(context.Context) error); ok {
+		r1 = rf(ctx)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
 }
 
-// MockRepository_Save_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'Save'
-type MockRepository_Save_Call struct {
+// MockRepository_NextSequence_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'NextSequence'
+type MockRepository_NextSequence_Call struct {
 	*mock.Call
 }
 
-// Save is a helper method to define mock.On call
+// NextSequence is a helper method to define mock.On call
 //   - ctx context.Context
-//   - userUUID uuid.UUID
-//   - shortURL string
-//   - url string
-func (_e *MockRepository_Expecter) Save(ctx interface{}, userUUID interface{}, shortURL interface{}, url interface{}) *MockRepository_Save_Call {
-	return &MockRepository_Save_Call{Call: _e.mock.On("Save", ctx, userUUID, shortURL, url)}
+func (_e *MockRepository_Expecter) NextSequence(ctx interface{}) *MockRepository_NextSequence_Call {
+	return &MockRepository_NextSequence_Call{Call: _e.mock.On("NextSequence", ctx)}
 }
 
-func (_c *MockRepository_Save_Call) Run(run func(ctx context.Context, userUUID uuid.UUID, shortURL string, url string)) *MockRepository_Save_Call {
+func (_c *MockRepository_NextSequence_Call) Run(run func(ctx context.Context)) *MockRepository_NextSequence_Call {
 	_c.Call.Run(func(args mock.Arguments) {
-		run(args[0].(context.Context), args[1].(uuid.UUID), args[2].(string), args[3].(string))
+		run(args[0].(context.Context))
 	})
 	return _c
 }
 
-func (_c *MockRepository_Save_Call) Return(_a0 error) *MockRepository_Save_Call {
-	_c.Call.Return(_a0)
+func (_c *MockRepository_NextSequence_Call) Return(_a0 int64, _a1 error) *MockRepository_NextSequence_Call {
+	_c.Call.Return(_a0, _a1)
 	return _c
 }
 
-func (_c *MockRepository_Save_Call) RunAndReturn(run func(context.Context, uuid.UUID, string, string) error) *MockRepository_Save_Call {
+func (_c *MockRepository_NextSequence_Call) RunAndReturn(run func(context.Context) (int64, error)) *MockRepository_NextSequence_Call {
 	_c.Call.Return(run)
 	return _c
 }
 
-// SaveBatch provides a mock function with given fields: ctx, userUUID, batch
-func (_m *MockRepository) SaveBatch(ctx context.Context, userUUID uuid.UUID, batch []model.URL) error {
-	ret := _m.Called(ctx, userUUID, batch)
+// Ping provides a mock function with given fields: ctx
+func (_m *MockRepository) Ping(ctx context.Context) error {
+	ret := _m.Called(ctx)
 
 	if len(ret) == 0 {
-		panic("no return value specified for SaveBatch")
+		panic("no return value specified for Ping")
 	}
 
 	var r0 error
-	if rf, ok := ret.Get(0).(func(context.Context, uuid.UUID, []model.URL) error); ok {
-		r0 = rf(ctx, userUUID, batch)
+	if rf, ok := ret.Get(0).(func(context.Context) error); ok {
+		r0 = rf(ctx)
 	} else {
 		r0 = ret.Error(0)
 	}
@@ -311,32 +600,744 @@ func (_m *MockRepository) SaveBatch(ctx context.Context, userUUID uuid.UUID, bat
 	return r0
 }
 
-// MockRepository_SaveBatch_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'SaveBatch'
-type MockRepository_SaveBatch_Call struct {
+// MockRepository_Ping_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'Ping'
+type MockRepository_Ping_Call struct {
 	*mock.Call
 }
 
-// SaveBatch is a helper method to define mock.On call
+// Ping is a helper method to define mock.On call
 //   - ctx context.Context
-//   - userUUID uuid.UUID
-//   - batch []model.URL
-func (_e *MockRepository_Expecter) SaveBatch(ctx interface{}, userUUID interface{}, batch interface{}) *MockRepository_SaveBatch_Call {
-	return &MockRepository_SaveBatch_Call{Call: _e.mock.On("SaveBatch", ctx, userUUID, batch)}
+func (_e *MockRepository_Expecter) Ping(ctx interface{}) *MockRepository_Ping_Call {
+	return &MockRepository_Ping_Call{Call: _e.mock.On("Ping", ctx)}
 }
 
-func (_c *MockRepository_SaveBatch_Call) Run(run func(ctx context.Context, userUUID uuid.UUID, batch []model.URL)) *MockRepository_SaveBatch_Call {
+func (_c *MockRepository_Ping_Call) Run(run func(ctx context.Context)) *MockRepository_Ping_Call {
 	_c.Call.Run(func(args mock.Arguments) {
-		run(args[0].(context.Context), args[1].(uuid.UUID), args[2].([]model.URL))
+		run(args[0].(context.Context))
 	})
 	return _c
 }
 
-func (_c *MockRepository_SaveBatch_Call) Return(_a0 error) *MockRepository_SaveBatch_Call {
+func (_c *MockRepository_Ping_Call) Return(_a0 error) *MockRepository_Ping_Call {
 	_c.Call.Return(_a0)
 	return _c
 }
 
-func (_c *MockRepository_SaveBatch_Call) RunAndReturn(run func(context.Context, uuid.UUID, []model.URL) error) *MockRepository_SaveBatch_Call {
+func (_c *MockRepository_Ping_Call) RunAndReturn(run func(context.Context) error) *MockRepository_Ping_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// PurgeDeleted provides a mock function with given fields: ctx, before, limit
+func (_m *MockRepository) PurgeDeleted(ctx context.Context, before time.Time, limit int) (int, error) {
+	ret := _m.Called(ctx, before, limit)
+
+	if len(ret) == 0 {
+		panic("no return value specified for PurgeDeleted")
+	}
+
+	var r0 int
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, time.Time, int) (int, error)); ok {
+		return rf(ctx, before, limit)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, time.Time, int) int); ok {
+		r0 = rf(ctx, before, limit)
+	} else {
+		r0 = ret.Get(0).(int)
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, time.Time, int) error); ok {
+		r1 = rf(ctx, before, limit)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// MockRepository_PurgeDeleted_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'PurgeDeleted'
+type MockRepository_PurgeDeleted_Call struct {
+	*mock.Call
+}
+
+// PurgeDeleted is a helper method to define mock.On call
+//   - ctx context.Context
+//   - before time.Time
+//   - limit int
+func (_e *MockRepository_Expecter) PurgeDeleted(ctx interface{}, before interface{}, limit interface{}) *MockRepository_PurgeDeleted_Call {
+	return &MockRepository_PurgeDeleted_Call{Call: _e.mock.On("PurgeDeleted", ctx, before, limit)}
+}
+
+func (_c *MockRepository_PurgeDeleted_Call) Run(run func(ctx context.Context, before time.Time, limit int)) *MockRepository_PurgeDeleted_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(context.Context), args[1].(time.Time), args[2].(int))
+	})
+	return _c
+}
+
+func (_c *MockRepository_PurgeDeleted_Call) Return(_a0 int, _a1 error) *MockRepository_PurgeDeleted_Call {
+	_c.Call.Return(_a0, _a1)
+	return _c
+}
+
+func (_c *MockRepository_PurgeDeleted_Call) RunAndReturn(run func(context.Context, time.Time, int) (int, error)) *MockRepository_PurgeDeleted_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// ResolveAPIKey provides a mock function with given fields: ctx, keyHash
+func (_m *MockRepository) ResolveAPIKey(ctx context.Context, keyHash string) (uuid.UUID, error) {
+	ret := _m.Called(ctx, keyHash)
+
+	if len(ret) == 0 {
+		panic("no return value specified for ResolveAPIKey")
+	}
+
+	var r0 uuid.UUID
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, string) (uuid.UUID, error)); ok {
+		return rf(ctx, keyHash)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, string) uuid.UUID); ok {
+		r0 = rf(ctx, keyHash)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(uuid.UUID)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, string) error); ok {
+		r1 = rf(ctx, keyHash)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// MockRepository_ResolveAPIKey_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'ResolveAPIKey'
+type MockRepository_ResolveAPIKey_Call struct {
+	*mock.Call
+}
+
+// ResolveAPIKey is a helper method to define mock.On call
+//   - ctx context.Context
+//   - keyHash string
+func (_e *MockRepository_Expecter) ResolveAPIKey(ctx interface{}, keyHash interface{}) *MockRepository_ResolveAPIKey_Call {
+	return &MockRepository_ResolveAPIKey_Call{Call: _e.mock.On("ResolveAPIKey", ctx, keyHash)}
+}
+
+func (_c *MockRepository_ResolveAPIKey_Call) Run(run func(ctx context.Context, keyHash string)) *MockRepository_ResolveAPIKey_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(context.Context), args[1].(string))
+	})
+	return _c
+}
+
+func (_c *MockRepository_ResolveAPIKey_Call) Return(_a0 uuid.UUID, _a1 error) *MockRepository_ResolveAPIKey_Call {
+	_c.Call.Return(_a0, _a1)
+	return _c
+}
+
+func (_c *MockRepository_ResolveAPIKey_Call) RunAndReturn(run func(context.Context, string) (uuid.UUID, error)) *MockRepository_ResolveAPIKey_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// RevokeAPIKey provides a mock function with given fields: ctx, userUUID, keyHash
+func (_m *MockRepository) RevokeAPIKey(ctx context.Context, userUUID uuid.UUID, keyHash string) error {
+	ret := _m.Called(ctx, userUUID, keyHash)
+
+	if len(ret) == 0 {
+		panic("no return value specified for RevokeAPIKey")
+	}
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, uuid.UUID, string) error); ok {
+		r0 = rf(ctx, userUUID, keyHash)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// MockRepository_RevokeAPIKey_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'RevokeAPIKey'
+type MockRepository_RevokeAPIKey_Call struct {
+	*mock.Call
+}
+
+// RevokeAPIKey is a helper method to define mock.On call
+//   - ctx context.Context
+//   - userUUID uuid.UUID
+//   - keyHash string
+func (_e *MockRepository_Expecter) RevokeAPIKey(ctx interface{}, userUUID interface{}, keyHash interface{}) *MockRepository_RevokeAPIKey_Call {
+	return &MockRepository_RevokeAPIKey_Call{Call: _e.mock.On("RevokeAPIKey", ctx, userUUID, keyHash)}
+}
+
+func (_c *MockRepository_RevokeAPIKey_Call) Run(run func(ctx context.Context, userUUID uuid.UUID, keyHash string)) *MockRepository_RevokeAPIKey_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(context.Context), args[1].(uuid.UUID), args[2].(string))
+	})
+	return _c
+}
+
+func (_c *MockRepository_RevokeAPIKey_Call) Return(_a0 error) *MockRepository_RevokeAPIKey_Call {
+	_c.Call.Return(_a0)
+	return _c
+}
+
+func (_c *MockRepository_RevokeAPIKey_Call) RunAndReturn(run func(context.Context, uuid.UUID, string) error) *MockRepository_RevokeAPIKey_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// Save provides a mock function with given fields: ctx, userUUID, shortURL, url, referer, userAgent, expiresAt, createdAt
+func (_m *MockRepository) Save(ctx context.Context, userUUID uuid.UUID, shortURL string, url string, referer string, userAgent string, expiresAt time.Time, createdAt time.Time) (string, error) {
+	ret := _m.Called(ctx, userUUID, shortURL, url, referer, userAgent, expiresAt, createdAt)
+
+	if len(ret) == 0 {
+		panic("no return value specified for Save")
+	}
+
+	var r0 string
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, uuid.UUID, string, string, string, string, time.Time, time.Time) (string, error)); ok {
+		return rf(ctx, userUUID, shortURL, url, referer, userAgent, expiresAt, createdAt)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, uuid.UUID, string, string, string, string, time.Time, time.Time) string); ok {
+		r0 = rf(ctx, userUUID, shortURL, url, referer, userAgent, expiresAt, createdAt)
+	} else {
+		r0 = ret.Get(0).(string)
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, uuid.UUID, string, string, string, string, time.Time, time.Time) error); ok {
+		r1 = rf(ctx, userUUID, shortURL, url, referer, userAgent, expiresAt, createdAt)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// MockRepository_Save_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'Save'
+type MockRepository_Save_Call struct {
+	*mock.Call
+}
+
+// Save is a helper method to define mock.On call
+//   - ctx context.Context
+//   - userUUID uuid.UUID
+//   - shortURL string
+//   - url string
+//   - referer string
+//   - userAgent string
+//   - expiresAt time.Time
+//   - createdAt time.Time
+func (_e *MockRepository_Expecter) Save(ctx interface{}, userUUID interface{}, shortURL interface{}, url interface{}, referer interface{}, userAgent interface{}, expiresAt interface{}, createdAt interface{}) *MockRepository_Save_Call {
+	return &MockRepository_Save_Call{Call: _e.mock.On("Save", ctx, userUUID, shortURL, url, referer, userAgent, expiresAt, createdAt)}
+}
+
+func (_c *MockRepository_Save_Call) Run(run func(ctx context.Context, userUUID uuid.UUID, shortURL string, url string, referer string, userAgent string, expiresAt time.Time, createdAt time.Time)) *MockRepository_Save_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(context.Context), args[1].(uuid.UUID), args[2].(string), args[3].(string), args[4].(string), args[5].(string), args[6].(time.Time), args[7].(time.Time))
+	})
+	return _c
+}
+
+func (_c *MockRepository_Save_Call) Return(_a0 string, _a1 error) *MockRepository_Save_Call {
+	_c.Call.Return(_a0, _a1)
+	return _c
+}
+
+func (_c *MockRepository_Save_Call) RunAndReturn(run func(context.Context, uuid.UUID, string, string, string, string, time.Time, time.Time) (string, error)) *MockRepository_Save_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// SaveAPIKey provides a mock function with given fields: ctx, userUUID, keyHash, createdAt
+func (_m *MockRepository) SaveAPIKey(ctx context.Context, userUUID uuid.UUID, keyHash string, createdAt time.Time) error {
+	ret := _m.Called(ctx, userUUID, keyHash, createdAt)
+
+	if len(ret) == 0 {
+		panic("no return value specified for SaveAPIKey")
+	}
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, uuid.UUID, string, time.Time) error); ok {
+		r0 = rf(ctx, userUUID, keyHash, createdAt)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// MockRepository_SaveAPIKey_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'SaveAPIKey'
+type MockRepository_SaveAPIKey_Call struct {
+	*mock.Call
+}
+
+// SaveAPIKey is a helper method to define mock.On call
+//   - ctx context.Context
+//   - userUUID uuid.UUID
+//   - keyHash string
+//   - createdAt time.Time
+func (_e *MockRepository_Expecter) SaveAPIKey(ctx interface{}, userUUID interface{}, keyHash interface{}, createdAt interface{}) *MockRepository_SaveAPIKey_Call {
+	return &MockRepository_SaveAPIKey_Call{Call: _e.mock.On("SaveAPIKey", ctx, userUUID, keyHash, createdAt)}
+}
+
+func (_c *MockRepository_SaveAPIKey_Call) Run(run func(ctx context.Context, userUUID uuid.UUID, keyHash string, createdAt time.Time)) *MockRepository_SaveAPIKey_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(context.Context), args[1].(uuid.UUID), args[2].(string), args[3].(time.Time))
+	})
+	return _c
+}
+
+func (_c *MockRepository_SaveAPIKey_Call) Return(_a0 error) *MockRepository_SaveAPIKey_Call {
+	_c.Call.Return(_a0)
+	return _c
+}
+
+func (_c *MockRepository_SaveAPIKey_Call) RunAndReturn(run func(context.Context, uuid.UUID, string, time.Time) error) *MockRepository_SaveAPIKey_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// SaveBatch provides a mock function with given fields: ctx, userUUID, batch
+func (_m *MockRepository) SaveBatch(ctx context.Context, userUUID uuid.UUID, batch []model.URL) ([]string, error) {
+	ret := _m.Called(ctx, userUUID, batch)
+
+	if len(ret) == 0 {
+		panic("no return value specified for SaveBatch")
+	}
+
+	var r0 []string
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, uuid.UUID, []model.URL) ([]string, error)); ok {
+		return rf(ctx, userUUID, batch)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, uuid.UUID, []model.URL) []string); ok {
+		r0 = rf(ctx, userUUID, batch)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]string)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, uuid.UUID, []model.URL) error); ok {
+		r1 = rf(ctx, userUUID, batch)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// MockRepository_SaveBatch_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'SaveBatch'
+type MockRepository_SaveBatch_Call struct {
+	*mock.Call
+}
+
+// SaveBatch is a helper method to define mock.On call
+//   - ctx context.Context
+//   - userUUID uuid.UUID
+//   - batch []model.URL
+func (_e *MockRepository_Expecter) SaveBatch(ctx interface{}, userUUID interface{}, batch interface{}) *MockRepository_SaveBatch_Call {
+	return &MockRepository_SaveBatch_Call{Call: _e.mock.On("SaveBatch", ctx, userUUID, batch)}
+}
+
+func (_c *MockRepository_SaveBatch_Call) Run(run func(ctx context.Context, userUUID uuid.UUID, batch []model.URL)) *MockRepository_SaveBatch_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(context.Context), args[1].(uuid.UUID), args[2].([]model.URL))
+	})
+	return _c
+}
+
+func (_c *MockRepository_SaveBatch_Call) Return(_a0 []string, _a1 error) *MockRepository_SaveBatch_Call {
+	_c.Call.Return(_a0, _a1)
+	return _c
+}
+
+func (_c *MockRepository_SaveBatch_Call) RunAndReturn(run func(context.Context, uuid.UUID, []model.URL) ([]string, error)) *MockRepository_SaveBatch_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// SetPasswordedSlug provides a mock function with given fields: ctx, shortURL, passwordHash
+func (_m *MockRepository) SetPasswordedSlug(ctx context.Context, shortURL string, passwordHash string) error {
+	ret := _m.Called(ctx, shortURL, passwordHash)
+
+	if len(ret) == 0 {
+		panic("no return value specified for SetPasswordedSlug")
+	}
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, string, string) error); ok {
+		r0 = rf(ctx, shortURL, passwordHash)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// MockRepository_SetPasswordedSlug_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'SetPasswordedSlug'
+type MockRepository_SetPasswordedSlug_Call struct {
+	*mock.Call
+}
+
+// SetPasswordedSlug is a helper method to define mock.On call
+//   - ctx context.Context
+//   - shortURL string
+//   - passwordHash string
+func (_e *MockRepository_Expecter) SetPasswordedSlug(ctx interface{}, shortURL interface{}, passwordHash interface{}) *MockRepository_SetPasswordedSlug_Call {
+	return &MockRepository_SetPasswordedSlug_Call{Call: _e.mock.On("SetPasswordedSlug", ctx, shortURL, passwordHash)}
+}
+
+func (_c *MockRepository_SetPasswordedSlug_Call) Run(run func(ctx context.Context, shortURL string, passwordHash string)) *MockRepository_SetPasswordedSlug_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(context.Context), args[1].(string), args[2].(string))
+	})
+	return _c
+}
+
+func (_c *MockRepository_SetPasswordedSlug_Call) Return(_a0 error) *MockRepository_SetPasswordedSlug_Call {
+	_c.Call.Return(_a0)
+	return _c
+}
+
+func (_c *MockRepository_SetPasswordedSlug_Call) RunAndReturn(run func(context.Context, string, string) error) *MockRepository_SetPasswordedSlug_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// SetRedirectStatus provides a mock function with given fields: ctx, shortURL, status
+func (_m *MockRepository) SetRedirectStatus(ctx context.Context, shortURL string, status int) error {
+	ret := _m.Called(ctx, shortURL, status)
+
+	if len(ret) == 0 {
+		panic("no return value specified for SetRedirectStatus")
+	}
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, string, int) error); ok {
+		r0 = rf(ctx, shortURL, status)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// MockRepository_SetRedirectStatus_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'SetRedirectStatus'
+type MockRepository_SetRedirectStatus_Call struct {
+	*mock.Call
+}
+
+// SetRedirectStatus is a helper method to define mock.On call
+//   - ctx context.Context
+//   - shortURL string
+//   - status int
+func (_e *MockRepository_Expecter) SetRedirectStatus(ctx interface{}, shortURL interface{}, status interface{}) *MockRepository_SetRedirectStatus_Call {
+	return &MockRepository_SetRedirectStatus_Call{Call: _e.mock.On("SetRedirectStatus", ctx, shortURL, status)}
+}
+
+func (_c *MockRepository_SetRedirectStatus_Call) Run(run func(ctx context.Context, shortURL string, status int)) *MockRepository_SetRedirectStatus_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(context.Context), args[1].(string), args[2].(int))
+	})
+	return _c
+}
+
+func (_c *MockRepository_SetRedirectStatus_Call) Return(_a0 error) *MockRepository_SetRedirectStatus_Call {
+	_c.Call.Return(_a0)
+	return _c
+}
+
+func (_c *MockRepository_SetRedirectStatus_Call) RunAndReturn(run func(context.Context, string, int) error) *MockRepository_SetRedirectStatus_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// Stats provides a mock function with given fields: ctx
+func (_m *MockRepository) Stats(ctx context.Context) (model.Stats, error) {
+	ret := _m.Called(ctx)
+
+	if len(ret) == 0 {
+		panic("no return value specified for Stats")
+	}
+
+	var r0 model.Stats
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context) (model.Stats, error)); ok {
+		return rf(ctx)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context) model.Stats); ok {
+		r0 = rf(ctx)
+	} else {
+		r0 = ret.Get(0).(model.Stats)
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context) error); ok {
+		r1 = rf(ctx)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// MockRepository_Stats_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'Stats'
+type MockRepository_Stats_Call struct {
+	*mock.Call
+}
+
+// Stats is a helper method to define mock.On call
+//   - ctx context.Context
+func (_e *MockRepository_Expecter) Stats(ctx interface{}) *MockRepository_Stats_Call {
+	return &MockRepository_Stats_Call{Call: _e.mock.On("Stats", ctx)}
+}
+
+func (_c *MockRepository_Stats_Call) Run(run func(ctx context.Context)) *MockRepository_Stats_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(context.Context))
+	})
+	return _c
+}
+
+func (_c *MockRepository_Stats_Call) Return(_a0 model.Stats, _a1 error) *MockRepository_Stats_Call {
+	_c.Call.Return(_a0, _a1)
+	return _c
+}
+
+func (_c *MockRepository_Stats_Call) RunAndReturn(run func(context.Context) (model.Stats, error)) *MockRepository_Stats_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// SweepExpired provides a mock function with given fields: ctx, before, limit
+func (_m *MockRepository) SweepExpired(ctx context.Context, before time.Time, limit int) (int, error) {
+	ret := _m.Called(ctx, before, limit)
+
+	if len(ret) == 0 {
+		panic("no return value specified for SweepExpired")
+	}
+
+	var r0 int
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, time.Time, int) (int, error)); ok {
+		return rf(ctx, before, limit)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, time.Time, int) int); ok {
+		r0 = rf(ctx, before, limit)
+	} else {
+		r0 = ret.Get(0).(int)
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, time.Time, int) error); ok {
+		r1 = rf(ctx, before, limit)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// MockRepository_SweepExpired_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'SweepExpired'
+type MockRepository_SweepExpired_Call struct {
+	*mock.Call
+}
+
+// SweepExpired is a helper method to define mock.On call
+//   - ctx context.Context
+//   - before time.Time
+//   - limit int
+func (_e *MockRepository_Expecter) SweepExpired(ctx interface{}, before interface{}, limit interface{}) *MockRepository_SweepExpired_Call {
+	return &MockRepository_SweepExpired_Call{Call: _e.mock.On("SweepExpired", ctx, before, limit)}
+}
+
+func (_c *MockRepository_SweepExpired_Call) Run(run func(ctx context.Context, before time.Time, limit int)) *MockRepository_SweepExpired_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(context.Context), args[1].(time.Time), args[2].(int))
+	})
+	return _c
+}
+
+func (_c *MockRepository_SweepExpired_Call) Return(_a0 int, _a1 error) *MockRepository_SweepExpired_Call {
+	_c.Call.Return(_a0, _a1)
+	return _c
+}
+
+func (_c *MockRepository_SweepExpired_Call) RunAndReturn(run func(context.Context, time.Time, int) (int, error)) *MockRepository_SweepExpired_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// TopHosts provides a mock function with given fields: ctx, n
+func (_m *MockRepository) TopHosts(ctx context.Context, n int) ([]model.HostCount, error) {
+	ret := _m.Called(ctx, n)
+
+	if len(ret) == 0 {
+		panic("no return value specified for TopHosts")
+	}
+
+	var r0 []model.HostCount
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, int) ([]model.HostCount, error)); ok {
+		return rf(ctx, n)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, int) []model.HostCount); ok {
+		r0 = rf(ctx, n)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]model.HostCount)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, int) error); ok {
+		r1 = rf(ctx, n)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// MockRepository_TopHosts_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'TopHosts'
+type MockRepository_TopHosts_Call struct {
+	*mock.Call
+}
+
+// TopHosts is a helper method to define mock.On call
+//   - ctx context.Context
+//   - n int
+func (_e *MockRepository_Expecter) TopHosts(ctx interface{}, n interface{}) *MockRepository_TopHosts_Call {
+	return &MockRepository_TopHosts_Call{Call: _e.mock.On("TopHosts", ctx, n)}
+}
+
+func (_c *MockRepository_TopHosts_Call) Run(run func(ctx context.Context, n int)) *MockRepository_TopHosts_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(context.Context), args[1].(int))
+	})
+	return _c
+}
+
+func (_c *MockRepository_TopHosts_Call) Return(_a0 []model.HostCount, _a1 error) *MockRepository_TopHosts_Call {
+	_c.Call.Return(_a0, _a1)
+	return _c
+}
+
+func (_c *MockRepository_TopHosts_Call) RunAndReturn(run func(context.Context, int) ([]model.HostCount, error)) *MockRepository_TopHosts_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// UpdateURL provides a mock function with given fields: ctx, userUUID, shortURL, newURL, updatedAt
+func (_m *MockRepository) UpdateURL(ctx context.Context, userUUID uuid.UUID, shortURL string, newURL string, updatedAt time.Time) error {
+	ret := _m.Called(ctx, userUUID, shortURL, newURL, updatedAt)
+
+	if len(ret) == 0 {
+		panic("no return value specified for UpdateURL")
+	}
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, uuid.UUID, string, string, time.Time) error); ok {
+		r0 = rf(ctx, userUUID, shortURL, newURL, updatedAt)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// MockRepository_UpdateURL_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'UpdateURL'
+type MockRepository_UpdateURL_Call struct {
+	*mock.Call
+}
+
+// UpdateURL is a helper method to define mock.On call
+//   - ctx context.Context
+//   - userUUID uuid.UUID
+//   - shortURL string
+//   - newURL string
+//   - updatedAt time.Time
+func (_e *MockRepository_Expecter) UpdateURL(ctx interface{}, userUUID interface{}, shortURL interface{}, newURL interface{}, updatedAt interface{}) *MockRepository_UpdateURL_Call {
+	return &MockRepository_UpdateURL_Call{Call: _e.mock.On("UpdateURL", ctx, userUUID, shortURL, newURL, updatedAt)}
+}
+
+func (_c *MockRepository_UpdateURL_Call) Run(run func(ctx context.Context, userUUID uuid.UUID, shortURL string, newURL string, updatedAt time.Time)) *MockRepository_UpdateURL_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(context.Context), args[1].(uuid.UUID), args[2].(string), args[3].(string), args[4].(time.Time))
+	})
+	return _c
+}
+
+func (_c *MockRepository_UpdateURL_Call) Return(_a0 error) *MockRepository_UpdateURL_Call {
+	_c.Call.Return(_a0)
+	return _c
+}
+
+func (_c *MockRepository_UpdateURL_Call) RunAndReturn(run func(context.Context, uuid.UUID, string, string, time.Time) error) *MockRepository_UpdateURL_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// Verify provides a mock function with given fields: ctx
+func (_m *MockRepository) Verify(ctx context.Context) ([]string, error) {
+	ret := _m.Called(ctx)
+
+	if len(ret) == 0 {
+		panic("no return value specified for Verify")
+	}
+
+	var r0 []string
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context) ([]string, error)); ok {
+		return rf(ctx)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context) []string); ok {
+		r0 = rf(ctx)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]string)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context) error); ok {
+		r1 = rf(ctx)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// MockRepository_Verify_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'Verify'
+type MockRepository_Verify_Call struct {
+	*mock.Call
+}
+
+// Verify is a helper method to define mock.On call
+//   - ctx context.Context
+func (_e *MockRepository_Expecter) Verify(ctx interface{}) *MockRepository_Verify_Call {
+	return &MockRepository_Verify_Call{Call: _e.mock.On("Verify", ctx)}
+}
+
+func (_c *MockRepository_Verify_Call) Run(run func(ctx context.Context)) *MockRepository_Verify_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(context.Context))
+	})
+	return _c
+}
+
+func (_c *MockRepository_Verify_Call) Return(_a0 []string, _a1 error) *MockRepository_Verify_Call {
+	_c.Call.Return(_a0, _a1)
+	return _c
+}
+
+func (_c *MockRepository_Verify_Call) RunAndReturn(run func(context.Context) ([]string, error)) *MockRepository_Verify_Call {
 	_c.Call.Return(run)
 	return _c
 }
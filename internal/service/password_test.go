@@ -0,0 +1,69 @@
+package service
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/ar4ie13/shortener/internal/repository/memory"
+	"github.com/google/uuid"
+	"github.com/rs/zerolog"
+)
+
+func TestService_SaveURL_PasswordProtected(t *testing.T) {
+	repo := memory.NewMemStorage()
+	srv := NewService(repo, zerolog.Nop(), false, time.Second, "", 0, nil, 0, 0, 0, 0, nil, 0, false, 0, false, zerolog.Nop(), 0, "", 0, 0, nil, nil, 0)
+	defer srv.Close()
+
+	userUUID := uuid.New()
+
+	slug, err := srv.SaveURL(context.Background(), userUUID, "https://example.com/one", "", "", "", "secret", false, 0)
+	if err != nil {
+		t.Fatalf("SaveURL() unexpected error = %v", err)
+	}
+
+	ok, err := srv.VerifyPassword(context.Background(), slug, "secret")
+	if err != nil {
+		t.Fatalf("VerifyPassword() unexpected error = %v", err)
+	}
+	if !ok {
+		t.Error("VerifyPassword() with correct password = false, want true")
+	}
+
+	ok, err = srv.VerifyPassword(context.Background(), slug, "wrong")
+	if err != nil {
+		t.Fatalf("VerifyPassword() unexpected error = %v", err)
+	}
+	if ok {
+		t.Error("VerifyPassword() with wrong password = true, want false")
+	}
+
+	ok, err = srv.VerifyPassword(context.Background(), slug, "")
+	if err != nil {
+		t.Fatalf("VerifyPassword() unexpected error = %v", err)
+	}
+	if ok {
+		t.Error("VerifyPassword() with missing password = true, want false")
+	}
+}
+
+func TestService_VerifyPassword_UnprotectedSlugAcceptsAnyPassword(t *testing.T) {
+	repo := memory.NewMemStorage()
+	srv := NewService(repo, zerolog.Nop(), false, time.Second, "", 0, nil, 0, 0, 0, 0, nil, 0, false, 0, false, zerolog.Nop(), 0, "", 0, 0, nil, nil, 0)
+	defer srv.Close()
+
+	userUUID := uuid.New()
+
+	slug, err := srv.SaveURL(context.Background(), userUUID, "https://example.com/one", "", "", "", "", false, 0)
+	if err != nil {
+		t.Fatalf("SaveURL() unexpected error = %v", err)
+	}
+
+	ok, err := srv.VerifyPassword(context.Background(), slug, "")
+	if err != nil {
+		t.Fatalf("VerifyPassword() unexpected error = %v", err)
+	}
+	if !ok {
+		t.Error("VerifyPassword() on unprotected slug with empty password = false, want true")
+	}
+}
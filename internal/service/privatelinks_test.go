@@ -0,0 +1,72 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/ar4ie13/shortener/internal/myerrors"
+	"github.com/ar4ie13/shortener/internal/repository/memory"
+	"github.com/google/uuid"
+	"github.com/rs/zerolog"
+)
+
+func TestService_GetURL_PrivateLinks_OwnerAccessSucceeds(t *testing.T) {
+	repo := memory.NewMemStorage()
+	srv := NewService(repo, zerolog.Nop(), false, time.Second, "", 0, nil, 0, 0, 0, 0, nil, 0, false, 0, true, zerolog.Nop(), 0, "", 0, 0, nil, nil, 0)
+	defer srv.Close()
+
+	owner := uuid.New()
+	slug, err := srv.SaveURL(context.Background(), owner, "https://example.com/one", "", "", "", "", false, 0)
+	if err != nil {
+		t.Fatalf("SaveURL() unexpected error = %v", err)
+	}
+
+	gotURL, err := srv.GetURL(context.Background(), owner, slug)
+	if err != nil {
+		t.Fatalf("GetURL() unexpected error = %v", err)
+	}
+	if gotURL != "https://example.com/one" {
+		t.Errorf("GetURL() = %q, want %q", gotURL, "https://example.com/one")
+	}
+}
+
+func TestService_GetURL_PrivateLinks_NonOwnerAccessFails(t *testing.T) {
+	repo := memory.NewMemStorage()
+	srv := NewService(repo, zerolog.Nop(), false, time.Second, "", 0, nil, 0, 0, 0, 0, nil, 0, false, 0, true, zerolog.Nop(), 0, "", 0, 0, nil, nil, 0)
+	defer srv.Close()
+
+	owner := uuid.New()
+	other := uuid.New()
+	slug, err := srv.SaveURL(context.Background(), owner, "https://example.com/one", "", "", "", "", false, 0)
+	if err != nil {
+		t.Fatalf("SaveURL() unexpected error = %v", err)
+	}
+
+	_, err = srv.GetURL(context.Background(), other, slug)
+	if !errors.Is(err, myerrors.ErrNotFound) {
+		t.Fatalf("GetURL() error = %v, want %v", err, myerrors.ErrNotFound)
+	}
+}
+
+func TestService_GetURL_PublicByDefault_AnyUserCanResolve(t *testing.T) {
+	repo := memory.NewMemStorage()
+	srv := NewService(repo, zerolog.Nop(), false, time.Second, "", 0, nil, 0, 0, 0, 0, nil, 0, false, 0, false, zerolog.Nop(), 0, "", 0, 0, nil, nil, 0)
+	defer srv.Close()
+
+	owner := uuid.New()
+	other := uuid.New()
+	slug, err := srv.SaveURL(context.Background(), owner, "https://example.com/one", "", "", "", "", false, 0)
+	if err != nil {
+		t.Fatalf("SaveURL() unexpected error = %v", err)
+	}
+
+	gotURL, err := srv.GetURL(context.Background(), other, slug)
+	if err != nil {
+		t.Fatalf("GetURL() unexpected error = %v", err)
+	}
+	if gotURL != "https://example.com/one" {
+		t.Errorf("GetURL() = %q, want %q", gotURL, "https://example.com/one")
+	}
+}
@@ -0,0 +1,147 @@
+package service
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/ar4ie13/shortener/internal/model"
+	"github.com/google/uuid"
+	"github.com/rs/zerolog"
+)
+
+// purgeTrackingRepository implements Repository, counting rows and calls across successive
+// PurgeDeleted batches to verify Service.PurgeDeleted loops in batches rather than issuing a
+// single unbounded call
+type purgeTrackingRepository struct {
+	remaining int
+	calls     int
+	err       error
+}
+
+func (r *purgeTrackingRepository) GetURL(_ context.Context, _ uuid.UUID, _ string, _ bool) (string, error) {
+	return "", nil
+}
+func (r *purgeTrackingRepository) GetURLs(_ context.Context, _ uuid.UUID, shortURLs []string, _ bool) ([]model.URLExpansion, error) {
+	return make([]model.URLExpansion, len(shortURLs)), nil
+}
+func (r *purgeTrackingRepository) GetShortURL(_ context.Context, _ string) (string, error) {
+	return "", nil
+}
+func (r *purgeTrackingRepository) Save(_ context.Context, _ uuid.UUID, _ string, _ string, _ string, _ string, _ time.Time, _ time.Time) (string, error) {
+	return "", nil
+}
+func (r *purgeTrackingRepository) SaveBatch(_ context.Context, _ uuid.UUID, _ []model.URL) ([]string, error) {
+	return nil, nil
+}
+func (r *purgeTrackingRepository) GetUserShortURLs(_ context.Context, _ uuid.UUID) ([]model.URL, error) {
+	return nil, nil
+}
+func (r *purgeTrackingRepository) UpdateURL(_ context.Context, _ uuid.UUID, _ string, _ string, _ time.Time) error {
+	return nil
+}
+func (r *purgeTrackingRepository) DeleteUserShortURLs(_ context.Context, _ map[uuid.UUID][]string, _ time.Time) error {
+	return nil
+}
+func (r *purgeTrackingRepository) SweepExpired(_ context.Context, _ time.Time, _ int) (int, error) {
+	return 0, nil
+}
+func (r *purgeTrackingRepository) PurgeDeleted(_ context.Context, _ time.Time, limit int) (int, error) {
+	r.calls++
+	if r.err != nil {
+		return 0, r.err
+	}
+	n := limit
+	if n > r.remaining {
+		n = r.remaining
+	}
+	r.remaining -= n
+	return n, nil
+}
+func (r *purgeTrackingRepository) Ping(_ context.Context) error {
+	return nil
+}
+func (r *purgeTrackingRepository) NextSequence(_ context.Context) (int64, error) {
+	return 0, nil
+}
+func (r *purgeTrackingRepository) SetPasswordedSlug(_ context.Context, _ string, _ string) error {
+	return nil
+}
+func (r *purgeTrackingRepository) GetPasswordHash(_ context.Context, _ string) (string, error) {
+	return "", nil
+}
+func (r *purgeTrackingRepository) SetRedirectStatus(_ context.Context, _ string, _ int) error {
+	return nil
+}
+func (r *purgeTrackingRepository) GetRedirectStatus(_ context.Context, _ string) (int, error) {
+	return 0, nil
+}
+func (r *purgeTrackingRepository) SaveAPIKey(_ context.Context, _ uuid.UUID, _ string, _ time.Time) error {
+	return nil
+}
+func (r *purgeTrackingRepository) ResolveAPIKey(_ context.Context, _ string) (uuid.UUID, error) {
+	return uuid.Nil, nil
+}
+func (r *purgeTrackingRepository) RevokeAPIKey(_ context.Context, _ uuid.UUID, _ string) error {
+	return nil
+}
+func (r *purgeTrackingRepository) TopHosts(_ context.Context, _ int) ([]model.HostCount, error) {
+	return nil, nil
+}
+func (r *purgeTrackingRepository) Stats(_ context.Context) (model.Stats, error) {
+	return model.Stats{}, nil
+}
+func (r *purgeTrackingRepository) BackfillHosts(_ context.Context, _ int) (int, error) {
+	return 0, nil
+}
+func (r *purgeTrackingRepository) Verify(_ context.Context) ([]string, error) {
+	return nil, nil
+}
+
+func (r *purgeTrackingRepository) Close() error {
+	return nil
+}
+
+func TestService_PurgeDeleted_LoopsInBatches(t *testing.T) {
+	repo := &purgeTrackingRepository{remaining: 25}
+	srv := NewService(repo, zerolog.Nop(), false, time.Second, "", 0, nil, 0, 0, 0, 0, nil, 0, false, 0, false, zerolog.Nop(), 0, "", 0, 0, nil, nil, 0)
+	defer srv.Close()
+
+	purged, err := srv.PurgeDeleted(context.Background(), time.Now(), 10, 0)
+	if err != nil {
+		t.Fatalf("PurgeDeleted() unexpected error = %v", err)
+	}
+	if purged != 25 {
+		t.Errorf("PurgeDeleted() purged = %d, want 25", purged)
+	}
+	if repo.calls != 3 {
+		t.Errorf("PurgeDeleted() repo calls = %d, want 3 batches (10+10+5)", repo.calls)
+	}
+}
+
+func TestService_PurgeDeleted_StopsOnCancelledContext(t *testing.T) {
+	repo := &purgeTrackingRepository{remaining: 1000}
+	srv := NewService(repo, zerolog.Nop(), false, time.Second, "", 0, nil, 0, 0, 0, 0, nil, 0, false, 0, false, zerolog.Nop(), 0, "", 0, 0, nil, nil, 0)
+	defer srv.Close()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	purged, err := srv.PurgeDeleted(ctx, time.Now(), 10, time.Millisecond)
+	if err == nil {
+		t.Fatal("PurgeDeleted() expected an error from the cancelled context, got none")
+	}
+	if purged != 10 {
+		t.Errorf("PurgeDeleted() purged = %d, want 10 from the single batch issued before the cancelled pause was observed", purged)
+	}
+}
+
+func TestService_PurgeDeleted_PropagatesRepositoryError(t *testing.T) {
+	repo := &purgeTrackingRepository{err: context.DeadlineExceeded}
+	srv := NewService(repo, zerolog.Nop(), false, time.Second, "", 0, nil, 0, 0, 0, 0, nil, 0, false, 0, false, zerolog.Nop(), 0, "", 0, 0, nil, nil, 0)
+	defer srv.Close()
+
+	if _, err := srv.PurgeDeleted(context.Background(), time.Now(), 10, 0); err == nil {
+		t.Fatal("PurgeDeleted() expected an error, got none")
+	}
+}
@@ -0,0 +1,76 @@
+package service
+
+import "sync"
+
+// recentSlugsCapacity bounds the memory used by the recently-generated slug tracker
+const recentSlugsCapacity = 1024
+
+// recentSlugs is a small bounded set of the most recently generated slugs, used to
+// bias generation away from likely-fresh collisions before the store check.
+type recentSlugs struct {
+	mu       sync.Mutex
+	set      map[string]struct{}
+	order    []string
+	capacity int
+}
+
+// newRecentSlugs constructs a recentSlugs tracker bounded to capacity entries
+func newRecentSlugs(capacity int) *recentSlugs {
+	return &recentSlugs{
+		set:      make(map[string]struct{}, capacity),
+		order:    make([]string, 0, capacity),
+		capacity: capacity,
+	}
+}
+
+// Contains reports whether slug was recently generated
+func (r *recentSlugs) Contains(slug string) bool {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	_, ok := r.set[slug]
+	return ok
+}
+
+// Add records slug as recently generated, evicting the oldest entry once capacity is reached
+func (r *recentSlugs) Add(slug string) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if _, ok := r.set[slug]; ok {
+		return
+	}
+
+	if len(r.order) >= r.capacity {
+		oldest := r.order[0]
+		r.order = r.order[1:]
+		delete(r.set, oldest)
+	}
+
+	r.set[slug] = struct{}{}
+	r.order = append(r.order, slug)
+}
+
+// generateShortURLAvoiding generates a slug biased away from the recentSlugs set. It never
+// blocks on the bias: if every attempt collides with a recent entry, the last generated
+// slug is still returned so a valid slug is never incorrectly skipped.
+func generateShortURLAvoiding(length int, alphabet string, avoid *recentSlugs) (string, error) {
+	const maxBiasAttempts = 3
+
+	var (
+		slug string
+		err  error
+	)
+	for attempt := 1; attempt <= maxBiasAttempts; attempt++ {
+		slug, err = generateShortURL(length, alphabet)
+		if err != nil {
+			return "", err
+		}
+
+		if !avoid.Contains(slug) {
+			return slug, nil
+		}
+	}
+
+	return slug, nil
+}
@@ -0,0 +1,68 @@
+package service
+
+import (
+	"testing"
+)
+
+func TestRecentSlugs_AddAndContains(t *testing.T) {
+	r := newRecentSlugs(2)
+
+	r.Add("abc")
+	if !r.Contains("abc") {
+		t.Errorf("Contains(abc) = false, want true")
+	}
+
+	r.Add("def")
+	r.Add("ghi") // evicts "abc", the oldest entry
+
+	if r.Contains("abc") {
+		t.Errorf("Contains(abc) = true, want false after eviction")
+	}
+	if !r.Contains("def") || !r.Contains("ghi") {
+		t.Errorf("Contains() = false for entries still within capacity")
+	}
+}
+
+// TestGenerateShortURLAvoiding_NeverSkipsValidSlug asserts the bias never blocks slug
+// generation: even when every candidate collides with the avoid set, a slug of the
+// requested length is still returned instead of being incorrectly withheld.
+func TestGenerateShortURLAvoiding_NeverSkipsValidSlug(t *testing.T) {
+	avoid := newRecentSlugs(recentSlugsCapacity)
+
+	for i := 0; i < 1000; i++ {
+		slug, err := generateShortURLAvoiding(defaultShortURLLen, defaultRandGenerateSymbols, avoid)
+		if err != nil {
+			t.Fatalf("generateShortURLAvoiding() unexpected error = %v", err)
+		}
+		if len(slug) != defaultShortURLLen {
+			t.Fatalf("generateShortURLAvoiding() len = %d, want %d", len(slug), defaultShortURLLen)
+		}
+		avoid.Add(slug)
+	}
+}
+
+// BenchmarkGenerateShortURL_WithAndWithoutAvoidance compares plain generation against the
+// recent-slugs-biased variant under a deliberately small slug length, where collisions
+// with the avoid set are common. Run with -benchmem to see allocation cost of the bias.
+func BenchmarkGenerateShortURL_WithoutAvoidance(b *testing.B) {
+	const smallLen = 2 // small alphabet space forces frequent collisions
+
+	for i := 0; i < b.N; i++ {
+		if _, err := generateShortURL(smallLen, defaultRandGenerateSymbols); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+func BenchmarkGenerateShortURL_WithAvoidance(b *testing.B) {
+	const smallLen = 2
+
+	avoid := newRecentSlugs(recentSlugsCapacity)
+	for i := 0; i < b.N; i++ {
+		slug, err := generateShortURLAvoiding(smallLen, defaultRandGenerateSymbols, avoid)
+		if err != nil {
+			b.Fatal(err)
+		}
+		avoid.Add(slug)
+	}
+}
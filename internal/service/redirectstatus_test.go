@@ -0,0 +1,68 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/ar4ie13/shortener/internal/myerrors"
+	"github.com/ar4ie13/shortener/internal/repository/memory"
+	"github.com/google/uuid"
+	"github.com/rs/zerolog"
+)
+
+func TestService_SaveURL_RedirectStatusOverride(t *testing.T) {
+	repo := memory.NewMemStorage()
+	srv := NewService(repo, zerolog.Nop(), false, time.Second, "", 0, nil, 0, 0, 0, 0, nil, 0, false, 0, false, zerolog.Nop(), 0, "", 0, 0, nil, nil, 0)
+	defer srv.Close()
+
+	userUUID := uuid.New()
+
+	slug, err := srv.SaveURL(context.Background(), userUUID, "https://example.com/one", "", "", "", "", false, 301)
+	if err != nil {
+		t.Fatalf("SaveURL() unexpected error = %v", err)
+	}
+
+	status, err := srv.GetRedirectStatus(context.Background(), slug)
+	if err != nil {
+		t.Fatalf("GetRedirectStatus() unexpected error = %v", err)
+	}
+	if status != 301 {
+		t.Errorf("GetRedirectStatus() = %d, want 301", status)
+	}
+}
+
+func TestService_GetRedirectStatus_DefaultsToZero(t *testing.T) {
+	repo := memory.NewMemStorage()
+	srv := NewService(repo, zerolog.Nop(), false, time.Second, "", 0, nil, 0, 0, 0, 0, nil, 0, false, 0, false, zerolog.Nop(), 0, "", 0, 0, nil, nil, 0)
+	defer srv.Close()
+
+	userUUID := uuid.New()
+
+	slug, err := srv.SaveURL(context.Background(), userUUID, "https://example.com/one", "", "", "", "", false, 0)
+	if err != nil {
+		t.Fatalf("SaveURL() unexpected error = %v", err)
+	}
+
+	status, err := srv.GetRedirectStatus(context.Background(), slug)
+	if err != nil {
+		t.Fatalf("GetRedirectStatus() unexpected error = %v", err)
+	}
+	if status != 0 {
+		t.Errorf("GetRedirectStatus() = %d, want 0", status)
+	}
+}
+
+func TestService_SaveURL_InvalidRedirectStatusRejected(t *testing.T) {
+	repo := memory.NewMemStorage()
+	srv := NewService(repo, zerolog.Nop(), false, time.Second, "", 0, nil, 0, 0, 0, 0, nil, 0, false, 0, false, zerolog.Nop(), 0, "", 0, 0, nil, nil, 0)
+	defer srv.Close()
+
+	userUUID := uuid.New()
+
+	_, err := srv.SaveURL(context.Background(), userUUID, "https://example.com/one", "", "", "", "", false, 418)
+	if !errors.Is(err, myerrors.ErrInvalidRedirectStatus) {
+		t.Errorf("SaveURL() error = %v, want %v", err, myerrors.ErrInvalidRedirectStatus)
+	}
+}
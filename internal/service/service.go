@@ -2,86 +2,553 @@ package service
 
 import (
 	"context"
+	"crypto/rand"
 	"errors"
 	"fmt"
-	"math/rand"
+	"math/big"
+	"net/http"
 	"net/url"
+	"sort"
 	"strings"
 	"sync"
+	"sync/atomic"
 	"time"
 
+	"github.com/ar4ie13/shortener/internal/clock"
+	"github.com/ar4ie13/shortener/internal/metrics"
 	"github.com/ar4ie13/shortener/internal/model"
 	"github.com/ar4ie13/shortener/internal/myerrors"
+	"github.com/ar4ie13/shortener/internal/requestlog"
 	"github.com/google/uuid"
 	"github.com/rs/zerolog"
+	"golang.org/x/crypto/bcrypt"
 )
 
 const (
-	randGenerateSymbols  = "abcdefghijklmnopqrstuvwxyzABCDEFGHIJKLMNOPQRSTUVWXYZ"
-	shortURLLen          = 8
-	timeToCollectDeleted = 2 * time.Second
+	defaultRandGenerateSymbols = "abcdefghijklmnopqrstuvwxyzABCDEFGHIJKLMNOPQRSTUVWXYZ0123456789"
+	defaultShortURLLen         = 8
+	timeToCollectDeleted       = 2 * time.Second
+	defaultDeleteFlushTime     = 5 * time.Second
+	defaultSweepLimit          = 1000
+	defaultPurgeBatchSize      = 1000
+	defaultTopHostsLimit       = 20
+	maxSlugAutoExtend          = 5
+	deleteChanCapacity         = 10000
+	defaultDeleteWorkers       = 4
 )
 
-// Repository interface used to interact with repository package to store or retrieve values
-type Repository interface {
-	GetURL(ctx context.Context, shortURL string) (string, error)
+// URLReader is the read-only subset of Repository used to resolve short URLs and report on
+// the store's contents, satisfied by a backend that only needs to serve reads, e.g. a
+// postgres read replica
+type URLReader interface {
+	// GetURL returns the destination for shortURL. When requireOwnership is true, it returns
+	// myerrors.ErrNotFound unless userUUID owns shortURL.
+	GetURL(ctx context.Context, userUUID uuid.UUID, shortURL string, requireOwnership bool) (string, error)
+	// GetURLs resolves many slugs in one call, returning one model.URLExpansion per entry in
+	// shortURLs, in the same order, each reporting either its destination or why it could not
+	// be resolved. requireOwnership has the same meaning as in GetURL.
+	GetURLs(ctx context.Context, userUUID uuid.UUID, shortURLs []string, requireOwnership bool) ([]model.URLExpansion, error)
 	GetShortURL(ctx context.Context, originalURL string) (string, error)
-	Save(ctx context.Context, userUUID uuid.UUID, shortURL string, url string) error
-	SaveBatch(ctx context.Context, userUUID uuid.UUID, batch []model.URL) error
-	GetUserShortURLs(ctx context.Context, userUUID uuid.UUID) (map[string]string, error)
-	DeleteUserShortURLs(ctx context.Context, shortURLsToDelete map[uuid.UUID][]string) error
+	// GetPasswordHash returns the bcrypt hash guarding shortURL, or "" if it is not
+	// password-protected
+	GetPasswordHash(ctx context.Context, shortURL string) (string, error)
+	// GetRedirectStatus returns the per-link redirect status override for shortURL, or 0 if
+	// it has none, in which case the caller's own configured default applies
+	GetRedirectStatus(ctx context.Context, shortURL string) (int, error)
+	// TopHosts returns the n hosts with the most non-deleted short URLs, ordered by count descending
+	TopHosts(ctx context.Context, n int) ([]model.HostCount, error)
+	// Stats returns the total number of non-deleted short URLs and the total number of
+	// distinct users that have ever saved one
+	Stats(ctx context.Context) (model.Stats, error)
+}
+
+// URLWriter is the subset of Repository used to create and update short URLs, satisfied by a
+// backend that accepts writes
+type URLWriter interface {
+	// Save creates a new short URL record. On success it returns "", nil. On a duplicate
+	// original URL it returns myerrors.ErrURLExist; if the backend can identify the already-saved
+	// slug as part of the same write (e.g. an upsert RETURNING clause) it returns that slug
+	// instead of "", letting the caller skip a separate GetShortURL lookup.
+	Save(ctx context.Context, userUUID uuid.UUID, shortURL string, url string, referer string, userAgent string, expiresAt time.Time, createdAt time.Time) (string, error)
+	// SaveBatch saves batch in a single transaction. The returned slice is aligned with batch:
+	// an empty entry means that row was written under its requested short URL; a non-empty
+	// entry is the short URL originalURL was already saved under, either before this call or
+	// from an earlier entry in the same batch, and that row was not written, letting the caller
+	// report it as a conflict instead of failing the whole batch.
+	SaveBatch(ctx context.Context, userUUID uuid.UUID, batch []model.URL) ([]string, error)
+	// UpdateURL changes the destination for shortURL, owned by userUUID, to newURL. It returns
+	// myerrors.ErrNotFound if shortURL does not exist and myerrors.ErrForbidden if it exists
+	// but is not owned by userUUID. updatedAt is stamped as the time of this change.
+	UpdateURL(ctx context.Context, userUUID uuid.UUID, shortURL string, newURL string, updatedAt time.Time) error
+	// NextSequence returns the next value of a monotonically increasing sequence, starting at
+	// 1, used by the sequential slug generation strategy
+	NextSequence(ctx context.Context) (int64, error)
+	// SetPasswordedSlug stores passwordHash as the bcrypt hash guarding shortURL
+	SetPasswordedSlug(ctx context.Context, shortURL string, passwordHash string) error
+	// SetRedirectStatus stores status as the per-link override of the HTTP status used when
+	// redirecting shortURL
+	SetRedirectStatus(ctx context.Context, shortURL string, status int) error
+	// BackfillHosts computes and persists the host for up to limit rows missing it, returning
+	// how many rows were backfilled
+	BackfillHosts(ctx context.Context, limit int) (int, error)
+}
+
+// UserURLStore is the subset of Repository used to list a user's own short URLs and manage
+// their API keys
+type UserURLStore interface {
+	GetUserShortURLs(ctx context.Context, userUUID uuid.UUID) ([]model.URL, error)
+	// SaveAPIKey stores a new API key record, keyed by the sha256 hash of its plaintext form
+	SaveAPIKey(ctx context.Context, userUUID uuid.UUID, keyHash string, createdAt time.Time) error
+	// ResolveAPIKey returns the UserUUID owning keyHash, or myerrors.ErrNotFound if it does
+	// not exist or has been revoked
+	ResolveAPIKey(ctx context.Context, keyHash string) (uuid.UUID, error)
+	// RevokeAPIKey marks keyHash as revoked. It returns myerrors.ErrNotFound if keyHash does
+	// not exist and myerrors.ErrForbidden if it exists but is not owned by userUUID.
+	RevokeAPIKey(ctx context.Context, userUUID uuid.UUID, keyHash string) error
+}
+
+// Deleter is the subset of Repository used to remove or expire short URLs, whether requested
+// by a user or run as background maintenance
+type Deleter interface {
+	// DeleteUserShortURLs soft-deletes the given slugs, stamping deletedAt as their deletion time
+	DeleteUserShortURLs(ctx context.Context, shortURLsToDelete map[uuid.UUID][]string, deletedAt time.Time) error
+	// SweepExpired soft-deletes up to limit links whose expiry is before the given time,
+	// returning the number of links swept
+	SweepExpired(ctx context.Context, before time.Time, limit int) (int, error)
+	// PurgeDeleted permanently removes up to limit already soft-deleted rows whose deletion
+	// time is before the given time, returning how many rows were removed
+	PurgeDeleted(ctx context.Context, before time.Time, limit int) (int, error)
+}
+
+// Repository interface used to interact with repository package to store or retrieve values.
+// It composes URLReader, URLWriter, UserURLStore and Deleter so a backend that supports only
+// some of those roles (e.g. a read-only replica) can still be used anywhere one of the
+// narrower interfaces is expected, while Service itself is handed a backend that supports all
+// of them.
+type Repository interface {
+	URLReader
+	URLWriter
+	UserURLStore
+	Deleter
+	// Ping reports whether the backend is reachable; memory/file backends have nothing to
+	// check and always return nil
+	Ping(ctx context.Context) error
+	// Verify checks the repository's stored data for internal inconsistencies, returning a
+	// human-readable description of each one found. A nil or empty slice means no
+	// inconsistencies were found.
+	Verify(ctx context.Context) ([]string, error)
+	// Close releases any resources held by the backend, e.g. a postgres connection pool;
+	// memory/file backends have nothing to release and always return nil. Callers should
+	// call this only after Service.Close has finished draining pending work against the
+	// repository.
+	Close() error
 }
 
 // Service is a main object of the package that implements Repository interface
 type Service struct {
-	repo         Repository
-	toDeleteChan []chan map[uuid.UUID][]string
-	zlog         zerolog.Logger
+	repo              Repository
+	deleteChan        chan deleteBatch
+	deleteWG          sync.WaitGroup
+	deleteJobs        *deleteJobTracker
+	zlog              zerolog.Logger
+	recentSlugs       *recentSlugs
+	slugGen           SlugGenerator
+	stop              chan struct{}
+	ctx               context.Context
+	cancel            context.CancelFunc
+	flushTimeout      time.Duration
+	alphabet          string
+	slugLength        int
+	clock             clock.Clock
+	linkTTL           time.Duration
+	sweepLimit        int
+	sweepStop         chan struct{}
+	sweepDone         chan struct{}
+	deleteGracePeriod time.Duration
+	httpsUpgradeHosts map[string]struct{}
+	deleteQueueLimit  int
+	pendingDeletes    int64
+	autoExtendSlug    bool
+	anonymousLinkTTL  time.Duration
+	privateLinks      bool
+	dedup             metrics.Dedup
+	auditLog          zerolog.Logger
+	urlCache          *urlCache
+	cacheStats        metrics.Cache
+	validator         Validator
+	trackingParams    map[string]struct{}
+	events            *eventBus
 }
 
-// NewService is a constructor for Service object
-func NewService(r Repository, zlog zerolog.Logger) *Service {
+// NewService is a constructor for Service object. avoidRecentSlugs enables biasing slug
+// generation away from a small bounded set of recently generated slugs. flushTimeout bounds
+// each periodic flush of the delete worker; a non-positive value falls back to a default.
+// alphabet and slugLength configure slug generation; an empty alphabet or a non-positive
+// slugLength falls back to the package default. clk provides the current time for TTL
+// expiry and sweeping; linkTTL, if positive, is applied to every newly saved link.
+// sweepInterval, if positive, starts a background sweeper that soft-deletes expired links
+// every interval, processing at most sweepLimit (or defaultSweepLimit, if non-positive)
+// links per sweep. deleteGracePeriod, if positive, keeps a soft-deleted slug redirecting
+// for that long after deletion before GetURL reports it as gone.
+//
+// slugLength only sizes newly generated slugs; GetURL never filters by length, so changing
+// it on an existing deployment does not affect the resolvability of slugs already saved
+// under a different length. httpsUpgradeHosts, if non-empty, lists hostnames whose http://
+// destinations are upgraded to https:// before saving and deduplicating. deleteQueueLimit,
+// if positive, caps the number of slugs awaiting asynchronous deletion; once reached,
+// SendShortURLForDelete rejects new work with myerrors.ErrDeleteQueueFull instead of
+// growing the backlog further. autoExtendSlug, when true, retries a generated-slug save at
+// slugLength+1, +2, and so on (up to maxSlugAutoExtend characters longer) if every attempt at
+// the current length collides, guaranteeing success even in a nearly-full keyspace instead of
+// surfacing myerrors.ErrShortURLExist to the caller.
+// anonymousLinkTTL, if positive, is applied instead of linkTTL to links saved by an identity
+// that has not been claimed via POST /api/user/claim; a non-positive value falls back to
+// linkTTL for those links too. privateLinks, when true, restricts GetURL to the slug's owner,
+// returning myerrors.ErrNotFound to every other caller instead of resolving the slug publicly.
+// auditLog receives one structured entry per slug soft-deleted and one aggregate entry per
+// purge batch, independent of zlog's operational logging level, so deletions and purges can
+// be reviewed for compliance even when zlog is configured at a level that would drop them.
+// deleteWorkers sets how many goroutines concurrently drain the asynchronous delete queue; a
+// non-positive value falls back to defaultDeleteWorkers.
+// slugStrategy selects how newly generated slugs are produced: "random" draws from
+// crypto/rand (optionally avoiding avoidRecentSlugs), "sequential" base-encodes successive
+// values from r's NextSequence, and "hash" derives a slug deterministically from the URL
+// being shortened. An empty or unrecognized value falls back to "random".
+// cacheSize and cacheTTL, if both positive, enable an in-process LRU cache of up to cacheSize
+// GetURL resolutions in front of the repository, each valid for cacheTTL; either being
+// non-positive disables the cache. The cache is never consulted when privateLinks is set,
+// since a cached resolution cannot carry per-caller ownership checks.
+// validator, if non-nil, is consulted by SaveURL and SaveBatch before a new URL is
+// shortened; a rejection is reported as myerrors.ErrURLBlocked. A nil validator accepts
+// every URL.
+// trackingParams, if non-empty, lists query parameter names (e.g. "utm_source", "gclid")
+// that SaveURL strips before deduplicating and slugging a URL, in addition to the
+// unconditional canonicalization SaveURL always applies: lowercasing the host, stripping a
+// port matching the scheme's default, and cleaning "." / ".." path segments. Together these
+// mean two URLs that only differ in case, default port, path traversal, or a stripped
+// tracking parameter resolve to the same slug instead of each minting a new one.
+// eventBufferSize sizes the per-subscriber channel backing Subscribe; a non-positive value
+// falls back to defaultEventBufferSize. A subscriber that cannot keep up has events dropped
+// for it rather than slowing down SaveURL, SendShortURLForDelete, or GetURL for anyone else.
+func NewService(r Repository, zlog zerolog.Logger, avoidRecentSlugs bool, flushTimeout time.Duration, alphabet string, slugLength int, clk clock.Clock, linkTTL time.Duration, sweepInterval time.Duration, sweepLimit int, deleteGracePeriod time.Duration, httpsUpgradeHosts []string, deleteQueueLimit int, autoExtendSlug bool, anonymousLinkTTL time.Duration, privateLinks bool, auditLog zerolog.Logger, deleteWorkers int, slugStrategy string, cacheSize int, cacheTTL time.Duration, validator Validator, trackingParams []string, eventBufferSize int) *Service {
+	if flushTimeout <= 0 {
+		flushTimeout = defaultDeleteFlushTime
+	}
+
+	if alphabet == "" {
+		alphabet = defaultRandGenerateSymbols
+	}
+
+	if slugLength <= 0 {
+		slugLength = defaultShortURLLen
+	}
+
+	if clk == nil {
+		clk = clock.RealClock{}
+	}
+
+	if sweepLimit <= 0 {
+		sweepLimit = defaultSweepLimit
+	}
+
+	if deleteWorkers <= 0 {
+		deleteWorkers = defaultDeleteWorkers
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
 	srv := &Service{
-		repo:         r,
-		toDeleteChan: []chan map[uuid.UUID][]string{},
-		zlog:         zlog,
+		repo:              r,
+		deleteChan:        make(chan deleteBatch, deleteChanCapacity),
+		deleteJobs:        newDeleteJobTracker(deleteJobTrackerCapacity),
+		zlog:              zlog,
+		stop:              make(chan struct{}),
+		ctx:               ctx,
+		cancel:            cancel,
+		flushTimeout:      flushTimeout,
+		alphabet:          alphabet,
+		slugLength:        slugLength,
+		clock:             clk,
+		linkTTL:           linkTTL,
+		sweepLimit:        sweepLimit,
+		deleteGracePeriod: deleteGracePeriod,
+		deleteQueueLimit:  deleteQueueLimit,
+		autoExtendSlug:    autoExtendSlug,
+		anonymousLinkTTL:  anonymousLinkTTL,
+		privateLinks:      privateLinks,
+		auditLog:          auditLog,
+		urlCache:          newURLCache(cacheSize, cacheTTL, clk),
+		validator:         validator,
+		events:            newEventBus(eventBufferSize),
+	}
+	if len(httpsUpgradeHosts) > 0 {
+		srv.httpsUpgradeHosts = make(map[string]struct{}, len(httpsUpgradeHosts))
+		for _, host := range httpsUpgradeHosts {
+			srv.httpsUpgradeHosts[host] = struct{}{}
+		}
+	}
+	if avoidRecentSlugs {
+		srv.recentSlugs = newRecentSlugs(recentSlugsCapacity)
+	}
+	if len(trackingParams) > 0 {
+		srv.trackingParams = make(map[string]struct{}, len(trackingParams))
+		for _, param := range trackingParams {
+			srv.trackingParams[param] = struct{}{}
+		}
+	}
+
+	switch slugStrategy {
+	case "sequential":
+		srv.slugGen = &sequentialSlugGenerator{source: r, alphabet: alphabet}
+	case "hash":
+		srv.slugGen = &hashSlugGenerator{}
+	default:
+		srv.slugGen = &randomSlugGenerator{alphabet: alphabet, avoid: srv.recentSlugs}
+	}
+
+	srv.deleteWG.Add(deleteWorkers)
+	for i := 0; i < deleteWorkers; i++ {
+		go srv.deleteWorker()
+	}
+
+	if sweepInterval > 0 {
+		srv.sweepStop = make(chan struct{})
+		srv.sweepDone = make(chan struct{})
+		go srv.sweepExpiredLinks(sweepInterval)
 	}
-	go srv.deleteShortURLs()
+
 	return srv
 }
 
-// GetURL method gets URL by provided id
+// Close cancels the service context to abort any in-progress flush, signals the delete
+// workers to stop, waits for each of them to perform a final flush of any pending deletions,
+// and returns once they have all exited. If the sweeper is running, it is stopped too.
+func (s *Service) Close() error {
+	s.cancel()
+	close(s.stop)
+	s.deleteWG.Wait()
+	if s.sweepStop != nil {
+		close(s.sweepStop)
+		<-s.sweepDone
+	}
+	return nil
+}
+
+// Subscribe registers a new listener for userUUID's link lifecycle events (create, delete,
+// redirect) and returns a channel of them alongside an unsubscribe function the caller must
+// call exactly once, when it stops listening, to release the channel.
+func (s *Service) Subscribe(userUUID uuid.UUID) (<-chan model.Event, func()) {
+	return s.events.subscribe(userUUID)
+}
+
+// GetURL method gets URL by provided id. If the slug was soft-deleted within the configured
+// delete grace period, the original URL is still returned alongside
+// myerrors.ErrShortURLGracePeriod so the caller can redirect once more before it expires. In
+// private-links mode (see NewService), a shortURL not owned by userUUID is reported as
+// myerrors.ErrNotFound, indistinguishable from a slug that does not exist.
+// GetURL resolves shortURL and, on success, publishes an EventRedirect to Subscribe(userUUID).
+// In privateLinks mode userUUID is the verified owner, so the event reaches the owner's own
+// dashboard; outside privateLinks mode ownership is never looked up here, so the event is
+// published under the requester's own UserUUID instead of the link's actual owner, which is
+// only useful when the owner is clicking their own link from another session.
 func (s *Service) GetURL(ctx context.Context, userUUID uuid.UUID, shortURL string) (string, error) {
 	if shortURL == "" {
 		return "", myerrors.ErrEmptyID
 	}
 
-	getURL, err := s.repo.GetURL(ctx, shortURL)
-	if getURL == "" || err != nil {
+	if !s.privateLinks {
+		if cached, ok := s.urlCache.Get(shortURL); ok {
+			s.cacheStats.IncHit()
+			s.events.publish(model.Event{Type: model.EventRedirect, UserUUID: userUUID, ShortURL: shortURL, OriginalURL: cached})
+			return cached, nil
+		}
+		s.cacheStats.IncMiss()
+	}
+
+	getURL, err := s.repo.GetURL(ctx, userUUID, shortURL, s.privateLinks)
+	if err != nil {
+		var deletedErr *myerrors.DeletedURLError
+		if errors.As(err, &deletedErr) && s.deleteGracePeriod > 0 && s.clock.Now().Before(deletedErr.DeletedAt.Add(s.deleteGracePeriod)) {
+			return deletedErr.OriginalURL, myerrors.ErrShortURLGracePeriod
+		}
 		return "", fmt.Errorf("failed to get URL: %w", err)
 	}
+	if getURL == "" {
+		return "", fmt.Errorf("failed to get URL: %w", myerrors.ErrNotFound)
+	}
+
+	if !s.privateLinks {
+		s.urlCache.Set(shortURL, getURL)
+	}
 
+	s.events.publish(model.Event{Type: model.EventRedirect, UserUUID: userUUID, ShortURL: shortURL, OriginalURL: getURL})
 	return getURL, nil
 }
 
-// GetUserShortURLs method gets all shortURLs and URL saved by user
-func (s *Service) GetUserShortURLs(ctx context.Context, userUUID uuid.UUID) (map[string]string, error) {
+// InspectURL returns shortURL's destination and deletion state without resolving it as a
+// redirect. Unlike GetURL, a soft-deleted slug still within the configured delete grace
+// period is reported as deleted (isDeleted=true, deletedAt set) rather than silently passed
+// through. It returns myerrors.ErrNotFound if shortURL does not exist, has already been
+// purged, or (in private-links mode) is not owned by userUUID.
+func (s *Service) InspectURL(ctx context.Context, userUUID uuid.UUID, shortURL string) (originalURL string, isDeleted bool, deletedAt time.Time, err error) {
+	if shortURL == "" {
+		return "", false, time.Time{}, myerrors.ErrEmptyID
+	}
+
+	originalURL, err = s.repo.GetURL(ctx, userUUID, shortURL, s.privateLinks)
+	if err != nil {
+		var deletedErr *myerrors.DeletedURLError
+		if errors.As(err, &deletedErr) {
+			return deletedErr.OriginalURL, true, deletedErr.DeletedAt, nil
+		}
+		return "", false, time.Time{}, fmt.Errorf("failed to inspect URL: %w", err)
+	}
+	if originalURL == "" {
+		return "", false, time.Time{}, fmt.Errorf("failed to inspect URL: %w", myerrors.ErrNotFound)
+	}
+
+	return originalURL, false, time.Time{}, nil
+}
+
+// InspectURLs is the batch form of InspectURL: it resolves many slugs in one call, returning
+// one model.URLExpansion per entry in shortURLs, in the same order. An empty shortURL in the
+// input is reported as myerrors.ErrEmptyID in that entry's Err field rather than failing the
+// whole batch.
+func (s *Service) InspectURLs(ctx context.Context, userUUID uuid.UUID, shortURLs []string) ([]model.URLExpansion, error) {
+	toResolve := make([]string, 0, len(shortURLs))
+	for _, shortURL := range shortURLs {
+		if shortURL != "" {
+			toResolve = append(toResolve, shortURL)
+		}
+	}
+
+	resolved, err := s.repo.GetURLs(ctx, userUUID, toResolve, s.privateLinks)
+	if err != nil {
+		return nil, fmt.Errorf("failed to inspect URLs: %w", err)
+	}
+
+	resolvedByShortURL := make(map[string]model.URLExpansion, len(resolved))
+	for _, exp := range resolved {
+		resolvedByShortURL[exp.ShortURL] = exp
+	}
+
+	result := make([]model.URLExpansion, len(shortURLs))
+	for i, shortURL := range shortURLs {
+		if shortURL == "" {
+			result[i] = model.URLExpansion{ShortURL: shortURL, Err: myerrors.ErrEmptyID.Error()}
+			continue
+		}
+		result[i] = resolvedByShortURL[shortURL]
+	}
+
+	return result, nil
+}
+
+// Ping reports whether the backing repository is reachable
+func (s *Service) Ping(ctx context.Context) error {
+	return s.repo.Ping(ctx)
+}
+
+// GetUserShortURLs returns userUUID's non-deleted short URLs, sorted by sortBy ("short_url"
+// or "created_at"; see handlers.parseSort) for a stable order across repeated calls and
+// across backends, none of which guarantee any particular order on their own. Rows saved
+// before CreatedAt was tracked sort as the zero time, i.e. first. offset skips that many
+// URLs from the start of the sorted result before limit caps how many are returned; limit
+// of 0 means unlimited.
+func (s *Service) GetUserShortURLs(ctx context.Context, userUUID uuid.UUID, sortBy string, limit int, offset int) ([]model.URL, error) {
 	result, err := s.repo.GetUserShortURLs(ctx, userUUID)
 	if err != nil {
 		return nil, fmt.Errorf("failed to get short urls: %w", err)
 	}
 
+	if sortBy == "created_at" {
+		sort.Slice(result, func(i, j int) bool {
+			if !result[i].CreatedAt.Equal(result[j].CreatedAt) {
+				return result[i].CreatedAt.Before(result[j].CreatedAt)
+			}
+			return result[i].ShortURL < result[j].ShortURL
+		})
+	} else {
+		sort.Slice(result, func(i, j int) bool {
+			return result[i].ShortURL < result[j].ShortURL
+		})
+	}
+
+	if offset > 0 {
+		if offset >= len(result) {
+			return []model.URL{}, nil
+		}
+		result = result[offset:]
+	}
+	if limit > 0 && limit < len(result) {
+		result = result[:limit]
+	}
+
 	return result, nil
 }
 
-// SaveURL generates shortURL for non-existent URL and stores it in the Repository
-func (s *Service) SaveURL(ctx context.Context, userUUID uuid.UUID, urlLink string) (slug string, err error) {
+// UpdateURL changes the destination behind shortURL, owned by userUUID, to newURL. It
+// returns myerrors.ErrNotFound if shortURL does not exist and myerrors.ErrForbidden if it
+// exists but is not owned by userUUID. On success, any cached resolution for shortURL is
+// invalidated so the next GetURL reflects the new destination immediately.
+func (s *Service) UpdateURL(ctx context.Context, userUUID uuid.UUID, shortURL string, newURL string) error {
+	if shortURL == "" {
+		return myerrors.ErrEmptyID
+	}
+
+	newURL = strings.TrimRight(newURL, "/")
+	if newURL == "" {
+		return myerrors.ErrEmptyURL
+	}
+
+	parsedURL, err := url.Parse(newURL)
+	if err != nil {
+		return myerrors.ErrInvalidURLFormat
+	}
+	if parsedURL.Scheme != "http" && parsedURL.Scheme != "https" {
+		return myerrors.ErrWrongHTTPScheme
+	}
+	if parsedURL.Host == "" {
+		return myerrors.ErrMustIncludeHost
+	}
+
+	if parsedURL.Scheme == "http" {
+		if _, ok := s.httpsUpgradeHosts[parsedURL.Hostname()]; ok {
+			parsedURL.Scheme = "https"
+			newURL = parsedURL.String()
+		}
+	}
+
+	if err := s.repo.UpdateURL(ctx, userUUID, shortURL, newURL, s.clock.Now()); err != nil {
+		return fmt.Errorf("failed to update URL: %w", err)
+	}
+
+	s.urlCache.Invalidate(shortURL)
+
+	return nil
+}
+
+// SaveURL generates shortURL for non-existent URL and stores it in the Repository.
+// Before saving, urlLink is canonicalized (see NewService's trackingParams) so the same
+// logical destination reached via a different case, port, path traversal, or tracking
+// parameter still dedups and slugs identically.
+// customAlias, if non-empty, is used as the shortURL instead of a generated one; unlike a
+// generated slug, a taken customAlias is not retried and is reported as myerrors.ErrShortURLExist.
+// password, if non-empty, is hashed and stored so GET /{id} requires it before redirecting.
+// anonymous marks the saving identity as not yet claimed via POST /api/user/claim, subjecting
+// the link to anonymousLinkTTL instead of linkTTL.
+// redirectStatus, if non-zero, overrides the operator's configured default HTTP status for
+// GET/HEAD /{id} redirects to this link; it must be 301, 302, 307 or 308.
+func (s *Service) SaveURL(ctx context.Context, userUUID uuid.UUID, urlLink string, referer string, userAgent string, customAlias string, password string, anonymous bool, redirectStatus int) (slug string, err error) {
 	urlLink = strings.TrimRight(urlLink, "/")
 
 	if urlLink == "" {
 		return "", myerrors.ErrEmptyURL
 	}
 
+	if !isValidRedirectStatus(redirectStatus) {
+		return "", myerrors.ErrInvalidRedirectStatus
+	}
+
 	// Validate the URL format
 	parsedURL, err := url.Parse(urlLink)
 	if err != nil {
@@ -98,8 +565,74 @@ func (s *Service) SaveURL(ctx context.Context, userUUID uuid.UUID, urlLink strin
 		return "", myerrors.ErrMustIncludeHost
 	}
 
+	if parsedURL.Scheme == "http" {
+		if _, ok := s.httpsUpgradeHosts[parsedURL.Hostname()]; ok {
+			parsedURL.Scheme = "https"
+		}
+	}
+
+	urlLink = canonicalizeURL(parsedURL, s.trackingParams)
+
+	if s.validator != nil {
+		if err = s.validator.Validate(ctx, urlLink); err != nil {
+			return "", fmt.Errorf("%w: %s", myerrors.ErrURLBlocked, err)
+		}
+	}
+
+	expiresAt := s.expiryFor(anonymous)
+	createdAt := s.clock.Now()
+
+	if customAlias != "" {
+		var existingSlug string
+		existingSlug, err = s.repo.Save(ctx, userUUID, customAlias, urlLink, referer, userAgent, expiresAt, createdAt)
+		if err == nil {
+			if s.recentSlugs != nil {
+				s.recentSlugs.Add(customAlias)
+			}
+			if err = s.setPassword(ctx, customAlias, password); err != nil {
+				return "", err
+			}
+			if err = s.setRedirectStatus(ctx, customAlias, redirectStatus); err != nil {
+				return "", err
+			}
+			s.dedup.IncCreated()
+			s.events.publish(model.Event{Type: model.EventCreated, UserUUID: userUUID, ShortURL: customAlias, OriginalURL: urlLink})
+			return customAlias, nil
+		}
+
+		if errors.Is(err, myerrors.ErrURLExist) {
+			if existingSlug == "" {
+				existingSlug, err = s.repo.GetShortURL(ctx, urlLink)
+				if err != nil {
+					return "", myerrors.ErrNotFound
+				}
+			}
+			s.dedup.IncReused()
+			return existingSlug, myerrors.ErrURLExist
+		}
+
+		return "", err
+	}
+
+	slug, err = s.trySaveGeneratedSlug(ctx, userUUID, urlLink, referer, userAgent, password, redirectStatus, expiresAt, createdAt, s.slugLength)
+	for length := s.slugLength + 1; s.autoExtendSlug && errors.Is(err, myerrors.ErrShortURLExist) && length <= s.slugLength+maxSlugAutoExtend; length++ {
+		requestlog.FromContext(ctx, s.zlog).Debug().Msgf("collision retries exhausted at slug length %d, extending to %d", length-1, length)
+		slug, err = s.trySaveGeneratedSlug(ctx, userUUID, urlLink, referer, userAgent, password, redirectStatus, expiresAt, createdAt, length)
+	}
+	return slug, err
+}
+
+// trySaveGeneratedSlug generates a random slug of the given length and attempts to save it,
+// retrying up to 3 times on a colliding slug. It returns the saved slug and nil on success, the
+// existing slug and myerrors.ErrURLExist if urlLink was already shortened under a different
+// slug, or "" and an error (myerrors.ErrShortURLExist after exhausting retries) otherwise.
+func (s *Service) trySaveGeneratedSlug(ctx context.Context, userUUID uuid.UUID, urlLink string, referer string, userAgent string, password string, redirectStatus int, expiresAt time.Time, createdAt time.Time, length int) (slug string, err error) {
 	for attempt := 1; attempt <= 3; attempt++ {
-		slug, err = generateShortURL(shortURLLen)
+		seed := urlLink
+		if attempt > 1 {
+			seed = fmt.Sprintf("%s#%d", urlLink, attempt)
+		}
+		slug, err = s.slugGen.Generate(ctx, seed, length)
 
 		if err != nil {
 			if attempt == 3 {
@@ -108,38 +641,233 @@ func (s *Service) SaveURL(ctx context.Context, userUUID uuid.UUID, urlLink strin
 			continue
 		}
 
-		err = s.repo.Save(ctx, userUUID, slug, urlLink)
+		var existingSlug string
+		existingSlug, err = s.repo.Save(ctx, userUUID, slug, urlLink, referer, userAgent, expiresAt, createdAt)
 
 		if err == nil {
+			if s.recentSlugs != nil {
+				s.recentSlugs.Add(slug)
+			}
+			if err = s.setPassword(ctx, slug, password); err != nil {
+				return "", err
+			}
+			if err = s.setRedirectStatus(ctx, slug, redirectStatus); err != nil {
+				return "", err
+			}
+			s.dedup.IncCreated()
+			s.events.publish(model.Event{Type: model.EventCreated, UserUUID: userUUID, ShortURL: slug, OriginalURL: urlLink})
 			return slug, nil
 		}
 
 		if errors.Is(err, myerrors.ErrURLExist) {
-			slug, err = s.repo.GetShortURL(ctx, urlLink)
-			if err != nil {
-				return "", myerrors.ErrNotFound
+			if existingSlug == "" {
+				existingSlug, err = s.repo.GetShortURL(ctx, urlLink)
+				if err != nil {
+					return "", myerrors.ErrNotFound
+				}
 			}
-			return slug, myerrors.ErrURLExist
+			s.dedup.IncReused()
+			return existingSlug, myerrors.ErrURLExist
 		}
 
 		if attempt == 3 {
 			if errors.Is(err, myerrors.ErrShortURLExist) {
 				return "", fmt.Errorf("failed to save URL to repository: %w", err)
 			}
+		} else if errors.Is(err, myerrors.ErrShortURLExist) {
+			s.dedup.IncCollisionRetry()
 		}
 	}
 	return "", err
 }
 
-// SaveBatch saves batch of jsonl rows to the repository
-func (s *Service) SaveBatch(ctx context.Context, userUUID uuid.UUID, batch []model.URL) ([]model.URL, error) {
-	result := make([]model.URL, len(batch))
-	for i := range batch {
-		slug, err := generateShortURL(shortURLLen)
-		if err != nil {
-			return nil, fmt.Errorf("failed to generate short url: %w", err)
+// setPassword hashes password with bcrypt and stores it against slug, if password is non-empty
+func (s *Service) setPassword(ctx context.Context, slug string, password string) error {
+	if password == "" {
+		return nil
+	}
+
+	hash, err := bcrypt.GenerateFromPassword([]byte(password), bcrypt.DefaultCost)
+	if err != nil {
+		return fmt.Errorf("failed to hash password: %w", err)
+	}
+
+	if err = s.repo.SetPasswordedSlug(ctx, slug, string(hash)); err != nil {
+		return fmt.Errorf("failed to store password hash: %w", err)
+	}
+
+	return nil
+}
+
+// isValidRedirectStatus reports whether status is an acceptable redirect status override: 0
+// (unset, falls back to the caller's configured default) or one of the four standard
+// redirect statuses.
+func isValidRedirectStatus(status int) bool {
+	switch status {
+	case 0, http.StatusMovedPermanently, http.StatusFound, http.StatusTemporaryRedirect, http.StatusPermanentRedirect:
+		return true
+	default:
+		return false
+	}
+}
+
+// setRedirectStatus stores status as slug's per-link redirect status override. status == 0
+// is a no-op, since it means the caller did not ask for an override.
+func (s *Service) setRedirectStatus(ctx context.Context, slug string, status int) error {
+	if status == 0 {
+		return nil
+	}
+
+	if err := s.repo.SetRedirectStatus(ctx, slug, status); err != nil {
+		return fmt.Errorf("failed to store redirect status: %w", err)
+	}
+
+	return nil
+}
+
+// GetRedirectStatus returns shortURL's per-link redirect status override, or 0 if it has
+// none, in which case the caller should fall back to its own configured default.
+func (s *Service) GetRedirectStatus(ctx context.Context, shortURL string) (int, error) {
+	status, err := s.repo.GetRedirectStatus(ctx, shortURL)
+	if err != nil {
+		return 0, fmt.Errorf("failed to get redirect status: %w", err)
+	}
+
+	return status, nil
+}
+
+// VerifyPassword reports whether password unlocks shortURL. A slug with no stored password
+// hash is never protected, so any password (including an empty one) is accepted for it.
+func (s *Service) VerifyPassword(ctx context.Context, shortURL string, password string) (bool, error) {
+	hash, err := s.repo.GetPasswordHash(ctx, shortURL)
+	if err != nil {
+		return false, fmt.Errorf("failed to get password hash: %w", err)
+	}
+	if hash == "" {
+		return true, nil
+	}
+
+	if err = bcrypt.CompareHashAndPassword([]byte(hash), []byte(password)); err != nil {
+		if errors.Is(err, bcrypt.ErrMismatchedHashAndPassword) {
+			return false, nil
 		}
+		return false, fmt.Errorf("failed to verify password: %w", err)
+	}
 
+	return true, nil
+}
+
+// CreateAPIKey stores a new API key record for userUUID, keyed by keyHash. The caller is
+// responsible for generating and hashing the plaintext key; Service only persists the result.
+func (s *Service) CreateAPIKey(ctx context.Context, userUUID uuid.UUID, keyHash string) error {
+	if err := s.repo.SaveAPIKey(ctx, userUUID, keyHash, s.clock.Now()); err != nil {
+		return fmt.Errorf("failed to store API key: %w", err)
+	}
+
+	return nil
+}
+
+// ResolveAPIKey returns the UserUUID owning keyHash, or myerrors.ErrNotFound if it does not
+// exist or has been revoked.
+func (s *Service) ResolveAPIKey(ctx context.Context, keyHash string) (uuid.UUID, error) {
+	userUUID, err := s.repo.ResolveAPIKey(ctx, keyHash)
+	if err != nil {
+		return uuid.Nil, fmt.Errorf("failed to resolve API key: %w", err)
+	}
+
+	return userUUID, nil
+}
+
+// RevokeAPIKey marks keyHash as revoked. It returns myerrors.ErrNotFound if keyHash does
+// not exist and myerrors.ErrForbidden if it exists but is not owned by userUUID.
+func (s *Service) RevokeAPIKey(ctx context.Context, userUUID uuid.UUID, keyHash string) error {
+	if err := s.repo.RevokeAPIKey(ctx, userUUID, keyHash); err != nil {
+		return fmt.Errorf("failed to revoke API key: %w", err)
+	}
+
+	return nil
+}
+
+// TopHosts returns the n hosts with the most non-deleted short URLs, ordered by count
+// descending. If n is not positive, defaultTopHostsLimit is used instead.
+func (s *Service) TopHosts(ctx context.Context, n int) ([]model.HostCount, error) {
+	if n <= 0 {
+		n = defaultTopHostsLimit
+	}
+
+	hostCounts, err := s.repo.TopHosts(ctx, n)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get top hosts: %w", err)
+	}
+
+	return hostCounts, nil
+}
+
+// Stats returns the total number of non-deleted short URLs and the total number of distinct
+// users that have ever saved one
+func (s *Service) Stats(ctx context.Context) (model.Stats, error) {
+	stats, err := s.repo.Stats(ctx)
+	if err != nil {
+		return model.Stats{}, fmt.Errorf("failed to get stats: %w", err)
+	}
+
+	return stats, nil
+}
+
+// DedupStats returns a snapshot of how shorten requests have been resolved since startup:
+// how many created a brand-new slug, how many reused an existing one, and how many generated
+// slugs collided and had to be retried
+func (s *Service) DedupStats() metrics.DedupSnapshot {
+	return s.dedup.Snapshot()
+}
+
+// CacheStats returns a snapshot of how GetURL lookups have been resolved since startup: a
+// hit served from the in-process cache, or a miss that went to the repository
+func (s *Service) CacheStats() metrics.CacheSnapshot {
+	return s.cacheStats.Snapshot()
+}
+
+// PendingDeletes returns how many shortURLs are currently queued for asynchronous deletion but
+// have not yet been flushed to the repository
+func (s *Service) PendingDeletes() int {
+	return int(atomic.LoadInt64(&s.pendingDeletes))
+}
+
+// BackfillHosts computes and persists the host for up to limit rows missing it, returning
+// how many rows were backfilled
+func (s *Service) BackfillHosts(ctx context.Context, limit int) (int, error) {
+	backfilled, err := s.repo.BackfillHosts(ctx, limit)
+	if err != nil {
+		return 0, fmt.Errorf("failed to backfill hosts: %w", err)
+	}
+
+	return backfilled, nil
+}
+
+// VerifyIntegrity checks the repository's stored data for internal inconsistencies, returning
+// a human-readable description of each one found
+func (s *Service) VerifyIntegrity(ctx context.Context) ([]string, error) {
+	violations, err := s.repo.Verify(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to verify repository: %w", err)
+	}
+
+	return violations, nil
+}
+
+// SaveBatch saves batch of jsonl rows to the repository. When reuseOnConflict is true, a URL
+// already present in the repository is resolved to its existing short URL up front, saving a
+// round trip to the repository. Either way, a URL that turns out to already be saved when the
+// repository is written is resolved to its existing short URL and reported with Conflict set,
+// rather than failing the whole batch. anonymous has the same meaning as in SaveURL.
+func (s *Service) SaveBatch(ctx context.Context, userUUID uuid.UUID, batch []model.URL, referer string, userAgent string, reuseOnConflict bool, anonymous bool) ([]model.URL, error) {
+	expiresAt := s.expiryFor(anonymous)
+	createdAt := s.clock.Now()
+
+	result := make([]model.URL, len(batch))
+	var toSave []model.URL
+	var toSaveIdx []int
+	for i := range batch {
 		urlLink := strings.TrimRight(batch[i].OriginalURL, "/")
 
 		if urlLink == "" {
@@ -161,90 +889,289 @@ func (s *Service) SaveBatch(ctx context.Context, userUUID uuid.UUID, batch []mod
 		if parsedURL.Host == "" {
 			return nil, myerrors.ErrMustIncludeHost
 		}
+
+		if s.validator != nil {
+			if err = s.validator.Validate(ctx, urlLink); err != nil {
+				return nil, fmt.Errorf("%w: %s", myerrors.ErrURLBlocked, err)
+			}
+		}
+
+		if reuseOnConflict {
+			if existingSlug, err := s.repo.GetShortURL(ctx, urlLink); err == nil {
+				result[i] = model.URL{ShortURL: existingSlug, OriginalURL: urlLink, UUID: batch[i].UUID}
+				s.dedup.IncReused()
+				continue
+			}
+		}
+
+		slug, err := s.slugGen.Generate(ctx, urlLink, s.slugLength)
+		if err != nil {
+			return nil, fmt.Errorf("failed to generate short url: %w", err)
+		}
+
 		result[i] = model.URL{
 			ShortURL:    slug,
 			OriginalURL: urlLink,
 			UUID:        batch[i].UUID,
+			Referer:     referer,
+			UserAgent:   userAgent,
+			ExpiresAt:   expiresAt,
+			CreatedAt:   createdAt,
+			UpdatedAt:   createdAt,
 		}
+		toSave = append(toSave, result[i])
+		toSaveIdx = append(toSaveIdx, i)
 	}
 
-	err := s.repo.SaveBatch(ctx, userUUID, result)
-	if err != nil {
-		return nil, fmt.Errorf("failed to save batch: %w", err)
+	if len(toSave) > 0 {
+		existing, err := s.repo.SaveBatch(ctx, userUUID, toSave)
+		if err != nil {
+			return nil, fmt.Errorf("failed to save batch: %w", err)
+		}
+		for i, existingSlug := range existing {
+			if existingSlug != "" {
+				result[toSaveIdx[i]].ShortURL = existingSlug
+				result[toSaveIdx[i]].Conflict = true
+				s.dedup.IncReused()
+				continue
+			}
+			s.dedup.IncCreated()
+		}
 	}
 
 	return result, nil
 }
 
-// generateShortURL is a sub-function for SaveURL
-func generateShortURL(length int) (string, error) {
+// expiryFor returns the expiry time to apply to a link saved now, or the zero time if
+// the service has no configured TTL. anonymous, when true and anonymousLinkTTL is positive,
+// applies anonymousLinkTTL instead of linkTTL.
+func (s *Service) expiryFor(anonymous bool) time.Time {
+	ttl := s.linkTTL
+	if anonymous && s.anonymousLinkTTL > 0 {
+		ttl = s.anonymousLinkTTL
+	}
+	if ttl <= 0 {
+		return time.Time{}
+	}
+	return s.clock.Now().Add(ttl)
+}
+
+// sweepExpiredLinks runs as a separate goroutine and periodically soft-deletes links whose
+// TTL has elapsed, stopping cleanly once sweepStop is closed
+func (s *Service) sweepExpiredLinks(interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	defer close(s.sweepDone)
+
+	for {
+		select {
+		case <-ticker.C:
+			s.sweepOnce()
+		case <-s.sweepStop:
+			return
+		}
+	}
+}
+
+// sweepOnce soft-deletes up to sweepLimit links that have expired as of the current time
+func (s *Service) sweepOnce() {
+	ctx, cancel := context.WithTimeout(s.ctx, s.flushTimeout)
+	defer cancel()
+
+	n, err := s.repo.SweepExpired(ctx, s.clock.Now(), s.sweepLimit)
+	if err != nil {
+		s.zlog.Err(err).Msg("failed to sweep expired short urls")
+		return
+	}
+	if n > 0 {
+		s.zlog.Debug().Msgf("swept %d expired short urls", n)
+	}
+}
+
+// PurgeDeleted permanently removes already soft-deleted links whose deletion time is
+// before the given time, working in batches of batchSize (or defaultPurgeBatchSize, if
+// non-positive) with a pause between batches so a large purge does not hold the table for
+// its full duration or disrupt live traffic. It stops once no rows remain to purge or ctx
+// is cancelled, returning the total number of rows purged so far either way.
+func (s *Service) PurgeDeleted(ctx context.Context, before time.Time, batchSize int, pause time.Duration) (int, error) {
+	if batchSize <= 0 {
+		batchSize = defaultPurgeBatchSize
+	}
+
+	var total int
+	for {
+		n, err := s.repo.PurgeDeleted(ctx, before, batchSize)
+		if err != nil {
+			return total, fmt.Errorf("failed to purge deleted short urls: %w", err)
+		}
+		total += n
+
+		if n > 0 {
+			s.auditLog.Info().
+				Str("action", "purge").
+				Int("count", n).
+				Time("before", before).
+				Time("purged_at", s.clock.Now()).
+				Msg("soft-deleted short urls purged")
+		}
+
+		if n < batchSize {
+			return total, nil
+		}
+
+		if pause <= 0 {
+			continue
+		}
+
+		select {
+		case <-time.After(pause):
+		case <-ctx.Done():
+			return total, ctx.Err()
+		}
+	}
+}
+
+// generateShortURL is a sub-function for SaveURL. It draws from crypto/rand rather than
+// math/rand so generated slugs cannot be predicted or enumerated by an attacker who has
+// observed a few of them.
+func generateShortURL(length int, alphabet string) (string, error) {
 	if length <= 0 {
 		return "", myerrors.ErrShortURLLength
 	}
 
+	alphabetLen := big.NewInt(int64(len(alphabet)))
 	shortURL := make([]byte, length)
 	for i := range shortURL {
-		shortURL[i] = randGenerateSymbols[rand.Intn(len(randGenerateSymbols))]
+		n, err := rand.Int(rand.Reader, alphabetLen)
+		if err != nil {
+			return "", fmt.Errorf("failed to generate random slug character: %w", err)
+		}
+		shortURL[i] = alphabet[n.Int64()]
 	}
 
 	return string(shortURL), nil
 }
 
-// SendShortURLForDelete writes userUUID and slugs for deletion to toDeleteChan slice of channels
-func (s *Service) SendShortURLForDelete(_ context.Context, userUUID uuid.UUID, shortURLs []string) {
-	data := make(chan map[uuid.UUID][]string, 1)
-	defer close(data)
-	data <- map[uuid.UUID][]string{userUUID: shortURLs}
-	s.toDeleteChan = append(s.toDeleteChan, data)
+// deleteBatch is one call's worth of slugs awaiting deletion, carried through deleteChan so
+// the eventual flush can report its outcome back against jobID.
+type deleteBatch struct {
+	jobID     uuid.UUID
+	userUUID  uuid.UUID
+	shortURLs []string
 }
 
-// collectShortURLs aggregates all channels from toDeleteChan slice into resulting one
-func (s *Service) collectShortURLs() chan map[uuid.UUID][]string {
-	finalCh := make(chan map[uuid.UUID][]string)
+// SendShortURLForDelete enqueues userUUID and shortURLs on deleteChan for one of the delete
+// workers to pick up, returning a job ID that DeleteJobStatus can later be polled with to
+// learn whether the deletion succeeded. It returns myerrors.ErrDeleteQueueFull without
+// enqueueing anything if deleteQueueLimit is positive and accepting shortURLs would push the
+// backlog over it, or if deleteChan's buffer is already full despite that check.
+func (s *Service) SendShortURLForDelete(_ context.Context, userUUID uuid.UUID, shortURLs []string) (uuid.UUID, error) {
+	if s.deleteQueueLimit > 0 && int(atomic.LoadInt64(&s.pendingDeletes))+len(shortURLs) > s.deleteQueueLimit {
+		return uuid.Nil, myerrors.ErrDeleteQueueFull
+	}
 
-	var wg sync.WaitGroup
+	jobID := s.deleteJobs.start(userUUID)
 
-	for _, ch := range s.toDeleteChan {
-		chClosure := ch
-		wg.Add(1)
-		go func() {
-			defer wg.Done()
-			for data := range chClosure {
-				finalCh <- data
-			}
-		}()
+	select {
+	case s.deleteChan <- deleteBatch{jobID: jobID, userUUID: userUUID, shortURLs: shortURLs}:
+	default:
+		s.deleteJobs.cancel(jobID)
+		return uuid.Nil, myerrors.ErrDeleteQueueFull
+	}
+
+	atomic.AddInt64(&s.pendingDeletes, int64(len(shortURLs)))
+	return jobID, nil
+}
+
+// DeleteJobStatus reports the status of a delete job previously returned by
+// SendShortURLForDelete. It returns myerrors.ErrNotFound if jobID is unknown to userUUID,
+// either because it never existed, it belongs to someone else, or it aged out of the tracker.
+func (s *Service) DeleteJobStatus(_ context.Context, userUUID uuid.UUID, jobID uuid.UUID) (model.DeleteJob, error) {
+	result, ok := s.deleteJobs.lookup(jobID)
+	if !ok || result.userUUID != userUUID {
+		return model.DeleteJob{}, myerrors.ErrNotFound
 	}
-	go func() {
-		wg.Wait()
-		close(finalCh)
-	}()
-	return finalCh
+	return model.DeleteJob{Status: result.status, Error: result.err}, nil
 }
 
-// deleteShortURLs runs as a separate goroutine and periodically send batch of slugs for deletion to repository
-func (s *Service) deleteShortURLs() {
+// deleteWorker is one of deleteWorkers goroutines competing for batches off the shared
+// deleteChan. It accumulates incoming batches for up to timeToCollectDeleted before flushing
+// them together, and performs one final flush of anything still accumulated or left buffered
+// in deleteChan once s.stop is closed.
+func (s *Service) deleteWorker() {
+	defer s.deleteWG.Done()
+
 	ticker := time.NewTicker(timeToCollectDeleted)
 	defer ticker.Stop()
 
 	shortURLsForDelete := make(map[uuid.UUID][]string)
-	var ch = make(chan map[uuid.UUID][]string)
+	var jobIDs []uuid.UUID
 	for {
 		select {
-		case toDelete := <-ch:
-			for k, v := range toDelete {
-				shortURLsForDelete[k] = append(shortURLsForDelete[k], v...)
-			}
+		case batch := <-s.deleteChan:
+			shortURLsForDelete[batch.userUUID] = append(shortURLsForDelete[batch.userUUID], batch.shortURLs...)
+			jobIDs = append(jobIDs, batch.jobID)
 
 		case <-ticker.C:
-			ch = s.collectShortURLs()
-			if len(shortURLsForDelete) == 0 {
-				continue
-			}
-			err := s.repo.DeleteUserShortURLs(context.TODO(), shortURLsForDelete)
-			if err != nil {
-				s.zlog.Err(err).Msg("failed to delete short urls")
-			}
+			s.flushDeletes(s.ctx, shortURLsForDelete, jobIDs)
 			shortURLsForDelete = make(map[uuid.UUID][]string)
+			jobIDs = nil
+
+		case <-s.stop:
+		drain:
+			for {
+				select {
+				case batch := <-s.deleteChan:
+					shortURLsForDelete[batch.userUUID] = append(shortURLsForDelete[batch.userUUID], batch.shortURLs...)
+					jobIDs = append(jobIDs, batch.jobID)
+				default:
+					break drain
+				}
+			}
+			// The service context may already be cancelled by Close, so the final flush
+			// uses a fresh context bounded only by flushTimeout.
+			s.flushDeletes(context.Background(), shortURLsForDelete, jobIDs)
+			return
+		}
+	}
+}
+
+// flushDeletes sends pending deletions to the repository, if any, bounding the call by
+// flushTimeout derived from base so a slow or cancelled context cannot hang the worker.
+// jobIDs records SendShortURLForDelete's job IDs for every slug in shortURLsForDelete, so their
+// outcome can be reported back through DeleteJobStatus.
+func (s *Service) flushDeletes(base context.Context, shortURLsForDelete map[uuid.UUID][]string, jobIDs []uuid.UUID) {
+	if len(shortURLsForDelete) == 0 {
+		return
+	}
+
+	var flushed int64
+	for _, slugs := range shortURLsForDelete {
+		flushed += int64(len(slugs))
+	}
+	atomic.AddInt64(&s.pendingDeletes, -flushed)
+
+	ctx, cancel := context.WithTimeout(base, s.flushTimeout)
+	defer cancel()
+
+	if err := s.repo.DeleteUserShortURLs(ctx, shortURLsForDelete, s.clock.Now()); err != nil {
+		s.zlog.Err(err).Msg("failed to delete short urls")
+		s.deleteJobs.finish(jobIDs, err)
+		return
+	}
+	s.deleteJobs.finish(jobIDs, nil)
+
+	deletedAt := s.clock.Now()
+	for userUUID, slugs := range shortURLsForDelete {
+		for _, slug := range slugs {
+			s.urlCache.Invalidate(slug)
+			s.auditLog.Info().
+				Str("action", "delete").
+				Str("user_uuid", userUUID.String()).
+				Str("slug", slug).
+				Time("deleted_at", deletedAt).
+				Msg("short url deleted")
+			s.events.publish(model.Event{Type: model.EventDeleted, UserUUID: userUUID, ShortURL: slug})
 		}
 	}
 }
@@ -4,7 +4,9 @@ import (
 	"context"
 	"errors"
 	"testing"
+	"time"
 
+	"github.com/ar4ie13/shortener/internal/clock"
 	"github.com/ar4ie13/shortener/internal/model"
 	"github.com/ar4ie13/shortener/internal/myerrors"
 	"github.com/ar4ie13/shortener/internal/service/internal/mockery"
@@ -21,7 +23,7 @@ type HandyMockRepository struct {
 	err  error
 }
 
-func (m *HandyMockRepository) GetURL(_ context.Context, id string) (string, error) {
+func (m *HandyMockRepository) GetURL(_ context.Context, _ uuid.UUID, id string, _ bool) (string, error) {
 	url, exists := m.urls[id]
 	if !exists {
 		return "", myerrors.ErrNotFound
@@ -29,6 +31,20 @@ func (m *HandyMockRepository) GetURL(_ context.Context, id string) (string, erro
 	return url, nil
 }
 
+func (m *HandyMockRepository) GetURLs(ctx context.Context, userUUID uuid.UUID, shortURLs []string, requireOwnership bool) ([]model.URLExpansion, error) {
+	result := make([]model.URLExpansion, len(shortURLs))
+	for i, shortURL := range shortURLs {
+		result[i] = model.URLExpansion{ShortURL: shortURL}
+		originalURL, err := m.GetURL(ctx, userUUID, shortURL, requireOwnership)
+		if err != nil {
+			result[i].Err = err.Error()
+			continue
+		}
+		result[i].OriginalURL = originalURL
+	}
+	return result, nil
+}
+
 func (m *HandyMockRepository) GetShortURL(_ context.Context, urllink string) (string, error) {
 	for k, v := range m.urls {
 		if urllink == v {
@@ -38,40 +54,103 @@ func (m *HandyMockRepository) GetShortURL(_ context.Context, urllink string) (st
 	return "", myerrors.ErrNotFound
 }
 
-func (m *HandyMockRepository) Save(_ context.Context, userUUID uuid.UUID, id string, url string) error {
+func (m *HandyMockRepository) Save(_ context.Context, userUUID uuid.UUID, id string, url string, referer string, userAgent string, expiresAt time.Time, createdAt time.Time) (string, error) {
 	if id == "" || url == "" {
-		return myerrors.ErrEmptyShortURLorURL
+		return "", myerrors.ErrEmptyShortURLorURL
 	}
 	if m.err != nil {
-		return m.err
+		return "", m.err
 	}
 	for _, v := range m.urls {
 		if v == url {
-			return myerrors.ErrURLExist
+			return "", myerrors.ErrURLExist
 		}
 	}
 	m.urls[id] = url
-	return nil
+	return "", nil
 }
-func (m *HandyMockRepository) SaveBatch(_ context.Context, userUUID uuid.UUID, batch []model.URL) error {
+func (m *HandyMockRepository) SaveBatch(_ context.Context, userUUID uuid.UUID, batch []model.URL) ([]string, error) {
 	for i := range batch {
 		if batch[i].ShortURL == "" || batch[i].OriginalURL == "" {
-			return myerrors.ErrEmptyShortURLorURL
+			return nil, myerrors.ErrEmptyShortURLorURL
 		}
 		if m.err != nil {
-			return m.err
+			return nil, m.err
 		}
 
 	}
-	return nil
+	return make([]string, len(batch)), nil
 }
 
-func (m *HandyMockRepository) GetUserShortURLs(ctx context.Context, userUUID uuid.UUID) (map[string]string, error) {
-	return m.urls, m.err
+func (m *HandyMockRepository) GetUserShortURLs(ctx context.Context, userUUID uuid.UUID) ([]model.URL, error) {
+	var result []model.URL
+	for slug, url := range m.urls {
+		result = append(result, model.URL{ShortURL: slug, OriginalURL: url})
+	}
+	return result, m.err
+}
+func (m *HandyMockRepository) UpdateURL(_ context.Context, _ uuid.UUID, shortURL string, newURL string, updatedAt time.Time) error {
+	if _, exists := m.urls[shortURL]; !exists {
+		return myerrors.ErrNotFound
+	}
+	if m.err != nil {
+		return m.err
+	}
+	m.urls[shortURL] = newURL
+	return nil
+}
+func (m *HandyMockRepository) DeleteUserShortURLs(ctx context.Context, shortURLsToDelete map[uuid.UUID][]string, deletedAt time.Time) error {
+	return m.err
+}
+func (m *HandyMockRepository) SweepExpired(_ context.Context, _ time.Time, _ int) (int, error) {
+	return 0, m.err
+}
+func (m *HandyMockRepository) PurgeDeleted(_ context.Context, _ time.Time, _ int) (int, error) {
+	return 0, m.err
+}
+func (m *HandyMockRepository) Ping(_ context.Context) error {
+	return m.err
+}
+func (m *HandyMockRepository) NextSequence(_ context.Context) (int64, error) {
+	return 0, m.err
+}
+func (m *HandyMockRepository) SetPasswordedSlug(_ context.Context, _ string, _ string) error {
+	return m.err
+}
+func (m *HandyMockRepository) GetPasswordHash(_ context.Context, _ string) (string, error) {
+	return "", m.err
 }
-func (m *HandyMockRepository) DeleteUserShortURLs(ctx context.Context, shortURLsToDelete map[uuid.UUID][]string) error {
+func (m *HandyMockRepository) SetRedirectStatus(_ context.Context, _ string, _ int) error {
 	return m.err
 }
+func (m *HandyMockRepository) GetRedirectStatus(_ context.Context, _ string) (int, error) {
+	return 0, m.err
+}
+func (m *HandyMockRepository) SaveAPIKey(_ context.Context, _ uuid.UUID, _ string, _ time.Time) error {
+	return m.err
+}
+func (m *HandyMockRepository) ResolveAPIKey(_ context.Context, _ string) (uuid.UUID, error) {
+	return uuid.Nil, m.err
+}
+func (m *HandyMockRepository) RevokeAPIKey(_ context.Context, _ uuid.UUID, _ string) error {
+	return m.err
+}
+func (m *HandyMockRepository) TopHosts(_ context.Context, _ int) ([]model.HostCount, error) {
+	return nil, m.err
+}
+func (m *HandyMockRepository) Stats(_ context.Context) (model.Stats, error) {
+	return model.Stats{}, m.err
+}
+func (m *HandyMockRepository) BackfillHosts(_ context.Context, _ int) (int, error) {
+	return 0, m.err
+}
+func (m *HandyMockRepository) Verify(_ context.Context) ([]string, error) {
+	return nil, m.err
+}
+
+func (m *HandyMockRepository) Close() error {
+	return nil
+}
 
 func TestService_GenerateShortURL(t *testing.T) {
 	type args struct {
@@ -141,11 +220,15 @@ func TestService_GenerateShortURL(t *testing.T) {
 				tt.fields.err,
 			}
 			s := Service{
-				repo:         &r,
-				toDeleteChan: []chan map[uuid.UUID][]string{},
-				zlog:         zerolog.Logger{},
+				repo:       &r,
+				zlog:       zerolog.Logger{},
+				alphabet:   defaultRandGenerateSymbols,
+				slugLength: defaultShortURLLen,
+				slugGen:    &randomSlugGenerator{alphabet: defaultRandGenerateSymbols},
+				urlCache:   newURLCache(0, 0, nil),
+				clock:      clock.RealClock{},
 			}
-			_, err := s.SaveURL(context.Background(), uuid.New(), tt.args.url)
+			_, err := s.SaveURL(context.Background(), uuid.New(), tt.args.url, "", "", "", "", false, 0)
 			if ((err != nil) != tt.wantErr) || (tt.wantErr && !errors.Is(err, tt.wantErrMsg)) {
 				t.Errorf("%v", !errors.Is(err, tt.wantErrMsg))
 				t.Errorf("SaveURL() error = %v, wantErr %v", err, tt.wantErrMsg)
@@ -193,7 +276,7 @@ func Test_generateShortURL(t *testing.T) {
 	}
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			got, err := generateShortURL(tt.args.length)
+			got, err := generateShortURL(tt.args.length, defaultRandGenerateSymbols)
 			if (err != nil) != tt.wantErr {
 				t.Errorf("generateShortURL() error = %v, wantErr %v", err, tt.wantErr)
 			}
@@ -247,13 +330,14 @@ func TestService_GetURL_Mockery(t *testing.T) {
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
 			mockRepo := mockery.NewMockRepository(t)
-			service := Service{repo: mockRepo}
+			service := Service{repo: mockRepo, urlCache: newURLCache(0, 0, nil)}
+			userUUID := uuid.New()
 
 			if tt.shouldCallRepo {
-				mockRepo.On("GetURL", context.Background(), tt.shortURL).Return(tt.mockReturnURL, tt.mockReturnErr)
+				mockRepo.On("GetURL", context.Background(), userUUID, tt.shortURL, false).Return(tt.mockReturnURL, tt.mockReturnErr)
 			}
 
-			result, err := service.GetURL(context.Background(), uuid.New(), tt.shortURL)
+			result, err := service.GetURL(context.Background(), userUUID, tt.shortURL)
 
 			assert.Equal(t, tt.expectedURL, result)
 			if tt.expectedErr != nil {
@@ -267,10 +351,45 @@ func TestService_GetURL_Mockery(t *testing.T) {
 			}
 
 			if tt.shouldCallRepo {
-				mockRepo.AssertCalled(t, "GetURL", context.Background(), tt.shortURL)
+				mockRepo.AssertCalled(t, "GetURL", context.Background(), userUUID, tt.shortURL, false)
 			} else {
 				mockRepo.AssertNotCalled(t, "GetURL", mock.Anything)
 			}
 		})
 	}
 }
+
+func TestService_GetUserShortURLs_SortAndPaginate(t *testing.T) {
+	mockRepo := mockery.NewMockRepository(t)
+	service := Service{repo: mockRepo}
+	userUUID := uuid.New()
+
+	unordered := []model.URL{
+		{ShortURL: "ccc"},
+		{ShortURL: "aaa"},
+		{ShortURL: "bbb"},
+		{ShortURL: "ddd"},
+	}
+	mockRepo.On("GetUserShortURLs", context.Background(), userUUID).Return(unordered, nil)
+
+	result, err := service.GetUserShortURLs(context.Background(), userUUID, "short_url", 0, 0)
+	require.NoError(t, err)
+	require.Len(t, result, 4)
+	assert.Equal(t, []string{"aaa", "bbb", "ccc", "ddd"}, shortURLsOf(result))
+
+	result, err = service.GetUserShortURLs(context.Background(), userUUID, "short_url", 2, 1)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"bbb", "ccc"}, shortURLsOf(result))
+
+	result, err = service.GetUserShortURLs(context.Background(), userUUID, "short_url", 0, 10)
+	require.NoError(t, err)
+	assert.Empty(t, result)
+}
+
+func shortURLsOf(urls []model.URL) []string {
+	result := make([]string, len(urls))
+	for i, u := range urls {
+		result[i] = u.ShortURL
+	}
+	return result
+}
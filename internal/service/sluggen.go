@@ -0,0 +1,98 @@
+package service
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"strings"
+
+	"github.com/ar4ie13/shortener/internal/myerrors"
+)
+
+// SlugGenerator produces a candidate slug of length characters for a URL being shortened.
+// seed is the original URL, optionally salted by the caller on a retry after a collision;
+// random and sequential implementations ignore it, deterministic ones (hashSlugGenerator)
+// depend on it to avoid generating the same colliding slug forever.
+type SlugGenerator interface {
+	Generate(ctx context.Context, seed string, length int) (string, error)
+}
+
+// randomSlugGenerator draws length characters from alphabet using crypto/rand, optionally
+// biased away from a small set of recently generated slugs via avoid.
+type randomSlugGenerator struct {
+	alphabet string
+	avoid    *recentSlugs
+}
+
+func (g *randomSlugGenerator) Generate(_ context.Context, _ string, length int) (string, error) {
+	if g.avoid != nil {
+		return generateShortURLAvoiding(length, g.alphabet, g.avoid)
+	}
+	return generateShortURL(length, g.alphabet)
+}
+
+// SequenceSource issues monotonically increasing numbers backing sequentialSlugGenerator, e.g.
+// a database sequence so slugs stay unique and ordered across multiple instances sharing a
+// repository.
+type SequenceSource interface {
+	NextSequence(ctx context.Context) (int64, error)
+}
+
+// sequentialSlugGenerator encodes successive values from a SequenceSource in alphabet,
+// left-padded with alphabet's first character to length.
+type sequentialSlugGenerator struct {
+	source   SequenceSource
+	alphabet string
+}
+
+func (g *sequentialSlugGenerator) Generate(ctx context.Context, _ string, length int) (string, error) {
+	n, err := g.source.NextSequence(ctx)
+	if err != nil {
+		return "", fmt.Errorf("failed to obtain next slug sequence value: %w", err)
+	}
+	return encodeBase(n, g.alphabet, length), nil
+}
+
+// hashSlugGenerator derives a slug deterministically from seed (the URL being shortened) by
+// truncating its SHA-256 hex digest to length, so the same URL always maps to the same slug.
+// Callers should salt seed on retry after a collision, since otherwise every attempt would
+// produce the identical, already-colliding slug.
+type hashSlugGenerator struct{}
+
+func (g *hashSlugGenerator) Generate(_ context.Context, seed string, length int) (string, error) {
+	if length <= 0 {
+		return "", myerrors.ErrShortURLLength
+	}
+
+	sum := sha256.Sum256([]byte(seed))
+	digest := hex.EncodeToString(sum[:])
+	for len(digest) < length {
+		sum = sha256.Sum256([]byte(digest))
+		digest += hex.EncodeToString(sum[:])
+	}
+
+	return digest[:length], nil
+}
+
+// encodeBase encodes n in alphabet, left-padding with alphabet's first character to at least
+// length characters so sequential slugs sort and display consistently.
+func encodeBase(n int64, alphabet string, length int) string {
+	base := int64(len(alphabet))
+	if n == 0 {
+		return strings.Repeat(string(alphabet[0]), max(length, 1))
+	}
+
+	var encoded []byte
+	for n > 0 {
+		encoded = append([]byte{alphabet[n%base]}, encoded...)
+		n /= base
+	}
+
+	if len(encoded) < length {
+		pad := strings.Repeat(string(alphabet[0]), length-len(encoded))
+		encoded = append([]byte(pad), encoded...)
+	}
+
+	return string(encoded)
+}
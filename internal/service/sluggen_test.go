@@ -0,0 +1,131 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"testing"
+)
+
+type fakeSequenceSource struct {
+	next int64
+	err  error
+}
+
+func (f *fakeSequenceSource) NextSequence(_ context.Context) (int64, error) {
+	if f.err != nil {
+		return 0, f.err
+	}
+	f.next++
+	return f.next, nil
+}
+
+func TestRandomSlugGenerator_Generate(t *testing.T) {
+	g := &randomSlugGenerator{alphabet: defaultRandGenerateSymbols}
+
+	slug, err := g.Generate(context.Background(), "https://example.com", defaultShortURLLen)
+	if err != nil {
+		t.Fatalf("Generate() unexpected error = %v", err)
+	}
+	if len(slug) != defaultShortURLLen {
+		t.Errorf("Generate() len = %d, want %d", len(slug), defaultShortURLLen)
+	}
+}
+
+func TestRandomSlugGenerator_Generate_AvoidsRecentSlugs(t *testing.T) {
+	avoid := newRecentSlugs(recentSlugsCapacity)
+	g := &randomSlugGenerator{alphabet: defaultRandGenerateSymbols, avoid: avoid}
+
+	slug, err := g.Generate(context.Background(), "https://example.com", defaultShortURLLen)
+	if err != nil {
+		t.Fatalf("Generate() unexpected error = %v", err)
+	}
+	avoid.Add(slug)
+	if !avoid.Contains(slug) {
+		t.Errorf("expected avoid set to contain generated slug %q", slug)
+	}
+}
+
+func TestSequentialSlugGenerator_Generate(t *testing.T) {
+	source := &fakeSequenceSource{}
+	g := &sequentialSlugGenerator{source: source, alphabet: "0123456789"}
+
+	first, err := g.Generate(context.Background(), "seed", 4)
+	if err != nil {
+		t.Fatalf("Generate() unexpected error = %v", err)
+	}
+	if first != "0001" {
+		t.Errorf("Generate() = %q, want %q", first, "0001")
+	}
+
+	second, err := g.Generate(context.Background(), "seed", 4)
+	if err != nil {
+		t.Fatalf("Generate() unexpected error = %v", err)
+	}
+	if second != "0002" {
+		t.Errorf("Generate() = %q, want %q", second, "0002")
+	}
+}
+
+func TestSequentialSlugGenerator_Generate_PropagatesSourceError(t *testing.T) {
+	wantErr := errors.New("sequence unavailable")
+	source := &fakeSequenceSource{err: wantErr}
+	g := &sequentialSlugGenerator{source: source, alphabet: "0123456789"}
+
+	if _, err := g.Generate(context.Background(), "seed", 4); !errors.Is(err, wantErr) {
+		t.Errorf("Generate() error = %v, want wrapped %v", err, wantErr)
+	}
+}
+
+func TestHashSlugGenerator_Generate_IsDeterministic(t *testing.T) {
+	g := &hashSlugGenerator{}
+
+	first, err := g.Generate(context.Background(), "https://example.com", 8)
+	if err != nil {
+		t.Fatalf("Generate() unexpected error = %v", err)
+	}
+	second, err := g.Generate(context.Background(), "https://example.com", 8)
+	if err != nil {
+		t.Fatalf("Generate() unexpected error = %v", err)
+	}
+	if first != second {
+		t.Errorf("Generate() = %q and %q for the same seed, want identical slugs", first, second)
+	}
+
+	other, err := g.Generate(context.Background(), "https://example.com#2", 8)
+	if err != nil {
+		t.Fatalf("Generate() unexpected error = %v", err)
+	}
+	if other == first {
+		t.Errorf("Generate() returned the same slug for different seeds, want distinct salted output")
+	}
+}
+
+func TestHashSlugGenerator_Generate_LongerThanDigest(t *testing.T) {
+	g := &hashSlugGenerator{}
+
+	slug, err := g.Generate(context.Background(), "https://example.com", 100)
+	if err != nil {
+		t.Fatalf("Generate() unexpected error = %v", err)
+	}
+	if len(slug) != 100 {
+		t.Errorf("Generate() len = %d, want 100", len(slug))
+	}
+}
+
+func TestEncodeBase_PadsToLength(t *testing.T) {
+	tests := []struct {
+		n    int64
+		want string
+	}{
+		{0, "0000"},
+		{1, "0001"},
+		{10, "0010"},
+		{12345, "12345"},
+	}
+
+	for _, tt := range tests {
+		if got := encodeBase(tt.n, "0123456789", 4); got != tt.want {
+			t.Errorf("encodeBase(%d, ..., 4) = %q, want %q", tt.n, got, tt.want)
+		}
+	}
+}
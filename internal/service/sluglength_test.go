@@ -0,0 +1,118 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/ar4ie13/shortener/internal/myerrors"
+	"github.com/ar4ie13/shortener/internal/repository/memory"
+	"github.com/ar4ie13/shortener/internal/service/internal/mockery"
+	"github.com/google/uuid"
+	"github.com/rs/zerolog"
+	"github.com/stretchr/testify/mock"
+)
+
+// TestService_GetURL_SlugLengthChange confirms that reconfiguring slugLength (e.g. via
+// SHORT_URL_LENGTH on a redeploy) does not break resolution of slugs generated under the
+// previous length, since GetURL never filters by length.
+func TestService_GetURL_SlugLengthChange(t *testing.T) {
+	repo := memory.NewMemStorage()
+	userUUID := uuid.New()
+
+	oldSrv := NewService(repo, zerolog.Nop(), false, time.Second, "", 8, nil, 0, 0, 0, 0, nil, 0, false, 0, false, zerolog.Nop(), 0, "", 0, 0, nil, nil, 0)
+	slug, err := oldSrv.SaveURL(context.Background(), userUUID, "https://example.com/one", "", "", "", "", false, 0)
+	oldSrv.Close()
+	if err != nil {
+		t.Fatalf("SaveURL() unexpected error = %v", err)
+	}
+	if len(slug) != 8 {
+		t.Fatalf("SaveURL() slug length = %d, want 8", len(slug))
+	}
+
+	newSrv := NewService(repo, zerolog.Nop(), false, time.Second, "", 4, nil, 0, 0, 0, 0, nil, 0, false, 0, false, zerolog.Nop(), 0, "", 0, 0, nil, nil, 0)
+	defer newSrv.Close()
+
+	gotURL, err := newSrv.GetURL(context.Background(), userUUID, slug)
+	if err != nil {
+		t.Fatalf("GetURL() unexpected error = %v", err)
+	}
+	if gotURL != "https://example.com/one" {
+		t.Errorf("GetURL() = %q, want %q", gotURL, "https://example.com/one")
+	}
+}
+
+// TestService_SaveURL_AutoExtendSlugOnCollisionExhaustion simulates a nearly-full 1-character
+// keyspace: every save at that length collides, so with AutoExtendSlug enabled the service
+// should retry once at length+1 and succeed instead of surfacing the collision to the caller.
+func TestService_SaveURL_AutoExtendSlugOnCollisionExhaustion(t *testing.T) {
+	mockRepo := mockery.NewMockRepository(t)
+	mockRepo.On("Save", mock.Anything, mock.Anything, mock.MatchedBy(func(id string) bool { return len(id) == 1 }), mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything).
+		Return("", myerrors.ErrShortURLExist)
+	mockRepo.On("Save", mock.Anything, mock.Anything, mock.MatchedBy(func(id string) bool { return len(id) == 2 }), mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything).
+		Return("", nil)
+
+	srv := NewService(mockRepo, zerolog.Nop(), false, time.Second, "a", 1, nil, 0, 0, 0, 0, nil, 0, true, 0, false, zerolog.Nop(), 0, "", 0, 0, nil, nil, 0)
+	defer srv.Close()
+
+	slug, err := srv.SaveURL(context.Background(), uuid.New(), "https://example.com/one", "", "", "", "", false, 0)
+	if err != nil {
+		t.Fatalf("SaveURL() unexpected error = %v", err)
+	}
+	if len(slug) != 2 {
+		t.Errorf("SaveURL() slug length = %d, want 2 after extending past the exhausted 1-character keyspace", len(slug))
+	}
+}
+
+// TestService_SaveURL_AutoExtendSlugKeepsGrowing confirms the extension is not limited to a
+// single +1 step: if length+1 is also exhausted, the service keeps growing the slug until it
+// finds a length with room, up to maxSlugAutoExtend characters longer than configured.
+func TestService_SaveURL_AutoExtendSlugKeepsGrowing(t *testing.T) {
+	mockRepo := mockery.NewMockRepository(t)
+	mockRepo.On("Save", mock.Anything, mock.Anything, mock.MatchedBy(func(id string) bool { return len(id) <= 2 }), mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything).
+		Return("", myerrors.ErrShortURLExist)
+	mockRepo.On("Save", mock.Anything, mock.Anything, mock.MatchedBy(func(id string) bool { return len(id) == 3 }), mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything).
+		Return("", nil)
+
+	srv := NewService(mockRepo, zerolog.Nop(), false, time.Second, "a", 1, nil, 0, 0, 0, 0, nil, 0, true, 0, false, zerolog.Nop(), 0, "", 0, 0, nil, nil, 0)
+	defer srv.Close()
+
+	slug, err := srv.SaveURL(context.Background(), uuid.New(), "https://example.com/one", "", "", "", "", false, 0)
+	if err != nil {
+		t.Fatalf("SaveURL() unexpected error = %v", err)
+	}
+	if len(slug) != 3 {
+		t.Errorf("SaveURL() slug length = %d, want 3 after exhausting lengths 1 and 2", len(slug))
+	}
+}
+
+// TestService_SaveURL_AutoExtendSlugGivesUpAfterMax confirms the growth is bounded by
+// maxSlugAutoExtend so a pathologically full keyspace still fails instead of retrying forever.
+func TestService_SaveURL_AutoExtendSlugGivesUpAfterMax(t *testing.T) {
+	mockRepo := mockery.NewMockRepository(t)
+	mockRepo.On("Save", mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything).
+		Return("", myerrors.ErrShortURLExist)
+
+	srv := NewService(mockRepo, zerolog.Nop(), false, time.Second, "a", 1, nil, 0, 0, 0, 0, nil, 0, true, 0, false, zerolog.Nop(), 0, "", 0, 0, nil, nil, 0)
+	defer srv.Close()
+
+	if _, err := srv.SaveURL(context.Background(), uuid.New(), "https://example.com/one", "", "", "", "", false, 0); !errors.Is(err, myerrors.ErrShortURLExist) {
+		t.Fatalf("SaveURL() error = %v, want it to wrap myerrors.ErrShortURLExist", err)
+	}
+}
+
+// TestService_SaveURL_CollisionExhaustionWithoutAutoExtend confirms the extension only kicks
+// in when AutoExtendSlug is enabled; otherwise exhausting collision retries still fails.
+func TestService_SaveURL_CollisionExhaustionWithoutAutoExtend(t *testing.T) {
+	mockRepo := mockery.NewMockRepository(t)
+	mockRepo.On("Save", mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything).
+		Return("", myerrors.ErrShortURLExist)
+
+	srv := NewService(mockRepo, zerolog.Nop(), false, time.Second, "a", 1, nil, 0, 0, 0, 0, nil, 0, false, 0, false, zerolog.Nop(), 0, "", 0, 0, nil, nil, 0)
+	defer srv.Close()
+
+	if _, err := srv.SaveURL(context.Background(), uuid.New(), "https://example.com/one", "", "", "", "", false, 0); !errors.Is(err, myerrors.ErrShortURLExist) {
+		t.Fatalf("SaveURL() error = %v, want it to wrap myerrors.ErrShortURLExist", err)
+	}
+}
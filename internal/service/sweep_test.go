@@ -0,0 +1,214 @@
+package service
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/ar4ie13/shortener/internal/clock"
+	"github.com/ar4ie13/shortener/internal/model"
+	"github.com/google/uuid"
+	"github.com/rs/zerolog"
+)
+
+// sweepTrackingRepository implements Repository, recording every SweepExpired call and
+// soft-deleting entries from an in-memory map of slug to expiry, mirroring memory.MemStorage.
+// mu guards expiresAt/deleted/calls against concurrent access between the background sweeper
+// goroutine and the test goroutine polling deleted()/calls().
+type sweepTrackingRepository struct {
+	mu        sync.Mutex
+	expiresAt map[string]time.Time
+	deleted   map[string]bool
+	calls     int
+}
+
+// isDeleted reports whether shortURL has been swept, for tests polling from outside the
+// sweeper goroutine
+func (r *sweepTrackingRepository) isDeleted(shortURL string) bool {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.deleted[shortURL]
+}
+
+// callCount returns the number of SweepExpired calls so far
+func (r *sweepTrackingRepository) callCount() int {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.calls
+}
+
+// deletedCount returns the number of swept entries so far
+func (r *sweepTrackingRepository) deletedCount() int {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return len(r.deleted)
+}
+
+func (r *sweepTrackingRepository) GetURL(_ context.Context, _ uuid.UUID, _ string, _ bool) (string, error) {
+	return "", nil
+}
+func (r *sweepTrackingRepository) GetURLs(_ context.Context, _ uuid.UUID, shortURLs []string, _ bool) ([]model.URLExpansion, error) {
+	return make([]model.URLExpansion, len(shortURLs)), nil
+}
+func (r *sweepTrackingRepository) GetShortURL(_ context.Context, _ string) (string, error) {
+	return "", nil
+}
+func (r *sweepTrackingRepository) Save(_ context.Context, _ uuid.UUID, shortURL string, _ string, _ string, _ string, expiresAt time.Time, _ time.Time) (string, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.expiresAt[shortURL] = expiresAt
+	return "", nil
+}
+func (r *sweepTrackingRepository) SaveBatch(_ context.Context, _ uuid.UUID, _ []model.URL) ([]string, error) {
+	return nil, nil
+}
+func (r *sweepTrackingRepository) GetUserShortURLs(_ context.Context, _ uuid.UUID) ([]model.URL, error) {
+	return nil, nil
+}
+func (r *sweepTrackingRepository) UpdateURL(_ context.Context, _ uuid.UUID, _ string, _ string, _ time.Time) error {
+	return nil
+}
+func (r *sweepTrackingRepository) DeleteUserShortURLs(_ context.Context, _ map[uuid.UUID][]string, _ time.Time) error {
+	return nil
+}
+func (r *sweepTrackingRepository) SweepExpired(_ context.Context, before time.Time, limit int) (int, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.calls++
+	swept := 0
+	for shortURL, expiresAt := range r.expiresAt {
+		if swept >= limit {
+			break
+		}
+		if expiresAt.IsZero() || !expiresAt.Before(before) || r.deleted[shortURL] {
+			continue
+		}
+		r.deleted[shortURL] = true
+		swept++
+	}
+	return swept, nil
+}
+func (r *sweepTrackingRepository) PurgeDeleted(_ context.Context, _ time.Time, _ int) (int, error) {
+	return 0, nil
+}
+func (r *sweepTrackingRepository) Ping(_ context.Context) error {
+	return nil
+}
+func (r *sweepTrackingRepository) NextSequence(_ context.Context) (int64, error) {
+	return 0, nil
+}
+func (r *sweepTrackingRepository) SetPasswordedSlug(_ context.Context, _ string, _ string) error {
+	return nil
+}
+func (r *sweepTrackingRepository) GetPasswordHash(_ context.Context, _ string) (string, error) {
+	return "", nil
+}
+func (r *sweepTrackingRepository) SetRedirectStatus(_ context.Context, _ string, _ int) error {
+	return nil
+}
+func (r *sweepTrackingRepository) GetRedirectStatus(_ context.Context, _ string) (int, error) {
+	return 0, nil
+}
+func (r *sweepTrackingRepository) SaveAPIKey(_ context.Context, _ uuid.UUID, _ string, _ time.Time) error {
+	return nil
+}
+func (r *sweepTrackingRepository) ResolveAPIKey(_ context.Context, _ string) (uuid.UUID, error) {
+	return uuid.Nil, nil
+}
+func (r *sweepTrackingRepository) RevokeAPIKey(_ context.Context, _ uuid.UUID, _ string) error {
+	return nil
+}
+func (r *sweepTrackingRepository) TopHosts(_ context.Context, _ int) ([]model.HostCount, error) {
+	return nil, nil
+}
+func (r *sweepTrackingRepository) Stats(_ context.Context) (model.Stats, error) {
+	return model.Stats{}, nil
+}
+func (r *sweepTrackingRepository) BackfillHosts(_ context.Context, _ int) (int, error) {
+	return 0, nil
+}
+func (r *sweepTrackingRepository) Verify(_ context.Context) ([]string, error) {
+	return nil, nil
+}
+
+func (r *sweepTrackingRepository) Close() error {
+	return nil
+}
+
+func TestService_SweepOnce_MarksExpiredLinksDeleted(t *testing.T) {
+	fakeClock := clock.NewFakeClock(time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC))
+	repo := &sweepTrackingRepository{
+		expiresAt: map[string]time.Time{},
+		deleted:   map[string]bool{},
+	}
+
+	srv := NewService(repo, zerolog.Nop(), false, time.Second, "", 0, fakeClock, time.Minute, 0, 0, 0, nil, 0, false, 0, false, zerolog.Nop(), 0, "", 0, 0, nil, nil, 0)
+	defer srv.Close()
+
+	if _, err := srv.SaveURL(context.Background(), uuid.New(), "https://example.com/one", "", "", "", "", false, 0); err != nil {
+		t.Fatalf("SaveURL() unexpected error = %v", err)
+	}
+
+	fakeClock.Advance(30 * time.Second)
+	srv.sweepOnce()
+	if repo.callCount() != 1 || repo.deletedCount() != 0 {
+		t.Fatalf("sweepOnce() before expiry: calls = %d, deleted = %d, want no links swept yet", repo.callCount(), repo.deletedCount())
+	}
+
+	fakeClock.Advance(time.Minute)
+	srv.sweepOnce()
+	if repo.callCount() != 2 || repo.deletedCount() != 1 {
+		t.Fatalf("sweepOnce() after expiry: calls = %d, deleted = %d, want exactly one link swept", repo.callCount(), repo.deletedCount())
+	}
+}
+
+func TestService_SweepOnce_AnonymousLinksExpireSeparatelyFromClaimed(t *testing.T) {
+	fakeClock := clock.NewFakeClock(time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC))
+	repo := &sweepTrackingRepository{
+		expiresAt: map[string]time.Time{},
+		deleted:   map[string]bool{},
+	}
+
+	srv := NewService(repo, zerolog.Nop(), false, time.Second, "", 0, fakeClock, 0, 0, 0, 0, nil, 0, false, time.Minute, false, zerolog.Nop(), 0, "", 0, 0, nil, nil, 0)
+	defer srv.Close()
+
+	anonSlug, err := srv.SaveURL(context.Background(), uuid.New(), "https://example.com/anon", "", "", "", "", true, 0)
+	if err != nil {
+		t.Fatalf("SaveURL() unexpected error = %v", err)
+	}
+	claimedSlug, err := srv.SaveURL(context.Background(), uuid.New(), "https://example.com/claimed", "", "", "", "", false, 0)
+	if err != nil {
+		t.Fatalf("SaveURL() unexpected error = %v", err)
+	}
+
+	fakeClock.Advance(2 * time.Minute)
+	srv.sweepOnce()
+
+	if !repo.isDeleted(anonSlug) {
+		t.Errorf("anonymous link %q was not swept after its TTL", anonSlug)
+	}
+	if repo.isDeleted(claimedSlug) {
+		t.Errorf("claimed link %q was swept, but has no TTL and should persist", claimedSlug)
+	}
+}
+
+func TestService_SweepExpiredLinks_RunsOnInterval(t *testing.T) {
+	fakeClock := clock.NewFakeClock(time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC))
+	repo := &sweepTrackingRepository{
+		expiresAt: map[string]time.Time{"abc123": fakeClock.Now().Add(-time.Second)},
+		deleted:   map[string]bool{},
+	}
+
+	srv := NewService(repo, zerolog.Nop(), false, time.Second, "", 0, fakeClock, time.Minute, 10*time.Millisecond, 0, 0, nil, 0, false, 0, false, zerolog.Nop(), 0, "", 0, 0, nil, nil, 0)
+	defer srv.Close()
+
+	deadline := time.Now().Add(2 * time.Second)
+	for time.Now().Before(deadline) {
+		if repo.isDeleted("abc123") {
+			return
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+	t.Fatalf("sweeper did not mark expired link as deleted within deadline")
+}
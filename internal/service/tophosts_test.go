@@ -0,0 +1,61 @@
+package service
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/ar4ie13/shortener/internal/repository/memory"
+	"github.com/google/uuid"
+	"github.com/rs/zerolog"
+)
+
+func TestService_TopHosts_AggregatesMultipleSlugsPerHost(t *testing.T) {
+	repo := memory.NewMemStorage()
+	srv := NewService(repo, zerolog.Nop(), false, time.Second, "", 0, nil, 0, 0, 0, 0, nil, 0, false, 0, false, zerolog.Nop(), 0, "", 0, 0, nil, nil, 0)
+	defer srv.Close()
+
+	userUUID := uuid.New()
+	urls := []string{
+		"https://spam.example.com/a",
+		"https://spam.example.com/b",
+		"https://spam.example.com/c",
+		"https://legit.example.org/x",
+	}
+	for _, u := range urls {
+		if _, err := srv.SaveURL(context.Background(), userUUID, u, "", "", "", "", false, 0); err != nil {
+			t.Fatalf("SaveURL(%q) unexpected error = %v", u, err)
+		}
+	}
+
+	got, err := srv.TopHosts(context.Background(), 1)
+	if err != nil {
+		t.Fatalf("TopHosts() unexpected error = %v", err)
+	}
+
+	if len(got) != 1 {
+		t.Fatalf("TopHosts() returned %d hosts, want 1", len(got))
+	}
+	if got[0].Host != "spam.example.com" || got[0].Count != 3 {
+		t.Errorf("TopHosts() = %+v, want {spam.example.com 3}", got[0])
+	}
+}
+
+func TestService_TopHosts_DefaultsLimitWhenNonPositive(t *testing.T) {
+	repo := memory.NewMemStorage()
+	srv := NewService(repo, zerolog.Nop(), false, time.Second, "", 0, nil, 0, 0, 0, 0, nil, 0, false, 0, false, zerolog.Nop(), 0, "", 0, 0, nil, nil, 0)
+	defer srv.Close()
+
+	userUUID := uuid.New()
+	if _, err := srv.SaveURL(context.Background(), userUUID, "https://example.com/a", "", "", "", "", false, 0); err != nil {
+		t.Fatalf("SaveURL() unexpected error = %v", err)
+	}
+
+	got, err := srv.TopHosts(context.Background(), 0)
+	if err != nil {
+		t.Fatalf("TopHosts() unexpected error = %v", err)
+	}
+	if len(got) != 1 || got[0].Host != "example.com" {
+		t.Errorf("TopHosts() = %+v, want a single example.com entry", got)
+	}
+}
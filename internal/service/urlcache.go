@@ -0,0 +1,118 @@
+package service
+
+import (
+	"container/list"
+	"sync"
+	"time"
+
+	"github.com/ar4ie13/shortener/internal/clock"
+)
+
+// urlCacheEntry is the value stored per list.Element, carrying the key alongside the cached
+// value and its expiry so an evicted/expired element can remove itself from the lookup map
+type urlCacheEntry struct {
+	key       string
+	value     string
+	expiresAt time.Time
+}
+
+// urlCache is a bounded, TTL-expiring LRU cache mapping a short URL to its destination, used
+// to spare the repository a lookup for hot redirects. Safe for concurrent use.
+type urlCache struct {
+	mu       sync.Mutex
+	capacity int
+	ttl      time.Duration
+	clock    clock.Clock
+	list     *list.List
+	index    map[string]*list.Element
+}
+
+// newURLCache constructs a urlCache bounded to capacity entries, each valid for ttl after
+// being set. A non-positive capacity or ttl disables the cache: Get always misses and Set
+// is a no-op.
+func newURLCache(capacity int, ttl time.Duration, clk clock.Clock) *urlCache {
+	if clk == nil {
+		clk = clock.RealClock{}
+	}
+	return &urlCache{
+		capacity: capacity,
+		ttl:      ttl,
+		clock:    clk,
+		list:     list.New(),
+		index:    make(map[string]*list.Element),
+	}
+}
+
+// enabled reports whether the cache is configured to hold anything
+func (c *urlCache) enabled() bool {
+	return c.capacity > 0 && c.ttl > 0
+}
+
+// Get returns the cached destination for shortURL, marking it most-recently-used. The second
+// return value is false on a miss, including when the entry has expired.
+func (c *urlCache) Get(shortURL string) (string, bool) {
+	if !c.enabled() {
+		return "", false
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	elem, ok := c.index[shortURL]
+	if !ok {
+		return "", false
+	}
+
+	entry := elem.Value.(*urlCacheEntry)
+	if c.clock.Now().After(entry.expiresAt) {
+		c.list.Remove(elem)
+		delete(c.index, shortURL)
+		return "", false
+	}
+
+	c.list.MoveToFront(elem)
+	return entry.value, true
+}
+
+// Set caches originalURL as the destination for shortURL, evicting the least-recently-used
+// entry if the cache is at capacity.
+func (c *urlCache) Set(shortURL string, originalURL string) {
+	if !c.enabled() {
+		return
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if elem, ok := c.index[shortURL]; ok {
+		elem.Value.(*urlCacheEntry).value = originalURL
+		elem.Value.(*urlCacheEntry).expiresAt = c.clock.Now().Add(c.ttl)
+		c.list.MoveToFront(elem)
+		return
+	}
+
+	if c.list.Len() >= c.capacity {
+		oldest := c.list.Back()
+		if oldest != nil {
+			c.list.Remove(oldest)
+			delete(c.index, oldest.Value.(*urlCacheEntry).key)
+		}
+	}
+
+	entry := &urlCacheEntry{key: shortURL, value: originalURL, expiresAt: c.clock.Now().Add(c.ttl)}
+	c.index[shortURL] = c.list.PushFront(entry)
+}
+
+// Invalidate removes shortURL from the cache, if present. Used to keep a deleted slug from
+// continuing to resolve out of a stale cache entry.
+func (c *urlCache) Invalidate(shortURL string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	elem, ok := c.index[shortURL]
+	if !ok {
+		return
+	}
+	c.list.Remove(elem)
+	delete(c.index, shortURL)
+}
@@ -0,0 +1,73 @@
+package service
+
+import (
+	"testing"
+	"time"
+
+	"github.com/ar4ie13/shortener/internal/clock"
+)
+
+func TestURLCache_SetAndGet(t *testing.T) {
+	c := newURLCache(2, time.Minute, nil)
+
+	if _, ok := c.Get("abc"); ok {
+		t.Fatalf("Get() on empty cache = hit, want miss")
+	}
+
+	c.Set("abc", "https://example.com")
+	got, ok := c.Get("abc")
+	if !ok || got != "https://example.com" {
+		t.Fatalf("Get() = (%q, %v), want (%q, true)", got, ok, "https://example.com")
+	}
+}
+
+func TestURLCache_EvictsLeastRecentlyUsed(t *testing.T) {
+	c := newURLCache(2, time.Minute, nil)
+
+	c.Set("a", "1")
+	c.Set("b", "2")
+	c.Get("a") // touch "a" so "b" becomes least recently used
+	c.Set("c", "3")
+
+	if _, ok := c.Get("b"); ok {
+		t.Errorf("Get(b) = hit, want miss after eviction")
+	}
+	if _, ok := c.Get("a"); !ok {
+		t.Errorf("Get(a) = miss, want hit: most recently used entry should survive eviction")
+	}
+	if _, ok := c.Get("c"); !ok {
+		t.Errorf("Get(c) = miss, want hit: just-inserted entry should be present")
+	}
+}
+
+func TestURLCache_ExpiresAfterTTL(t *testing.T) {
+	fakeClock := clock.NewFakeClock(time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC))
+	c := newURLCache(10, time.Minute, fakeClock)
+
+	c.Set("abc", "https://example.com")
+	fakeClock.Advance(2 * time.Minute)
+
+	if _, ok := c.Get("abc"); ok {
+		t.Errorf("Get() = hit, want miss after ttl elapsed")
+	}
+}
+
+func TestURLCache_Invalidate(t *testing.T) {
+	c := newURLCache(10, time.Minute, nil)
+
+	c.Set("abc", "https://example.com")
+	c.Invalidate("abc")
+
+	if _, ok := c.Get("abc"); ok {
+		t.Errorf("Get() = hit, want miss after Invalidate")
+	}
+}
+
+func TestURLCache_DisabledWhenUnconfigured(t *testing.T) {
+	c := newURLCache(0, 0, nil)
+
+	c.Set("abc", "https://example.com")
+	if _, ok := c.Get("abc"); ok {
+		t.Errorf("Get() = hit, want miss: cache with zero size/ttl should never store anything")
+	}
+}
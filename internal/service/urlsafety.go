@@ -0,0 +1,192 @@
+package service
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"os"
+	"strings"
+	"time"
+)
+
+// safeBrowsingDefaultEndpoint is the Google Safe Browsing v4 threatMatches:find endpoint used
+// when SafeBrowsingValidator is not given one explicitly (tests override it to a local server).
+const safeBrowsingDefaultEndpoint = "https://safebrowsing.googleapis.com/v4/threatMatches:find"
+
+// safeBrowsingTimeout bounds each call to the Safe Browsing API, so a slow or unreachable
+// endpoint cannot stall URL creation indefinitely.
+const safeBrowsingTimeout = 5 * time.Second
+
+// Validator checks a URL for safety before it is shortened, returning a non-nil error
+// describing why the URL was rejected. SaveURL and SaveBatch wrap that error in
+// myerrors.ErrURLBlocked so callers and handlers see one consistent sentinel regardless of
+// which Validator rejected the URL.
+type Validator interface {
+	Validate(ctx context.Context, urlLink string) error
+}
+
+// MultiValidator runs several Validators in order, returning the first rejection. It lets
+// main wire up a blocklist and the Safe Browsing API as independent, individually optional
+// checks instead of one monolithic validator.
+type MultiValidator struct {
+	validators []Validator
+}
+
+// NewMultiValidator returns a Validator that runs each of validators in order, stopping at
+// the first one that rejects the URL. Nil entries are skipped, so callers can build the
+// slice conditionally without filtering it themselves.
+func NewMultiValidator(validators ...Validator) *MultiValidator {
+	nonNil := make([]Validator, 0, len(validators))
+	for _, v := range validators {
+		if v != nil {
+			nonNil = append(nonNil, v)
+		}
+	}
+	return &MultiValidator{validators: nonNil}
+}
+
+// Validate runs urlLink through every configured Validator, returning the first error.
+func (m *MultiValidator) Validate(ctx context.Context, urlLink string) error {
+	for _, v := range m.validators {
+		if err := v.Validate(ctx, urlLink); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// BlocklistValidator rejects URLs whose host appears in a fixed set of blocked hosts, loaded
+// once from a file at construction. The file format is one host per line (e.g. "evil.example.com"),
+// blank lines and lines starting with "#" are ignored.
+type BlocklistValidator struct {
+	hosts map[string]struct{}
+}
+
+// NewBlocklistValidator reads path and returns a BlocklistValidator rejecting any URL whose
+// host matches a line in it. An empty path is rejected by the caller; a missing or malformed
+// file is reported as an error rather than silently validating nothing.
+func NewBlocklistValidator(path string) (*BlocklistValidator, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open blocklist file: %w", err)
+	}
+	defer file.Close()
+
+	hosts := make(map[string]struct{})
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		hosts[strings.ToLower(line)] = struct{}{}
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read blocklist file: %w", err)
+	}
+
+	return &BlocklistValidator{hosts: hosts}, nil
+}
+
+// Validate rejects urlLink if its host is in the blocklist.
+func (v *BlocklistValidator) Validate(_ context.Context, urlLink string) error {
+	parsed, err := url.Parse(urlLink)
+	if err != nil {
+		return fmt.Errorf("failed to parse URL for blocklist check: %w", err)
+	}
+	if _, blocked := v.hosts[strings.ToLower(parsed.Hostname())]; blocked {
+		return fmt.Errorf("host %s is on the blocklist", parsed.Hostname())
+	}
+	return nil
+}
+
+// SafeBrowsingValidator rejects URLs flagged by the Google Safe Browsing v4 API.
+type SafeBrowsingValidator struct {
+	apiKey     string
+	endpoint   string
+	httpClient *http.Client
+}
+
+// NewSafeBrowsingValidator returns a SafeBrowsingValidator authenticating with apiKey. An
+// empty endpoint falls back to safeBrowsingDefaultEndpoint.
+func NewSafeBrowsingValidator(apiKey string, endpoint string) *SafeBrowsingValidator {
+	if endpoint == "" {
+		endpoint = safeBrowsingDefaultEndpoint
+	}
+	return &SafeBrowsingValidator{
+		apiKey:     apiKey,
+		endpoint:   endpoint,
+		httpClient: &http.Client{Timeout: safeBrowsingTimeout},
+	}
+}
+
+// safeBrowsingRequest mirrors the subset of the threatMatches:find request body needed to
+// look up a single URL against the malware and social engineering threat lists.
+type safeBrowsingRequest struct {
+	Client struct {
+		ClientID      string `json:"clientId"`
+		ClientVersion string `json:"clientVersion"`
+	} `json:"client"`
+	ThreatInfo struct {
+		ThreatTypes      []string `json:"threatTypes"`
+		PlatformTypes    []string `json:"platformTypes"`
+		ThreatEntryTypes []string `json:"threatEntryTypes"`
+		ThreatEntries    []struct {
+			URL string `json:"url"`
+		} `json:"threatEntries"`
+	} `json:"threatInfo"`
+}
+
+// safeBrowsingResponse mirrors the subset of the threatMatches:find response needed to tell
+// whether any match was found; the matched threat's own details are not surfaced.
+type safeBrowsingResponse struct {
+	Matches []json.RawMessage `json:"matches"`
+}
+
+// Validate calls the Safe Browsing API and rejects urlLink if it matches any threat list.
+func (v *SafeBrowsingValidator) Validate(ctx context.Context, urlLink string) error {
+	var body safeBrowsingRequest
+	body.Client.ClientID = "shortener"
+	body.Client.ClientVersion = "1.0.0"
+	body.ThreatInfo.ThreatTypes = []string{"MALWARE", "SOCIAL_ENGINEERING", "UNWANTED_SOFTWARE"}
+	body.ThreatInfo.PlatformTypes = []string{"ANY_PLATFORM"}
+	body.ThreatInfo.ThreatEntryTypes = []string{"URL"}
+	body.ThreatInfo.ThreatEntries = []struct {
+		URL string `json:"url"`
+	}{{URL: urlLink}}
+
+	payload, err := json.Marshal(body)
+	if err != nil {
+		return fmt.Errorf("failed to build Safe Browsing request: %w", err)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, v.endpoint+"?key="+url.QueryEscape(v.apiKey), strings.NewReader(string(payload)))
+	if err != nil {
+		return fmt.Errorf("failed to build Safe Browsing request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := v.httpClient.Do(req)
+	if err != nil {
+		return fmt.Errorf("failed to call Safe Browsing API: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("Safe Browsing API returned status %d", resp.StatusCode)
+	}
+
+	var result safeBrowsingResponse
+	if err := json.NewDecoder(resp.Body).Decode(&result); err != nil {
+		return fmt.Errorf("failed to decode Safe Browsing response: %w", err)
+	}
+
+	if len(result.Matches) > 0 {
+		return fmt.Errorf("URL matched %d Safe Browsing threat entries", len(result.Matches))
+	}
+
+	return nil
+}
@@ -0,0 +1,123 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+type fakeValidator struct {
+	err error
+}
+
+func (f *fakeValidator) Validate(_ context.Context, _ string) error {
+	return f.err
+}
+
+func TestMultiValidator_Validate_StopsAtFirstRejection(t *testing.T) {
+	wantErr := errors.New("rejected by first")
+	calledSecond := false
+	second := &fakeValidator{}
+	m := NewMultiValidator(&fakeValidator{err: wantErr}, validatorFunc(func() error {
+		calledSecond = true
+		return second.err
+	}))
+
+	if err := m.Validate(context.Background(), "https://example.com"); !errors.Is(err, wantErr) {
+		t.Errorf("Validate() error = %v, want %v", err, wantErr)
+	}
+	if calledSecond {
+		t.Error("Validate() called the second validator after the first rejected, want short-circuit")
+	}
+}
+
+func TestMultiValidator_Validate_AcceptsWhenAllValidatorsAccept(t *testing.T) {
+	m := NewMultiValidator(&fakeValidator{}, &fakeValidator{})
+
+	if err := m.Validate(context.Background(), "https://example.com"); err != nil {
+		t.Errorf("Validate() unexpected error = %v", err)
+	}
+}
+
+func TestMultiValidator_Validate_SkipsNilEntries(t *testing.T) {
+	m := NewMultiValidator(nil, &fakeValidator{})
+
+	if err := m.Validate(context.Background(), "https://example.com"); err != nil {
+		t.Errorf("Validate() unexpected error = %v", err)
+	}
+}
+
+func TestNewBlocklistValidator_ReturnsErrorForMissingFile(t *testing.T) {
+	if _, err := NewBlocklistValidator(filepath.Join(t.TempDir(), "missing.txt")); err == nil {
+		t.Error("NewBlocklistValidator() expected error for missing file, got none")
+	}
+}
+
+func TestBlocklistValidator_Validate(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "blocklist.txt")
+	if err := os.WriteFile(path, []byte("# comment\n\nEvil.Example.com\n"), 0o600); err != nil {
+		t.Fatalf("failed to write blocklist fixture: %v", err)
+	}
+
+	v, err := NewBlocklistValidator(path)
+	if err != nil {
+		t.Fatalf("NewBlocklistValidator() unexpected error = %v", err)
+	}
+
+	if err := v.Validate(context.Background(), "https://evil.example.com/phish"); err == nil {
+		t.Error("Validate() expected error for blocklisted host, got none")
+	}
+	if err := v.Validate(context.Background(), "https://safe.example.com"); err != nil {
+		t.Errorf("Validate() unexpected error for non-blocklisted host = %v", err)
+	}
+}
+
+func TestSafeBrowsingValidator_Validate_RejectsOnMatch(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write([]byte(`{"matches":[{"threatType":"MALWARE"}]}`))
+	}))
+	defer server.Close()
+
+	v := NewSafeBrowsingValidator("test-key", server.URL)
+	if err := v.Validate(context.Background(), "https://example.com"); err == nil {
+		t.Error("Validate() expected error for matched threat, got none")
+	}
+}
+
+func TestSafeBrowsingValidator_Validate_AcceptsWhenNoMatch(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write([]byte(`{}`))
+	}))
+	defer server.Close()
+
+	v := NewSafeBrowsingValidator("test-key", server.URL)
+	if err := v.Validate(context.Background(), "https://example.com"); err != nil {
+		t.Errorf("Validate() unexpected error = %v", err)
+	}
+}
+
+func TestSafeBrowsingValidator_Validate_PropagatesNonOKStatus(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		w.WriteHeader(http.StatusInternalServerError)
+	}))
+	defer server.Close()
+
+	v := NewSafeBrowsingValidator("test-key", server.URL)
+	if err := v.Validate(context.Background(), "https://example.com"); err == nil {
+		t.Error("Validate() expected error for non-200 response, got none")
+	}
+}
+
+// validatorFunc adapts a func() error into a Validator so tests can observe whether a given
+// validator was invoked without defining a dedicated type per test case.
+type validatorFunc func() error
+
+func (f validatorFunc) Validate(_ context.Context, _ string) error {
+	return f()
+}
@@ -0,0 +1,87 @@
+// Package tlscert generates a self-signed TLS certificate so -s/ENABLE_HTTPS can be turned
+// on without an operator having to provide certificate material up front.
+package tlscert
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"fmt"
+	"math/big"
+	"net"
+	"os"
+	"time"
+)
+
+// selfSignedValidity is how long a generated certificate remains valid
+const selfSignedValidity = 365 * 24 * time.Hour
+
+// EnsureSelfSigned writes a self-signed TLS certificate and private key to certPath and
+// keyPath if they do not already both exist. It is a no-op, even across restarts, once both
+// files are present, so an operator can drop in real certificate material at those paths
+// instead and it will never be overwritten.
+func EnsureSelfSigned(certPath string, keyPath string) error {
+	if fileExists(certPath) && fileExists(keyPath) {
+		return nil
+	}
+
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		return fmt.Errorf("failed to generate TLS key: %w", err)
+	}
+
+	serialNumber, err := rand.Int(rand.Reader, new(big.Int).Lsh(big.NewInt(1), 128))
+	if err != nil {
+		return fmt.Errorf("failed to generate certificate serial number: %w", err)
+	}
+
+	template := x509.Certificate{
+		SerialNumber: serialNumber,
+		Subject:      pkix.Name{Organization: []string{"shortener self-signed"}},
+		NotBefore:    time.Now(),
+		NotAfter:     time.Now().Add(selfSignedValidity),
+		KeyUsage:     x509.KeyUsageDigitalSignature | x509.KeyUsageCertSign,
+		ExtKeyUsage:  []x509.ExtKeyUsage{x509.ExtKeyUsageServerAuth},
+		IsCA:         true,
+		DNSNames:     []string{"localhost"},
+		IPAddresses:  []net.IP{net.IPv4(127, 0, 0, 1), net.IPv6loopback},
+	}
+
+	derCert, err := x509.CreateCertificate(rand.Reader, &template, &template, &key.PublicKey, key)
+	if err != nil {
+		return fmt.Errorf("failed to create self-signed certificate: %w", err)
+	}
+
+	if err := writePEMFile(certPath, "CERTIFICATE", derCert, 0644); err != nil {
+		return fmt.Errorf("failed to write certificate: %w", err)
+	}
+
+	keyBytes, err := x509.MarshalECPrivateKey(key)
+	if err != nil {
+		return fmt.Errorf("failed to marshal TLS private key: %w", err)
+	}
+	if err := writePEMFile(keyPath, "EC PRIVATE KEY", keyBytes, 0600); err != nil {
+		return fmt.Errorf("failed to write TLS private key: %w", err)
+	}
+
+	return nil
+}
+
+// writePEMFile PEM-encodes der and writes it to path with the given permissions
+func writePEMFile(path string, blockType string, der []byte, perm os.FileMode) error {
+	f, err := os.OpenFile(path, os.O_WRONLY|os.O_CREATE|os.O_TRUNC, perm)
+	if err != nil {
+		return fmt.Errorf("failed to open %s: %w", path, err)
+	}
+	defer f.Close()
+
+	return pem.Encode(f, &pem.Block{Type: blockType, Bytes: der})
+}
+
+func fileExists(path string) bool {
+	_, err := os.Stat(path)
+	return err == nil
+}
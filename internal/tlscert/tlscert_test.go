@@ -0,0 +1,76 @@
+package tlscert
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestEnsureSelfSigned_GeneratesLoadableCertAndKey(t *testing.T) {
+	dir := t.TempDir()
+	certPath := filepath.Join(dir, "server.crt")
+	keyPath := filepath.Join(dir, "server.key")
+
+	if err := EnsureSelfSigned(certPath, keyPath); err != nil {
+		t.Fatalf("EnsureSelfSigned() error = %v", err)
+	}
+
+	cert, err := tls.LoadX509KeyPair(certPath, keyPath)
+	if err != nil {
+		t.Fatalf("generated cert/key did not load: %v", err)
+	}
+	if len(cert.Certificate) == 0 {
+		t.Fatal("loaded certificate has no DER data")
+	}
+}
+
+func TestEnsureSelfSigned_DoesNotOverwriteExistingFiles(t *testing.T) {
+	dir := t.TempDir()
+	certPath := filepath.Join(dir, "server.crt")
+	keyPath := filepath.Join(dir, "server.key")
+
+	const sentinel = "not a real certificate"
+	if err := os.WriteFile(certPath, []byte(sentinel), 0644); err != nil {
+		t.Fatalf("failed to seed cert file: %v", err)
+	}
+	if err := os.WriteFile(keyPath, []byte(sentinel), 0600); err != nil {
+		t.Fatalf("failed to seed key file: %v", err)
+	}
+
+	if err := EnsureSelfSigned(certPath, keyPath); err != nil {
+		t.Fatalf("EnsureSelfSigned() error = %v", err)
+	}
+
+	got, err := os.ReadFile(certPath)
+	if err != nil {
+		t.Fatalf("failed to read cert file: %v", err)
+	}
+	if string(got) != sentinel {
+		t.Errorf("EnsureSelfSigned overwrote an existing certificate file")
+	}
+}
+
+func TestEnsureSelfSigned_CertificateNotYetExpired(t *testing.T) {
+	dir := t.TempDir()
+	certPath := filepath.Join(dir, "server.crt")
+	keyPath := filepath.Join(dir, "server.key")
+
+	if err := EnsureSelfSigned(certPath, keyPath); err != nil {
+		t.Fatalf("EnsureSelfSigned() error = %v", err)
+	}
+
+	cert, err := tls.LoadX509KeyPair(certPath, keyPath)
+	if err != nil {
+		t.Fatalf("generated cert/key did not load: %v", err)
+	}
+	leaf, err := x509.ParseCertificate(cert.Certificate[0])
+	if err != nil {
+		t.Fatalf("failed to parse generated certificate: %v", err)
+	}
+	if time.Now().After(leaf.NotAfter) {
+		t.Errorf("generated certificate already expired at %v", leaf.NotAfter)
+	}
+}